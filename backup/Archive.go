@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// Archives start with a header with the following format:
+//
+//	magic (4 bytes) | version (1 byte) | argon2 time (4 bytes) | argon2 memory in KiB (4 bytes) |
+//	argon2 threads (1 byte) | salt (16 bytes) | nonce (24 bytes)
+//
+// The rest of the archive is a serialized BackupStructure encrypted with
+// XChaCha20-Poly1305, using a key derived from the passphrase with
+// argon2id and the header as associated data. Integers are big endian.
+const (
+	archiveMagic   = "LSBK"
+	ArchiveVersion = 1
+
+	saltSize   = 16
+	headerSize = len(archiveMagic) + 1 + 4 + 4 + 1 + saltSize + chacha20poly1305.NonceSizeX
+)
+
+// Default argon2id parameters for new archives.
+const (
+	DefaultArgon2Time    = 3
+	DefaultArgon2Memory  = 64 * 1024
+	DefaultArgon2Threads = 4
+)
+
+// Limits for the argon2id parameters of archives that are decrypted, so
+// that a crafted archive can't make decryption use unbounded resources.
+const (
+	maxArgon2Time   = 64
+	maxArgon2Memory = 1024 * 1024
+)
+
+// Encrypt serializes the backup and encrypts it with the given passphrase.
+func (b *Backup) Encrypt(passphrase []byte) ([]byte, error) {
+	structure, err := b.structure()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := proto.Marshal(structure)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, archiveMagic...)
+	header = append(header, ArchiveVersion)
+	header = binary.BigEndian.AppendUint32(header, DefaultArgon2Time)
+	header = binary.BigEndian.AppendUint32(header, DefaultArgon2Memory)
+	header = append(header, DefaultArgon2Threads)
+	randomBytes := make([]byte, saltSize+chacha20poly1305.NonceSizeX)
+	// crypto/rand.Read never returns an error.
+	rand.Read(randomBytes)
+	header = append(header, randomBytes...)
+
+	aead, err := newArchiveCipher(header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, header[headerSize-chacha20poly1305.NonceSizeX:], plaintext, header), nil
+}
+
+// Decrypt decrypts an archive with the given passphrase. The records of
+// the returned backup use the given serializer.
+func Decrypt(archive, passphrase []byte, serializer *serialize.Serializer) (*Backup, error) {
+	if len(archive) < headerSize+chacha20poly1305.Overhead || !bytes.HasPrefix(archive, []byte(archiveMagic)) {
+		return nil, signalerror.ErrInvalidBackup
+	}
+	if version := archive[len(archiveMagic)]; version != ArchiveVersion {
+		return nil, fmt.Errorf("%w %d", signalerror.ErrUnknownBackupVersion, version)
+	}
+	header := archive[:headerSize]
+	aead, err := newArchiveCipher(header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := header[headerSize-chacha20poly1305.NonceSizeX:]
+	plaintext, err := aead.Open(nil, nonce, archive[headerSize:], header)
+	if err != nil {
+		return nil, signalerror.ErrBackupDecryptionFailed
+	}
+
+	var structure serialize.BackupStructure
+	if err = proto.Unmarshal(plaintext, &structure); err != nil {
+		return nil, fmt.Errorf("%w: %w", signalerror.ErrInvalidBackup, err)
+	}
+	return newBackupFromStructure(&structure, serializer)
+}
+
+// newArchiveCipher derives the archive key from the passphrase with the
+// argon2id parameters and salt in the given header.
+func newArchiveCipher(header, passphrase []byte) (cipher.AEAD, error) {
+	params := header[len(archiveMagic)+1:]
+	iterations := binary.BigEndian.Uint32(params[0:4])
+	memory := binary.BigEndian.Uint32(params[4:8])
+	threads := params[8]
+	salt := params[9 : 9+saltSize]
+	if iterations == 0 || iterations > maxArgon2Time || memory == 0 || memory > maxArgon2Memory || threads == 0 {
+		return nil, fmt.Errorf("%w (unsupported key derivation parameters)", signalerror.ErrInvalidBackup)
+	}
+
+	key := argon2.IDKey(passphrase, salt, iterations, memory, threads, chacha20poly1305.KeySize)
+	return chacha20poly1305.NewX(key)
+}
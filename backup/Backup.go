@@ -0,0 +1,213 @@
+// Package backup provides export and import of the whole protocol state
+// of a client as a single passphrase-encrypted archive. The archive can be
+// restored into any store, so it can be used to move a client to another
+// server or database without losing its sessions.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// Source is the interface that a store must implement to be backed up.
+// memstore, sqlstore and encstore implement it.
+type Source interface {
+	store.SignalProtocol
+	store.KyberPreKey
+	store.IdentityKeyLoader
+	store.RecordLister
+}
+
+// Identity is the saved identity key of a remote client.
+type Identity struct {
+	Address     *protocol.SignalAddress
+	IdentityKey *identity.Key
+}
+
+// Session is the session record of a remote device, including its
+// archived states.
+type Session struct {
+	Address *protocol.SignalAddress
+	Record  *record.Session
+}
+
+// SenderKey is the sender key record of a group member.
+type SenderKey struct {
+	Name   *protocol.SenderKeyName
+	Record *groupRecord.SenderKey
+}
+
+// Backup is the whole protocol state of a client.
+type Backup struct {
+	IdentityKeyPair *identity.KeyPair
+	RegistrationID  uint32
+	CreatedAt       time.Time
+
+	PreKeys       []*record.PreKey
+	SignedPreKeys []*record.SignedPreKey
+	KyberPreKeys  []*record.KyberPreKey
+	Identities    []*Identity
+	Sessions      []*Session
+	SenderKeys    []*SenderKey
+}
+
+// Export reads the whole state of the given store and returns it as an
+// archive encrypted with the given passphrase.
+func Export(ctx context.Context, source Source, passphrase []byte) ([]byte, error) {
+	backup, err := Read(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return backup.Encrypt(passphrase)
+}
+
+// Read reads the whole state of the given store. If the store supports
+// transactions, the state is read in a single transaction.
+func Read(ctx context.Context, source Source) (*Backup, error) {
+	backup := &Backup{
+		IdentityKeyPair: source.GetIdentityKeyPair(),
+		RegistrationID:  source.GetLocalRegistrationID(),
+		CreatedAt:       time.Now(),
+	}
+	err := store.WithTx(ctx, source, func(ctx context.Context) error {
+		return backup.read(ctx, source)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	return backup, nil
+}
+
+func (b *Backup) read(ctx context.Context, source Source) error {
+	preKeyIDs, err := source.ListPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, preKeyID := range preKeyIDs {
+		preKey, err := source.LoadPreKey(ctx, preKeyID)
+		if err != nil {
+			return err
+		} else if preKey != nil {
+			b.PreKeys = append(b.PreKeys, preKey)
+		}
+	}
+
+	if b.SignedPreKeys, err = source.LoadSignedPreKeys(ctx); err != nil {
+		return err
+	}
+
+	kyberPreKeyIDs, err := source.ListKyberPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, kyberPreKeyID := range kyberPreKeyIDs {
+		kyberPreKey, err := source.LoadKyberPreKey(ctx, kyberPreKeyID)
+		if err != nil {
+			return err
+		} else if kyberPreKey != nil {
+			b.KyberPreKeys = append(b.KyberPreKeys, kyberPreKey)
+		}
+	}
+
+	addresses, err := source.ListIdentities(ctx)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		identityKey, err := source.LoadIdentity(ctx, address)
+		if err != nil {
+			return err
+		} else if identityKey != nil {
+			b.Identities = append(b.Identities, &Identity{Address: address, IdentityKey: identityKey})
+		}
+	}
+
+	if addresses, err = source.ListSessions(ctx); err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		sessionRecord, err := source.LoadSession(ctx, address)
+		if err != nil {
+			return err
+		}
+		b.Sessions = append(b.Sessions, &Session{Address: address, Record: sessionRecord})
+	}
+
+	senderKeyNames, err := source.ListSenderKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, senderKeyName := range senderKeyNames {
+		keyRecord, err := source.LoadSenderKey(ctx, senderKeyName)
+		if err != nil {
+			return err
+		}
+		b.SenderKeys = append(b.SenderKeys, &SenderKey{Name: senderKeyName, Record: keyRecord})
+	}
+
+	return nil
+}
+
+// Restore writes all records of the backup into the given store. The store
+// must be created with the identity key pair and registration ID of the
+// backup, as the sessions can't be used with any other identity, otherwise
+// signalerror.ErrBackupIdentityMismatch is returned. If the store supports
+// transactions, all records are written in a single transaction.
+func (b *Backup) Restore(ctx context.Context, target store.SignalProtocol) error {
+	targetIdentity := target.GetIdentityKeyPair()
+	if targetIdentity == nil || !bytes.Equal(targetIdentity.PublicKey().Serialize(), b.IdentityKeyPair.PublicKey().Serialize()) {
+		return fmt.Errorf("%w (identity key)", signalerror.ErrBackupIdentityMismatch)
+	}
+	if target.GetLocalRegistrationID() != b.RegistrationID {
+		return fmt.Errorf("%w (registration ID %d, store has %d)", signalerror.ErrBackupIdentityMismatch,
+			b.RegistrationID, target.GetLocalRegistrationID())
+	}
+
+	kyberPreKeyStore, _ := target.(store.KyberPreKey)
+	if len(b.KyberPreKeys) > 0 && kyberPreKeyStore == nil {
+		return signalerror.ErrBackupMissingKyberSupport
+	}
+
+	return store.WithTx(ctx, target, func(ctx context.Context) error {
+		for _, preKey := range b.PreKeys {
+			if err := target.StorePreKey(ctx, preKey.ID().Value, preKey); err != nil {
+				return err
+			}
+		}
+		for _, signedPreKey := range b.SignedPreKeys {
+			if err := target.StoreSignedPreKey(ctx, signedPreKey.ID(), signedPreKey); err != nil {
+				return err
+			}
+		}
+		for _, kyberPreKey := range b.KyberPreKeys {
+			if err := kyberPreKeyStore.StoreKyberPreKey(ctx, kyberPreKey.ID(), kyberPreKey); err != nil {
+				return err
+			}
+		}
+		for _, remoteIdentity := range b.Identities {
+			if err := target.SaveIdentity(ctx, remoteIdentity.Address, remoteIdentity.IdentityKey); err != nil {
+				return err
+			}
+		}
+		for _, session := range b.Sessions {
+			if err := target.StoreSession(ctx, session.Address, session.Record); err != nil {
+				return err
+			}
+		}
+		for _, senderKey := range b.SenderKeys {
+			if err := target.StoreSenderKey(ctx, senderKey.Name, senderKey.Record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,171 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/libsignal/ecc"
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// archiveSerializer is used for the records in archives, so that archives
+// don't depend on the serializer of the store they were created from.
+var archiveSerializer = serialize.NewProtoBufSerializer()
+
+// structure returns the protobuf structure of the backup.
+func (b *Backup) structure() (*serialize.BackupStructure, error) {
+	structure := &serialize.BackupStructure{
+		IdentityKeyPair: b.IdentityKeyPair.Serialize(archiveSerializer.IdentityKeyPair),
+		RegistrationId:  &b.RegistrationID,
+		CreatedAt:       proto.Int64(b.CreatedAt.Unix()),
+	}
+
+	// Prekeys are copied into new records to serialize them with the
+	// archive serializer.
+	for _, preKey := range b.PreKeys {
+		structure.PreKeys = append(structure.PreKeys,
+			record.NewPreKey(preKey.ID().Value, preKey.KeyPair(), archiveSerializer.PreKeyRecord).Serialize())
+	}
+	for _, signedPreKey := range b.SignedPreKeys {
+		structure.SignedPreKeys = append(structure.SignedPreKeys, record.NewSignedPreKey(
+			signedPreKey.ID(), signedPreKey.Timestamp(), signedPreKey.KeyPair(),
+			signedPreKey.Signature(), archiveSerializer.SignedPreKeyRecord,
+		).Serialize())
+	}
+	for _, kyberPreKey := range b.KyberPreKeys {
+		structure.KyberPreKeys = append(structure.KyberPreKeys, record.NewKyberPreKey(
+			kyberPreKey.ID(), kyberPreKey.Timestamp(), kyberPreKey.KeyPair(),
+			kyberPreKey.Signature(), archiveSerializer.KyberPreKeyRecord,
+		).Serialize())
+	}
+
+	for _, remoteIdentity := range b.Identities {
+		structure.Identities = append(structure.Identities, &serialize.BackupStructure_Identity{
+			Name:        proto.String(remoteIdentity.Address.Name()),
+			DeviceId:    proto.Uint32(remoteIdentity.Address.DeviceID()),
+			IdentityKey: remoteIdentity.IdentityKey.Serialize(),
+		})
+	}
+	for _, session := range b.Sessions {
+		structure.Sessions = append(structure.Sessions, &serialize.BackupStructure_Session{
+			Name:     proto.String(session.Address.Name()),
+			DeviceId: proto.Uint32(session.Address.DeviceID()),
+			Record:   archiveSerializer.Session.Serialize(session.Record.Structure()),
+		})
+	}
+	for _, senderKey := range b.SenderKeys {
+		sender := senderKey.Name.Sender()
+		structure.SenderKeys = append(structure.SenderKeys, &serialize.BackupStructure_SenderKey{
+			GroupId:        proto.String(senderKey.Name.GroupID()),
+			SenderName:     proto.String(sender.Name()),
+			SenderDeviceId: proto.Uint32(sender.DeviceID()),
+			Record:         archiveSerializer.SenderKeyRecord.Serialize(senderKey.Record.Structure()),
+		})
+	}
+
+	return structure, nil
+}
+
+// newBackupFromStructure returns the backup in the given structure, with
+// records that use the given serializer.
+func newBackupFromStructure(structure *serialize.BackupStructure, serializer *serialize.Serializer) (*Backup, error) {
+	identityKeyPair, err := identity.NewKeyPairFromBytes(structure.GetIdentityKeyPair(), archiveSerializer.IdentityKeyPair)
+	if err != nil {
+		return nil, invalidRecord("identity key pair", err)
+	}
+	backup := &Backup{
+		IdentityKeyPair: identityKeyPair,
+		RegistrationID:  structure.GetRegistrationId(),
+		CreatedAt:       time.Unix(structure.GetCreatedAt(), 0),
+	}
+
+	for _, serialized := range structure.GetPreKeys() {
+		preKeyStructure, err := archiveSerializer.PreKeyRecord.Deserialize(serialized)
+		if err != nil {
+			return nil, invalidRecord("prekey", err)
+		}
+		preKey, err := record.NewPreKeyFromStruct(preKeyStructure, serializer.PreKeyRecord)
+		if err != nil {
+			return nil, invalidRecord("prekey", err)
+		}
+		backup.PreKeys = append(backup.PreKeys, preKey)
+	}
+	for _, serialized := range structure.GetSignedPreKeys() {
+		signedPreKeyStructure, err := archiveSerializer.SignedPreKeyRecord.Deserialize(serialized)
+		if err != nil {
+			return nil, invalidRecord("signed prekey", err)
+		}
+		signedPreKey, err := record.NewSignedPreKeyFromStruct(signedPreKeyStructure, serializer.SignedPreKeyRecord)
+		if err != nil {
+			return nil, invalidRecord("signed prekey", err)
+		}
+		backup.SignedPreKeys = append(backup.SignedPreKeys, signedPreKey)
+	}
+	for _, serialized := range structure.GetKyberPreKeys() {
+		kyberPreKeyStructure, err := archiveSerializer.KyberPreKeyRecord.Deserialize(serialized)
+		if err != nil {
+			return nil, invalidRecord("kyber prekey", err)
+		}
+		kyberPreKey, err := record.NewKyberPreKeyFromStruct(kyberPreKeyStructure, serializer.KyberPreKeyRecord)
+		if err != nil {
+			return nil, invalidRecord("kyber prekey", err)
+		}
+		backup.KyberPreKeys = append(backup.KyberPreKeys, kyberPreKey)
+	}
+
+	for _, remoteIdentity := range structure.GetIdentities() {
+		if len(remoteIdentity.GetIdentityKey()) != ecc.KeySize {
+			return nil, invalidRecord("identity key", fmt.Errorf("key is %d bytes", len(remoteIdentity.GetIdentityKey())))
+		}
+		identityKey, err := ecc.DecodePoint(remoteIdentity.GetIdentityKey(), 0)
+		if err != nil {
+			return nil, invalidRecord("identity key", err)
+		}
+		backup.Identities = append(backup.Identities, &Identity{
+			Address:     protocol.NewSignalAddress(remoteIdentity.GetName(), remoteIdentity.GetDeviceId()),
+			IdentityKey: identity.NewKey(identityKey),
+		})
+	}
+	for _, session := range structure.GetSessions() {
+		sessionStructure, err := archiveSerializer.Session.Deserialize(session.GetRecord())
+		if err != nil {
+			return nil, invalidRecord("session", err)
+		}
+		sessionRecord, err := record.NewSessionFromStructure(sessionStructure, serializer.Session, serializer.State)
+		if err != nil {
+			return nil, invalidRecord("session", err)
+		}
+		backup.Sessions = append(backup.Sessions, &Session{
+			Address: protocol.NewSignalAddress(session.GetName(), session.GetDeviceId()),
+			Record:  sessionRecord,
+		})
+	}
+	for _, senderKey := range structure.GetSenderKeys() {
+		senderKeyStructure, err := archiveSerializer.SenderKeyRecord.Deserialize(senderKey.GetRecord())
+		if err != nil {
+			return nil, invalidRecord("sender key", err)
+		}
+		keyRecord, err := groupRecord.NewSenderKeyFromStruct(senderKeyStructure, serializer.SenderKeyRecord, serializer.SenderKeyState)
+		if err != nil {
+			return nil, invalidRecord("sender key", err)
+		}
+		sender := protocol.NewSignalAddress(senderKey.GetSenderName(), senderKey.GetSenderDeviceId())
+		backup.SenderKeys = append(backup.SenderKeys, &SenderKey{
+			Name:   protocol.NewSenderKeyName(senderKey.GetGroupId(), sender),
+			Record: keyRecord,
+		})
+	}
+
+	return backup, nil
+}
+
+func invalidRecord(kind string, err error) error {
+	return fmt.Errorf("%w (invalid %s: %w)", signalerror.ErrInvalidBackup, kind, err)
+}
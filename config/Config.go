@@ -0,0 +1,137 @@
+package config
+
+import "time"
+
+// Default limits, which are the same as the ones used by libsignal.
+const (
+	DefaultMaxFutureMessages          uint32 = 2000
+	DefaultMaxMessageKeys             int    = 2000
+	DefaultMaxReceiverChains          int    = 5
+	DefaultMaxArchivedStates          int    = 40
+	DefaultMaxSenderKeyStates         int    = 5
+	DefaultMaxSenderMessageKeys       int    = 2000
+	DefaultMaxSenderKeyFutureMessages uint32 = 2000
+)
+
+// Default prekey lifecycle settings, which are the same as the ones used by
+// the Signal apps.
+const (
+	DefaultPreKeyLowWaterMark      int           = 10
+	DefaultPreKeyBatchSize         int           = 100
+	DefaultSignedPreKeyRotation    time.Duration = 2 * 24 * time.Hour
+	DefaultSignedPreKeyGracePeriod time.Duration = 30 * 24 * time.Hour
+)
+
+// Default will return a config with the default limits and settings.
+func Default() *Config {
+	return &Config{
+		MaxFutureMessages:          DefaultMaxFutureMessages,
+		MaxMessageKeys:             DefaultMaxMessageKeys,
+		MaxReceiverChains:          DefaultMaxReceiverChains,
+		MaxArchivedStates:          DefaultMaxArchivedStates,
+		MaxSenderKeyStates:         DefaultMaxSenderKeyStates,
+		MaxSenderMessageKeys:       DefaultMaxSenderMessageKeys,
+		MaxSenderKeyFutureMessages: DefaultMaxSenderKeyFutureMessages,
+		PreKeyLowWaterMark:         DefaultPreKeyLowWaterMark,
+		PreKeyBatchSize:            DefaultPreKeyBatchSize,
+		SignedPreKeyRotation:       DefaultSignedPreKeyRotation,
+		SignedPreKeyGracePeriod:    DefaultSignedPreKeyGracePeriod,
+	}
+}
+
+// Get will return a copy of the first given config with all unset (zero)
+// values replaced with the default values. If no config is given, the
+// default config is returned.
+func Get(cfg ...*Config) *Config {
+	if len(cfg) == 0 || cfg[0] == nil {
+		return Default()
+	}
+	c := *cfg[0]
+	if c.MaxFutureMessages == 0 {
+		c.MaxFutureMessages = DefaultMaxFutureMessages
+	}
+	if c.MaxMessageKeys == 0 {
+		c.MaxMessageKeys = DefaultMaxMessageKeys
+	}
+	if c.MaxReceiverChains == 0 {
+		c.MaxReceiverChains = DefaultMaxReceiverChains
+	}
+	if c.MaxArchivedStates == 0 {
+		c.MaxArchivedStates = DefaultMaxArchivedStates
+	}
+	if c.MaxSenderKeyStates == 0 {
+		c.MaxSenderKeyStates = DefaultMaxSenderKeyStates
+	}
+	if c.MaxSenderMessageKeys == 0 {
+		c.MaxSenderMessageKeys = DefaultMaxSenderMessageKeys
+	}
+	if c.MaxSenderKeyFutureMessages == 0 {
+		c.MaxSenderKeyFutureMessages = DefaultMaxSenderKeyFutureMessages
+	}
+	if c.PreKeyLowWaterMark == 0 {
+		c.PreKeyLowWaterMark = DefaultPreKeyLowWaterMark
+	}
+	if c.PreKeyBatchSize == 0 {
+		c.PreKeyBatchSize = DefaultPreKeyBatchSize
+	}
+	if c.SignedPreKeyRotation == 0 {
+		c.SignedPreKeyRotation = DefaultSignedPreKeyRotation
+	}
+	if c.SignedPreKeyGracePeriod == 0 {
+		c.SignedPreKeyGracePeriod = DefaultSignedPreKeyGracePeriod
+	}
+	return &c
+}
+
+// Config contains the protocol limits and prekey lifecycle settings. Values
+// that are left unset use the default values.
+type Config struct {
+	// MaxFutureMessages is how many messages a received session message
+	// may be ahead of the receiver chain.
+	MaxFutureMessages uint32
+
+	// MaxMessageKeys is how many skipped message keys are kept for each
+	// receiver chain of a session state.
+	MaxMessageKeys int
+
+	// MaxReceiverChains is how many receiver chains are kept in a session
+	// state.
+	MaxReceiverChains int
+
+	// MaxArchivedStates is how many previous session states are kept in a
+	// session record.
+	MaxArchivedStates int
+
+	// MaxSenderKeyStates is how many sender key states are kept in a sender
+	// key record.
+	MaxSenderKeyStates int
+
+	// MaxSenderMessageKeys is how many skipped message keys are kept for
+	// each sender key state.
+	MaxSenderMessageKeys int
+
+	// MaxSenderKeyFutureMessages is how many iterations a received group
+	// message may be ahead of the sender chain.
+	MaxSenderKeyFutureMessages uint32
+
+	// PreKeyLowWaterMark is the number of remaining one-time prekeys below
+	// which the prekey manager generates new ones.
+	PreKeyLowWaterMark int
+
+	// PreKeyBatchSize is the number of one-time prekeys the prekey manager
+	// keeps available after refilling.
+	PreKeyBatchSize int
+
+	// SignedPreKeyRotation is how often the prekey manager replaces the
+	// signed prekey.
+	SignedPreKeyRotation time.Duration
+
+	// SignedPreKeyGracePeriod is how long replaced signed prekeys are kept
+	// after rotation, so that messages sent to them can still be decrypted.
+	SignedPreKeyGracePeriod time.Duration
+
+	// RequireKyber makes session builders reject prekey bundles without a
+	// kyber prekey, instead of falling back to building a session without
+	// post-quantum protection.
+	RequireKyber bool
+}
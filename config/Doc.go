@@ -0,0 +1,5 @@
+// Package config provides the limits that are used by session and group
+// ciphers and their state records, such as how many skipped message keys
+// are kept for out of order messages, whether sessions must use kyber
+// prekeys, and the settings of the prekey manager.
+package config
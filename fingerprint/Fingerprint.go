@@ -11,7 +11,7 @@ func NewFingerprint(displayFingerprint *Display) *Fingerprint {
 // fingerprint for identity verification.
 type Fingerprint struct {
 	fingerprintDisplay *Display
-	fingerprintScan    string
+	fingerprintScan    *ScannableFingerprint
 }
 
 // Display will return a fingerprint display structure for getting a
@@ -20,8 +20,17 @@ func (f *Fingerprint) Display() *Display {
 	return f.fingerprintDisplay
 }
 
-// Scan will return a fingerprint scan structure for getting a scannable
-// representation of given keys.
+// Scan will return the serialized scannable representation of given keys,
+// or an empty string if the fingerprint has no scannable fingerprint.
 func (f *Fingerprint) Scan() string {
+	if f.fingerprintScan == nil {
+		return ""
+	}
+	return string(f.fingerprintScan.Serialize())
+}
+
+// Scannable will return a scannable fingerprint structure that can be
+// compared with the fingerprint scanned from the other user's device.
+func (f *Fingerprint) Scannable() *ScannableFingerprint {
 	return f.fingerprintScan
 }
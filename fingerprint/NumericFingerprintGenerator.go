@@ -0,0 +1,108 @@
+package fingerprint
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"sort"
+
+	"go.mau.fi/libsignal/keys/identity"
+)
+
+// fingerprintVersion is the version that is hashed into every fingerprint.
+const fingerprintVersion = 0
+
+// Lengths of the fingerprint hash that are used for displayable and
+// scannable fingerprints.
+const (
+	displayLength   = 30
+	scannableLength = 32
+)
+
+// NewNumericFingerprintGenerator will return a new fingerprint generator that
+// creates numeric fingerprints compatible with libsignal. The iteration count
+// should be the same as other clients use (5200 for Signal), and the version is
+// the scannable fingerprint version.
+//
+// Each fingerprint is calculated by iterating SHA-512 over the fingerprint
+// version, the identity key and the stable identifier of the user. A higher
+// iteration count makes it more expensive to find a key that matches a target
+// fingerprint.
+func NewNumericFingerprintGenerator(iterations int, version uint32) *NumericFingerprintGenerator {
+	return &NumericFingerprintGenerator{
+		iterations: iterations,
+		version:    version,
+	}
+}
+
+// NumericFingerprintGenerator is a FingerprintGenerator that creates numeric
+// "safety numbers" for identity verification.
+type NumericFingerprintGenerator struct {
+	iterations int
+	version    uint32
+}
+
+// CreateFor will return a fingerprint for the given local and remote identity
+// keys, with the stable identifiers of their users (such as phone numbers).
+func (n *NumericFingerprintGenerator) CreateFor(localStableIdentifier, remoteStableIdentifier string,
+	localIdentityKey, remoteIdentityKey *identity.Key) *Fingerprint {
+
+	return n.CreateForMultiple(
+		localStableIdentifier,
+		remoteStableIdentifier,
+		[]*identity.Key{localIdentityKey},
+		[]*identity.Key{remoteIdentityKey},
+	)
+}
+
+// CreateForMultiple will return a fingerprint for the given lists of local and
+// remote identity keys. This can be used if a user has multiple identity keys.
+func (n *NumericFingerprintGenerator) CreateForMultiple(localStableIdentifier, remoteStableIdentifier string,
+	localIdentityKeys, remoteIdentityKeys []*identity.Key) *Fingerprint {
+
+	localFingerprint := n.fingerprintFor(localStableIdentifier, localIdentityKeys)
+	remoteFingerprint := n.fingerprintFor(remoteStableIdentifier, remoteIdentityKeys)
+
+	fingerprint := NewFingerprint(NewDisplay(
+		localFingerprint[:displayLength],
+		remoteFingerprint[:displayLength],
+	))
+	fingerprint.fingerprintScan = NewScannableFingerprint(
+		n.version,
+		localFingerprint[:scannableLength],
+		remoteFingerprint[:scannableLength],
+	)
+
+	return fingerprint
+}
+
+// fingerprintFor will return the iterated hash of the given stable
+// identifier and identity keys.
+func (n *NumericFingerprintGenerator) fingerprintFor(stableIdentifier string, identityKeys []*identity.Key) []byte {
+	publicKey := logicalKeyBytes(identityKeys)
+	hash := append([]byte{byte(fingerprintVersion >> 8), byte(fingerprintVersion)}, publicKey...)
+	hash = append(hash, []byte(stableIdentifier)...)
+
+	digest := sha512.New()
+	for i := 0; i < n.iterations; i++ {
+		digest.Reset()
+		digest.Write(hash)
+		digest.Write(publicKey)
+		hash = digest.Sum(nil)
+	}
+
+	return hash
+}
+
+// logicalKeyBytes will return the sorted and concatenated bytes of the
+// given identity keys.
+func logicalKeyBytes(identityKeys []*identity.Key) []byte {
+	keys := make([][]byte, len(identityKeys))
+	for i, identityKey := range identityKeys {
+		keys[i] = identityKey.Serialize()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+
+	return bytes.Join(keys, nil)
+}
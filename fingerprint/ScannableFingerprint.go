@@ -0,0 +1,145 @@
+package fingerprint
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/serialize"
+	proto "google.golang.org/protobuf/proto"
+)
+
+// NewScannableFingerprint will return a new scannable fingerprint with the
+// given version and local and remote fingerprint hashes.
+func NewScannableFingerprint(version uint32, localFingerprint, remoteFingerprint []byte) *ScannableFingerprint {
+	return &ScannableFingerprint{
+		version:           version,
+		localFingerprint:  localFingerprint,
+		remoteFingerprint: remoteFingerprint,
+	}
+}
+
+// NewScannableFingerprintWithIdentifiers will return a new scannable
+// fingerprint that also includes the stable identifiers of both users.
+// Identifiers were only included by version 0 fingerprints.
+func NewScannableFingerprintWithIdentifiers(version uint32, localIdentifier, localFingerprint,
+	remoteIdentifier, remoteFingerprint []byte) *ScannableFingerprint {
+
+	scannable := NewScannableFingerprint(version, localFingerprint, remoteFingerprint)
+	scannable.localIdentifier = localIdentifier
+	scannable.remoteIdentifier = remoteIdentifier
+
+	return scannable
+}
+
+// ScannableFingerprint is a structure for fingerprints that can be encoded
+// into a QR code and compared with the fingerprint scanned from the other
+// user's device.
+type ScannableFingerprint struct {
+	version           uint32
+	localIdentifier   []byte
+	localFingerprint  []byte
+	remoteIdentifier  []byte
+	remoteFingerprint []byte
+}
+
+// Version returns the version of the scannable fingerprint.
+func (s *ScannableFingerprint) Version() uint32 {
+	return s.version
+}
+
+// Serialize will return the scannable fingerprint as the protobuf payload
+// that is encoded into QR codes.
+func (s *ScannableFingerprint) Serialize() []byte {
+	combinedFingerprints := &serialize.CombinedFingerprints{
+		Version: &s.version,
+		LocalFingerprint: &serialize.LogicalFingerprint{
+			Content:    s.localFingerprint,
+			Identifier: s.localIdentifier,
+		},
+		RemoteFingerprint: &serialize.LogicalFingerprint{
+			Content:    s.remoteFingerprint,
+			Identifier: s.remoteIdentifier,
+		},
+	}
+
+	serialized, err := proto.Marshal(combinedFingerprints)
+	if err != nil {
+		logger.Error("Error serializing scannable fingerprint: ", err)
+	}
+
+	return serialized
+}
+
+// CompareTo will compare this fingerprint with the one scanned from the
+// other user's device. The scanned fingerprint's local fingerprint must match
+// our remote fingerprint and vice versa. It returns nil if the fingerprints
+// match, or a *VersionMismatchError, *IdentifierMismatchError or
+// *KeyMismatchError describing why they don't.
+func (s *ScannableFingerprint) CompareTo(scanned []byte) error {
+	var combinedFingerprints serialize.CombinedFingerprints
+	err := proto.Unmarshal(scanned, &combinedFingerprints)
+	if err != nil {
+		return fmt.Errorf("failed to parse scanned fingerprint: %w", err)
+	}
+
+	if combinedFingerprints.GetVersion() != s.version {
+		return &VersionMismatchError{
+			OurVersion:   s.version,
+			TheirVersion: combinedFingerprints.GetVersion(),
+		}
+	}
+
+	theirLocal := combinedFingerprints.GetLocalFingerprint()
+	theirRemote := combinedFingerprints.GetRemoteFingerprint()
+	if !bytes.Equal(s.localIdentifier, theirRemote.GetIdentifier()) ||
+		!bytes.Equal(s.remoteIdentifier, theirLocal.GetIdentifier()) {
+		return &IdentifierMismatchError{
+			LocalIdentifier:  string(s.localIdentifier),
+			RemoteIdentifier: string(s.remoteIdentifier),
+			ScannedLocal:     string(theirLocal.GetIdentifier()),
+			ScannedRemote:    string(theirRemote.GetIdentifier()),
+		}
+	}
+
+	if subtle.ConstantTimeCompare(s.localFingerprint, theirRemote.GetContent()) != 1 ||
+		subtle.ConstantTimeCompare(s.remoteFingerprint, theirLocal.GetContent()) != 1 {
+		return &KeyMismatchError{}
+	}
+
+	return nil
+}
+
+// VersionMismatchError is returned when a scanned fingerprint has a
+// different version than ours.
+type VersionMismatchError struct {
+	OurVersion   uint32
+	TheirVersion uint32
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("fingerprint version mismatch: ours is %d, theirs is %d", e.OurVersion, e.TheirVersion)
+}
+
+// IdentifierMismatchError is returned when a scanned fingerprint was
+// created for different stable identifiers than ours.
+type IdentifierMismatchError struct {
+	LocalIdentifier  string
+	RemoteIdentifier string
+	ScannedLocal     string
+	ScannedRemote    string
+}
+
+func (e *IdentifierMismatchError) Error() string {
+	return fmt.Sprintf("fingerprint identifier mismatch: expected %q and %q, scanned %q and %q",
+		e.RemoteIdentifier, e.LocalIdentifier, e.ScannedLocal, e.ScannedRemote)
+}
+
+// KeyMismatchError is returned when a scanned fingerprint was created for
+// different identity keys than ours.
+type KeyMismatchError struct{}
+
+func (e *KeyMismatchError) Error() string {
+	return "fingerprint key mismatch"
+}
@@ -1,6 +1,6 @@
 module go.mau.fi/libsignal
 
-go 1.23.0
+go 1.24.0
 
 toolchain go1.24.3
 
@@ -9,3 +9,5 @@ require (
 	golang.org/x/crypto v0.38.0
 	google.golang.org/protobuf v1.36.6
 )
+
+require golang.org/x/sys v0.33.0 // indirect
@@ -5,37 +5,79 @@ import (
 	"fmt"
 
 	"go.mau.fi/libsignal/cipher"
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/groups/ratchet"
 	"go.mau.fi/libsignal/groups/state/record"
 	"go.mau.fi/libsignal/groups/state/store"
 	"go.mau.fi/libsignal/protocol"
 	"go.mau.fi/libsignal/signalerror"
+	signalStore "go.mau.fi/libsignal/state/store"
+	"go.mau.fi/libsignal/util/keylock"
 )
 
 // NewGroupCipher will return a new group message cipher that can be used for
-// encrypt/decrypt operations.
+// encrypt/decrypt operations. If no config is given, the config of the builder
+// is used.
 func NewGroupCipher(builder *SessionBuilder, senderKeyID *protocol.SenderKeyName,
-	senderKeyStore store.SenderKey) *GroupCipher {
+	senderKeyStore store.SenderKey, cfg ...*config.Config) *GroupCipher {
 
+	cipherConfig := builder.config
+	if len(cfg) > 0 {
+		cipherConfig = config.Get(cfg...)
+	}
 	return &GroupCipher{
 		senderKeyID:    senderKeyID,
 		senderKeyStore: senderKeyStore,
 		sessionBuilder: builder,
+		locker:         keylock.Default,
+		config:         cipherConfig,
 	}
 }
 
 // GroupCipher is the main entry point for group encrypt/decrypt operations.
 // Once a session has been established, this can be used for
 // all encrypt/decrypt operations within that session.
+//
+// Encrypt and decrypt operations hold a lock for the sender key name, so
+// ciphers for the same sender key can be used from multiple goroutines.
+// If the sender key store implements store.Transactional, the store calls
+// of each operation are run in a single transaction. The limits of the
+// cipher's config are applied to the sender key records it decrypts with.
 type GroupCipher struct {
 	senderKeyID    *protocol.SenderKeyName
 	senderKeyStore store.SenderKey
 	sessionBuilder *SessionBuilder
+	locker         keylock.Locker
+	config         *config.Config
+}
+
+// SetLocker sets the locker used to serialize operations on the sender key.
+// By default, all group ciphers share keylock.Default, which is only enough
+// if a single process uses the stores.
+func (c *GroupCipher) SetLocker(locker keylock.Locker) {
+	c.locker = locker
 }
 
 // Encrypt will take the given message in bytes and return encrypted bytes.
 func (c *GroupCipher) Encrypt(ctx context.Context, plaintext []byte) (protocol.GroupCiphertextMessage, error) {
+	unlock, err := c.locker.Lock(ctx, c.senderKeyID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var message protocol.GroupCiphertextMessage
+	err = signalStore.WithTx(ctx, c.senderKeyStore, func(ctx context.Context) (err error) {
+		message, err = c.encrypt(ctx, plaintext)
+		return err
+	})
+	return message, err
+}
+
+// encrypt will encrypt the given message using the stored sender key. The
+// caller must hold the lock for the sender key name.
+func (c *GroupCipher) encrypt(ctx context.Context, plaintext []byte) (protocol.GroupCiphertextMessage, error) {
 	// Load the sender key based on id from our store.
 	keyRecord, err := c.senderKeyStore.LoadSenderKey(ctx, c.senderKeyID)
 	if err != nil {
@@ -77,6 +119,23 @@ func (c *GroupCipher) Encrypt(ctx context.Context, plaintext []byte) (protocol.G
 // Decrypt decrypts the given message using an existing session that
 // is stored in the senderKey store.
 func (c *GroupCipher) Decrypt(ctx context.Context, senderKeyMessage *protocol.SenderKeyMessage) ([]byte, error) {
+	unlock, err := c.locker.Lock(ctx, c.senderKeyID.String())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var plaintext []byte
+	err = signalStore.WithTx(ctx, c.senderKeyStore, func(ctx context.Context) (err error) {
+		plaintext, err = c.decrypt(ctx, senderKeyMessage)
+		return err
+	})
+	return plaintext, err
+}
+
+// decrypt will decrypt the given message using the stored sender key. The
+// caller must hold the lock for the sender key name.
+func (c *GroupCipher) decrypt(ctx context.Context, senderKeyMessage *protocol.SenderKeyMessage) ([]byte, error) {
 	keyRecord, err := c.senderKeyStore.LoadSenderKey(ctx, c.senderKeyID)
 	if err != nil {
 		return nil, err
@@ -85,6 +144,7 @@ func (c *GroupCipher) Decrypt(ctx context.Context, senderKeyMessage *protocol.Se
 	if keyRecord.IsEmpty() {
 		return nil, fmt.Errorf("%w for %s in %s", signalerror.ErrNoSenderKeyForUser, c.senderKeyID.Sender().String(), c.senderKeyID.GroupID())
 	}
+	keyRecord.SetConfig(c.config)
 
 	// Get the senderkey state by id.
 	senderKeyState, err := keyRecord.GetSenderKeyStateByID(senderKeyMessage.KeyID())
@@ -134,8 +194,9 @@ func (c *GroupCipher) getSenderKey(senderKeyState *record.SenderKeyState, iterat
 		return nil, fmt.Errorf("%w (current: %d, received: %d)", signalerror.ErrOldCounter, senderChainKey.Iteration(), iteration)
 	}
 
-	if iteration-senderChainKey.Iteration() > 2000 {
-		return nil, signalerror.ErrTooFarIntoFuture
+	if iteration-senderChainKey.Iteration() > c.config.MaxSenderKeyFutureMessages {
+		return nil, fmt.Errorf("%w (current: %d, received: %d, max: %d)", signalerror.ErrTooFarIntoFuture,
+			senderChainKey.Iteration(), iteration, c.config.MaxSenderKeyFutureMessages)
 	}
 
 	for senderChainKey.Iteration() < iteration {
@@ -11,27 +11,35 @@ package groups
 import (
 	"context"
 
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/groups/state/record"
 	"go.mau.fi/libsignal/groups/state/store"
 	"go.mau.fi/libsignal/protocol"
 	"go.mau.fi/libsignal/serialize"
+	signalStore "go.mau.fi/libsignal/state/store"
 	"go.mau.fi/libsignal/util/keyhelper"
 )
 
-// NewGroupSessionBuilder will return a new group session builder.
+// NewGroupSessionBuilder will return a new group session builder. If no
+// config is given, the default limits are used.
 func NewGroupSessionBuilder(senderKeyStore store.SenderKey,
-	serializer *serialize.Serializer) *SessionBuilder {
+	serializer *serialize.Serializer, cfg ...*config.Config) *SessionBuilder {
 
 	return &SessionBuilder{
 		senderKeyStore: senderKeyStore,
 		serializer:     serializer,
+		config:         config.Get(cfg...),
 	}
 }
 
-// SessionBuilder is a structure for building group sessions.
+// SessionBuilder is a structure for building group sessions. If the sender
+// key store implements store.Transactional, the store calls of each
+// operation are run in a single transaction. The limits of the builder's
+// config are applied to the sender key records it loads.
 type SessionBuilder struct {
 	senderKeyStore store.SenderKey
 	serializer     *serialize.Serializer
+	config         *config.Config
 }
 
 // Process will process an incoming group message and set up the corresponding
@@ -39,19 +47,39 @@ type SessionBuilder struct {
 func (b *SessionBuilder) Process(ctx context.Context, senderKeyName *protocol.SenderKeyName,
 	msg *protocol.SenderKeyDistributionMessage) error {
 
+	return signalStore.WithTx(ctx, b.senderKeyStore, func(ctx context.Context) error {
+		return b.process(ctx, senderKeyName, msg)
+	})
+}
+
+// process will set up the session for the given distribution message.
+func (b *SessionBuilder) process(ctx context.Context, senderKeyName *protocol.SenderKeyName,
+	msg *protocol.SenderKeyDistributionMessage) error {
+
 	senderKeyRecord, err := b.senderKeyStore.LoadSenderKey(ctx, senderKeyName)
 	if err != nil {
 		return err
 	}
 	if senderKeyRecord == nil {
-		senderKeyRecord = record.NewSenderKey(b.serializer.SenderKeyRecord, b.serializer.SenderKeyState)
+		senderKeyRecord = record.NewSenderKey(b.serializer.SenderKeyRecord, b.serializer.SenderKeyState, b.config)
 	}
+	senderKeyRecord.SetConfig(b.config)
 	senderKeyRecord.AddSenderKeyState(msg.ID(), msg.Iteration(), msg.ChainKey(), msg.SignatureKey())
 	return b.senderKeyStore.StoreSenderKey(ctx, senderKeyName, senderKeyRecord)
 }
 
 // Create will create a new group session for the given name.
 func (b *SessionBuilder) Create(ctx context.Context, senderKeyName *protocol.SenderKeyName) (*protocol.SenderKeyDistributionMessage, error) {
+	var message *protocol.SenderKeyDistributionMessage
+	err := signalStore.WithTx(ctx, b.senderKeyStore, func(ctx context.Context) (err error) {
+		message, err = b.create(ctx, senderKeyName)
+		return err
+	})
+	return message, err
+}
+
+// create will create a new group session for the given name.
+func (b *SessionBuilder) create(ctx context.Context, senderKeyName *protocol.SenderKeyName) (*protocol.SenderKeyDistributionMessage, error) {
 	// Load the senderkey by name
 	senderKeyRecord, err := b.senderKeyStore.LoadSenderKey(ctx, senderKeyName)
 	if err != nil {
@@ -60,7 +88,7 @@ func (b *SessionBuilder) Create(ctx context.Context, senderKeyName *protocol.Sen
 
 	// If the record is empty, generate new keys.
 	if senderKeyRecord == nil || senderKeyRecord.IsEmpty() {
-		senderKeyRecord = record.NewSenderKey(b.serializer.SenderKeyRecord, b.serializer.SenderKeyState)
+		senderKeyRecord = record.NewSenderKey(b.serializer.SenderKeyRecord, b.serializer.SenderKeyState, b.config)
 		signingKey, err := keyhelper.GenerateSenderSigningKey()
 		if err != nil {
 			return nil, err
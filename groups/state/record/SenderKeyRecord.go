@@ -3,12 +3,11 @@ package record
 import (
 	"fmt"
 
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/signalerror"
 )
 
-const maxStates = 5
-
 // SenderKeySerializer is an interface for serializing and deserializing
 // SenderKey objects into bytes. An implementation of this interface should be
 // used to encode/decode the object into JSON, Protobuffers, etc.
@@ -17,9 +16,10 @@ type SenderKeySerializer interface {
 	Deserialize(serialized []byte) (*SenderKeyStructure, error)
 }
 
-// NewSenderKeyFromBytes will return a prekey record from the given bytes using the given serializer.
+// NewSenderKeyFromBytes will return a prekey record from the given bytes using the given serializer
+// and optional config.
 func NewSenderKeyFromBytes(serialized []byte, serializer SenderKeySerializer,
-	stateSerializer SenderKeyStateSerializer) (*SenderKey, error) {
+	stateSerializer SenderKeyStateSerializer, cfg ...*config.Config) (*SenderKey, error) {
 
 	// Use the given serializer to decode the senderkey record
 	senderKeyStructure, err := serializer.Deserialize(serialized)
@@ -27,18 +27,21 @@ func NewSenderKeyFromBytes(serialized []byte, serializer SenderKeySerializer,
 		return nil, err
 	}
 
-	return NewSenderKeyFromStruct(senderKeyStructure, serializer, stateSerializer)
+	return NewSenderKeyFromStruct(senderKeyStructure, serializer, stateSerializer, cfg...)
 }
 
-// NewSenderKeyFromStruct returns a SenderKey record using the given serializable structure.
+// NewSenderKeyFromStruct returns a SenderKey record using the given serializable structure
+// and optional config.
 func NewSenderKeyFromStruct(structure *SenderKeyStructure, serializer SenderKeySerializer,
-	stateSerializer SenderKeyStateSerializer) (*SenderKey, error) {
+	stateSerializer SenderKeyStateSerializer, cfg ...*config.Config) (*SenderKey, error) {
+
+	recordConfig := config.Get(cfg...)
 
 	// Build our sender key states from structure.
 	senderKeyStates := make([]*SenderKeyState, len(structure.SenderKeyStates))
 	for i := range structure.SenderKeyStates {
 		var err error
-		senderKeyStates[i], err = NewSenderKeyStateFromStructure(structure.SenderKeyStates[i], stateSerializer)
+		senderKeyStates[i], err = NewSenderKeyStateFromStructure(structure.SenderKeyStates[i], stateSerializer, recordConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -48,6 +51,8 @@ func NewSenderKeyFromStruct(structure *SenderKeyStructure, serializer SenderKeyS
 	senderKey := &SenderKey{
 		senderKeyStates: senderKeyStates,
 		serializer:      serializer,
+		stateSerializer: stateSerializer,
+		config:          recordConfig,
 	}
 
 	return senderKey, nil
@@ -55,14 +60,16 @@ func NewSenderKeyFromStruct(structure *SenderKeyStructure, serializer SenderKeyS
 }
 
 // NewSenderKey record returns a new sender key record that can
-// be stored in a SenderKeyStore.
+// be stored in a SenderKeyStore. If no config is given, the default
+// limits are used.
 func NewSenderKey(serializer SenderKeySerializer,
-	stateSerializer SenderKeyStateSerializer) *SenderKey {
+	stateSerializer SenderKeyStateSerializer, cfg ...*config.Config) *SenderKey {
 
 	return &SenderKey{
 		senderKeyStates: []*SenderKeyState{},
 		serializer:      serializer,
 		stateSerializer: stateSerializer,
+		config:          config.Get(cfg...),
 	}
 }
 
@@ -77,6 +84,16 @@ type SenderKey struct {
 	senderKeyStates []*SenderKeyState
 	serializer      SenderKeySerializer
 	stateSerializer SenderKeyStateSerializer
+	config          *config.Config
+}
+
+// SetConfig sets the limits that are used for this sender key record and
+// all of its sender key states.
+func (k *SenderKey) SetConfig(cfg *config.Config) {
+	k.config = config.Get(cfg)
+	for _, state := range k.senderKeyStates {
+		state.config = k.config
+	}
 }
 
 // SenderKeyState will return the first sender key state in the record's
@@ -111,10 +128,10 @@ func (k *SenderKey) IsEmpty() bool {
 func (k *SenderKey) AddSenderKeyState(id uint32, iteration uint32,
 	chainKey []byte, signatureKey ecc.ECPublicKeyable) {
 
-	newState := NewSenderKeyStateFromPublicKey(id, iteration, chainKey, signatureKey, k.stateSerializer)
+	newState := NewSenderKeyStateFromPublicKey(id, iteration, chainKey, signatureKey, k.stateSerializer, k.config)
 	k.senderKeyStates = append([]*SenderKeyState{newState}, k.senderKeyStates...)
 
-	if len(k.senderKeyStates) > maxStates {
+	if len(k.senderKeyStates) > k.config.MaxSenderKeyStates {
 		k.senderKeyStates = k.senderKeyStates[:len(k.senderKeyStates)-1]
 	}
 }
@@ -124,8 +141,8 @@ func (k *SenderKey) AddSenderKeyState(id uint32, iteration uint32,
 func (k *SenderKey) SetSenderKeyState(id uint32, iteration uint32,
 	chainKey []byte, signatureKey *ecc.ECKeyPair) {
 
-	newState := NewSenderKeyState(id, iteration, chainKey, signatureKey, k.stateSerializer)
-	k.senderKeyStates = make([]*SenderKeyState, 0, maxStates/2)
+	newState := NewSenderKeyState(id, iteration, chainKey, signatureKey, k.stateSerializer, k.config)
+	k.senderKeyStates = make([]*SenderKeyState, 0, k.config.MaxSenderKeyStates/2)
 	k.senderKeyStates = append(k.senderKeyStates, newState)
 }
 
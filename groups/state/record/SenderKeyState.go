@@ -1,13 +1,12 @@
 package record
 
 import (
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/groups/ratchet"
 	"go.mau.fi/libsignal/util/bytehelper"
 )
 
-const maxMessageKeys = 2000
-
 // SenderKeyStateSerializer is an interface for serializing and deserializing
 // a Signal State into bytes. An implementation of this interface should be
 // used to encode/decode the object into JSON, Protobuffers, etc.
@@ -17,42 +16,49 @@ type SenderKeyStateSerializer interface {
 }
 
 // NewSenderKeyStateFromBytes will return a Signal State from the given
-// bytes using the given serializer.
-func NewSenderKeyStateFromBytes(serialized []byte, serializer SenderKeyStateSerializer) (*SenderKeyState, error) {
+// bytes using the given serializer and optional config.
+func NewSenderKeyStateFromBytes(serialized []byte, serializer SenderKeyStateSerializer,
+	cfg ...*config.Config) (*SenderKeyState, error) {
+
 	// Use the given serializer to decode the signal message.
 	stateStructure, err := serializer.Deserialize(serialized)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewSenderKeyStateFromStructure(stateStructure, serializer)
+	return NewSenderKeyStateFromStructure(stateStructure, serializer, cfg...)
 }
 
-// NewSenderKeyState returns a new SenderKeyState.
+// NewSenderKeyState returns a new SenderKeyState with the given optional config.
 func NewSenderKeyState(keyID uint32, iteration uint32, chainKey []byte,
-	signatureKey *ecc.ECKeyPair, serializer SenderKeyStateSerializer) *SenderKeyState {
+	signatureKey *ecc.ECKeyPair, serializer SenderKeyStateSerializer, cfg ...*config.Config) *SenderKeyState {
 
+	stateConfig := config.Get(cfg...)
 	return &SenderKeyState{
-		keys:           make([]*ratchet.SenderMessageKey, 0, maxMessageKeys/2),
+		keys:           make([]*ratchet.SenderMessageKey, 0, stateConfig.MaxSenderMessageKeys/2),
 		keyID:          keyID,
 		senderChainKey: ratchet.NewSenderChainKey(iteration, chainKey),
 		signingKeyPair: signatureKey,
 		serializer:     serializer,
+		config:         stateConfig,
 	}
 }
 
-// NewSenderKeyStateFromPublicKey returns a new SenderKeyState with the given publicKey.
+// NewSenderKeyStateFromPublicKey returns a new SenderKeyState with the given publicKey
+// and optional config.
 func NewSenderKeyStateFromPublicKey(keyID uint32, iteration uint32, chainKey []byte,
-	signatureKey ecc.ECPublicKeyable, serializer SenderKeyStateSerializer) *SenderKeyState {
+	signatureKey ecc.ECPublicKeyable, serializer SenderKeyStateSerializer, cfg ...*config.Config) *SenderKeyState {
 
 	keyPair := ecc.NewECKeyPair(signatureKey, nil)
+	stateConfig := config.Get(cfg...)
 
 	return &SenderKeyState{
-		keys:           make([]*ratchet.SenderMessageKey, 0, maxMessageKeys/2),
+		keys:           make([]*ratchet.SenderMessageKey, 0, stateConfig.MaxSenderMessageKeys/2),
 		keyID:          keyID,
 		senderChainKey: ratchet.NewSenderChainKey(iteration, chainKey),
 		signingKeyPair: keyPair,
 		serializer:     serializer,
+		config:         stateConfig,
 	}
 }
 
@@ -60,7 +66,7 @@ func NewSenderKeyStateFromPublicKey(keyID uint32, iteration uint32, chainKey []b
 // given state structure. This structure is given back from an
 // implementation of the sender key state serializer.
 func NewSenderKeyStateFromStructure(structure *SenderKeyStateStructure,
-	serializer SenderKeyStateSerializer) (*SenderKeyState, error) {
+	serializer SenderKeyStateSerializer, cfg ...*config.Config) (*SenderKeyState, error) {
 
 	// Convert our ecc keys from bytes into object form.
 	signingKeyPublic, err := ecc.DecodePoint(structure.SigningKeyPublic, 0)
@@ -82,6 +88,7 @@ func NewSenderKeyStateFromStructure(structure *SenderKeyStateStructure,
 		senderChainKey: ratchet.NewSenderChainKeyFromStruct(structure.SenderChainKey),
 		signingKeyPair: ecc.NewECKeyPair(signingKeyPublic, signingKeyPrivate),
 		serializer:     serializer,
+		config:         config.Get(cfg...),
 	}
 
 	return state, nil
@@ -103,6 +110,7 @@ type SenderKeyState struct {
 	senderChainKey *ratchet.SenderChainKey
 	signingKeyPair *ecc.ECKeyPair
 	serializer     SenderKeyStateSerializer
+	config         *config.Config
 }
 
 // SigningKey returns the signing key pair of the sender key state.
@@ -135,7 +143,7 @@ func (k *SenderKeyState) HasSenderMessageKey(iteration uint32) bool {
 func (k *SenderKeyState) AddSenderMessageKey(senderMsgKey *ratchet.SenderMessageKey) {
 	k.keys = append(k.keys, senderMsgKey)
 
-	if len(k.keys) > maxMessageKeys {
+	if len(k.keys) > k.config.MaxSenderMessageKeys {
 		k.keys = k.keys[1:]
 	}
 }
@@ -0,0 +1,3 @@
+// Package kem provides a way to generate and use ML-KEM-1024 key encapsulation
+// keys, which are used for post-quantum prekeys (PQXDH).
+package kem
@@ -0,0 +1,144 @@
+package kem
+
+import (
+	"crypto/mlkem"
+	"errors"
+	"fmt"
+)
+
+// MLKEM1024Type is the key type of ML-KEM-1024 keys and ciphertexts.
+const MLKEM1024Type = 0x0A
+
+var (
+	ErrBadKeyType    = errors.New("bad KEM key type")
+	ErrBadKeyLength  = errors.New("bad KEM key length")
+	ErrBadCiphertext = errors.New("bad KEM ciphertext")
+)
+
+// GenerateKeyPair returns a new ML-KEM-1024 key pair.
+func GenerateKeyPair() (*KeyPair, error) {
+	decapsulationKey, err := mlkem.GenerateKey1024()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyPair(
+		&PublicKey{key: decapsulationKey.EncapsulationKey()},
+		&PrivateKey{key: decapsulationKey},
+	), nil
+}
+
+// DecodePublicKey will take the given bytes and return a KEM public key. The
+// first byte must be the key type.
+func DecodePublicKey(serialized []byte) (*PublicKey, error) {
+	keyBytes, err := stripType(serialized)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyBytes) != mlkem.EncapsulationKeySize1024 {
+		return nil, fmt.Errorf("%w %d (public key)", ErrBadKeyLength, len(keyBytes))
+	}
+	key, err := mlkem.NewEncapsulationKey1024(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{key: key}, nil
+}
+
+// DecodePrivateKey will take the given bytes and return a KEM private key. The
+// first byte must be the key type, followed by the 64 byte ML-KEM seed.
+func DecodePrivateKey(serialized []byte) (*PrivateKey, error) {
+	keyBytes, err := stripType(serialized)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyBytes) != mlkem.SeedSize {
+		return nil, fmt.Errorf("%w %d (private key)", ErrBadKeyLength, len(keyBytes))
+	}
+	key, err := mlkem.NewDecapsulationKey1024(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{key: key}, nil
+}
+
+// stripType checks the key type of the given bytes and returns the key
+// bytes without it.
+func stripType(serialized []byte) ([]byte, error) {
+	if len(serialized) == 0 {
+		return nil, fmt.Errorf("%w 0", ErrBadKeyLength)
+	}
+	if serialized[0] != MLKEM1024Type {
+		return nil, fmt.Errorf("%w %d", ErrBadKeyType, serialized[0])
+	}
+	return serialized[1:], nil
+}
+
+// PublicKey is an ML-KEM-1024 encapsulation key.
+type PublicKey struct {
+	key *mlkem.EncapsulationKey1024
+}
+
+// Serialize returns the type-prefixed bytes of the public key.
+func (p *PublicKey) Serialize() []byte {
+	return append([]byte{MLKEM1024Type}, p.key.Bytes()...)
+}
+
+// Encapsulate generates a new shared secret for this public key. It returns the
+// shared secret and the type-prefixed ciphertext that should be sent to the owner
+// of the key.
+func (p *PublicKey) Encapsulate() (sharedSecret, ciphertext []byte) {
+	sharedSecret, rawCiphertext := p.key.Encapsulate()
+	return sharedSecret, append([]byte{MLKEM1024Type}, rawCiphertext...)
+}
+
+// PrivateKey is an ML-KEM-1024 decapsulation key.
+type PrivateKey struct {
+	key *mlkem.DecapsulationKey1024
+}
+
+// Serialize returns the type-prefixed seed of the private key. libsignal
+// stores the expanded decapsulation key instead, which can't be decoded by
+// DecodePrivateKey.
+func (p *PrivateKey) Serialize() []byte {
+	return append([]byte{MLKEM1024Type}, p.key.Bytes()...)
+}
+
+// Decapsulate returns the shared secret from the given type-prefixed ciphertext.
+func (p *PrivateKey) Decapsulate(ciphertext []byte) ([]byte, error) {
+	rawCiphertext, err := stripType(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawCiphertext) != mlkem.CiphertextSize1024 {
+		return nil, fmt.Errorf("%w: length %d", ErrBadCiphertext, len(rawCiphertext))
+	}
+
+	return p.key.Decapsulate(rawCiphertext)
+}
+
+// NewKeyPair returns a new KEM key pair given the specified public and private keys.
+func NewKeyPair(publicKey *PublicKey, privateKey *PrivateKey) *KeyPair {
+	return &KeyPair{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}
+}
+
+// KeyPair is a combination of both public and private KEM keys.
+type KeyPair struct {
+	publicKey  *PublicKey
+	privateKey *PrivateKey
+}
+
+// PublicKey returns the public key from the key pair.
+func (k *KeyPair) PublicKey() *PublicKey {
+	return k.publicKey
+}
+
+// PrivateKey returns the private key from the key pair.
+func (k *KeyPair) PrivateKey() *PrivateKey {
+	return k.privateKey
+}
@@ -1,9 +1,24 @@
 package identity
 
 import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/crypto/curve25519"
+
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/bytehelper"
 )
 
+// KeyPairSerializer is an interface for serializing and deserializing
+// identity key pairs into bytes. An implementation of this interface should
+// be used to encode/decode the object into JSON, Protobuffers, etc.
+type KeyPairSerializer interface {
+	Serialize(keyPair *KeyPairStructure) []byte
+	Deserialize(serialized []byte) (*KeyPairStructure, error)
+}
+
 // NewKeyPair returns a new identity key with the given public and private keys.
 func NewKeyPair(publicKey *Key, privateKey ecc.ECPrivateKeyable) *KeyPair {
 	keyPair := KeyPair{
@@ -14,9 +29,47 @@ func NewKeyPair(publicKey *Key, privateKey ecc.ECPrivateKeyable) *KeyPair {
 	return &keyPair
 }
 
-// NewKeyPairFromBytes returns a new identity key from the given serialized bytes.
-//func NewKeyPairFromBytes(serialized []byte) KeyPair {
-//}
+// NewKeyPairFromBytes returns a new identity key pair from the given
+// serialized bytes using the given serializer.
+func NewKeyPairFromBytes(serialized []byte, serializer KeyPairSerializer) (*KeyPair, error) {
+	// Use the given serializer to decode the key pair.
+	keyPairStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyPairFromStruct(keyPairStructure)
+}
+
+// NewKeyPairFromStruct returns a new identity key pair using the given
+// serializable structure. The public key must belong to the private key.
+func NewKeyPairFromStruct(structure *KeyPairStructure) (*KeyPair, error) {
+	if len(structure.PublicKey) != ecc.KeySize {
+		return nil, fmt.Errorf("%w (public key is %d bytes)", signalerror.ErrInvalidIdentityKeyPair, len(structure.PublicKey))
+	}
+	if len(structure.PrivateKey) != 32 {
+		return nil, fmt.Errorf("%w (private key is %d bytes)", signalerror.ErrInvalidIdentityKeyPair, len(structure.PrivateKey))
+	}
+	publicKey, err := ecc.DecodePoint(structure.PublicKey, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", signalerror.ErrInvalidIdentityKeyPair, err)
+	}
+	derivedPublicKey, err := curve25519.X25519(structure.PrivateKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", signalerror.ErrInvalidIdentityKeyPair, err)
+	}
+	if !bytes.Equal(derivedPublicKey, bytehelper.ArrayToSlice(publicKey.PublicKey())) {
+		return nil, fmt.Errorf("%w (public key does not match private key)", signalerror.ErrInvalidIdentityKeyPair)
+	}
+
+	return NewKeyPair(NewKey(publicKey), ecc.NewDjbECPrivateKey([32]byte(structure.PrivateKey))), nil
+}
+
+// KeyPairStructure is a serializable structure of an identity key pair.
+type KeyPairStructure struct {
+	PublicKey  []byte
+	PrivateKey []byte
+}
 
 // KeyPair is a holder for public and private identity key pair.
 type KeyPair struct {
@@ -34,6 +87,16 @@ func (k *KeyPair) PrivateKey() ecc.ECPrivateKeyable {
 	return k.privateKey
 }
 
-// Serialize returns a byte array that represents the keypair.
-//func (k *KeyPair) Serialize() []byte {
-//}
+// Structure returns a serializable structure of the key pair.
+func (k *KeyPair) Structure() *KeyPairStructure {
+	return &KeyPairStructure{
+		PublicKey:  k.publicKey.Serialize(),
+		PrivateKey: bytehelper.ArrayToSlice(k.privateKey.Serialize()),
+	}
+}
+
+// Serialize uses the given serializer to return the key pair as serialized
+// bytes, so that it can be persistently stored.
+func (k *KeyPair) Serialize(serializer KeyPairSerializer) []byte {
+	return serializer.Serialize(k.Structure())
+}
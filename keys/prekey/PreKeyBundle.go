@@ -1,13 +1,90 @@
 // Package prekey provides prekey bundle structures for calculating
-// a new Signal session with a user asyncronously.
+// a new Signal session with a user asyncronously, and a manager that
+// keeps the local prekeys up to date.
 package prekey
 
 import (
+	"fmt"
+
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/kem"
 	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/bytehelper"
 	"go.mau.fi/libsignal/util/optional"
 )
 
+// NewBundleFromBytes will return a prekey bundle from the given bytes using
+// the given serializer. The bundle is not validated, so Validate should be
+// called before it is used to build a session.
+func NewBundleFromBytes(serialized []byte, serializer protocol.PreKeyBundleSerializer) (*Bundle, error) {
+	// Use the given serializer to decode the bundle.
+	bundleStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBundleFromStruct(bundleStructure)
+}
+
+// NewBundleFromStruct returns a prekey bundle using the given serializable
+// structure.
+func NewBundleFromStruct(structure *protocol.PreKeyBundleStructure) (*Bundle, error) {
+	bundle := &Bundle{
+		registrationID:        structure.RegistrationID,
+		deviceID:              structure.DeviceID,
+		preKeyID:              optional.NewEmptyUint32(),
+		signedPreKeyID:        structure.SignedPreKeyID,
+		signedPreKeySignature: bytehelper.SliceToArray64(structure.SignedPreKeySignature),
+		kyberPreKeyID:         structure.KyberPreKeyID,
+		kyberPreKeySignature:  bytehelper.SliceToArray64(structure.KyberPreKeySignature),
+	}
+
+	// Decode the EC keys from bytes.
+	identityKey, err := decodeBundleKey(structure.IdentityKey, "identity key")
+	if err != nil {
+		return nil, err
+	}
+	bundle.identityKey = identity.NewKey(identityKey)
+	bundle.signedPreKeyPublic, err = decodeBundleKey(structure.SignedPreKey, "signed prekey")
+	if err != nil {
+		return nil, err
+	}
+	if structure.PreKeyID != nil && !structure.PreKeyID.IsEmpty {
+		bundle.preKeyID = structure.PreKeyID
+		bundle.preKeyPublic, err = decodeBundleKey(structure.PreKey, "prekey")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Decode the kyber prekey if the bundle has one.
+	if len(structure.KyberPreKey) > 0 {
+		bundle.kyberPreKeyPublic, err = kem.DecodePublicKey(structure.KyberPreKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w (kyber prekey): %w", signalerror.ErrInvalidBundleKey, err)
+		}
+	}
+
+	return bundle, nil
+}
+
+// decodeBundleKey will decode the given EC public key from a bundle.
+func decodeBundleKey(serialized []byte, name string) (ecc.ECPublicKeyable, error) {
+	if len(serialized) == 0 {
+		return nil, fmt.Errorf("%w (%s)", signalerror.ErrIncompleteBundle, name)
+	}
+	if len(serialized) != ecc.KeySize {
+		return nil, fmt.Errorf("%w (%s): bad key length %d", signalerror.ErrInvalidBundleKey, name, len(serialized))
+	}
+	key, err := ecc.DecodePoint(serialized, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s): %w", signalerror.ErrInvalidBundleKey, name, err)
+	}
+	return key, nil
+}
+
 // NewBundle returns a Bundle structure that contains a remote PreKey
 // and collection of associated items.
 func NewBundle(registrationID, deviceID uint32, preKeyID *optional.Uint32, signedPreKeyID uint32,
@@ -28,6 +105,22 @@ func NewBundle(registrationID, deviceID uint32, preKeyID *optional.Uint32, signe
 	return &bundle
 }
 
+// NewBundleWithKyber returns a Bundle structure that also contains a signed
+// kyber prekey, which is used to build sessions with PQXDH.
+func NewBundleWithKyber(registrationID, deviceID uint32, preKeyID *optional.Uint32, signedPreKeyID uint32,
+	preKeyPublic, signedPreKeyPublic ecc.ECPublicKeyable, signedPreKeySig [64]byte,
+	kyberPreKeyID uint32, kyberPreKeyPublic *kem.PublicKey, kyberPreKeySig [64]byte,
+	identityKey *identity.Key) *Bundle {
+
+	bundle := NewBundle(registrationID, deviceID, preKeyID, signedPreKeyID, preKeyPublic,
+		signedPreKeyPublic, signedPreKeySig, identityKey)
+	bundle.kyberPreKeyID = kyberPreKeyID
+	bundle.kyberPreKeyPublic = kyberPreKeyPublic
+	bundle.kyberPreKeySignature = kyberPreKeySig
+
+	return bundle
+}
+
 // Bundle is a structure that contains a remote PreKey and collection
 // of associated items.
 type Bundle struct {
@@ -38,6 +131,9 @@ type Bundle struct {
 	signedPreKeyID        uint32
 	signedPreKeyPublic    ecc.ECPublicKeyable
 	signedPreKeySignature [64]byte
+	kyberPreKeyID         uint32
+	kyberPreKeyPublic     *kem.PublicKey
+	kyberPreKeySignature  [64]byte
 	identityKey           *identity.Key
 }
 
@@ -74,6 +170,24 @@ func (b *Bundle) SignedPreKeySignature() [64]byte {
 	return b.signedPreKeySignature
 }
 
+// KyberPreKeyID returns the unique key ID for the
+// kyber PreKey.
+func (b *Bundle) KyberPreKeyID() uint32 {
+	return b.kyberPreKeyID
+}
+
+// KyberPreKey returns the kyber PreKey for this PreKeyBundle,
+// or nil if the bundle does not have one.
+func (b *Bundle) KyberPreKey() *kem.PublicKey {
+	return b.kyberPreKeyPublic
+}
+
+// KyberPreKeySignature returns the signature over the
+// kyber PreKey.
+func (b *Bundle) KyberPreKeySignature() [64]byte {
+	return b.kyberPreKeySignature
+}
+
 // IdentityKey returns the Identity Key of this PreKey's owner.
 func (b *Bundle) IdentityKey() *identity.Key {
 	return b.identityKey
@@ -84,3 +198,36 @@ func (b *Bundle) IdentityKey() *identity.Key {
 func (b *Bundle) RegistrationID() uint32 {
 	return b.registrationID
 }
+
+// Structure returns a serializable structure of the bundle.
+func (b *Bundle) Structure() *protocol.PreKeyBundleStructure {
+	structure := &protocol.PreKeyBundleStructure{
+		RegistrationID:        b.registrationID,
+		DeviceID:              b.deviceID,
+		PreKeyID:              optional.NewEmptyUint32(),
+		SignedPreKeyID:        b.signedPreKeyID,
+		SignedPreKeySignature: bytehelper.ArrayToSlice64(b.signedPreKeySignature),
+	}
+	if b.identityKey != nil {
+		structure.IdentityKey = b.identityKey.Serialize()
+	}
+	if b.signedPreKeyPublic != nil {
+		structure.SignedPreKey = b.signedPreKeyPublic.Serialize()
+	}
+	if b.preKeyID != nil && !b.preKeyID.IsEmpty && b.preKeyPublic != nil {
+		structure.PreKeyID = b.preKeyID
+		structure.PreKey = b.preKeyPublic.Serialize()
+	}
+	if b.kyberPreKeyPublic != nil {
+		structure.KyberPreKeyID = b.kyberPreKeyID
+		structure.KyberPreKey = b.kyberPreKeyPublic.Serialize()
+		structure.KyberPreKeySignature = bytehelper.ArrayToSlice64(b.kyberPreKeySignature)
+	}
+	return structure
+}
+
+// Serialize uses the given serializer to return the bundle as serialized
+// bytes.
+func (b *Bundle) Serialize(serializer protocol.PreKeyBundleSerializer) []byte {
+	return serializer.Serialize(b.Structure())
+}
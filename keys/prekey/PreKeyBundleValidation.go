@@ -0,0 +1,69 @@
+package prekey
+
+import (
+	"fmt"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/medium"
+)
+
+// Validate checks that the bundle has all the keys needed to build a
+// session, that the keys have the right types, that the signed prekey and
+// kyber prekey are signed by the identity key, and that the key IDs are in
+// range. The returned errors wrap signalerror.ErrIncompleteBundle,
+// signalerror.ErrInvalidBundleKey, signalerror.ErrInvalidSignature or
+// signalerror.ErrInvalidBundleKeyID.
+func (b *Bundle) Validate() error {
+	// Check the keys and their types.
+	if b.identityKey == nil {
+		return fmt.Errorf("%w (identity key)", signalerror.ErrIncompleteBundle)
+	}
+	if err := checkBundleKey(b.identityKey.PublicKey(), "identity key"); err != nil {
+		return err
+	}
+	if err := checkBundleKey(b.signedPreKeyPublic, "signed prekey"); err != nil {
+		return err
+	}
+	hasPreKey := b.preKeyID != nil && !b.preKeyID.IsEmpty
+	if hasPreKey {
+		if err := checkBundleKey(b.preKeyPublic, "prekey"); err != nil {
+			return err
+		}
+	}
+
+	// Check the key IDs.
+	if hasPreKey && b.preKeyID.Value > medium.MaxValue {
+		return fmt.Errorf("%w (prekey %d)", signalerror.ErrInvalidBundleKeyID, b.preKeyID.Value)
+	}
+	if b.signedPreKeyID > medium.MaxValue {
+		return fmt.Errorf("%w (signed prekey %d)", signalerror.ErrInvalidBundleKeyID, b.signedPreKeyID)
+	}
+	if b.kyberPreKeyPublic != nil && b.kyberPreKeyID > medium.MaxValue {
+		return fmt.Errorf("%w (kyber prekey %d)", signalerror.ErrInvalidBundleKeyID, b.kyberPreKeyID)
+	}
+
+	// Verify the signatures with the identity key.
+	identityKey := b.identityKey.PublicKey()
+	if !ecc.VerifySignature(identityKey, b.signedPreKeyPublic.Serialize(), b.signedPreKeySignature) {
+		return fmt.Errorf("%w (signed prekey)", signalerror.ErrInvalidSignature)
+	}
+	if b.kyberPreKeyPublic != nil {
+		if !ecc.VerifySignature(identityKey, b.kyberPreKeyPublic.Serialize(), b.kyberPreKeySignature) {
+			return fmt.Errorf("%w (kyber prekey)", signalerror.ErrInvalidSignature)
+		}
+	}
+
+	return nil
+}
+
+// checkBundleKey checks that the given bundle key exists and is a DJB key.
+func checkBundleKey(key ecc.ECPublicKeyable, name string) error {
+	if key == nil {
+		return fmt.Errorf("%w (%s)", signalerror.ErrIncompleteBundle, name)
+	}
+	if key.Type() != ecc.DjbType {
+		return fmt.Errorf("%w (%s): %w %d", signalerror.ErrInvalidBundleKey, name, ecc.ErrBadKeyType, key.Type())
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package prekey
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mau.fi/libsignal/config"
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store"
+	"go.mau.fi/libsignal/util/keyhelper"
+	"go.mau.fi/libsignal/util/medium"
+)
+
+// NewManager returns a new prekey manager that stores generated prekeys in
+// the given stores. The state should be the last state returned by
+// Manager.State, or nil if the manager has never been used before.
+func NewManager(identityKeyPair *identity.KeyPair, preKeyStore store.PreKey,
+	signedPreKeyStore store.SignedPreKey, serializer *serialize.Serializer,
+	state *ManagerState, cfg ...*config.Config) *Manager {
+
+	manager := Manager{
+		identityKeyPair:   identityKeyPair,
+		preKeyStore:       preKeyStore,
+		signedPreKeyStore: signedPreKeyStore,
+		serializer:        serializer,
+		config:            config.Get(cfg...),
+	}
+	if state != nil {
+		manager.state = state.clone()
+	} else {
+		manager.state.NextPreKeyID = randomPreKeyID()
+	}
+
+	return &manager
+}
+
+// NewManagerFromSignal returns a new prekey manager that uses the identity
+// key pair and prekey stores of the given signal store.
+func NewManagerFromSignal(signalStore store.SignalProtocol, serializer *serialize.Serializer,
+	state *ManagerState, cfg ...*config.Config) *Manager {
+
+	return NewManager(signalStore.GetIdentityKeyPair(), signalStore, signalStore, serializer, state, cfg...)
+}
+
+// ManagerState is the part of the prekey manager's state that can't be
+// read from the prekey stores. Clients should persist it after every
+// refresh and give it to NewManager when the manager is created again.
+type ManagerState struct {
+	// NextPreKeyID is the ID of the next generated one-time prekey.
+	NextPreKeyID uint32
+
+	// PreKeyIDs are the IDs of the generated one-time prekeys that were
+	// still in the prekey store during the last refresh.
+	PreKeyIDs []uint32
+}
+
+func (s *ManagerState) clone() ManagerState {
+	return ManagerState{
+		NextPreKeyID: s.NextPreKeyID,
+		PreKeyIDs:    slices.Clone(s.PreKeyIDs),
+	}
+}
+
+// Upload contains the newly generated prekeys that should be uploaded to
+// the server after a refresh.
+type Upload struct {
+	// PreKeys are the new one-time prekeys.
+	PreKeys []*record.PreKey
+
+	// SignedPreKey is the new signed prekey, or nil if the signed prekey
+	// was not rotated.
+	SignedPreKey *record.SignedPreKey
+}
+
+// IsEmpty returns true if there is nothing to upload.
+func (u *Upload) IsEmpty() bool {
+	return len(u.PreKeys) == 0 && u.SignedPreKey == nil
+}
+
+// Manager keeps a client's one-time prekeys and signed prekey up to date.
+//
+// One-time prekeys are refilled when fewer than the configured low-water
+// mark are left. Their IDs are generated sequentially and wrap around
+// before medium.MaxValue, which is reserved for the last resort prekey.
+//
+// The signed prekey is rotated when it is older than the configured
+// rotation interval. Replaced signed prekeys are kept for the configured
+// grace period, so that messages that were sent to them before the
+// rotation can still be decrypted.
+type Manager struct {
+	identityKeyPair   *identity.KeyPair
+	preKeyStore       store.PreKey
+	signedPreKeyStore store.SignedPreKey
+	serializer        *serialize.Serializer
+	config            *config.Config
+	state             ManagerState
+	mutex             sync.Mutex
+}
+
+// State returns a copy of the manager's current state.
+func (m *Manager) State() *ManagerState {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := m.state.clone()
+	return &state
+}
+
+// RemainingPreKeys returns the number of one-time prekeys that were left
+// during the last refresh.
+func (m *Manager) RemainingPreKeys() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return len(m.state.PreKeyIDs)
+}
+
+// Refresh will refill one-time prekeys, rotate the signed prekey and
+// remove old signed prekeys as needed. It returns the new prekeys that
+// should be uploaded to the server. The manager's state is only updated
+// if the refresh succeeds, so the state should be persisted afterwards.
+func (m *Manager) Refresh(ctx context.Context) (*Upload, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var upload *Upload
+	var state ManagerState
+	err := store.WithTx(ctx, m.preKeyStore, func(ctx context.Context) (err error) {
+		upload = &Upload{}
+		state = m.state.clone()
+		if err = m.refillPreKeys(ctx, &state, upload); err != nil {
+			return err
+		}
+		return m.rotateSignedPreKey(ctx, upload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.state = state
+
+	return upload, nil
+}
+
+// refillPreKeys will remove the IDs of used one-time prekeys from the state
+// and generate new prekeys if fewer than the low-water mark are left.
+func (m *Manager) refillPreKeys(ctx context.Context, state *ManagerState, upload *Upload) error {
+	remaining := state.PreKeyIDs[:0]
+	for _, preKeyID := range state.PreKeyIDs {
+		exists, err := m.preKeyStore.ContainsPreKey(ctx, preKeyID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			remaining = append(remaining, preKeyID)
+		}
+	}
+	state.PreKeyIDs = remaining
+	if len(state.PreKeyIDs) >= m.config.PreKeyLowWaterMark {
+		return nil
+	}
+
+	for len(state.PreKeyIDs) < m.config.PreKeyBatchSize {
+		keyPair, err := ecc.GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+		preKeyID := state.NextPreKeyID
+		preKey := record.NewPreKey(preKeyID, keyPair, m.serializer.PreKeyRecord)
+		if err = m.preKeyStore.StorePreKey(ctx, preKeyID, preKey); err != nil {
+			return err
+		}
+		state.PreKeyIDs = append(state.PreKeyIDs, preKeyID)
+		state.NextPreKeyID = nextPreKeyID(preKeyID)
+		upload.PreKeys = append(upload.PreKeys, preKey)
+	}
+
+	return nil
+}
+
+// rotateSignedPreKey will generate a new signed prekey if the current one
+// is too old, and remove replaced signed prekeys after the grace period.
+func (m *Manager) rotateSignedPreKey(ctx context.Context, upload *Upload) error {
+	signedPreKeys, err := m.signedPreKeyStore.LoadSignedPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The newest signed prekey is the one that is currently in use.
+	slices.SortFunc(signedPreKeys, func(a, b *record.SignedPreKey) int {
+		return cmp.Compare(a.Timestamp(), b.Timestamp())
+	})
+	var current *record.SignedPreKey
+	if len(signedPreKeys) > 0 {
+		current = signedPreKeys[len(signedPreKeys)-1]
+	}
+
+	now := time.Now()
+	if current == nil || now.Sub(time.Unix(current.Timestamp(), 0)) >= m.config.SignedPreKeyRotation {
+		signedPreKeyID := randomPreKeyID()
+		if current != nil {
+			signedPreKeyID = nextPreKeyID(current.ID())
+		}
+		signedPreKey, err := keyhelper.GenerateSignedPreKey(m.identityKeyPair, signedPreKeyID, m.serializer.SignedPreKeyRecord)
+		if err != nil {
+			return err
+		}
+		if err = m.signedPreKeyStore.StoreSignedPreKey(ctx, signedPreKeyID, signedPreKey); err != nil {
+			return err
+		}
+		upload.SignedPreKey = signedPreKey
+		signedPreKeys = append(signedPreKeys, signedPreKey)
+	}
+
+	// Each signed prekey was replaced when the next one was created, so it
+	// can be removed once the next one is older than the grace period.
+	for i := 0; i < len(signedPreKeys)-1; i++ {
+		replacedAt := time.Unix(signedPreKeys[i+1].Timestamp(), 0)
+		if now.Sub(replacedAt) < m.config.SignedPreKeyGracePeriod {
+			break
+		}
+		if err = m.signedPreKeyStore.RemoveSignedPreKey(ctx, signedPreKeys[i].ID()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nextPreKeyID returns the ID after the given one, wrapping around before
+// medium.MaxValue.
+func nextPreKeyID(preKeyID uint32) uint32 {
+	return preKeyID%(medium.MaxValue-1) + 1
+}
+
+// randomPreKeyID returns a random prekey ID between 1 and
+// medium.MaxValue - 1.
+func randomPreKeyID() uint32 {
+	return keyhelper.GenerateRegistrationID()%(medium.MaxValue-1) + 1
+}
@@ -0,0 +1,134 @@
+package keyserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// NewClient returns a new client for the key server at the given base URL.
+// The serializer must use the same bundle encoding as the server.
+func NewClient(baseURL string, serializer *serialize.Serializer) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		serializer: serializer,
+	}
+}
+
+// Client is an HTTP client for a key server.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	serializer *serialize.Serializer
+}
+
+// Upload uploads the given keys for the device at the given address.
+func (c *Client) Upload(ctx context.Context, address *protocol.SignalAddress, upload *Upload) error {
+	body, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.deviceURL(address), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// FetchBundle fetches and validates a prekey bundle for the device at the
+// given address. Every fetch consumes one of the device's one-time prekeys
+// on the server.
+func (c *Client) FetchBundle(ctx context.Context, address *protocol.SignalAddress) (*prekey.Bundle, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.deviceURL(address), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	serialized, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := prekey.NewBundleFromBytes(serialized, c.serializer.PreKeyBundle)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.DeviceID() != address.DeviceID() {
+		return nil, fmt.Errorf("key server returned bundle for device %d instead of %d", bundle.DeviceID(), address.DeviceID())
+	}
+	if err = bundle.Validate(); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// Devices returns the IDs of the devices that have uploaded keys for the
+// given name.
+func (c *Client) Devices(ctx context.Context, name string) ([]uint32, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.BaseURL+"/v1/keys/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var deviceIDs []uint32
+	if err = json.NewDecoder(resp.Body).Decode(&deviceIDs); err != nil {
+		return nil, err
+	}
+	return deviceIDs, nil
+}
+
+// ProcessBundle fetches a prekey bundle for the given address and builds a
+// session with it using the given session builder. The builder must be
+// for the same remote address.
+func (c *Client) ProcessBundle(ctx context.Context, builder *session.Builder, address *protocol.SignalAddress) error {
+	bundle, err := c.FetchBundle(ctx, address)
+	if err != nil {
+		return err
+	}
+	return builder.ProcessBundle(ctx, bundle)
+}
+
+// deviceURL returns the URL of the given device's keys.
+func (c *Client) deviceURL(address *protocol.SignalAddress) string {
+	return c.BaseURL + "/v1/keys/" + url.PathEscape(address.Name()) + "/" + strconv.FormatUint(uint64(address.DeviceID()), 10)
+}
+
+// do sends a request to the key server and returns an error if the
+// response doesn't have a successful status code.
+func (c *Client) do(ctx context.Context, method, reqURL string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w (key server returned HTTP 404)", signalerror.ErrNoKeysForAddress)
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("key server returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
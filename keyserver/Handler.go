@@ -0,0 +1,92 @@
+package keyserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// Key server HTTP endpoints
+const (
+	keysPath   = "/v1/keys/{name}"
+	devicePath = "/v1/keys/{name}/{deviceID}"
+)
+
+// MaxUploadSize is the maximum size of an upload request body. It leaves
+// room for a few hundred kyber prekeys, which are the largest keys.
+const MaxUploadSize = 1 << 20
+
+// ServeHTTP handles the key server's HTTP API:
+//
+//	PUT /v1/keys/{name}/{deviceID}  uploads the JSON encoded Upload in the body (at most MaxUploadSize bytes)
+//	GET /v1/keys/{name}/{deviceID}  returns a bundle encoded with the server's serializer
+//	GET /v1/keys/{name}             returns the JSON encoded list of device IDs
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	address, ok := parseAddress(w, r)
+	if !ok {
+		return
+	}
+	var upload Upload
+	var maxBytesErr *http.MaxBytesError
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxUploadSize)).Decode(&upload)
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = s.Upload(address, &upload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleBundle(w http.ResponseWriter, r *http.Request) {
+	address, ok := parseAddress(w, r)
+	if !ok {
+		return
+	}
+	bundle, err := s.Bundle(address)
+	if errors.Is(err, signalerror.ErrNoKeysForAddress) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logger.Error("Error creating prekey bundle: ", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(bundle.Serialize(s.serializer.PreKeyBundle))
+}
+
+func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
+	deviceIDs := s.Devices(r.PathValue("name"))
+	if len(deviceIDs) == 0 {
+		http.Error(w, signalerror.ErrNoKeysForAddress.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deviceIDs)
+}
+
+// parseAddress returns the address in the request path, or writes an error
+// response if the device ID is invalid.
+func parseAddress(w http.ResponseWriter, r *http.Request) (*protocol.SignalAddress, bool) {
+	deviceID, err := strconv.ParseUint(r.PathValue("deviceID"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid device ID", http.StatusBadRequest)
+		return nil, false
+	}
+	return protocol.NewSignalAddress(r.PathValue("name"), uint32(deviceID)), true
+}
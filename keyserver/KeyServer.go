@@ -0,0 +1,212 @@
+// Package keyserver provides an in-memory key distribution server, which
+// hands out prekey bundles over HTTP, and a client for it. It is meant for
+// integration tests and small private deployments. The server does not
+// authenticate uploads, so it should be put behind some form of
+// authentication if it is reachable by untrusted clients.
+package keyserver
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/optional"
+)
+
+// NewServer returns a new empty key server. Bundles are encoded with the
+// given serializer when they are served over HTTP.
+func NewServer(serializer *serialize.Serializer) *Server {
+	server := &Server{
+		devices:    make(map[protocol.SignalAddress]*deviceKeys),
+		serializer: serializer,
+		mux:        http.NewServeMux(),
+	}
+	server.mux.HandleFunc("PUT "+devicePath, server.handleUpload)
+	server.mux.HandleFunc("GET "+devicePath, server.handleBundle)
+	server.mux.HandleFunc("GET "+keysPath, server.handleDevices)
+
+	return server
+}
+
+// Server is an in-memory directory of the keys uploaded by each device.
+// Every fetched bundle consumes one of the device's one-time prekeys, or
+// uses the last resort prekey once they have run out. Server implements
+// http.Handler, see ServeHTTP for the endpoints.
+type Server struct {
+	devices    map[protocol.SignalAddress]*deviceKeys
+	serializer *serialize.Serializer
+	mux        *http.ServeMux
+	mutex      sync.Mutex
+}
+
+// deviceKeys contains the keys that a device has uploaded.
+type deviceKeys struct {
+	registrationID        uint32
+	deviceID              uint32
+	identityKey           []byte
+	signedPreKey          *SignedKey
+	preKeys               []*Key
+	lastResortPreKey      *Key
+	kyberPreKeys          []*SignedKey
+	lastResortKyberPreKey *SignedKey
+}
+
+// bundleStructure returns a bundle structure with the device's signed
+// prekey and the given one-time or last resort keys.
+func (d *deviceKeys) bundleStructure(preKey *Key, kyberPreKey *SignedKey) *protocol.PreKeyBundleStructure {
+	structure := &protocol.PreKeyBundleStructure{
+		RegistrationID:        d.registrationID,
+		DeviceID:              d.deviceID,
+		PreKeyID:              optional.NewEmptyUint32(),
+		SignedPreKeyID:        d.signedPreKey.ID,
+		SignedPreKey:          d.signedPreKey.PublicKey,
+		SignedPreKeySignature: d.signedPreKey.Signature,
+		IdentityKey:           d.identityKey,
+	}
+	if preKey != nil {
+		structure.PreKeyID = optional.NewOptionalUint32(preKey.ID)
+		structure.PreKey = preKey.PublicKey
+	}
+	if kyberPreKey != nil {
+		structure.KyberPreKeyID = kyberPreKey.ID
+		structure.KyberPreKey = kyberPreKey.PublicKey
+		structure.KyberPreKeySignature = kyberPreKey.Signature
+	}
+	return structure
+}
+
+// validate checks the device's signed prekey and the given one-time or
+// last resort keys by building a bundle with them.
+func (d *deviceKeys) validate(preKey *Key, kyberPreKey *SignedKey) error {
+	bundle, err := prekey.NewBundleFromStruct(d.bundleStructure(preKey, kyberPreKey))
+	if err != nil {
+		return err
+	}
+	return bundle.Validate()
+}
+
+// Upload stores the uploaded keys of the device at the given address. All
+// keys are validated before any of them are stored. If the identity key is
+// different from the previously uploaded one, the device is treated as a
+// new install and its old keys are removed.
+func (s *Server) Upload(address *protocol.SignalAddress, upload *Upload) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Start from a copy of the current keys, so that nothing is changed if
+	// the upload is invalid.
+	device := &deviceKeys{deviceID: address.DeviceID()}
+	if existing, ok := s.devices[*address]; ok && bytes.Equal(existing.identityKey, upload.IdentityKey) {
+		*device = *existing
+		device.preKeys = slices.Clone(existing.preKeys)
+		device.kyberPreKeys = slices.Clone(existing.kyberPreKeys)
+	}
+	device.registrationID = upload.RegistrationID
+	device.identityKey = upload.IdentityKey
+	if upload.SignedPreKey != nil {
+		device.signedPreKey = upload.SignedPreKey
+	}
+	if device.signedPreKey == nil {
+		return fmt.Errorf("%w (signed prekey)", signalerror.ErrIncompleteBundle)
+	}
+	if err := device.validate(nil, nil); err != nil {
+		return err
+	}
+
+	// Validate and add the prekeys.
+	for _, preKey := range upload.PreKeys {
+		if preKey == nil {
+			return fmt.Errorf("%w (prekey)", signalerror.ErrIncompleteBundle)
+		}
+		if err := device.validate(preKey, nil); err != nil {
+			return err
+		}
+	}
+	device.preKeys = append(device.preKeys, upload.PreKeys...)
+	if upload.LastResortPreKey != nil {
+		if err := device.validate(upload.LastResortPreKey, nil); err != nil {
+			return err
+		}
+		device.lastResortPreKey = upload.LastResortPreKey
+	}
+	for _, kyberPreKey := range upload.KyberPreKeys {
+		if kyberPreKey == nil {
+			return fmt.Errorf("%w (kyber prekey)", signalerror.ErrIncompleteBundle)
+		}
+		if err := device.validate(nil, kyberPreKey); err != nil {
+			return err
+		}
+	}
+	device.kyberPreKeys = append(device.kyberPreKeys, upload.KyberPreKeys...)
+	if upload.LastResortKyberPreKey != nil {
+		if err := device.validate(nil, upload.LastResortKyberPreKey); err != nil {
+			return err
+		}
+		device.lastResortKyberPreKey = upload.LastResortKyberPreKey
+	}
+
+	s.devices[*address] = device
+	return nil
+}
+
+// Bundle returns a prekey bundle for the device at the given address. The
+// bundle contains the next one-time prekey and kyber prekey, which are
+// removed from the server, or the last resort keys if there are no
+// one-time keys left.
+func (s *Server) Bundle(address *protocol.SignalAddress) (*prekey.Bundle, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	device, ok := s.devices[*address]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", signalerror.ErrNoKeysForAddress, address.String())
+	}
+
+	preKey := device.lastResortPreKey
+	if len(device.preKeys) > 0 {
+		preKey = device.preKeys[0]
+		device.preKeys = device.preKeys[1:]
+	}
+	kyberPreKey := device.lastResortKyberPreKey
+	if len(device.kyberPreKeys) > 0 {
+		kyberPreKey = device.kyberPreKeys[0]
+		device.kyberPreKeys = device.kyberPreKeys[1:]
+	}
+
+	return prekey.NewBundleFromStruct(device.bundleStructure(preKey, kyberPreKey))
+}
+
+// PreKeyCount returns the number of one-time prekeys that are left for the
+// device at the given address.
+func (s *Server) PreKeyCount(address *protocol.SignalAddress) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	device, ok := s.devices[*address]
+	if !ok {
+		return 0
+	}
+	return len(device.preKeys)
+}
+
+// Devices returns the sorted IDs of the devices that have uploaded keys
+// for the given name.
+func (s *Server) Devices(name string) []uint32 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var deviceIDs []uint32
+	for address := range s.devices {
+		if address.Name() == name {
+			deviceIDs = append(deviceIDs, address.DeviceID())
+		}
+	}
+	slices.Sort(deviceIDs)
+	return deviceIDs
+}
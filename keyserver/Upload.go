@@ -0,0 +1,97 @@
+package keyserver
+
+import (
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/util/bytehelper"
+)
+
+// Key is the public part of a one-time or last resort prekey.
+type Key struct {
+	ID        uint32
+	PublicKey []byte
+}
+
+// SignedKey is the public part of a prekey that is signed by the identity
+// key, such as a signed prekey or a kyber prekey.
+type SignedKey struct {
+	ID        uint32
+	PublicKey []byte
+	Signature []byte
+}
+
+// Upload contains the keys that a device uploads to the key server. The
+// identity key is required in every upload. All other keys are optional:
+// one-time prekeys are added to the ones already on the server, and the
+// other keys replace the previous ones.
+type Upload struct {
+	RegistrationID        uint32
+	IdentityKey           []byte
+	SignedPreKey          *SignedKey
+	PreKeys               []*Key
+	LastResortPreKey      *Key
+	KyberPreKeys          []*SignedKey
+	LastResortKyberPreKey *SignedKey
+}
+
+// NewUpload returns an upload with the given identity key and the public
+// parts of the prekeys from a prekey manager refresh. The prekeys may be
+// nil if only other keys are uploaded.
+func NewUpload(registrationID uint32, identityKey *identity.Key, keys *prekey.Upload) *Upload {
+	upload := &Upload{
+		RegistrationID: registrationID,
+		IdentityKey:    identityKey.Serialize(),
+	}
+	if keys == nil {
+		return upload
+	}
+	for _, preKey := range keys.PreKeys {
+		upload.AddPreKey(preKey)
+	}
+	if keys.SignedPreKey != nil {
+		upload.SetSignedPreKey(keys.SignedPreKey)
+	}
+
+	return upload
+}
+
+// SetSignedPreKey sets the signed prekey of the upload.
+func (u *Upload) SetSignedPreKey(signedPreKey *record.SignedPreKey) {
+	u.SignedPreKey = &SignedKey{
+		ID:        signedPreKey.ID(),
+		PublicKey: signedPreKey.KeyPair().PublicKey().Serialize(),
+		Signature: bytehelper.ArrayToSlice64(signedPreKey.Signature()),
+	}
+}
+
+// AddPreKey adds the given prekey to the upload. The last resort prekey
+// replaces the previous last resort prekey on the server, other prekeys
+// are added as one-time prekeys.
+func (u *Upload) AddPreKey(preKey *record.PreKey) {
+	key := &Key{
+		ID:        preKey.ID().Value,
+		PublicKey: preKey.KeyPair().PublicKey().Serialize(),
+	}
+	if preKey.IsLastResort() {
+		u.LastResortPreKey = key
+	} else {
+		u.PreKeys = append(u.PreKeys, key)
+	}
+}
+
+// AddKyberPreKey adds the given kyber prekey to the upload. The last resort
+// kyber prekey replaces the previous one on the server, other kyber prekeys
+// are added as one-time kyber prekeys.
+func (u *Upload) AddKyberPreKey(kyberPreKey *record.KyberPreKey) {
+	key := &SignedKey{
+		ID:        kyberPreKey.ID(),
+		PublicKey: kyberPreKey.KeyPair().PublicKey().Serialize(),
+		Signature: bytehelper.ArrayToSlice64(kyberPreKey.Signature()),
+	}
+	if kyberPreKey.IsLastResort() {
+		u.LastResortKyberPreKey = key
+	} else {
+		u.KyberPreKeys = append(u.KyberPreKeys, key)
+	}
+}
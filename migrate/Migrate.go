@@ -0,0 +1,223 @@
+// Package migrate rewrites all the records in a store with a new
+// serializer. Together with serialize.NewDetectingSerializer, it can be
+// used to move a store from JSON records to ProtoBuf records (or the other
+// way around) without taking it offline: the store can keep running with
+// the detecting serializer while the records are rewritten, as long as the
+// migration uses the same locker as the session and group ciphers.
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store"
+	"go.mau.fi/libsignal/util/keylock"
+)
+
+// Store is the interface that a store must implement to be migrated.
+// memstore, sqlstore and encstore implement it. The store must be able to
+// load the records in their current format, for example by using a
+// serializer from serialize.NewDetectingSerializer.
+type Store interface {
+	store.SignalProtocol
+	store.KyberPreKey
+	store.RecordLister
+}
+
+// Record kinds reported in Progress
+const (
+	PreKeys       = "prekeys"
+	SignedPreKeys = "signed prekeys"
+	KyberPreKeys  = "kyber prekeys"
+	Sessions      = "sessions"
+	SenderKeys    = "sender keys"
+)
+
+// Progress describes how far a migration has got.
+type Progress struct {
+	// Kind is the kind of records that are being rewritten, such as
+	// Sessions.
+	Kind string
+
+	// Done is the number of records of that kind that have been rewritten.
+	Done int
+
+	// Total is the number of records of that kind in the store.
+	Total int
+}
+
+// Records rewrites every prekey, signed prekey, kyber prekey, session and
+// sender key record in the store, so that it is serialized with the given
+// serializer. The progress function, if not nil, is called after each
+// rewritten record. Each record is loaded and stored in its own
+// transaction, so the store can be used while it is being migrated, and an
+// interrupted migration can simply be run again.
+//
+// Sessions and sender keys are rewritten while holding the lock for their
+// address or sender key name, so that messages that are decrypted at the
+// same time aren't overwritten with an older record. The locker must be
+// the one used by the session and group ciphers of the store. If it is
+// nil, keylock.Default is used, which is what the ciphers use by default.
+func Records(ctx context.Context, s Store, serializer *serialize.Serializer, locker keylock.Locker,
+	progress func(Progress)) error {
+
+	if locker == nil {
+		locker = keylock.Default
+	}
+	m := &migration{store: s, serializer: serializer, locker: locker, progress: progress}
+	for _, step := range []func(ctx context.Context) error{
+		m.preKeys,
+		m.signedPreKeys,
+		m.kyberPreKeys,
+		m.sessions,
+		m.senderKeys,
+	} {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migration holds the state of a single Records call.
+type migration struct {
+	store      Store
+	serializer *serialize.Serializer
+	locker     keylock.Locker
+	progress   func(Progress)
+}
+
+// each calls fn for every key inside its own transaction and reports the
+// progress after each call. If lockKey is not nil, the lock for the key it
+// returns is held during each call.
+func each[K any](ctx context.Context, m *migration, kind string, keys []K, lockKey func(key K) string,
+	fn func(ctx context.Context, key K) error) error {
+
+	for i, key := range keys {
+		err := locked(ctx, m, key, lockKey, func() error {
+			return store.WithTx(ctx, m.store, func(ctx context.Context) error {
+				return fn(ctx, key)
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", kind, err)
+		}
+		if m.progress != nil {
+			m.progress(Progress{Kind: kind, Done: i + 1, Total: len(keys)})
+		}
+	}
+	return nil
+}
+
+// locked calls fn while holding the lock for the given key, if lockKey is
+// not nil.
+func locked[K any](ctx context.Context, m *migration, key K, lockKey func(key K) string, fn func() error) error {
+	if lockKey == nil {
+		return fn()
+	}
+	unlock, err := m.locker.Lock(ctx, lockKey(key))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+func (m *migration) preKeys(ctx context.Context) error {
+	preKeyIDs, err := m.store.ListPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	return each(ctx, m, PreKeys, preKeyIDs, nil, func(ctx context.Context, preKeyID uint32) error {
+		preKey, err := m.store.LoadPreKey(ctx, preKeyID)
+		if err != nil || preKey == nil {
+			return err
+		}
+		return m.store.StorePreKey(ctx, preKeyID,
+			record.NewPreKey(preKey.ID().Value, preKey.KeyPair(), m.serializer.PreKeyRecord))
+	})
+}
+
+func (m *migration) signedPreKeys(ctx context.Context) error {
+	signedPreKeys, err := m.store.LoadSignedPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	signedPreKeyIDs := make([]uint32, len(signedPreKeys))
+	for i, signedPreKey := range signedPreKeys {
+		signedPreKeyIDs[i] = signedPreKey.ID()
+	}
+	return each(ctx, m, SignedPreKeys, signedPreKeyIDs, nil, func(ctx context.Context, signedPreKeyID uint32) error {
+		signedPreKey, err := m.store.LoadSignedPreKey(ctx, signedPreKeyID)
+		if err != nil || signedPreKey == nil {
+			return err
+		}
+		return m.store.StoreSignedPreKey(ctx, signedPreKeyID, record.NewSignedPreKey(
+			signedPreKey.ID(), signedPreKey.Timestamp(), signedPreKey.KeyPair(),
+			signedPreKey.Signature(), m.serializer.SignedPreKeyRecord,
+		))
+	})
+}
+
+func (m *migration) kyberPreKeys(ctx context.Context) error {
+	kyberPreKeyIDs, err := m.store.ListKyberPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	return each(ctx, m, KyberPreKeys, kyberPreKeyIDs, nil, func(ctx context.Context, kyberPreKeyID uint32) error {
+		kyberPreKey, err := m.store.LoadKyberPreKey(ctx, kyberPreKeyID)
+		if err != nil || kyberPreKey == nil {
+			return err
+		}
+		return m.store.StoreKyberPreKey(ctx, kyberPreKeyID, record.NewKyberPreKey(
+			kyberPreKey.ID(), kyberPreKey.Timestamp(), kyberPreKey.KeyPair(),
+			kyberPreKey.Signature(), m.serializer.KyberPreKeyRecord,
+		))
+	})
+}
+
+func (m *migration) sessions(ctx context.Context) error {
+	addresses, err := m.store.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+	return each(ctx, m, Sessions, addresses, (*protocol.SignalAddress).String, func(ctx context.Context, address *protocol.SignalAddress) error {
+		// LoadSession returns an empty record for sessions that were
+		// deleted after they were listed, which must not be stored.
+		exists, err := m.store.ContainsSession(ctx, address)
+		if err != nil || !exists {
+			return err
+		}
+		sessionRecord, err := m.store.LoadSession(ctx, address)
+		if err != nil {
+			return err
+		}
+		sessionRecord, err = record.NewSessionFromStructure(sessionRecord.Structure(), m.serializer.Session, m.serializer.State)
+		if err != nil {
+			return err
+		}
+		return m.store.StoreSession(ctx, address, sessionRecord)
+	})
+}
+
+func (m *migration) senderKeys(ctx context.Context) error {
+	senderKeyNames, err := m.store.ListSenderKeys(ctx)
+	if err != nil {
+		return err
+	}
+	return each(ctx, m, SenderKeys, senderKeyNames, (*protocol.SenderKeyName).String, func(ctx context.Context, senderKeyName *protocol.SenderKeyName) error {
+		keyRecord, err := m.store.LoadSenderKey(ctx, senderKeyName)
+		if err != nil || keyRecord.IsEmpty() {
+			return err
+		}
+		keyRecord, err = groupRecord.NewSenderKeyFromStruct(keyRecord.Structure(), m.serializer.SenderKeyRecord, m.serializer.SenderKeyState)
+		if err != nil {
+			return err
+		}
+		return m.store.StoreSenderKey(ctx, senderKeyName, keyRecord)
+	})
+}
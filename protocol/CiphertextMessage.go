@@ -11,9 +11,24 @@ type GroupCiphertextMessage interface {
 }
 
 const UnsupportedVersion = 1
-const CurrentVersion = 3
+const CurrentVersion = 4
+
+// PreKyberVersion is the message version of sessions that were built
+// without a kyber prekey. Version 4 sessions are always built with one.
+const PreKyberVersion = 3
+
+// SenderKeyVersion is the version of sender key messages, which are not
+// affected by the post-quantum session versions.
+const SenderKeyVersion = 3
 
 const WHISPER_TYPE = 2
 const PREKEY_TYPE = 3
 const SENDERKEY_TYPE = 4
 const SENDERKEY_DISTRIBUTION_TYPE = 5
+
+// versionByte returns the byte that serialized messages of the given version
+// start with. The version is in both halves of the byte, which keeps version
+// 3 messages identical to the ones sent by older clients.
+func versionByte(version int) byte {
+	return byte((version<<4 | version) & 0xFF)
+}
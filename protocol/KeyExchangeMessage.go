@@ -0,0 +1,196 @@
+package protocol
+
+import (
+	"fmt"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/bytehelper"
+)
+
+// Flags that describe what kind of key exchange message a message is.
+const (
+	KeyExchangeInitiateFlag             uint32 = 0x01
+	KeyExchangeResponseFlag             uint32 = 0x02
+	KeyExchangeSimultaneousInitiateFlag uint32 = 0x04
+)
+
+// KeyExchangeMessageSerializer is an interface for serializing and deserializing
+// KeyExchangeMessages into bytes. An implementation of this interface should be
+// used to encode/decode the object into JSON, Protobuffers, etc.
+type KeyExchangeMessageSerializer interface {
+	Serialize(message *KeyExchangeMessageStructure) []byte
+	Deserialize(serialized []byte) (*KeyExchangeMessageStructure, error)
+}
+
+// NewKeyExchangeMessageFromBytes will return a key exchange message from the given
+// bytes using the given serializer.
+func NewKeyExchangeMessageFromBytes(serialized []byte, serializer KeyExchangeMessageSerializer) (*KeyExchangeMessage, error) {
+	// Use the given serializer to decode the key exchange message.
+	messageStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyExchangeMessageFromStruct(messageStructure, serializer)
+}
+
+// NewKeyExchangeMessageFromStruct will return a new key exchange message from the
+// given serializable structure.
+func NewKeyExchangeMessageFromStruct(structure *KeyExchangeMessageStructure,
+	serializer KeyExchangeMessageSerializer) (*KeyExchangeMessage, error) {
+
+	// Throw an error if the given message structure is an unsupported version.
+	if structure.Version < PreKyberVersion {
+		return nil, fmt.Errorf("%w %d (key exchange message)", signalerror.ErrOldMessageVersion, structure.Version)
+	}
+
+	// Throw an error if the given message structure is a future version.
+	if structure.Version > CurrentVersion {
+		return nil, fmt.Errorf("%w %d (key exchange message)", signalerror.ErrUnknownMessageVersion, structure.Version)
+	}
+
+	// Throw an error if the structure is missing critical fields.
+	if len(structure.BaseKey) != ecc.KeySize || len(structure.RatchetKey) != ecc.KeySize ||
+		len(structure.IdentityKey) != ecc.KeySize || len(structure.BaseKeySignature) != 64 {
+		return nil, fmt.Errorf("%w (key exchange message)", signalerror.ErrIncompleteMessage)
+	}
+
+	// Create the key exchange message object from the structure.
+	message := &KeyExchangeMessage{
+		structure:        *structure,
+		baseKeySignature: bytehelper.SliceToArray64(structure.BaseKeySignature),
+		serializer:       serializer,
+	}
+
+	// Generate the ECC keys from bytes.
+	var err error
+	message.baseKey, err = ecc.DecodePoint(structure.BaseKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	message.ratchetKey, err = ecc.DecodePoint(structure.RatchetKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	identityKey, err := ecc.DecodePoint(structure.IdentityKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	message.identityKey = identity.NewKey(identityKey)
+
+	return message, nil
+}
+
+// NewKeyExchangeMessage will return a new key exchange message with the given
+// properties.
+func NewKeyExchangeMessage(messageVersion int, sequence, flags uint32, baseKey ecc.ECPublicKeyable,
+	baseKeySignature [64]byte, ratchetKey ecc.ECPublicKeyable, identityKey *identity.Key,
+	serializer KeyExchangeMessageSerializer) *KeyExchangeMessage {
+
+	return &KeyExchangeMessage{
+		structure: KeyExchangeMessageStructure{
+			Version:          messageVersion,
+			SupportedVersion: CurrentVersion,
+			Sequence:         sequence,
+			Flags:            flags,
+			BaseKey:          baseKey.Serialize(),
+			BaseKeySignature: bytehelper.ArrayToSlice64(baseKeySignature),
+			RatchetKey:       ratchetKey.Serialize(),
+			IdentityKey:      identityKey.Serialize(),
+		},
+		baseKey:          baseKey,
+		baseKeySignature: baseKeySignature,
+		ratchetKey:       ratchetKey,
+		identityKey:      identityKey,
+		serializer:       serializer,
+	}
+}
+
+// KeyExchangeMessageStructure is a serializable structure for key exchange
+// messages.
+type KeyExchangeMessageStructure struct {
+	Version          int
+	SupportedVersion int
+	Sequence         uint32
+	Flags            uint32
+	BaseKey          []byte
+	BaseKeySignature []byte
+	RatchetKey       []byte
+	IdentityKey      []byte
+}
+
+// KeyExchangeMessage is a message used to build a session with someone
+// who is online, without using prekeys.
+type KeyExchangeMessage struct {
+	structure        KeyExchangeMessageStructure
+	baseKey          ecc.ECPublicKeyable
+	baseKeySignature [64]byte
+	ratchetKey       ecc.ECPublicKeyable
+	identityKey      *identity.Key
+	serializer       KeyExchangeMessageSerializer
+}
+
+// Version returns the message version of the key exchange.
+func (k *KeyExchangeMessage) Version() int {
+	return k.structure.Version
+}
+
+// MaxVersion returns the highest message version the sender supports.
+func (k *KeyExchangeMessage) MaxVersion() int {
+	return k.structure.SupportedVersion
+}
+
+// Sequence returns the sequence number of the key exchange.
+func (k *KeyExchangeMessage) Sequence() uint32 {
+	return k.structure.Sequence
+}
+
+// Flags returns the flags of the key exchange message.
+func (k *KeyExchangeMessage) Flags() uint32 {
+	return k.structure.Flags
+}
+
+// BaseKey returns the sender's base key.
+func (k *KeyExchangeMessage) BaseKey() ecc.ECPublicKeyable {
+	return k.baseKey
+}
+
+// BaseKeySignature returns the signature of the base key made with the
+// sender's identity key.
+func (k *KeyExchangeMessage) BaseKeySignature() [64]byte {
+	return k.baseKeySignature
+}
+
+// RatchetKey returns the sender's ratchet key.
+func (k *KeyExchangeMessage) RatchetKey() ecc.ECPublicKeyable {
+	return k.ratchetKey
+}
+
+// IdentityKey returns the sender's identity key.
+func (k *KeyExchangeMessage) IdentityKey() *identity.Key {
+	return k.identityKey
+}
+
+// IsInitiate returns true if this message starts a key exchange.
+func (k *KeyExchangeMessage) IsInitiate() bool {
+	return k.structure.Flags&KeyExchangeInitiateFlag != 0
+}
+
+// IsResponse returns true if this message is a response to a key exchange.
+func (k *KeyExchangeMessage) IsResponse() bool {
+	return k.structure.Flags&KeyExchangeResponseFlag != 0
+}
+
+// IsResponseForSimultaneousInitiate returns true if this message is a response
+// sent while both sides had initiated a key exchange at the same time.
+func (k *KeyExchangeMessage) IsResponseForSimultaneousInitiate() bool {
+	return k.structure.Flags&KeyExchangeSimultaneousInitiateFlag != 0
+}
+
+// Serialize will return the key exchange message as bytes using the
+// message's serializer.
+func (k *KeyExchangeMessage) Serialize() []byte {
+	return k.serializer.Serialize(&k.structure)
+}
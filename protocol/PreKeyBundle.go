@@ -0,0 +1,27 @@
+package protocol
+
+import "go.mau.fi/libsignal/util/optional"
+
+// PreKeyBundleSerializer is an interface for serializing and deserializing
+// prekey bundles into bytes. An implementation of this interface should be
+// used to encode/decode the object into JSON, Protobuffers, etc.
+type PreKeyBundleSerializer interface {
+	Serialize(bundle *PreKeyBundleStructure) []byte
+	Deserialize(serialized []byte) (*PreKeyBundleStructure, error)
+}
+
+// PreKeyBundleStructure is a flat structure of a prekey bundle, used for
+// serialization and deserialization. The bundle itself is prekey.Bundle.
+type PreKeyBundleStructure struct {
+	RegistrationID        uint32
+	DeviceID              uint32
+	PreKeyID              *optional.Uint32
+	PreKey                []byte
+	SignedPreKeyID        uint32
+	SignedPreKey          []byte
+	SignedPreKeySignature []byte
+	KyberPreKeyID         uint32
+	KyberPreKey           []byte
+	KyberPreKeySignature  []byte
+	IdentityKey           []byte
+}
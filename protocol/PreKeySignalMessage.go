@@ -50,6 +50,18 @@ func NewPreKeySignalMessageFromStruct(structure *PreKeySignalMessageStructure,
 		return nil, fmt.Errorf("%w (prekey message)", signalerror.ErrIncompleteMessage)
 	}
 
+	// Throw an error if the message only has half of the kyber prekey fields.
+	hasKyberPreKeyID := structure.KyberPreKeyID != nil && !structure.KyberPreKeyID.IsEmpty
+	if hasKyberPreKeyID != (structure.KyberCiphertext != nil) {
+		return nil, fmt.Errorf("%w (prekey message kyber fields)", signalerror.ErrIncompleteMessage)
+	}
+
+	// Version 4 sessions are always built with a kyber prekey. Version 3
+	// messages may still have one, as older clients send them that way.
+	if structure.Version > PreKyberVersion && !hasKyberPreKeyID {
+		return nil, fmt.Errorf("%w (version %d prekey message without kyber prekey)", signalerror.ErrIncompleteMessage, structure.Version)
+	}
+
 	// Create the signal message object from the structure.
 	preKeyWhisperMessage := &PreKeySignalMessage{structure: *structure, serializer: serializer}
 
@@ -81,14 +93,28 @@ func NewPreKeySignalMessageFromStruct(structure *PreKeySignalMessageStructure,
 func NewPreKeySignalMessage(version int, registrationID uint32, preKeyID *optional.Uint32, signedPreKeyID uint32,
 	baseKey ecc.ECPublicKeyable, identityKey *identity.Key, message *SignalMessage, serializer PreKeySignalMessageSerializer,
 	msgSerializer SignalMessageSerializer) (*PreKeySignalMessage, error) {
+	return NewKyberPreKeySignalMessage(version, registrationID, preKeyID, signedPreKeyID, optional.NewEmptyUint32(), nil,
+		baseKey, identityKey, message, serializer, msgSerializer)
+}
+
+// NewKyberPreKeySignalMessage will return a new PreKeySignalMessage object for
+// a session that was built with a kyber prekey. The kyber prekey ID should be
+// empty and the ciphertext nil if no kyber prekey was used, which is only
+// allowed for version 3 messages.
+func NewKyberPreKeySignalMessage(version int, registrationID uint32, preKeyID *optional.Uint32, signedPreKeyID uint32,
+	kyberPreKeyID *optional.Uint32, kyberCiphertext []byte, baseKey ecc.ECPublicKeyable, identityKey *identity.Key,
+	message *SignalMessage, serializer PreKeySignalMessageSerializer,
+	msgSerializer SignalMessageSerializer) (*PreKeySignalMessage, error) {
 	structure := &PreKeySignalMessageStructure{
-		Version:        version,
-		RegistrationID: registrationID,
-		PreKeyID:       preKeyID,
-		SignedPreKeyID: signedPreKeyID,
-		BaseKey:        baseKey.Serialize(),
-		IdentityKey:    identityKey.PublicKey().Serialize(),
-		Message:        message.Serialize(),
+		Version:         version,
+		RegistrationID:  registrationID,
+		PreKeyID:        preKeyID,
+		SignedPreKeyID:  signedPreKeyID,
+		KyberPreKeyID:   kyberPreKeyID,
+		KyberCiphertext: kyberCiphertext,
+		BaseKey:         baseKey.Serialize(),
+		IdentityKey:     identityKey.PublicKey().Serialize(),
+		Message:         message.Serialize(),
 	}
 	return NewPreKeySignalMessageFromStruct(structure, serializer, msgSerializer)
 }
@@ -96,13 +122,15 @@ func NewPreKeySignalMessage(version int, registrationID uint32, preKeyID *option
 // PreKeySignalMessageStructure is a serializable structure for
 // PreKeySignalMessages.
 type PreKeySignalMessageStructure struct {
-	RegistrationID uint32
-	PreKeyID       *optional.Uint32
-	SignedPreKeyID uint32
-	BaseKey        []byte
-	IdentityKey    []byte
-	Message        []byte
-	Version        int
+	RegistrationID  uint32
+	PreKeyID        *optional.Uint32
+	SignedPreKeyID  uint32
+	KyberPreKeyID   *optional.Uint32
+	KyberCiphertext []byte
+	BaseKey         []byte
+	IdentityKey     []byte
+	Message         []byte
+	Version         int
 }
 
 // PreKeySignalMessage is an encrypted Signal message that is designed
@@ -135,6 +163,20 @@ func (p *PreKeySignalMessage) SignedPreKeyID() uint32 {
 	return p.structure.SignedPreKeyID
 }
 
+// KyberPreKeyID returns the ID of the kyber prekey the message was encrypted
+// to. It is empty if the session was built without a kyber prekey.
+func (p *PreKeySignalMessage) KyberPreKeyID() *optional.Uint32 {
+	if p.structure.KyberPreKeyID == nil {
+		return optional.NewEmptyUint32()
+	}
+	return p.structure.KyberPreKeyID
+}
+
+// KyberCiphertext returns the KEM ciphertext for the kyber prekey.
+func (p *PreKeySignalMessage) KyberCiphertext() []byte {
+	return p.structure.KyberCiphertext
+}
+
 func (p *PreKeySignalMessage) BaseKey() ecc.ECPublicKeyable {
 	return p.baseKey
 }
@@ -0,0 +1,156 @@
+package protocol
+
+import (
+	"fmt"
+	"time"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/bytehelper"
+)
+
+// SenderCertificateSerializer is an interface for serializing and deserializing
+// SenderCertificates into bytes. An implementation of this interface should be
+// used to encode/decode the object into JSON, Protobuffers, etc.
+//
+// If the given structure has no signature, Serialize should only return the
+// encoded certificate contents, which are the bytes that get signed.
+type SenderCertificateSerializer interface {
+	Serialize(certificate *SenderCertificateStructure) []byte
+	Deserialize(serialized []byte) (*SenderCertificateStructure, error)
+}
+
+// NewSenderCertificateFromBytes will return a sender certificate from the given
+// bytes using the given serializer.
+func NewSenderCertificateFromBytes(serialized []byte, serializer SenderCertificateSerializer,
+	signerSerializer ServerCertificateSerializer) (*SenderCertificate, error) {
+
+	// Use the given serializer to decode the sender certificate.
+	certificateStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSenderCertificateFromStruct(certificateStructure, serializer, signerSerializer)
+}
+
+// NewSenderCertificateFromStruct will return a new sender certificate from the
+// given serializable structure.
+func NewSenderCertificateFromStruct(structure *SenderCertificateStructure,
+	serializer SenderCertificateSerializer, signerSerializer ServerCertificateSerializer) (*SenderCertificate, error) {
+
+	// Throw an error if the structure is missing critical fields.
+	if structure.Certificate == nil || structure.Sender == "" || structure.SenderDevice == 0 ||
+		structure.Expires == 0 || len(structure.IdentityKey) != ecc.KeySize || structure.Signer == nil ||
+		len(structure.Signature) != 64 {
+		return nil, fmt.Errorf("%w (sender certificate)", signalerror.ErrIncompleteMessage)
+	}
+
+	identityKey, err := ecc.DecodePoint(structure.IdentityKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewServerCertificateFromStruct(structure.Signer, signerSerializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SenderCertificate{
+		structure:   *structure,
+		identityKey: identityKey,
+		signer:      signer,
+		signature:   bytehelper.SliceToArray64(structure.Signature),
+		serializer:  serializer,
+	}, nil
+}
+
+// NewSenderCertificate will return a new sender certificate for the given
+// sender, signed with the private key of the given server certificate.
+func NewSenderCertificate(sender *SignalAddress, identityKey ecc.ECPublicKeyable, expires time.Time,
+	signer *ServerCertificate, signerKey ecc.ECPrivateKeyable, serializer SenderCertificateSerializer) *SenderCertificate {
+
+	structure := SenderCertificateStructure{
+		Sender:       sender.Name(),
+		SenderDevice: sender.DeviceID(),
+		Expires:      uint64(expires.UnixMilli()),
+		IdentityKey:  identityKey.Serialize(),
+		Signer:       signer.Structure(),
+	}
+	structure.Certificate = serializer.Serialize(&structure)
+	signature := ecc.CalculateSignature(signerKey, structure.Certificate)
+	structure.Signature = bytehelper.ArrayToSlice64(signature)
+
+	return &SenderCertificate{
+		structure:   structure,
+		identityKey: identityKey,
+		signer:      signer,
+		signature:   signature,
+		serializer:  serializer,
+	}
+}
+
+// SenderCertificateStructure is a serializable structure for sender
+// certificates. The Certificate field contains the encoded certificate
+// contents as they were signed. Expires is in milliseconds since the epoch.
+type SenderCertificateStructure struct {
+	Sender       string
+	SenderDevice uint32
+	Expires      uint64
+	IdentityKey  []byte
+	Signer       *ServerCertificateStructure
+	Certificate  []byte
+	Signature    []byte
+}
+
+// SenderCertificate is a certificate issued by the server that binds a
+// sender's address to their identity key. It is included in sealed sender
+// messages so the recipient can learn who sent the message.
+type SenderCertificate struct {
+	structure   SenderCertificateStructure
+	identityKey ecc.ECPublicKeyable
+	signer      *ServerCertificate
+	signature   [64]byte
+	serializer  SenderCertificateSerializer
+}
+
+// Sender returns the address of the sender.
+func (s *SenderCertificate) Sender() *SignalAddress {
+	return NewSignalAddress(s.structure.Sender, s.structure.SenderDevice)
+}
+
+// Expires returns the time after which the certificate is no longer valid.
+func (s *SenderCertificate) Expires() time.Time {
+	return time.UnixMilli(int64(s.structure.Expires))
+}
+
+// IdentityKey returns the sender's identity public key.
+func (s *SenderCertificate) IdentityKey() ecc.ECPublicKeyable {
+	return s.identityKey
+}
+
+// Signer returns the server certificate of the key that signed this certificate.
+func (s *SenderCertificate) Signer() *ServerCertificate {
+	return s.signer
+}
+
+// Certificate returns the signed certificate contents.
+func (s *SenderCertificate) Certificate() []byte {
+	return s.structure.Certificate
+}
+
+// Signature returns the server's signature of the certificate contents.
+func (s *SenderCertificate) Signature() [64]byte {
+	return s.signature
+}
+
+// Structure returns a serializable structure of the sender certificate.
+func (s *SenderCertificate) Structure() *SenderCertificateStructure {
+	structure := s.structure
+	return &structure
+}
+
+// Serialize will return the sender certificate as bytes using the
+// certificate's serializer.
+func (s *SenderCertificate) Serialize() []byte {
+	return s.serializer.Serialize(&s.structure)
+}
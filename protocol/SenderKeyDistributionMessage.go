@@ -40,7 +40,7 @@ func NewSenderKeyDistributionMessageFromStruct(structure *SenderKeyDistributionM
 	}
 
 	// Throw an error if the given message structure is a future version.
-	if structure.Version > CurrentVersion {
+	if structure.Version > SenderKeyVersion {
 		return nil, fmt.Errorf("%w %d (sender key distribution)", signalerror.ErrUnknownMessageVersion, structure.Version)
 	}
 
@@ -136,7 +136,7 @@ func (p *SenderKeyDistributionMessage) Serialize() []byte {
 		Iteration:  p.iteration,
 		ChainKey:   p.chainKey,
 		SigningKey: p.signatureKey.Serialize(),
-		Version:    CurrentVersion,
+		Version:    SenderKeyVersion,
 	}
 	return p.serializer.Serialize(structure)
 }
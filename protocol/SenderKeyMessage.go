@@ -41,7 +41,7 @@ func NewSenderKeyMessageFromStruct(structure *SenderKeyMessageStructure,
 	}
 
 	// Throw an error if the given message structure is a future version.
-	if structure.Version > CurrentVersion {
+	if structure.Version > SenderKeyVersion {
 		return nil, fmt.Errorf("%w %d (sender key message)", signalerror.ErrUnknownMessageVersion, structure.Version)
 	}
 
@@ -77,7 +77,7 @@ func NewSenderKeyMessage(keyID uint32, iteration uint32, ciphertext []byte,
 		keyID:      keyID,
 		iteration:  iteration,
 		ciphertext: ciphertext,
-		version:    CurrentVersion,
+		version:    SenderKeyVersion,
 		serializer: serializer,
 	}
 
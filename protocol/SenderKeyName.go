@@ -23,3 +23,8 @@ func (n *SenderKeyName) GroupID() string {
 func (n *SenderKeyName) Sender() *SignalAddress {
 	return n.sender
 }
+
+// String returns a string of both the group id and the sender address.
+func (n *SenderKeyName) String() string {
+	return n.groupID + ADDRESS_SEPARATOR + n.sender.String()
+}
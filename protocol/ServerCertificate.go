@@ -0,0 +1,127 @@
+package protocol
+
+import (
+	"fmt"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/bytehelper"
+)
+
+// ServerCertificateSerializer is an interface for serializing and deserializing
+// ServerCertificates into bytes. An implementation of this interface should be
+// used to encode/decode the object into JSON, Protobuffers, etc.
+//
+// If the given structure has no signature, Serialize should only return the
+// encoded certificate contents, which are the bytes that get signed.
+type ServerCertificateSerializer interface {
+	Serialize(certificate *ServerCertificateStructure) []byte
+	Deserialize(serialized []byte) (*ServerCertificateStructure, error)
+}
+
+// NewServerCertificateFromBytes will return a server certificate from the given
+// bytes using the given serializer.
+func NewServerCertificateFromBytes(serialized []byte, serializer ServerCertificateSerializer) (*ServerCertificate, error) {
+	// Use the given serializer to decode the server certificate.
+	certificateStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewServerCertificateFromStruct(certificateStructure, serializer)
+}
+
+// NewServerCertificateFromStruct will return a new server certificate from the
+// given serializable structure.
+func NewServerCertificateFromStruct(structure *ServerCertificateStructure,
+	serializer ServerCertificateSerializer) (*ServerCertificate, error) {
+
+	// Throw an error if the structure is missing critical fields.
+	if structure.Certificate == nil || len(structure.Key) != ecc.KeySize || len(structure.Signature) != 64 {
+		return nil, fmt.Errorf("%w (server certificate)", signalerror.ErrIncompleteMessage)
+	}
+
+	key, err := ecc.DecodePoint(structure.Key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServerCertificate{
+		structure:  *structure,
+		key:        key,
+		signature:  bytehelper.SliceToArray64(structure.Signature),
+		serializer: serializer,
+	}, nil
+}
+
+// NewServerCertificate will return a new server certificate for the given key,
+// signed with the given trust root.
+func NewServerCertificate(keyID uint32, key ecc.ECPublicKeyable, trustRoot ecc.ECPrivateKeyable,
+	serializer ServerCertificateSerializer) *ServerCertificate {
+
+	structure := ServerCertificateStructure{
+		KeyID: keyID,
+		Key:   key.Serialize(),
+	}
+	structure.Certificate = serializer.Serialize(&structure)
+	signature := ecc.CalculateSignature(trustRoot, structure.Certificate)
+	structure.Signature = bytehelper.ArrayToSlice64(signature)
+
+	return &ServerCertificate{
+		structure:  structure,
+		key:        key,
+		signature:  signature,
+		serializer: serializer,
+	}
+}
+
+// ServerCertificateStructure is a serializable structure for server
+// certificates. The Certificate field contains the encoded KeyID and Key
+// as they were signed.
+type ServerCertificateStructure struct {
+	KeyID       uint32
+	Key         []byte
+	Certificate []byte
+	Signature   []byte
+}
+
+// ServerCertificate is a certificate for a key that the server uses to sign
+// sender certificates. The certificate itself is signed by a trust root.
+type ServerCertificate struct {
+	structure  ServerCertificateStructure
+	key        ecc.ECPublicKeyable
+	signature  [64]byte
+	serializer ServerCertificateSerializer
+}
+
+// KeyID returns the ID of the server key.
+func (s *ServerCertificate) KeyID() uint32 {
+	return s.structure.KeyID
+}
+
+// Key returns the public key of the server that signs sender certificates.
+func (s *ServerCertificate) Key() ecc.ECPublicKeyable {
+	return s.key
+}
+
+// Certificate returns the signed certificate contents.
+func (s *ServerCertificate) Certificate() []byte {
+	return s.structure.Certificate
+}
+
+// Signature returns the trust root's signature of the certificate contents.
+func (s *ServerCertificate) Signature() [64]byte {
+	return s.signature
+}
+
+// Structure returns a serializable structure of the server certificate.
+func (s *ServerCertificate) Structure() *ServerCertificateStructure {
+	structure := s.structure
+	return &structure
+}
+
+// Serialize will return the server certificate as bytes using the
+// certificate's serializer.
+func (s *ServerCertificate) Serialize() []byte {
+	return s.serializer.Serialize(&s.structure)
+}
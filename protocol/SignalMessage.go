@@ -4,7 +4,6 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"fmt"
-	"strconv"
 
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/keys/identity"
@@ -71,7 +70,7 @@ func NewSignalMessage(messageVersion int, counter, previousCounter uint32, macKe
 	senderRatchetKey ecc.ECPublicKeyable, ciphertext []byte, senderIdentityKey,
 	receiverIdentityKey *identity.Key, serializer SignalMessageSerializer) (*SignalMessage, error) {
 
-	version := []byte(strconv.Itoa(messageVersion))
+	version := []byte{versionByte(messageVersion)}
 	// Build the signal message structure with the given data.
 	structure := &SignalMessageStructure{
 		Counter:         counter,
@@ -137,6 +136,12 @@ func (s *SignalMessage) Counter() uint32 {
 	return s.structure.Counter
 }
 
+// PreviousCounter will return the counter of the sender's previous
+// sending chain.
+func (s *SignalMessage) PreviousCounter() uint32 {
+	return s.structure.PreviousCounter
+}
+
 // Body will return the SignalMessage's ciphertext in bytes.
 func (s *SignalMessage) Body() []byte {
 	return s.structure.CipherText
@@ -157,7 +162,7 @@ func (s *SignalMessage) VerifyMac(messageVersion int, senderIdentityKey,
 	}
 	signalMessage.structure.Mac = nil
 	signalMessage.structure.Version = 0
-	version := []byte(strconv.Itoa(s.MessageVersion()))
+	version := []byte{versionByte(s.MessageVersion())}
 	serialized := append(version, signalMessage.Serialize()...)
 
 	// Calculate the message authentication code from the serialized structure.
@@ -0,0 +1,231 @@
+package protocol
+
+import (
+	"fmt"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// UnidentifiedSenderVersion is the current version of sealed sender messages.
+const UnidentifiedSenderVersion = 1
+
+// UnidentifiedSenderMessageSerializer is an interface for serializing and deserializing
+// UnidentifiedSenderMessages into bytes. An implementation of this interface should be
+// used to encode/decode the object into JSON, Protobuffers, etc.
+type UnidentifiedSenderMessageSerializer interface {
+	Serialize(message *UnidentifiedSenderMessageStructure) []byte
+	Deserialize(serialized []byte) (*UnidentifiedSenderMessageStructure, error)
+}
+
+// NewUnidentifiedSenderMessageFromBytes will return a sealed sender message from
+// the given bytes using the given serializer.
+func NewUnidentifiedSenderMessageFromBytes(serialized []byte,
+	serializer UnidentifiedSenderMessageSerializer) (*UnidentifiedSenderMessage, error) {
+
+	// Use the given serializer to decode the sealed sender message.
+	messageStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUnidentifiedSenderMessageFromStruct(messageStructure, serializer)
+}
+
+// NewUnidentifiedSenderMessageFromStruct will return a new sealed sender message
+// from the given serializable structure.
+func NewUnidentifiedSenderMessageFromStruct(structure *UnidentifiedSenderMessageStructure,
+	serializer UnidentifiedSenderMessageSerializer) (*UnidentifiedSenderMessage, error) {
+
+	// Throw an error if the given message structure is an unsupported version.
+	if structure.Version < UnidentifiedSenderVersion {
+		return nil, fmt.Errorf("%w %d (unidentified sender message)", signalerror.ErrOldMessageVersion, structure.Version)
+	}
+
+	// Throw an error if the given message structure is a future version.
+	if structure.Version > UnidentifiedSenderVersion {
+		return nil, fmt.Errorf("%w %d (unidentified sender message)", signalerror.ErrUnknownMessageVersion, structure.Version)
+	}
+
+	// Throw an error if the structure is missing critical fields.
+	if len(structure.EphemeralPublic) != ecc.KeySize || structure.EncryptedStatic == nil || structure.EncryptedMessage == nil {
+		return nil, fmt.Errorf("%w (unidentified sender message)", signalerror.ErrIncompleteMessage)
+	}
+
+	ephemeral, err := ecc.DecodePoint(structure.EphemeralPublic, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnidentifiedSenderMessage{
+		structure:  *structure,
+		ephemeral:  ephemeral,
+		serializer: serializer,
+	}, nil
+}
+
+// NewUnidentifiedSenderMessage will return a new sealed sender message with
+// the given ephemeral key and encrypted contents.
+func NewUnidentifiedSenderMessage(ephemeral ecc.ECPublicKeyable, encryptedStatic, encryptedMessage []byte,
+	serializer UnidentifiedSenderMessageSerializer) *UnidentifiedSenderMessage {
+
+	return &UnidentifiedSenderMessage{
+		structure: UnidentifiedSenderMessageStructure{
+			Version:          UnidentifiedSenderVersion,
+			EphemeralPublic:  ephemeral.Serialize(),
+			EncryptedStatic:  encryptedStatic,
+			EncryptedMessage: encryptedMessage,
+		},
+		ephemeral:  ephemeral,
+		serializer: serializer,
+	}
+}
+
+// UnidentifiedSenderMessageStructure is a serializable structure for sealed
+// sender messages.
+type UnidentifiedSenderMessageStructure struct {
+	Version          int
+	EphemeralPublic  []byte
+	EncryptedStatic  []byte
+	EncryptedMessage []byte
+}
+
+// UnidentifiedSenderMessage is the outer layer of a sealed sender message. It
+// hides the sender's identity key and certificate from everyone except the
+// recipient.
+type UnidentifiedSenderMessage struct {
+	structure  UnidentifiedSenderMessageStructure
+	ephemeral  ecc.ECPublicKeyable
+	serializer UnidentifiedSenderMessageSerializer
+}
+
+// Version returns the sealed sender version of the message.
+func (u *UnidentifiedSenderMessage) Version() int {
+	return u.structure.Version
+}
+
+// Ephemeral returns the sender's ephemeral public key.
+func (u *UnidentifiedSenderMessage) Ephemeral() ecc.ECPublicKeyable {
+	return u.ephemeral
+}
+
+// EncryptedStatic returns the sender's encrypted identity key.
+func (u *UnidentifiedSenderMessage) EncryptedStatic() []byte {
+	return u.structure.EncryptedStatic
+}
+
+// EncryptedMessage returns the encrypted message content.
+func (u *UnidentifiedSenderMessage) EncryptedMessage() []byte {
+	return u.structure.EncryptedMessage
+}
+
+// Serialize will return the sealed sender message as bytes using the
+// message's serializer.
+func (u *UnidentifiedSenderMessage) Serialize() []byte {
+	return u.serializer.Serialize(&u.structure)
+}
+
+// UnidentifiedSenderMessageContentSerializer is an interface for serializing and
+// deserializing UnidentifiedSenderMessageContents into bytes. An implementation of
+// this interface should be used to encode/decode the object into JSON, Protobuffers, etc.
+type UnidentifiedSenderMessageContentSerializer interface {
+	Serialize(content *UnidentifiedSenderMessageContentStructure) []byte
+	Deserialize(serialized []byte) (*UnidentifiedSenderMessageContentStructure, error)
+}
+
+// NewUnidentifiedSenderMessageContentFromBytes will return sealed sender message
+// content from the given bytes using the given serializers.
+func NewUnidentifiedSenderMessageContentFromBytes(serialized []byte,
+	serializer UnidentifiedSenderMessageContentSerializer, certificateSerializer SenderCertificateSerializer,
+	signerSerializer ServerCertificateSerializer) (*UnidentifiedSenderMessageContent, error) {
+
+	// Use the given serializer to decode the sealed sender message content.
+	contentStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUnidentifiedSenderMessageContentFromStruct(contentStructure, serializer, certificateSerializer, signerSerializer)
+}
+
+// NewUnidentifiedSenderMessageContentFromStruct will return sealed sender message
+// content from the given serializable structure.
+func NewUnidentifiedSenderMessageContentFromStruct(structure *UnidentifiedSenderMessageContentStructure,
+	serializer UnidentifiedSenderMessageContentSerializer, certificateSerializer SenderCertificateSerializer,
+	signerSerializer ServerCertificateSerializer) (*UnidentifiedSenderMessageContent, error) {
+
+	// Only regular and prekey signal messages can be sent with sealed sender.
+	if structure.Type != WHISPER_TYPE && structure.Type != PREKEY_TYPE {
+		return nil, fmt.Errorf("%w %d (unidentified sender message content)", signalerror.ErrUnknownMessageType, structure.Type)
+	}
+
+	// Throw an error if the structure is missing critical fields.
+	if structure.Content == nil || structure.SenderCertificate == nil {
+		return nil, fmt.Errorf("%w (unidentified sender message content)", signalerror.ErrIncompleteMessage)
+	}
+
+	senderCertificate, err := NewSenderCertificateFromStruct(structure.SenderCertificate, certificateSerializer, signerSerializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnidentifiedSenderMessageContent{
+		structure:         *structure,
+		senderCertificate: senderCertificate,
+		serializer:        serializer,
+	}, nil
+}
+
+// NewUnidentifiedSenderMessageContent will return new sealed sender message
+// content wrapping the given ciphertext message.
+func NewUnidentifiedSenderMessageContent(message CiphertextMessage, senderCertificate *SenderCertificate,
+	serializer UnidentifiedSenderMessageContentSerializer) *UnidentifiedSenderMessageContent {
+
+	return &UnidentifiedSenderMessageContent{
+		structure: UnidentifiedSenderMessageContentStructure{
+			Type:              message.Type(),
+			SenderCertificate: senderCertificate.Structure(),
+			Content:           message.Serialize(),
+		},
+		senderCertificate: senderCertificate,
+		serializer:        serializer,
+	}
+}
+
+// UnidentifiedSenderMessageContentStructure is a serializable structure for
+// sealed sender message contents. Type is the CiphertextMessage type of the
+// wrapped message.
+type UnidentifiedSenderMessageContentStructure struct {
+	Type              uint32
+	SenderCertificate *SenderCertificateStructure
+	Content           []byte
+}
+
+// UnidentifiedSenderMessageContent is the decrypted content of a sealed sender
+// message. It contains the sender's certificate and the wrapped signal message.
+type UnidentifiedSenderMessageContent struct {
+	structure         UnidentifiedSenderMessageContentStructure
+	senderCertificate *SenderCertificate
+	serializer        UnidentifiedSenderMessageContentSerializer
+}
+
+// Type returns the CiphertextMessage type of the wrapped message.
+func (u *UnidentifiedSenderMessageContent) Type() uint32 {
+	return u.structure.Type
+}
+
+// SenderCertificate returns the certificate of the message sender.
+func (u *UnidentifiedSenderMessageContent) SenderCertificate() *SenderCertificate {
+	return u.senderCertificate
+}
+
+// Content returns the serialized wrapped message.
+func (u *UnidentifiedSenderMessageContent) Content() []byte {
+	return u.structure.Content
+}
+
+// Serialize will return the sealed sender message content as bytes using the
+// content's serializer.
+func (u *UnidentifiedSenderMessageContent) Serialize() []byte {
+	return u.serializer.Serialize(&u.structure)
+}
@@ -3,8 +3,8 @@
 package ratchet
 
 import (
+	"bytes"
 	"encoding/base64"
-	"encoding/binary"
 
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/kdf"
@@ -15,6 +15,13 @@ import (
 
 var b64 = base64.StdEncoding.EncodeToString
 
+// Info strings for deriving the initial root and chain keys. Sessions built
+// with a kyber prekey use a different info string than X3DH sessions.
+const (
+	x3dhInfo  = "WhisperText"
+	pqxdhInfo = "WhisperText_X25519_SHA-256_CRYSTALS-KYBER-1024"
+)
+
 func genDiscontinuity() [32]byte {
 	var discontinuity [32]byte
 	for i := range discontinuity {
@@ -74,8 +81,18 @@ func CalculateSenderSession(parameters *SenderParameters) (*session.KeyPair, err
 
 	}
 
+	// If they have a kyber prekey, encapsulate a shared secret to it and add it
+	// to the master secret. The ciphertext must be sent to the receiver.
+	info := x3dhInfo
+	if parameters.TheirKyberPreKey() != nil {
+		kyberSecret, kyberCiphertext := parameters.TheirKyberPreKey().Encapsulate()
+		masterSecret = append(masterSecret, kyberSecret...)
+		parameters.kyberCiphertext = kyberCiphertext
+		info = pqxdhInfo
+	}
+
 	// Derive the root and chain keys based on the master secret.
-	derivedKeysBytes, err := kdf.DeriveSecrets(masterSecret, nil, []byte("WhisperText"), root.DerivedSecretsSize)
+	derivedKeysBytes, err := kdf.DeriveSecrets(masterSecret, nil, []byte(info), root.DerivedSecretsSize)
 	if err != nil {
 		return nil, err
 	}
@@ -140,8 +157,20 @@ func CalculateReceiverSession(parameters *ReceiverParameters) (*session.KeyPair,
 
 	}
 
+	// If we had a kyber prekey, use it to decapsulate the shared secret the
+	// sender encapsulated to it.
+	info := x3dhInfo
+	if parameters.OurKyberPreKey() != nil {
+		kyberSecret, err := parameters.OurKyberPreKey().PrivateKey().Decapsulate(parameters.TheirKyberCiphertext())
+		if err != nil {
+			return nil, err
+		}
+		masterSecret = append(masterSecret, kyberSecret...)
+		info = pqxdhInfo
+	}
+
 	// Derive the root and chain keys based on the master secret.
-	derivedKeysBytes, err := kdf.DeriveSecrets(masterSecret, nil, []byte("WhisperText"), root.DerivedSecretsSize)
+	derivedKeysBytes, err := kdf.DeriveSecrets(masterSecret, nil, []byte(info), root.DerivedSecretsSize)
 	if err != nil {
 		return nil, err
 	}
@@ -160,9 +189,9 @@ func CalculateReceiverSession(parameters *ReceiverParameters) (*session.KeyPair,
 // This is useful for establishing a session if both users are online.
 func CalculateSymmetricSession(parameters *SymmetricParameters) (*session.KeyPair, error) {
 	// Compare the base public keys so we can deterministically know whether we should
-	// be setting up a sender or receiver session. If our key converted to an integer is
-	// less than the other user's, act as a sender.
-	if isSender(parameters.OurBaseKey.PublicKey(), parameters.TheirBaseKey) {
+	// be setting up a sender or receiver session. If our key is less than the other
+	// user's, act as a sender.
+	if IsSymmetricSender(parameters.OurBaseKey.PublicKey(), parameters.TheirBaseKey) {
 		senderParameters := &SenderParameters{
 			ourBaseKey:         parameters.OurBaseKey,
 			ourIdentityKeyPair: parameters.OurIdentityKeyPair,
@@ -186,12 +215,9 @@ func CalculateSymmetricSession(parameters *SymmetricParameters) (*session.KeyPai
 	return CalculateReceiverSession(receiverParameters)
 }
 
-// isSender is a private method for determining if a symmetric session should
-// be calculated as the sender or receiver. It does so by converting the given
-// keys into integers and comparing the size of those integers.
-func isSender(ourKey, theirKey ecc.ECPublicKeyable) bool {
-	ourKeyInt := binary.BigEndian.Uint32(ourKey.Serialize())
-	theirKeyInt := binary.BigEndian.Uint32(theirKey.Serialize())
-
-	return ourKeyInt < theirKeyInt
+// IsSymmetricSender determines if a symmetric session should be calculated as
+// the sender or receiver. It does so by comparing the serialized base keys of
+// both users, so both sides will always come to opposite conclusions.
+func IsSymmetricSender(ourKey, theirKey ecc.ECPublicKeyable) bool {
+	return bytes.Compare(ourKey.Serialize(), theirKey.Serialize()) < 0
 }
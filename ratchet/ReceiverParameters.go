@@ -2,6 +2,7 @@ package ratchet
 
 import (
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/kem"
 	"go.mau.fi/libsignal/keys/identity"
 )
 
@@ -41,8 +42,11 @@ type ReceiverParameters struct {
 	ourOneTimePreKey   *ecc.ECKeyPair
 	ourRatchetKey      *ecc.ECKeyPair
 
-	theirBaseKey     ecc.ECPublicKeyable
-	theirIdentityKey *identity.Key
+	ourKyberPreKey *kem.KeyPair
+
+	theirBaseKey         ecc.ECPublicKeyable
+	theirIdentityKey     *identity.Key
+	theirKyberCiphertext []byte
 }
 
 // OurIdentityKeyPair returns the identity key of the receiver.
@@ -104,3 +108,24 @@ func (r *ReceiverParameters) SetTheirBaseKey(theirBaseKey ecc.ECPublicKeyable) {
 func (r *ReceiverParameters) SetTheirIdentityKey(theirIdentityKey *identity.Key) {
 	r.theirIdentityKey = theirIdentityKey
 }
+
+// OurKyberPreKey returns the kyber prekey of the receiver.
+func (r *ReceiverParameters) OurKyberPreKey() *kem.KeyPair {
+	return r.ourKyberPreKey
+}
+
+// TheirKyberCiphertext returns the KEM ciphertext sent by the sender.
+func (r *ReceiverParameters) TheirKyberCiphertext() []byte {
+	return r.theirKyberCiphertext
+}
+
+// SetOurKyberPreKey sets the kyber prekey of the receiver. If it is set, the
+// session will be calculated using PQXDH.
+func (r *ReceiverParameters) SetOurKyberPreKey(ourKyberPreKey *kem.KeyPair) {
+	r.ourKyberPreKey = ourKyberPreKey
+}
+
+// SetTheirKyberCiphertext sets the KEM ciphertext sent by the sender.
+func (r *ReceiverParameters) SetTheirKyberCiphertext(theirKyberCiphertext []byte) {
+	r.theirKyberCiphertext = theirKyberCiphertext
+}
@@ -2,6 +2,7 @@ package ratchet
 
 import (
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/kem"
 	"go.mau.fi/libsignal/keys/identity"
 )
 
@@ -43,6 +44,9 @@ type SenderParameters struct {
 	theirSignedPreKey  ecc.ECPublicKeyable
 	theirOneTimePreKey ecc.ECPublicKeyable
 	theirRatchetKey    ecc.ECPublicKeyable
+	theirKyberPreKey   *kem.PublicKey
+
+	kyberCiphertext []byte
 }
 
 // OurIdentityKey returns the identity key pair of the sender.
@@ -104,3 +108,20 @@ func (s *SenderParameters) SetTheirOneTimePreKey(theirOneTimePreKey ecc.ECPublic
 func (s *SenderParameters) SetTheirRatchetKey(theirRatchetKey ecc.ECPublicKeyable) {
 	s.theirRatchetKey = theirRatchetKey
 }
+
+// TheirKyberPreKey returns the receiver's kyber prekey.
+func (s *SenderParameters) TheirKyberPreKey() *kem.PublicKey {
+	return s.theirKyberPreKey
+}
+
+// SetTheirKyberPreKey sets the receiver's kyber prekey. If it is set, the
+// session will be calculated using PQXDH.
+func (s *SenderParameters) SetTheirKyberPreKey(theirKyberPreKey *kem.PublicKey) {
+	s.theirKyberPreKey = theirKyberPreKey
+}
+
+// KyberCiphertext returns the KEM ciphertext that was encapsulated to the
+// receiver's kyber prekey when calculating the sender session.
+func (s *SenderParameters) KyberCiphertext() []byte {
+	return s.kyberCiphertext
+}
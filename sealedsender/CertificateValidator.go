@@ -0,0 +1,54 @@
+package sealedsender
+
+import (
+	"fmt"
+	"time"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// NewCertificateValidator will return a new certificate validator that trusts
+// server certificates signed with the given trust root.
+func NewCertificateValidator(trustRoot ecc.ECPublicKeyable) *CertificateValidator {
+	return &CertificateValidator{
+		trustRoot: trustRoot,
+	}
+}
+
+// CertificateValidator is a structure for validating the sender certificates
+// included in sealed sender messages.
+type CertificateValidator struct {
+	trustRoot ecc.ECPublicKeyable
+}
+
+// Validate will check that the given sender certificate was signed by a server
+// certificate issued by the trust root, and that it hasn't expired at the given
+// time.
+func (c *CertificateValidator) Validate(certificate *protocol.SenderCertificate, validationTime time.Time) error {
+	signer := certificate.Signer()
+	if err := c.ValidateServerCertificate(signer); err != nil {
+		return err
+	}
+
+	if !ecc.VerifySignature(signer.Key(), certificate.Certificate(), certificate.Signature()) {
+		return fmt.Errorf("%w (sender certificate)", signalerror.ErrInvalidCertificate)
+	}
+
+	if validationTime.After(certificate.Expires()) {
+		return fmt.Errorf("%w at %s", signalerror.ErrExpiredCertificate, certificate.Expires())
+	}
+
+	return nil
+}
+
+// ValidateServerCertificate will check that the given server certificate was
+// signed by the trust root.
+func (c *CertificateValidator) ValidateServerCertificate(certificate *protocol.ServerCertificate) error {
+	if !ecc.VerifySignature(c.trustRoot, certificate.Certificate(), certificate.Signature()) {
+		return fmt.Errorf("%w (server certificate %d)", signalerror.ErrInvalidCertificate, certificate.KeyID())
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+// Package sealedsender provides sealed sender (unidentified delivery)
+// encryption, which hides the sender of a Signal message from the server
+// that relays it. It is based on:
+// https://github.com/signalapp/libsignal-metadata-java/blob/master/java/src/main/java/org/signal/libsignal/metadata/SealedSessionCipher.java
+package sealedsender
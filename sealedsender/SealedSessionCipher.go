@@ -0,0 +1,320 @@
+package sealedsender
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/kdf"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store"
+)
+
+const (
+	derivedKeysSize = 96
+	macSize         = 10
+)
+
+var saltPrefix = []byte("UnidentifiedDelivery")
+
+// NewCipher constructs a new sealed sender cipher for the given local address.
+// The local address is used to detect messages that were sent by ourselves.
+func NewCipher(signalStore store.SignalProtocol, localAddress *protocol.SignalAddress,
+	serializer *serialize.Serializer) *Cipher {
+
+	return &Cipher{
+		signalStore:  signalStore,
+		localAddress: localAddress,
+		serializer:   serializer,
+	}
+}
+
+// Cipher is the main entry point for sealed sender encryption and decryption.
+// It wraps messages encrypted with a session.Cipher in an additional layer that
+// hides the sender from the server delivering the message. A session with the
+// recipient must already exist before encrypting.
+type Cipher struct {
+	signalStore  store.SignalProtocol
+	localAddress *protocol.SignalAddress
+	serializer   *serialize.Serializer
+}
+
+// Encrypt will encrypt the given plaintext for the given destination and return
+// the serialized sealed sender message. The given sender certificate is included
+// in the message so that the recipient can learn who sent it.
+func (c *Cipher) Encrypt(ctx context.Context, destination *protocol.SignalAddress,
+	senderCertificate *protocol.SenderCertificate, plaintext []byte) ([]byte, error) {
+
+	// Get the identity key of the recipient from the session and make sure
+	// it's trusted before the session is advanced.
+	sessionRecord, err := c.signalStore.LoadSession(ctx, destination)
+	if err != nil {
+		return nil, err
+	}
+	theirIdentity := sessionRecord.SessionState().RemoteIdentityKey()
+	if theirIdentity == nil {
+		return nil, fmt.Errorf("%w %s", signalerror.ErrNoSessionForUser, destination.String())
+	}
+	trusted, err := store.IsTrustedIdentity(ctx, c.signalStore, destination, theirIdentity, store.DirectionSending)
+	if err != nil {
+		return nil, err
+	}
+	if !trusted {
+		return nil, c.untrustedIdentityError(ctx, destination, theirIdentity)
+	}
+	ourIdentity := c.signalStore.GetIdentityKeyPair()
+
+	// Encrypt the message with our regular session.
+	sessionCipher := c.sessionCipher(destination)
+	message, err := sessionCipher.Encrypt(ctx, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt our identity key with an ephemeral key.
+	ephemeral, err := ecc.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	ephemeralSalt := concat(saltPrefix, theirIdentity.Serialize(), ephemeral.PublicKey().Serialize())
+	ephemeralKeys, err := calculateKeys(theirIdentity.PublicKey(), ephemeral.PrivateKey(), ephemeralSalt)
+	if err != nil {
+		return nil, err
+	}
+	staticKeyCiphertext, err := encrypt(ephemeralKeys.cipherKey, ephemeralKeys.macKey, ourIdentity.PublicKey().Serialize())
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the message content with our identity key, which binds the message
+	// to the identity key in the sender certificate.
+	staticSalt := concat(ephemeralKeys.chainKey, staticKeyCiphertext)
+	staticKeys, err := calculateKeys(theirIdentity.PublicKey(), ourIdentity.PrivateKey(), staticSalt)
+	if err != nil {
+		return nil, err
+	}
+	content := protocol.NewUnidentifiedSenderMessageContent(message, senderCertificate, c.serializer.UnidentifiedSenderMessageContent)
+	messageCiphertext, err := encrypt(staticKeys.cipherKey, staticKeys.macKey, content.Serialize())
+	if err != nil {
+		return nil, err
+	}
+
+	sealedMessage := protocol.NewUnidentifiedSenderMessage(
+		ephemeral.PublicKey(),
+		staticKeyCiphertext,
+		messageCiphertext,
+		c.serializer.UnidentifiedSenderMessage,
+	)
+	return sealedMessage.Serialize(), nil
+}
+
+// Decrypt will decrypt the given sealed sender message and return the address
+// of the sender along with the plaintext. The sender certificate in the message
+// is validated with the given validator at the given time, which must not be
+// nil.
+func (c *Cipher) Decrypt(ctx context.Context, validator *CertificateValidator,
+	ciphertext []byte, timestamp time.Time) (*protocol.SignalAddress, []byte, error) {
+
+	if validator == nil {
+		return nil, nil, signalerror.ErrNoCertificateValidator
+	}
+	sealedMessage, err := protocol.NewUnidentifiedSenderMessageFromBytes(ciphertext, c.serializer.UnidentifiedSenderMessage)
+	if err != nil {
+		return nil, nil, err
+	}
+	ourIdentity := c.signalStore.GetIdentityKeyPair()
+
+	// Decrypt the sender's identity key with the ephemeral key.
+	ephemeralSalt := concat(saltPrefix, ourIdentity.PublicKey().Serialize(), sealedMessage.Ephemeral().Serialize())
+	ephemeralKeys, err := calculateKeys(sealedMessage.Ephemeral(), ourIdentity.PrivateKey(), ephemeralSalt)
+	if err != nil {
+		return nil, nil, err
+	}
+	staticKeyBytes, err := decrypt(ephemeralKeys.cipherKey, ephemeralKeys.macKey, sealedMessage.EncryptedStatic())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(staticKeyBytes) != ecc.KeySize {
+		return nil, nil, fmt.Errorf("%w (sealed sender static key)", signalerror.ErrIncompleteMessage)
+	}
+	staticKey, err := ecc.DecodePoint(staticKeyBytes, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decrypt the message content with the sender's identity key.
+	staticSalt := concat(ephemeralKeys.chainKey, sealedMessage.EncryptedStatic())
+	staticKeys, err := calculateKeys(staticKey, ourIdentity.PrivateKey(), staticSalt)
+	if err != nil {
+		return nil, nil, err
+	}
+	messageBytes, err := decrypt(staticKeys.cipherKey, staticKeys.macKey, sealedMessage.EncryptedMessage())
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := protocol.NewUnidentifiedSenderMessageContentFromBytes(
+		messageBytes,
+		c.serializer.UnidentifiedSenderMessageContent,
+		c.serializer.SenderCertificate,
+		c.serializer.ServerCertificate,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Make sure the sender certificate is valid and belongs to the key that
+	// encrypted the message.
+	senderCertificate := content.SenderCertificate()
+	if err := validator.Validate(senderCertificate, timestamp); err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare(senderCertificate.IdentityKey().Serialize(), staticKeyBytes) != 1 {
+		return nil, nil, signalerror.ErrCertificateKeyMismatch
+	}
+	sender := senderCertificate.Sender()
+	if sender.Name() == c.localAddress.Name() && sender.DeviceID() == c.localAddress.DeviceID() {
+		return nil, nil, signalerror.ErrSelfSend
+	}
+
+	// Decrypt the inner message with our regular session.
+	plaintext, err := c.decryptContent(ctx, sender, content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sender, plaintext, nil
+}
+
+// untrustedIdentityError returns an untrusted identity error for sending
+// to the given address, including the saved identity key if the store can
+// load it.
+func (c *Cipher) untrustedIdentityError(ctx context.Context, address *protocol.SignalAddress,
+	newIdentity *identity.Key) error {
+
+	identityErr := &session.UntrustedIdentityError{
+		Address:     address,
+		NewIdentity: newIdentity,
+		Direction:   store.DirectionSending,
+	}
+	if loader, ok := c.signalStore.(store.IdentityKeyLoader); ok {
+		storedIdentity, err := loader.LoadIdentity(ctx, address)
+		if err != nil {
+			return err
+		}
+		identityErr.StoredIdentity = storedIdentity
+	}
+	return identityErr
+}
+
+// decryptContent decrypts the signal message wrapped in the given content.
+func (c *Cipher) decryptContent(ctx context.Context, sender *protocol.SignalAddress,
+	content *protocol.UnidentifiedSenderMessageContent) ([]byte, error) {
+
+	sessionCipher := c.sessionCipher(sender)
+	switch content.Type() {
+	case protocol.PREKEY_TYPE:
+		message, err := protocol.NewPreKeySignalMessageFromBytes(content.Content(), c.serializer.PreKeySignalMessage, c.serializer.SignalMessage)
+		if err != nil {
+			return nil, err
+		}
+		return sessionCipher.DecryptMessage(ctx, message)
+	case protocol.WHISPER_TYPE:
+		message, err := protocol.NewSignalMessageFromBytes(content.Content(), c.serializer.SignalMessage)
+		if err != nil {
+			return nil, err
+		}
+		return sessionCipher.Decrypt(ctx, message)
+	default:
+		return nil, fmt.Errorf("%w %d", signalerror.ErrUnknownMessageType, content.Type())
+	}
+}
+
+// sessionCipher returns a session cipher for the given address.
+func (c *Cipher) sessionCipher(address *protocol.SignalAddress) *session.Cipher {
+	builder := session.NewBuilderFromSignal(c.signalStore, address, c.serializer)
+	return session.NewCipher(builder, address)
+}
+
+// derivedKeys is a structure for the keys derived for one layer of a sealed
+// sender message.
+type derivedKeys struct {
+	chainKey  []byte
+	cipherKey []byte
+	macKey    []byte
+}
+
+// calculateKeys derives the keys for one layer of a sealed sender message from
+// the agreement between the given keys.
+func calculateKeys(theirKey ecc.ECPublicKeyable, ourKey ecc.ECPrivateKeyable, salt []byte) (*derivedKeys, error) {
+	sharedSecret := kdf.CalculateSharedSecret(theirKey.PublicKey(), ourKey.Serialize())
+	derived, err := kdf.DeriveSecrets(sharedSecret[:], salt, nil, derivedKeysSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &derivedKeys{
+		chainKey:  derived[:32],
+		cipherKey: derived[32:64],
+		macKey:    derived[64:],
+	}, nil
+}
+
+// encrypt encrypts the given plaintext with AES-CTR and appends a truncated MAC.
+func encrypt(cipherKey, macKey, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(ciphertext, plaintext)
+
+	return append(ciphertext, calculateMAC(macKey, ciphertext)...), nil
+}
+
+// decrypt verifies the truncated MAC of the given ciphertext and decrypts it
+// with AES-CTR.
+func decrypt(cipherKey, macKey, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < macSize {
+		return nil, fmt.Errorf("%w (sealed sender)", signalerror.ErrIncompleteMessage)
+	}
+	mac := ciphertext[len(ciphertext)-macSize:]
+	ciphertext = ciphertext[:len(ciphertext)-macSize]
+	if !hmac.Equal(calculateMAC(macKey, ciphertext), mac) {
+		return nil, fmt.Errorf("%w (sealed sender)", signalerror.ErrBadMAC)
+	}
+
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(plaintext, ciphertext)
+
+	return plaintext, nil
+}
+
+// calculateMAC returns the truncated HMAC-SHA256 of the given data.
+func calculateMAC(macKey, data []byte) []byte {
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(data)
+	return mac.Sum(nil)[:macSize]
+}
+
+// concat returns the given byte slices joined into a new slice.
+func concat(parts ...[]byte) []byte {
+	var joined []byte
+	for _, part := range parts {
+		joined = append(joined, part...)
+	}
+	return joined
+}
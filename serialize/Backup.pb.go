@@ -0,0 +1,411 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: serialize/Backup.proto
+
+package serialize
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BackupStructure is the decrypted content of a backup archive. Records are
+// serialized with the protobuf record serializers, and the identity key
+// pair is an IdentityKeyPairStructure.
+type BackupStructure struct {
+	state           protoimpl.MessageState       `protogen:"open.v1"`
+	IdentityKeyPair []byte                       `protobuf:"bytes,1,opt,name=identityKeyPair" json:"identityKeyPair,omitempty"`
+	RegistrationId  *uint32                      `protobuf:"varint,2,opt,name=registrationId" json:"registrationId,omitempty"`
+	CreatedAt       *int64                       `protobuf:"varint,3,opt,name=createdAt" json:"createdAt,omitempty"`
+	PreKeys         [][]byte                     `protobuf:"bytes,4,rep,name=preKeys" json:"preKeys,omitempty"`
+	SignedPreKeys   [][]byte                     `protobuf:"bytes,5,rep,name=signedPreKeys" json:"signedPreKeys,omitempty"`
+	KyberPreKeys    [][]byte                     `protobuf:"bytes,6,rep,name=kyberPreKeys" json:"kyberPreKeys,omitempty"`
+	Identities      []*BackupStructure_Identity  `protobuf:"bytes,7,rep,name=identities" json:"identities,omitempty"`
+	Sessions        []*BackupStructure_Session   `protobuf:"bytes,8,rep,name=sessions" json:"sessions,omitempty"`
+	SenderKeys      []*BackupStructure_SenderKey `protobuf:"bytes,9,rep,name=senderKeys" json:"senderKeys,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BackupStructure) Reset() {
+	*x = BackupStructure{}
+	mi := &file_serialize_Backup_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupStructure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupStructure) ProtoMessage() {}
+
+func (x *BackupStructure) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_Backup_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupStructure.ProtoReflect.Descriptor instead.
+func (*BackupStructure) Descriptor() ([]byte, []int) {
+	return file_serialize_Backup_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BackupStructure) GetIdentityKeyPair() []byte {
+	if x != nil {
+		return x.IdentityKeyPair
+	}
+	return nil
+}
+
+func (x *BackupStructure) GetRegistrationId() uint32 {
+	if x != nil && x.RegistrationId != nil {
+		return *x.RegistrationId
+	}
+	return 0
+}
+
+func (x *BackupStructure) GetCreatedAt() int64 {
+	if x != nil && x.CreatedAt != nil {
+		return *x.CreatedAt
+	}
+	return 0
+}
+
+func (x *BackupStructure) GetPreKeys() [][]byte {
+	if x != nil {
+		return x.PreKeys
+	}
+	return nil
+}
+
+func (x *BackupStructure) GetSignedPreKeys() [][]byte {
+	if x != nil {
+		return x.SignedPreKeys
+	}
+	return nil
+}
+
+func (x *BackupStructure) GetKyberPreKeys() [][]byte {
+	if x != nil {
+		return x.KyberPreKeys
+	}
+	return nil
+}
+
+func (x *BackupStructure) GetIdentities() []*BackupStructure_Identity {
+	if x != nil {
+		return x.Identities
+	}
+	return nil
+}
+
+func (x *BackupStructure) GetSessions() []*BackupStructure_Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+func (x *BackupStructure) GetSenderKeys() []*BackupStructure_SenderKey {
+	if x != nil {
+		return x.SenderKeys
+	}
+	return nil
+}
+
+type BackupStructure_Identity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	DeviceId      *uint32                `protobuf:"varint,2,opt,name=deviceId" json:"deviceId,omitempty"`
+	IdentityKey   []byte                 `protobuf:"bytes,3,opt,name=identityKey" json:"identityKey,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupStructure_Identity) Reset() {
+	*x = BackupStructure_Identity{}
+	mi := &file_serialize_Backup_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupStructure_Identity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupStructure_Identity) ProtoMessage() {}
+
+func (x *BackupStructure_Identity) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_Backup_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupStructure_Identity.ProtoReflect.Descriptor instead.
+func (*BackupStructure_Identity) Descriptor() ([]byte, []int) {
+	return file_serialize_Backup_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *BackupStructure_Identity) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *BackupStructure_Identity) GetDeviceId() uint32 {
+	if x != nil && x.DeviceId != nil {
+		return *x.DeviceId
+	}
+	return 0
+}
+
+func (x *BackupStructure_Identity) GetIdentityKey() []byte {
+	if x != nil {
+		return x.IdentityKey
+	}
+	return nil
+}
+
+type BackupStructure_Session struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          *string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	DeviceId      *uint32                `protobuf:"varint,2,opt,name=deviceId" json:"deviceId,omitempty"`
+	Record        []byte                 `protobuf:"bytes,3,opt,name=record" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupStructure_Session) Reset() {
+	*x = BackupStructure_Session{}
+	mi := &file_serialize_Backup_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupStructure_Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupStructure_Session) ProtoMessage() {}
+
+func (x *BackupStructure_Session) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_Backup_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupStructure_Session.ProtoReflect.Descriptor instead.
+func (*BackupStructure_Session) Descriptor() ([]byte, []int) {
+	return file_serialize_Backup_proto_rawDescGZIP(), []int{0, 1}
+}
+
+func (x *BackupStructure_Session) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *BackupStructure_Session) GetDeviceId() uint32 {
+	if x != nil && x.DeviceId != nil {
+		return *x.DeviceId
+	}
+	return 0
+}
+
+func (x *BackupStructure_Session) GetRecord() []byte {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type BackupStructure_SenderKey struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	GroupId        *string                `protobuf:"bytes,1,opt,name=groupId" json:"groupId,omitempty"`
+	SenderName     *string                `protobuf:"bytes,2,opt,name=senderName" json:"senderName,omitempty"`
+	SenderDeviceId *uint32                `protobuf:"varint,3,opt,name=senderDeviceId" json:"senderDeviceId,omitempty"`
+	Record         []byte                 `protobuf:"bytes,4,opt,name=record" json:"record,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BackupStructure_SenderKey) Reset() {
+	*x = BackupStructure_SenderKey{}
+	mi := &file_serialize_Backup_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupStructure_SenderKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupStructure_SenderKey) ProtoMessage() {}
+
+func (x *BackupStructure_SenderKey) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_Backup_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupStructure_SenderKey.ProtoReflect.Descriptor instead.
+func (*BackupStructure_SenderKey) Descriptor() ([]byte, []int) {
+	return file_serialize_Backup_proto_rawDescGZIP(), []int{0, 2}
+}
+
+func (x *BackupStructure_SenderKey) GetGroupId() string {
+	if x != nil && x.GroupId != nil {
+		return *x.GroupId
+	}
+	return ""
+}
+
+func (x *BackupStructure_SenderKey) GetSenderName() string {
+	if x != nil && x.SenderName != nil {
+		return *x.SenderName
+	}
+	return ""
+}
+
+func (x *BackupStructure_SenderKey) GetSenderDeviceId() uint32 {
+	if x != nil && x.SenderDeviceId != nil {
+		return *x.SenderDeviceId
+	}
+	return 0
+}
+
+func (x *BackupStructure_SenderKey) GetRecord() []byte {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+var File_serialize_Backup_proto protoreflect.FileDescriptor
+
+const file_serialize_Backup_proto_rawDesc = "" +
+	"\n" +
+	"\x16serialize/Backup.proto\x12\n" +
+	"textsecure\"\xec\x05\n" +
+	"\x0fBackupStructure\x12(\n" +
+	"\x0fidentityKeyPair\x18\x01 \x01(\fR\x0fidentityKeyPair\x12&\n" +
+	"\x0eregistrationId\x18\x02 \x01(\rR\x0eregistrationId\x12\x1c\n" +
+	"\tcreatedAt\x18\x03 \x01(\x03R\tcreatedAt\x12\x18\n" +
+	"\apreKeys\x18\x04 \x03(\fR\apreKeys\x12$\n" +
+	"\rsignedPreKeys\x18\x05 \x03(\fR\rsignedPreKeys\x12\"\n" +
+	"\fkyberPreKeys\x18\x06 \x03(\fR\fkyberPreKeys\x12D\n" +
+	"\n" +
+	"identities\x18\a \x03(\v2$.textsecure.BackupStructure.IdentityR\n" +
+	"identities\x12?\n" +
+	"\bsessions\x18\b \x03(\v2#.textsecure.BackupStructure.SessionR\bsessions\x12E\n" +
+	"\n" +
+	"senderKeys\x18\t \x03(\v2%.textsecure.BackupStructure.SenderKeyR\n" +
+	"senderKeys\x1a\\\n" +
+	"\bIdentity\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bdeviceId\x18\x02 \x01(\rR\bdeviceId\x12 \n" +
+	"\videntityKey\x18\x03 \x01(\fR\videntityKey\x1aQ\n" +
+	"\aSession\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bdeviceId\x18\x02 \x01(\rR\bdeviceId\x12\x16\n" +
+	"\x06record\x18\x03 \x01(\fR\x06record\x1a\x85\x01\n" +
+	"\tSenderKey\x12\x18\n" +
+	"\agroupId\x18\x01 \x01(\tR\agroupId\x12\x1e\n" +
+	"\n" +
+	"senderName\x18\x02 \x01(\tR\n" +
+	"senderName\x12&\n" +
+	"\x0esenderDeviceId\x18\x03 \x01(\rR\x0esenderDeviceId\x12\x16\n" +
+	"\x06record\x18\x04 \x01(\fR\x06record"
+
+var (
+	file_serialize_Backup_proto_rawDescOnce sync.Once
+	file_serialize_Backup_proto_rawDescData []byte
+)
+
+func file_serialize_Backup_proto_rawDescGZIP() []byte {
+	file_serialize_Backup_proto_rawDescOnce.Do(func() {
+		file_serialize_Backup_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_serialize_Backup_proto_rawDesc), len(file_serialize_Backup_proto_rawDesc)))
+	})
+	return file_serialize_Backup_proto_rawDescData
+}
+
+var file_serialize_Backup_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_serialize_Backup_proto_goTypes = []any{
+	(*BackupStructure)(nil),           // 0: textsecure.BackupStructure
+	(*BackupStructure_Identity)(nil),  // 1: textsecure.BackupStructure.Identity
+	(*BackupStructure_Session)(nil),   // 2: textsecure.BackupStructure.Session
+	(*BackupStructure_SenderKey)(nil), // 3: textsecure.BackupStructure.SenderKey
+}
+var file_serialize_Backup_proto_depIdxs = []int32{
+	1, // 0: textsecure.BackupStructure.identities:type_name -> textsecure.BackupStructure.Identity
+	2, // 1: textsecure.BackupStructure.sessions:type_name -> textsecure.BackupStructure.Session
+	3, // 2: textsecure.BackupStructure.senderKeys:type_name -> textsecure.BackupStructure.SenderKey
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_serialize_Backup_proto_init() }
+func file_serialize_Backup_proto_init() {
+	if File_serialize_Backup_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_serialize_Backup_proto_rawDesc), len(file_serialize_Backup_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_serialize_Backup_proto_goTypes,
+		DependencyIndexes: file_serialize_Backup_proto_depIdxs,
+		MessageInfos:      file_serialize_Backup_proto_msgTypes,
+	}.Build()
+	File_serialize_Backup_proto = out.File
+	file_serialize_Backup_proto_goTypes = nil
+	file_serialize_Backup_proto_depIdxs = nil
+}
@@ -0,0 +1,96 @@
+package serialize
+
+import (
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// NewDetectingSerializer will return a serializer for all Signal objects that
+// can decode both JSON and ProtoBuf bytes, and always encodes objects with the
+// given target serializer. It is meant for moving stored records from one
+// format to another: records are read in whichever format they were written
+// in, and rewritten in the target format the next time they are stored.
+//
+// The format is detected from the first byte of the input. The JSON
+// serializer always produces objects, which start with '{', while none of
+// the ProtoBuf encodings can start with that byte.
+func NewDetectingSerializer(target *Serializer) *Serializer {
+	jsonSerializer := NewJSONSerializer()
+	protoSerializer := NewProtoBufSerializer()
+	serializer := NewSerializer()
+
+	serializer.SignalMessage = &detectingSerializer[protocol.SignalMessageStructure]{
+		target.SignalMessage, jsonSerializer.SignalMessage, protoSerializer.SignalMessage}
+	serializer.PreKeySignalMessage = &detectingSerializer[protocol.PreKeySignalMessageStructure]{
+		target.PreKeySignalMessage, jsonSerializer.PreKeySignalMessage, protoSerializer.PreKeySignalMessage}
+	serializer.KeyExchangeMessage = &detectingSerializer[protocol.KeyExchangeMessageStructure]{
+		target.KeyExchangeMessage, jsonSerializer.KeyExchangeMessage, protoSerializer.KeyExchangeMessage}
+	serializer.SenderKeyMessage = &detectingSerializer[protocol.SenderKeyMessageStructure]{
+		target.SenderKeyMessage, jsonSerializer.SenderKeyMessage, protoSerializer.SenderKeyMessage}
+	serializer.SenderKeyDistributionMessage = &detectingSerializer[protocol.SenderKeyDistributionMessageStructure]{
+		target.SenderKeyDistributionMessage, jsonSerializer.SenderKeyDistributionMessage, protoSerializer.SenderKeyDistributionMessage}
+	serializer.SignedPreKeyRecord = &detectingSerializer[record.SignedPreKeyStructure]{
+		target.SignedPreKeyRecord, jsonSerializer.SignedPreKeyRecord, protoSerializer.SignedPreKeyRecord}
+	serializer.PreKeyRecord = &detectingSerializer[record.PreKeyStructure]{
+		target.PreKeyRecord, jsonSerializer.PreKeyRecord, protoSerializer.PreKeyRecord}
+	serializer.KyberPreKeyRecord = &detectingSerializer[record.KyberPreKeyStructure]{
+		target.KyberPreKeyRecord, jsonSerializer.KyberPreKeyRecord, protoSerializer.KyberPreKeyRecord}
+	serializer.PreKeyBundle = &detectingSerializer[protocol.PreKeyBundleStructure]{
+		target.PreKeyBundle, jsonSerializer.PreKeyBundle, protoSerializer.PreKeyBundle}
+	serializer.IdentityKeyPair = &detectingSerializer[identity.KeyPairStructure]{
+		target.IdentityKeyPair, jsonSerializer.IdentityKeyPair, protoSerializer.IdentityKeyPair}
+	serializer.State = &detectingSerializer[record.StateStructure]{
+		target.State, jsonSerializer.State, protoSerializer.State}
+	serializer.Session = &detectingSerializer[record.SessionStructure]{
+		target.Session, jsonSerializer.Session, protoSerializer.Session}
+	serializer.SenderKeyRecord = &detectingSerializer[groupRecord.SenderKeyStructure]{
+		target.SenderKeyRecord, jsonSerializer.SenderKeyRecord, protoSerializer.SenderKeyRecord}
+	serializer.SenderKeyState = &detectingSerializer[groupRecord.SenderKeyStateStructure]{
+		target.SenderKeyState, jsonSerializer.SenderKeyState, protoSerializer.SenderKeyState}
+	serializer.ServerCertificate = &detectingSerializer[protocol.ServerCertificateStructure]{
+		target.ServerCertificate, jsonSerializer.ServerCertificate, protoSerializer.ServerCertificate}
+	serializer.SenderCertificate = &detectingSerializer[protocol.SenderCertificateStructure]{
+		target.SenderCertificate, jsonSerializer.SenderCertificate, protoSerializer.SenderCertificate}
+	serializer.UnidentifiedSenderMessage = &detectingSerializer[protocol.UnidentifiedSenderMessageStructure]{
+		target.UnidentifiedSenderMessage, jsonSerializer.UnidentifiedSenderMessage, protoSerializer.UnidentifiedSenderMessage}
+	serializer.UnidentifiedSenderMessageContent = &detectingSerializer[protocol.UnidentifiedSenderMessageContentStructure]{
+		target.UnidentifiedSenderMessageContent, jsonSerializer.UnidentifiedSenderMessageContent, protoSerializer.UnidentifiedSenderMessageContent}
+
+	return serializer
+}
+
+// IsJSON returns true if the given serialized object was produced by the
+// JSON serializer rather than the ProtoBuf serializer.
+func IsJSON(serialized []byte) bool {
+	return len(serialized) > 0 && serialized[0] == '{'
+}
+
+// structureSerializer is the interface that all serializers of a single
+// object type implement.
+type structureSerializer[T any] interface {
+	Serialize(structure *T) []byte
+	Deserialize(serialized []byte) (*T, error)
+}
+
+// detectingSerializer is a serializer for a single object type that decodes
+// both JSON and ProtoBuf bytes, and encodes with the target serializer.
+type detectingSerializer[T any] struct {
+	target   structureSerializer[T]
+	json     structureSerializer[T]
+	protobuf structureSerializer[T]
+}
+
+// Serialize will take a structure and convert it to bytes using the target serializer.
+func (d *detectingSerializer[T]) Serialize(structure *T) []byte {
+	return d.target.Serialize(structure)
+}
+
+// Deserialize will take in JSON or ProtoBuf bytes and return a structure.
+func (d *detectingSerializer[T]) Deserialize(serialized []byte) (*T, error) {
+	if IsJSON(serialized) {
+		return d.json.Deserialize(serialized)
+	}
+	return d.protobuf.Deserialize(serialized)
+}
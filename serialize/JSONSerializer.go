@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/keys/identity"
 	"go.mau.fi/libsignal/logger"
 	"go.mau.fi/libsignal/protocol"
 	"go.mau.fi/libsignal/state/record"
@@ -16,14 +17,22 @@ func NewJSONSerializer() *Serializer {
 
 	serializer.SignalMessage = &JSONSignalMessageSerializer{}
 	serializer.PreKeySignalMessage = &JSONPreKeySignalMessageSerializer{}
+	serializer.KeyExchangeMessage = &JSONKeyExchangeMessageSerializer{}
 	serializer.SignedPreKeyRecord = &JSONSignedPreKeyRecordSerializer{}
 	serializer.PreKeyRecord = &JSONPreKeyRecordSerializer{}
+	serializer.KyberPreKeyRecord = &JSONKyberPreKeyRecordSerializer{}
+	serializer.PreKeyBundle = &JSONPreKeyBundleSerializer{}
+	serializer.IdentityKeyPair = &JSONIdentityKeyPairSerializer{}
 	serializer.State = &JSONStateSerializer{}
 	serializer.Session = &JSONSessionSerializer{}
 	serializer.SenderKeyMessage = &JSONSenderKeyMessageSerializer{}
 	serializer.SenderKeyDistributionMessage = &JSONSenderKeyDistributionMessageSerializer{}
 	serializer.SenderKeyRecord = &JSONSenderKeySessionSerializer{}
 	serializer.SenderKeyState = &JSONSenderKeyStateSerializer{}
+	serializer.ServerCertificate = &JSONServerCertificateSerializer{}
+	serializer.SenderCertificate = &JSONSenderCertificateSerializer{}
+	serializer.UnidentifiedSenderMessage = &JSONUnidentifiedSenderMessageSerializer{}
+	serializer.UnidentifiedSenderMessageContent = &JSONUnidentifiedSenderMessageContentSerializer{}
 
 	return serializer
 }
@@ -82,6 +91,32 @@ func (j *JSONPreKeySignalMessageSerializer) Deserialize(serialized []byte) (*pro
 	return &preKeySignalMessage, nil
 }
 
+// JSONKeyExchangeMessageSerializer is a structure for serializing key exchange messages
+// into and from JSON.
+type JSONKeyExchangeMessageSerializer struct{}
+
+// Serialize will take a key exchange message structure and convert it to JSON bytes.
+func (j *JSONKeyExchangeMessageSerializer) Serialize(message *protocol.KeyExchangeMessageStructure) []byte {
+	serialized, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Error serializing key exchange message: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a key exchange message structure.
+func (j *JSONKeyExchangeMessageSerializer) Deserialize(serialized []byte) (*protocol.KeyExchangeMessageStructure, error) {
+	var keyExchangeMessage protocol.KeyExchangeMessageStructure
+	err := json.Unmarshal(serialized, &keyExchangeMessage)
+	if err != nil {
+		logger.Error("Error deserializing key exchange message: ", err)
+		return nil, err
+	}
+
+	return &keyExchangeMessage, nil
+}
+
 // JSONSignedPreKeyRecordSerializer is a structure for serializing signed prekey records
 // into and from JSON.
 type JSONSignedPreKeyRecordSerializer struct{}
@@ -109,6 +144,32 @@ func (j *JSONSignedPreKeyRecordSerializer) Deserialize(serialized []byte) (*reco
 	return &signedPreKeyStructure, nil
 }
 
+// JSONKyberPreKeyRecordSerializer is a structure for serializing kyber prekey records
+// into and from JSON.
+type JSONKyberPreKeyRecordSerializer struct{}
+
+// Serialize will take a kyber prekey record structure and convert it to JSON bytes.
+func (j *JSONKyberPreKeyRecordSerializer) Serialize(kyberPreKey *record.KyberPreKeyStructure) []byte {
+	serialized, err := json.Marshal(kyberPreKey)
+	if err != nil {
+		logger.Error("Error serializing kyber prekey record: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a kyber prekey record structure.
+func (j *JSONKyberPreKeyRecordSerializer) Deserialize(serialized []byte) (*record.KyberPreKeyStructure, error) {
+	var kyberPreKeyStructure record.KyberPreKeyStructure
+	err := json.Unmarshal(serialized, &kyberPreKeyStructure)
+	if err != nil {
+		logger.Error("Error deserializing kyber prekey record: ", err)
+		return nil, err
+	}
+
+	return &kyberPreKeyStructure, nil
+}
+
 // JSONPreKeyRecordSerializer is a structure for serializing prekey records
 // into and from JSON.
 type JSONPreKeyRecordSerializer struct{}
@@ -301,3 +362,285 @@ func (j *JSONSenderKeySessionSerializer) Deserialize(serialized []byte) (*groupR
 
 	return &sessionStructure, nil
 }
+
+// jsonSignedCertificate is the JSON representation of a signed certificate.
+// Only the signed certificate contents are trusted when deserializing.
+type jsonSignedCertificate struct {
+	Certificate []byte
+	Signature   []byte
+}
+
+// jsonServerCertificateContents is the JSON representation of the signed
+// contents of a server certificate.
+type jsonServerCertificateContents struct {
+	KeyID uint32
+	Key   []byte
+}
+
+// JSONServerCertificateSerializer is a structure for serializing server certificates
+// into and from JSON.
+type JSONServerCertificateSerializer struct{}
+
+// Serialize will take a server certificate structure and convert it to JSON bytes.
+func (j *JSONServerCertificateSerializer) Serialize(certificate *protocol.ServerCertificateStructure) []byte {
+	contents := certificate.Certificate
+	if contents == nil {
+		var err error
+		contents, err = json.Marshal(jsonServerCertificateContents{
+			KeyID: certificate.KeyID,
+			Key:   certificate.Key,
+		})
+		if err != nil {
+			logger.Error("Error serializing server certificate contents: ", err)
+		}
+	}
+	if certificate.Signature == nil {
+		return contents
+	}
+
+	serialized, err := json.Marshal(jsonSignedCertificate{
+		Certificate: contents,
+		Signature:   certificate.Signature,
+	})
+	if err != nil {
+		logger.Error("Error serializing server certificate: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a server certificate structure.
+func (j *JSONServerCertificateSerializer) Deserialize(serialized []byte) (*protocol.ServerCertificateStructure, error) {
+	var certificate jsonSignedCertificate
+	err := json.Unmarshal(serialized, &certificate)
+	if err != nil {
+		logger.Error("Error deserializing server certificate: ", err)
+		return nil, err
+	}
+	var contents jsonServerCertificateContents
+	err = json.Unmarshal(certificate.Certificate, &contents)
+	if err != nil {
+		logger.Error("Error deserializing server certificate contents: ", err)
+		return nil, err
+	}
+
+	return &protocol.ServerCertificateStructure{
+		KeyID:       contents.KeyID,
+		Key:         contents.Key,
+		Certificate: certificate.Certificate,
+		Signature:   certificate.Signature,
+	}, nil
+}
+
+// jsonSenderCertificateContents is the JSON representation of the signed
+// contents of a sender certificate.
+type jsonSenderCertificateContents struct {
+	Sender       string
+	SenderDevice uint32
+	Expires      uint64
+	IdentityKey  []byte
+	Signer       []byte
+}
+
+// JSONSenderCertificateSerializer is a structure for serializing sender certificates
+// into and from JSON.
+type JSONSenderCertificateSerializer struct{}
+
+// Serialize will take a sender certificate structure and convert it to JSON bytes.
+func (j *JSONSenderCertificateSerializer) Serialize(certificate *protocol.SenderCertificateStructure) []byte {
+	contents := certificate.Certificate
+	if contents == nil {
+		var signer []byte
+		if certificate.Signer != nil {
+			signer = (&JSONServerCertificateSerializer{}).Serialize(certificate.Signer)
+		}
+		var err error
+		contents, err = json.Marshal(jsonSenderCertificateContents{
+			Sender:       certificate.Sender,
+			SenderDevice: certificate.SenderDevice,
+			Expires:      certificate.Expires,
+			IdentityKey:  certificate.IdentityKey,
+			Signer:       signer,
+		})
+		if err != nil {
+			logger.Error("Error serializing sender certificate contents: ", err)
+		}
+	}
+	if certificate.Signature == nil {
+		return contents
+	}
+
+	serialized, err := json.Marshal(jsonSignedCertificate{
+		Certificate: contents,
+		Signature:   certificate.Signature,
+	})
+	if err != nil {
+		logger.Error("Error serializing sender certificate: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a sender certificate structure.
+func (j *JSONSenderCertificateSerializer) Deserialize(serialized []byte) (*protocol.SenderCertificateStructure, error) {
+	var certificate jsonSignedCertificate
+	err := json.Unmarshal(serialized, &certificate)
+	if err != nil {
+		logger.Error("Error deserializing sender certificate: ", err)
+		return nil, err
+	}
+	var contents jsonSenderCertificateContents
+	err = json.Unmarshal(certificate.Certificate, &contents)
+	if err != nil {
+		logger.Error("Error deserializing sender certificate contents: ", err)
+		return nil, err
+	}
+
+	structure := &protocol.SenderCertificateStructure{
+		Sender:       contents.Sender,
+		SenderDevice: contents.SenderDevice,
+		Expires:      contents.Expires,
+		IdentityKey:  contents.IdentityKey,
+		Certificate:  certificate.Certificate,
+		Signature:    certificate.Signature,
+	}
+	if contents.Signer != nil {
+		structure.Signer, err = (&JSONServerCertificateSerializer{}).Deserialize(contents.Signer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return structure, nil
+}
+
+// JSONUnidentifiedSenderMessageSerializer is a structure for serializing sealed sender
+// messages into and from JSON.
+type JSONUnidentifiedSenderMessageSerializer struct{}
+
+// Serialize will take a sealed sender message structure and convert it to JSON bytes.
+func (j *JSONUnidentifiedSenderMessageSerializer) Serialize(message *protocol.UnidentifiedSenderMessageStructure) []byte {
+	serialized, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("Error serializing unidentified sender message: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a sealed sender message structure.
+func (j *JSONUnidentifiedSenderMessageSerializer) Deserialize(serialized []byte) (*protocol.UnidentifiedSenderMessageStructure, error) {
+	var message protocol.UnidentifiedSenderMessageStructure
+	err := json.Unmarshal(serialized, &message)
+	if err != nil {
+		logger.Error("Error deserializing unidentified sender message: ", err)
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// jsonUnidentifiedSenderMessageContent is the JSON representation of sealed
+// sender message content.
+type jsonUnidentifiedSenderMessageContent struct {
+	Type              uint32
+	SenderCertificate []byte
+	Content           []byte
+}
+
+// JSONUnidentifiedSenderMessageContentSerializer is a structure for serializing sealed
+// sender message contents into and from JSON.
+type JSONUnidentifiedSenderMessageContentSerializer struct{}
+
+// Serialize will take a sealed sender message content structure and convert it to JSON bytes.
+func (j *JSONUnidentifiedSenderMessageContentSerializer) Serialize(content *protocol.UnidentifiedSenderMessageContentStructure) []byte {
+	var senderCertificate []byte
+	if content.SenderCertificate != nil {
+		senderCertificate = (&JSONSenderCertificateSerializer{}).Serialize(content.SenderCertificate)
+	}
+	serialized, err := json.Marshal(jsonUnidentifiedSenderMessageContent{
+		Type:              content.Type,
+		SenderCertificate: senderCertificate,
+		Content:           content.Content,
+	})
+	if err != nil {
+		logger.Error("Error serializing unidentified sender message content: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a sealed sender message content structure.
+func (j *JSONUnidentifiedSenderMessageContentSerializer) Deserialize(serialized []byte) (*protocol.UnidentifiedSenderMessageContentStructure, error) {
+	var content jsonUnidentifiedSenderMessageContent
+	err := json.Unmarshal(serialized, &content)
+	if err != nil {
+		logger.Error("Error deserializing unidentified sender message content: ", err)
+		return nil, err
+	}
+
+	structure := &protocol.UnidentifiedSenderMessageContentStructure{
+		Type:    content.Type,
+		Content: content.Content,
+	}
+	if content.SenderCertificate != nil {
+		structure.SenderCertificate, err = (&JSONSenderCertificateSerializer{}).Deserialize(content.SenderCertificate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return structure, nil
+}
+
+// JSONPreKeyBundleSerializer is a structure for serializing prekey bundles
+// into and from JSON.
+type JSONPreKeyBundleSerializer struct{}
+
+// Serialize will take a prekey bundle structure and convert it to JSON bytes.
+func (j *JSONPreKeyBundleSerializer) Serialize(bundle *protocol.PreKeyBundleStructure) []byte {
+	serialized, err := json.Marshal(bundle)
+	if err != nil {
+		logger.Error("Error serializing prekey bundle: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return a prekey bundle structure.
+func (j *JSONPreKeyBundleSerializer) Deserialize(serialized []byte) (*protocol.PreKeyBundleStructure, error) {
+	var bundle protocol.PreKeyBundleStructure
+	err := json.Unmarshal(serialized, &bundle)
+	if err != nil {
+		logger.Error("Error deserializing prekey bundle: ", err)
+		return nil, err
+	}
+
+	return &bundle, nil
+}
+
+// JSONIdentityKeyPairSerializer is a structure for serializing identity key
+// pairs into and from JSON.
+type JSONIdentityKeyPairSerializer struct{}
+
+// Serialize will take an identity key pair structure and convert it to JSON bytes.
+func (j *JSONIdentityKeyPairSerializer) Serialize(keyPair *identity.KeyPairStructure) []byte {
+	serialized, err := json.Marshal(keyPair)
+	if err != nil {
+		logger.Error("Error serializing identity key pair: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in JSON bytes and return an identity key pair structure.
+func (j *JSONIdentityKeyPairSerializer) Deserialize(serialized []byte) (*identity.KeyPairStructure, error) {
+	var keyPair identity.KeyPairStructure
+	err := json.Unmarshal(serialized, &keyPair)
+	if err != nil {
+		logger.Error("Error deserializing identity key pair: ", err)
+		return nil, err
+	}
+
+	return &keyPair, nil
+}
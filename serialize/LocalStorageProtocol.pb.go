@@ -2,18 +2,18 @@
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
-// 	protoc        v3.12.4
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
 // source: serialize/LocalStorageProtocol.proto
 
 package serialize
 
 import (
-	reflect "reflect"
-	sync "sync"
-
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -24,10 +24,7 @@ const (
 )
 
 type SessionStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
+	state                protoimpl.MessageState               `protogen:"open.v1"`
 	SessionVersion       *uint32                              `protobuf:"varint,1,opt,name=sessionVersion" json:"sessionVersion,omitempty"`
 	LocalIdentityPublic  []byte                               `protobuf:"bytes,2,opt,name=localIdentityPublic" json:"localIdentityPublic,omitempty"`
 	RemoteIdentityPublic []byte                               `protobuf:"bytes,3,opt,name=remoteIdentityPublic" json:"remoteIdentityPublic,omitempty"`
@@ -41,15 +38,16 @@ type SessionStructure struct {
 	LocalRegistrationId  *uint32                              `protobuf:"varint,11,opt,name=localRegistrationId" json:"localRegistrationId,omitempty"`
 	NeedsRefresh         *bool                                `protobuf:"varint,12,opt,name=needsRefresh" json:"needsRefresh,omitempty"`
 	AliceBaseKey         []byte                               `protobuf:"bytes,13,opt,name=aliceBaseKey" json:"aliceBaseKey,omitempty"`
+	PendingKyberPreKey   *SessionStructure_PendingKyberPreKey `protobuf:"bytes,15,opt,name=pendingKyberPreKey" json:"pendingKyberPreKey,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *SessionStructure) Reset() {
 	*x = SessionStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SessionStructure) String() string {
@@ -60,7 +58,7 @@ func (*SessionStructure) ProtoMessage() {}
 
 func (x *SessionStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -166,22 +164,26 @@ func (x *SessionStructure) GetAliceBaseKey() []byte {
 	return nil
 }
 
-type RecordStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *SessionStructure) GetPendingKyberPreKey() *SessionStructure_PendingKyberPreKey {
+	if x != nil {
+		return x.PendingKyberPreKey
+	}
+	return nil
+}
 
-	CurrentSession   *SessionStructure   `protobuf:"bytes,1,opt,name=currentSession" json:"currentSession,omitempty"`
-	PreviousSessions []*SessionStructure `protobuf:"bytes,2,rep,name=previousSessions" json:"previousSessions,omitempty"`
+type RecordStructure struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	CurrentSession   *SessionStructure      `protobuf:"bytes,1,opt,name=currentSession" json:"currentSession,omitempty"`
+	PreviousSessions []*SessionStructure    `protobuf:"bytes,2,rep,name=previousSessions" json:"previousSessions,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *RecordStructure) Reset() {
 	*x = RecordStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *RecordStructure) String() string {
@@ -192,7 +194,7 @@ func (*RecordStructure) ProtoMessage() {}
 
 func (x *RecordStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -222,22 +224,19 @@ func (x *RecordStructure) GetPreviousSessions() []*SessionStructure {
 }
 
 type PreKeyRecordStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *uint32                `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	PublicKey     []byte                 `protobuf:"bytes,2,opt,name=publicKey" json:"publicKey,omitempty"`
+	PrivateKey    []byte                 `protobuf:"bytes,3,opt,name=privateKey" json:"privateKey,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id         *uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	PublicKey  []byte  `protobuf:"bytes,2,opt,name=publicKey" json:"publicKey,omitempty"`
-	PrivateKey []byte  `protobuf:"bytes,3,opt,name=privateKey" json:"privateKey,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PreKeyRecordStructure) Reset() {
 	*x = PreKeyRecordStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *PreKeyRecordStructure) String() string {
@@ -248,7 +247,7 @@ func (*PreKeyRecordStructure) ProtoMessage() {}
 
 func (x *PreKeyRecordStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -285,24 +284,21 @@ func (x *PreKeyRecordStructure) GetPrivateKey() []byte {
 }
 
 type SignedPreKeyRecordStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *uint32                `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	PublicKey     []byte                 `protobuf:"bytes,2,opt,name=publicKey" json:"publicKey,omitempty"`
+	PrivateKey    []byte                 `protobuf:"bytes,3,opt,name=privateKey" json:"privateKey,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,4,opt,name=signature" json:"signature,omitempty"`
+	Timestamp     *uint64                `protobuf:"fixed64,5,opt,name=timestamp" json:"timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id         *uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	PublicKey  []byte  `protobuf:"bytes,2,opt,name=publicKey" json:"publicKey,omitempty"`
-	PrivateKey []byte  `protobuf:"bytes,3,opt,name=privateKey" json:"privateKey,omitempty"`
-	Signature  []byte  `protobuf:"bytes,4,opt,name=signature" json:"signature,omitempty"`
-	Timestamp  *uint64 `protobuf:"fixed64,5,opt,name=timestamp" json:"timestamp,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SignedPreKeyRecordStructure) Reset() {
 	*x = SignedPreKeyRecordStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SignedPreKeyRecordStructure) String() string {
@@ -313,7 +309,7 @@ func (*SignedPreKeyRecordStructure) ProtoMessage() {}
 
 func (x *SignedPreKeyRecordStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -364,21 +360,18 @@ func (x *SignedPreKeyRecordStructure) GetTimestamp() uint64 {
 }
 
 type IdentityKeyPairStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PublicKey     []byte                 `protobuf:"bytes,1,opt,name=publicKey" json:"publicKey,omitempty"`
+	PrivateKey    []byte                 `protobuf:"bytes,2,opt,name=privateKey" json:"privateKey,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	PublicKey  []byte `protobuf:"bytes,1,opt,name=publicKey" json:"publicKey,omitempty"`
-	PrivateKey []byte `protobuf:"bytes,2,opt,name=privateKey" json:"privateKey,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *IdentityKeyPairStructure) Reset() {
 	*x = IdentityKeyPairStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *IdentityKeyPairStructure) String() string {
@@ -389,7 +382,7 @@ func (*IdentityKeyPairStructure) ProtoMessage() {}
 
 func (x *IdentityKeyPairStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -419,23 +412,20 @@ func (x *IdentityKeyPairStructure) GetPrivateKey() []byte {
 }
 
 type SenderKeyStateStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
+	state             protoimpl.MessageState                      `protogen:"open.v1"`
 	SenderKeyId       *uint32                                     `protobuf:"varint,1,opt,name=senderKeyId" json:"senderKeyId,omitempty"`
 	SenderChainKey    *SenderKeyStateStructure_SenderChainKey     `protobuf:"bytes,2,opt,name=senderChainKey" json:"senderChainKey,omitempty"`
 	SenderSigningKey  *SenderKeyStateStructure_SenderSigningKey   `protobuf:"bytes,3,opt,name=senderSigningKey" json:"senderSigningKey,omitempty"`
 	SenderMessageKeys []*SenderKeyStateStructure_SenderMessageKey `protobuf:"bytes,4,rep,name=senderMessageKeys" json:"senderMessageKeys,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *SenderKeyStateStructure) Reset() {
 	*x = SenderKeyStateStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyStateStructure) String() string {
@@ -446,7 +436,7 @@ func (*SenderKeyStateStructure) ProtoMessage() {}
 
 func (x *SenderKeyStateStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -490,20 +480,17 @@ func (x *SenderKeyStateStructure) GetSenderMessageKeys() []*SenderKeyStateStruct
 }
 
 type SenderKeyRecordStructure struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
+	state           protoimpl.MessageState     `protogen:"open.v1"`
 	SenderKeyStates []*SenderKeyStateStructure `protobuf:"bytes,1,rep,name=senderKeyStates" json:"senderKeyStates,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *SenderKeyRecordStructure) Reset() {
 	*x = SenderKeyRecordStructure{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[6]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyRecordStructure) String() string {
@@ -514,7 +501,7 @@ func (*SenderKeyRecordStructure) ProtoMessage() {}
 
 func (x *SenderKeyRecordStructure) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[6]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -537,23 +524,20 @@ func (x *SenderKeyRecordStructure) GetSenderKeyStates() []*SenderKeyStateStructu
 }
 
 type SessionStructure_Chain struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
+	state                   protoimpl.MessageState               `protogen:"open.v1"`
 	SenderRatchetKey        []byte                               `protobuf:"bytes,1,opt,name=senderRatchetKey" json:"senderRatchetKey,omitempty"`
 	SenderRatchetKeyPrivate []byte                               `protobuf:"bytes,2,opt,name=senderRatchetKeyPrivate" json:"senderRatchetKeyPrivate,omitempty"`
 	ChainKey                *SessionStructure_Chain_ChainKey     `protobuf:"bytes,3,opt,name=chainKey" json:"chainKey,omitempty"`
 	MessageKeys             []*SessionStructure_Chain_MessageKey `protobuf:"bytes,4,rep,name=messageKeys" json:"messageKeys,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *SessionStructure_Chain) Reset() {
 	*x = SessionStructure_Chain{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SessionStructure_Chain) String() string {
@@ -564,7 +548,7 @@ func (*SessionStructure_Chain) ProtoMessage() {}
 
 func (x *SessionStructure_Chain) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -608,26 +592,23 @@ func (x *SessionStructure_Chain) GetMessageKeys() []*SessionStructure_Chain_Mess
 }
 
 type SessionStructure_PendingKeyExchange struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	Sequence                *uint32 `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
-	LocalBaseKey            []byte  `protobuf:"bytes,2,opt,name=localBaseKey" json:"localBaseKey,omitempty"`
-	LocalBaseKeyPrivate     []byte  `protobuf:"bytes,3,opt,name=localBaseKeyPrivate" json:"localBaseKeyPrivate,omitempty"`
-	LocalRatchetKey         []byte  `protobuf:"bytes,4,opt,name=localRatchetKey" json:"localRatchetKey,omitempty"`
-	LocalRatchetKeyPrivate  []byte  `protobuf:"bytes,5,opt,name=localRatchetKeyPrivate" json:"localRatchetKeyPrivate,omitempty"`
-	LocalIdentityKey        []byte  `protobuf:"bytes,7,opt,name=localIdentityKey" json:"localIdentityKey,omitempty"`
-	LocalIdentityKeyPrivate []byte  `protobuf:"bytes,8,opt,name=localIdentityKeyPrivate" json:"localIdentityKeyPrivate,omitempty"`
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Sequence                *uint32                `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	LocalBaseKey            []byte                 `protobuf:"bytes,2,opt,name=localBaseKey" json:"localBaseKey,omitempty"`
+	LocalBaseKeyPrivate     []byte                 `protobuf:"bytes,3,opt,name=localBaseKeyPrivate" json:"localBaseKeyPrivate,omitempty"`
+	LocalRatchetKey         []byte                 `protobuf:"bytes,4,opt,name=localRatchetKey" json:"localRatchetKey,omitempty"`
+	LocalRatchetKeyPrivate  []byte                 `protobuf:"bytes,5,opt,name=localRatchetKeyPrivate" json:"localRatchetKeyPrivate,omitempty"`
+	LocalIdentityKey        []byte                 `protobuf:"bytes,7,opt,name=localIdentityKey" json:"localIdentityKey,omitempty"`
+	LocalIdentityKeyPrivate []byte                 `protobuf:"bytes,8,opt,name=localIdentityKeyPrivate" json:"localIdentityKeyPrivate,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *SessionStructure_PendingKeyExchange) Reset() {
 	*x = SessionStructure_PendingKeyExchange{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[8]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SessionStructure_PendingKeyExchange) String() string {
@@ -638,7 +619,7 @@ func (*SessionStructure_PendingKeyExchange) ProtoMessage() {}
 
 func (x *SessionStructure_PendingKeyExchange) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[8]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -703,22 +684,19 @@ func (x *SessionStructure_PendingKeyExchange) GetLocalIdentityKeyPrivate() []byt
 }
 
 type SessionStructure_PendingPreKey struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	PreKeyId       *uint32 `protobuf:"varint,1,opt,name=preKeyId" json:"preKeyId,omitempty"`
-	SignedPreKeyId *int32  `protobuf:"varint,3,opt,name=signedPreKeyId" json:"signedPreKeyId,omitempty"`
-	BaseKey        []byte  `protobuf:"bytes,2,opt,name=baseKey" json:"baseKey,omitempty"`
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PreKeyId       *uint32                `protobuf:"varint,1,opt,name=preKeyId" json:"preKeyId,omitempty"`
+	SignedPreKeyId *int32                 `protobuf:"varint,3,opt,name=signedPreKeyId" json:"signedPreKeyId,omitempty"`
+	BaseKey        []byte                 `protobuf:"bytes,2,opt,name=baseKey" json:"baseKey,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SessionStructure_PendingPreKey) Reset() {
 	*x = SessionStructure_PendingPreKey{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[9]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SessionStructure_PendingPreKey) String() string {
@@ -729,7 +707,7 @@ func (*SessionStructure_PendingPreKey) ProtoMessage() {}
 
 func (x *SessionStructure_PendingPreKey) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[9]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -765,22 +743,71 @@ func (x *SessionStructure_PendingPreKey) GetBaseKey() []byte {
 	return nil
 }
 
-type SessionStructure_Chain_ChainKey struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+type SessionStructure_PendingKyberPreKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PreKeyId      *uint32                `protobuf:"varint,1,opt,name=preKeyId" json:"preKeyId,omitempty"`
+	Ciphertext    []byte                 `protobuf:"bytes,2,opt,name=ciphertext" json:"ciphertext,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	Index *uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
-	Key   []byte  `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+func (x *SessionStructure_PendingKyberPreKey) Reset() {
+	*x = SessionStructure_PendingKyberPreKey{}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *SessionStructure_Chain_ChainKey) Reset() {
-	*x = SessionStructure_Chain_ChainKey{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[10]
+func (x *SessionStructure_PendingKyberPreKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionStructure_PendingKyberPreKey) ProtoMessage() {}
+
+func (x *SessionStructure_PendingKyberPreKey) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[10]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionStructure_PendingKyberPreKey.ProtoReflect.Descriptor instead.
+func (*SessionStructure_PendingKyberPreKey) Descriptor() ([]byte, []int) {
+	return file_serialize_LocalStorageProtocol_proto_rawDescGZIP(), []int{0, 3}
+}
+
+func (x *SessionStructure_PendingKyberPreKey) GetPreKeyId() uint32 {
+	if x != nil && x.PreKeyId != nil {
+		return *x.PreKeyId
+	}
+	return 0
+}
+
+func (x *SessionStructure_PendingKyberPreKey) GetCiphertext() []byte {
+	if x != nil {
+		return x.Ciphertext
 	}
+	return nil
+}
+
+type SessionStructure_Chain_ChainKey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         *uint32                `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Key           []byte                 `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionStructure_Chain_ChainKey) Reset() {
+	*x = SessionStructure_Chain_ChainKey{}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SessionStructure_Chain_ChainKey) String() string {
@@ -790,8 +817,8 @@ func (x *SessionStructure_Chain_ChainKey) String() string {
 func (*SessionStructure_Chain_ChainKey) ProtoMessage() {}
 
 func (x *SessionStructure_Chain_ChainKey) ProtoReflect() protoreflect.Message {
-	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[10]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[11]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -821,23 +848,20 @@ func (x *SessionStructure_Chain_ChainKey) GetKey() []byte {
 }
 
 type SessionStructure_Chain_MessageKey struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         *uint32                `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	CipherKey     []byte                 `protobuf:"bytes,2,opt,name=cipherKey" json:"cipherKey,omitempty"`
+	MacKey        []byte                 `protobuf:"bytes,3,opt,name=macKey" json:"macKey,omitempty"`
+	Iv            []byte                 `protobuf:"bytes,4,opt,name=iv" json:"iv,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Index     *uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
-	CipherKey []byte  `protobuf:"bytes,2,opt,name=cipherKey" json:"cipherKey,omitempty"`
-	MacKey    []byte  `protobuf:"bytes,3,opt,name=macKey" json:"macKey,omitempty"`
-	Iv        []byte  `protobuf:"bytes,4,opt,name=iv" json:"iv,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SessionStructure_Chain_MessageKey) Reset() {
 	*x = SessionStructure_Chain_MessageKey{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[11]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SessionStructure_Chain_MessageKey) String() string {
@@ -847,8 +871,8 @@ func (x *SessionStructure_Chain_MessageKey) String() string {
 func (*SessionStructure_Chain_MessageKey) ProtoMessage() {}
 
 func (x *SessionStructure_Chain_MessageKey) ProtoReflect() protoreflect.Message {
-	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[11]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[12]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -892,21 +916,18 @@ func (x *SessionStructure_Chain_MessageKey) GetIv() []byte {
 }
 
 type SenderKeyStateStructure_SenderChainKey struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Iteration     *uint32                `protobuf:"varint,1,opt,name=iteration" json:"iteration,omitempty"`
+	Seed          []byte                 `protobuf:"bytes,2,opt,name=seed" json:"seed,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Iteration *uint32 `protobuf:"varint,1,opt,name=iteration" json:"iteration,omitempty"`
-	Seed      []byte  `protobuf:"bytes,2,opt,name=seed" json:"seed,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SenderKeyStateStructure_SenderChainKey) Reset() {
 	*x = SenderKeyStateStructure_SenderChainKey{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[12]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyStateStructure_SenderChainKey) String() string {
@@ -916,8 +937,8 @@ func (x *SenderKeyStateStructure_SenderChainKey) String() string {
 func (*SenderKeyStateStructure_SenderChainKey) ProtoMessage() {}
 
 func (x *SenderKeyStateStructure_SenderChainKey) ProtoReflect() protoreflect.Message {
-	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[12]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[13]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -947,21 +968,18 @@ func (x *SenderKeyStateStructure_SenderChainKey) GetSeed() []byte {
 }
 
 type SenderKeyStateStructure_SenderMessageKey struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Iteration     *uint32                `protobuf:"varint,1,opt,name=iteration" json:"iteration,omitempty"`
+	Seed          []byte                 `protobuf:"bytes,2,opt,name=seed" json:"seed,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Iteration *uint32 `protobuf:"varint,1,opt,name=iteration" json:"iteration,omitempty"`
-	Seed      []byte  `protobuf:"bytes,2,opt,name=seed" json:"seed,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SenderKeyStateStructure_SenderMessageKey) Reset() {
 	*x = SenderKeyStateStructure_SenderMessageKey{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[13]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyStateStructure_SenderMessageKey) String() string {
@@ -971,8 +989,8 @@ func (x *SenderKeyStateStructure_SenderMessageKey) String() string {
 func (*SenderKeyStateStructure_SenderMessageKey) ProtoMessage() {}
 
 func (x *SenderKeyStateStructure_SenderMessageKey) ProtoReflect() protoreflect.Message {
-	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[13]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[14]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -1002,21 +1020,18 @@ func (x *SenderKeyStateStructure_SenderMessageKey) GetSeed() []byte {
 }
 
 type SenderKeyStateStructure_SenderSigningKey struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Public        []byte                 `protobuf:"bytes,1,opt,name=public" json:"public,omitempty"`
+	Private       []byte                 `protobuf:"bytes,2,opt,name=private" json:"private,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Public  []byte `protobuf:"bytes,1,opt,name=public" json:"public,omitempty"`
-	Private []byte `protobuf:"bytes,2,opt,name=private" json:"private,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SenderKeyStateStructure_SenderSigningKey) Reset() {
 	*x = SenderKeyStateStructure_SenderSigningKey{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[14]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyStateStructure_SenderSigningKey) String() string {
@@ -1026,8 +1041,8 @@ func (x *SenderKeyStateStructure_SenderSigningKey) String() string {
 func (*SenderKeyStateStructure_SenderSigningKey) ProtoMessage() {}
 
 func (x *SenderKeyStateStructure_SenderSigningKey) ProtoReflect() protoreflect.Message {
-	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[14]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_serialize_LocalStorageProtocol_proto_msgTypes[15]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -1058,205 +1073,111 @@ func (x *SenderKeyStateStructure_SenderSigningKey) GetPrivate() []byte {
 
 var File_serialize_LocalStorageProtocol_proto protoreflect.FileDescriptor
 
-var file_serialize_LocalStorageProtocol_proto_rawDesc = []byte{
-	0x0a, 0x24, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x2f, 0x4c, 0x6f, 0x63, 0x61,
-	0x6c, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65, 0x63, 0x75,
-	0x72, 0x65, 0x22, 0xbf, 0x0c, 0x0a, 0x10, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74,
-	0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x65, 0x73, 0x73, 0x69,
-	0x6f, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x0e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x30, 0x0a, 0x13, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
-	0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x13, 0x6c, 0x6f,
-	0x63, 0x61, 0x6c, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x50, 0x75, 0x62, 0x6c, 0x69,
-	0x63, 0x12, 0x32, 0x0a, 0x14, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x74, 0x79, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x14, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x50,
-	0x75, 0x62, 0x6c, 0x69, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x74, 0x4b, 0x65, 0x79,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x72, 0x6f, 0x6f, 0x74, 0x4b, 0x65, 0x79, 0x12,
-	0x28, 0x0a, 0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74,
-	0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f,
-	0x75, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x44, 0x0a, 0x0b, 0x73, 0x65, 0x6e,
-	0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22,
-	0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x43, 0x68, 0x61,
-	0x69, 0x6e, 0x52, 0x0b, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12,
-	0x4a, 0x0a, 0x0e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65,
-	0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x75,
-	0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x52, 0x0e, 0x72, 0x65, 0x63,
-	0x65, 0x69, 0x76, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x5f, 0x0a, 0x12, 0x70,
-	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67,
-	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65,
-	0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x75,
-	0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79,
-	0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x12, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e,
-	0x67, 0x4b, 0x65, 0x79, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x50, 0x0a, 0x0d,
-	0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x09, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65,
-	0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72,
-	0x65, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x52,
-	0x0d, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x32,
-	0x0a, 0x14, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x72, 0x65,
-	0x6d, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x49, 0x64, 0x12, 0x30, 0x0a, 0x13, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x52, 0x65, 0x67, 0x69, 0x73,
-	0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x13, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x65, 0x65, 0x64, 0x73, 0x52, 0x65, 0x66,
-	0x72, 0x65, 0x73, 0x68, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x6e, 0x65, 0x65, 0x64,
-	0x73, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x12, 0x22, 0x0a, 0x0c, 0x61, 0x6c, 0x69, 0x63,
-	0x65, 0x42, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c,
-	0x61, 0x6c, 0x69, 0x63, 0x65, 0x42, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x1a, 0xa5, 0x03, 0x0a,
-	0x05, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x2a, 0x0a, 0x10, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72,
-	0x52, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x10, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b,
-	0x65, 0x79, 0x12, 0x38, 0x0a, 0x17, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x61, 0x74, 0x63,
-	0x68, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x17, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x61, 0x74, 0x63, 0x68,
-	0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x47, 0x0a, 0x08,
-	0x63, 0x68, 0x61, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b,
-	0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x43, 0x68, 0x61,
-	0x69, 0x6e, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x52, 0x08, 0x63, 0x68, 0x61,
-	0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x4f, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x4b, 0x65, 0x79, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x74, 0x65, 0x78,
-	0x74, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53,
-	0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x4b, 0x65, 0x79, 0x73, 0x1a, 0x32, 0x0a, 0x08, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x4b,
-	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x1a, 0x68, 0x0a, 0x0a, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65,
-	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c,
-	0x0a, 0x09, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x09, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06,
-	0x6d, 0x61, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6d, 0x61,
-	0x63, 0x4b, 0x65, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x76, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x02, 0x69, 0x76, 0x1a, 0xce, 0x02, 0x0a, 0x12, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
-	0x4b, 0x65, 0x79, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73,
-	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x73,
-	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
-	0x42, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x6c,
-	0x6f, 0x63, 0x61, 0x6c, 0x42, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x30, 0x0a, 0x13, 0x6c,
-	0x6f, 0x63, 0x61, 0x6c, 0x42, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x69, 0x76, 0x61,
-	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x13, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x42,
-	0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12, 0x28, 0x0a,
-	0x0f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x52, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b, 0x65, 0x79,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x52, 0x61, 0x74,
-	0x63, 0x68, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x36, 0x0a, 0x16, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
-	0x52, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74,
-	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x16, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x52, 0x61,
-	0x74, 0x63, 0x68, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x12,
-	0x2a, 0x0a, 0x10, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
-	0x4b, 0x65, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
-	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x38, 0x0a, 0x17, 0x6c,
-	0x6f, 0x63, 0x61, 0x6c, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x4b, 0x65, 0x79, 0x50,
-	0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x17, 0x6c, 0x6f,
-	0x63, 0x61, 0x6c, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x4b, 0x65, 0x79, 0x50, 0x72,
-	0x69, 0x76, 0x61, 0x74, 0x65, 0x1a, 0x6d, 0x0a, 0x0d, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67,
-	0x50, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x4b, 0x65, 0x79,
-	0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x72, 0x65, 0x4b, 0x65, 0x79,
-	0x49, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x50, 0x72, 0x65, 0x4b,
-	0x65, 0x79, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x73, 0x69, 0x67, 0x6e,
-	0x65, 0x64, 0x50, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61,
-	0x73, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x62, 0x61, 0x73,
-	0x65, 0x4b, 0x65, 0x79, 0x22, 0xa1, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53,
-	0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x44, 0x0a, 0x0e, 0x63, 0x75, 0x72, 0x72,
-	0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1c, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x52, 0x0e,
-	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x48,
-	0x0a, 0x10, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73,
-	0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x72,
-	0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x52, 0x10, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73,
-	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x65, 0x0a, 0x15, 0x50, 0x72, 0x65, 0x4b,
-	0x65, 0x79, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72,
-	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69,
-	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12,
-	0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x22,
-	0xa7, 0x01, 0x0a, 0x1b, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x50, 0x72, 0x65, 0x4b, 0x65, 0x79,
-	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12,
-	0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x1e, 0x0a,
-	0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a,
-	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x06, 0x52, 0x09,
-	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x58, 0x0a, 0x18, 0x49, 0x64, 0x65,
-	0x6e, 0x74, 0x69, 0x74, 0x79, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x69, 0x72, 0x53, 0x74, 0x72, 0x75,
-	0x63, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b,
-	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
-	0x4b, 0x65, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65,
-	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65,
-	0x4b, 0x65, 0x79, 0x22, 0xad, 0x04, 0x0a, 0x17, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65,
-	0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12,
-	0x20, 0x0a, 0x0b, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x49, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x49,
-	0x64, 0x12, 0x5a, 0x0a, 0x0e, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x74, 0x65, 0x78, 0x74,
-	0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x53,
-	0x65, 0x6e, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x52, 0x0e, 0x73,
-	0x65, 0x6e, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x60, 0x0a,
-	0x10, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65,
-	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65,
-	0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x74,
-	0x61, 0x74, 0x65, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x6e,
-	0x64, 0x65, 0x72, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x52, 0x10, 0x73,
-	0x65, 0x6e, 0x64, 0x65, 0x72, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x12,
-	0x62, 0x0a, 0x11, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x4b, 0x65, 0x79, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x74, 0x65, 0x78,
-	0x74, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65,
-	0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x2e,
-	0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79,
-	0x52, 0x11, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4b,
-	0x65, 0x79, 0x73, 0x1a, 0x42, 0x0a, 0x0e, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x43, 0x68, 0x61,
-	0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x1a, 0x44, 0x0a, 0x10, 0x53, 0x65, 0x6e, 0x64, 0x65,
-	0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x69,
-	0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
-	0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65,
-	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x1a, 0x44, 0x0a,
-	0x10, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65,
-	0x79, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x69,
-	0x76, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x72, 0x69, 0x76,
-	0x61, 0x74, 0x65, 0x22, 0x69, 0x0a, 0x18, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79,
-	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x12,
-	0x4d, 0x0a, 0x0f, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x74, 0x61, 0x74,
-	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x73,
-	0x65, 0x63, 0x75, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x52, 0x0f, 0x73,
-	0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x42, 0x33,
-	0x0a, 0x22, 0x6f, 0x72, 0x67, 0x2e, 0x77, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x73, 0x79, 0x73,
-	0x74, 0x65, 0x6d, 0x73, 0x2e, 0x6c, 0x69, 0x62, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x2e, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x42, 0x0d, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x6f,
-	0x74, 0x6f, 0x73,
-}
+const file_serialize_LocalStorageProtocol_proto_rawDesc = "" +
+	"\n" +
+	"$serialize/LocalStorageProtocol.proto\x12\n" +
+	"textsecure\"\xf2\r\n" +
+	"\x10SessionStructure\x12&\n" +
+	"\x0esessionVersion\x18\x01 \x01(\rR\x0esessionVersion\x120\n" +
+	"\x13localIdentityPublic\x18\x02 \x01(\fR\x13localIdentityPublic\x122\n" +
+	"\x14remoteIdentityPublic\x18\x03 \x01(\fR\x14remoteIdentityPublic\x12\x18\n" +
+	"\arootKey\x18\x04 \x01(\fR\arootKey\x12(\n" +
+	"\x0fpreviousCounter\x18\x05 \x01(\rR\x0fpreviousCounter\x12D\n" +
+	"\vsenderChain\x18\x06 \x01(\v2\".textsecure.SessionStructure.ChainR\vsenderChain\x12J\n" +
+	"\x0ereceiverChains\x18\a \x03(\v2\".textsecure.SessionStructure.ChainR\x0ereceiverChains\x12_\n" +
+	"\x12pendingKeyExchange\x18\b \x01(\v2/.textsecure.SessionStructure.PendingKeyExchangeR\x12pendingKeyExchange\x12P\n" +
+	"\rpendingPreKey\x18\t \x01(\v2*.textsecure.SessionStructure.PendingPreKeyR\rpendingPreKey\x122\n" +
+	"\x14remoteRegistrationId\x18\n" +
+	" \x01(\rR\x14remoteRegistrationId\x120\n" +
+	"\x13localRegistrationId\x18\v \x01(\rR\x13localRegistrationId\x12\"\n" +
+	"\fneedsRefresh\x18\f \x01(\bR\fneedsRefresh\x12\"\n" +
+	"\faliceBaseKey\x18\r \x01(\fR\faliceBaseKey\x12_\n" +
+	"\x12pendingKyberPreKey\x18\x0f \x01(\v2/.textsecure.SessionStructure.PendingKyberPreKeyR\x12pendingKyberPreKey\x1a\xa5\x03\n" +
+	"\x05Chain\x12*\n" +
+	"\x10senderRatchetKey\x18\x01 \x01(\fR\x10senderRatchetKey\x128\n" +
+	"\x17senderRatchetKeyPrivate\x18\x02 \x01(\fR\x17senderRatchetKeyPrivate\x12G\n" +
+	"\bchainKey\x18\x03 \x01(\v2+.textsecure.SessionStructure.Chain.ChainKeyR\bchainKey\x12O\n" +
+	"\vmessageKeys\x18\x04 \x03(\v2-.textsecure.SessionStructure.Chain.MessageKeyR\vmessageKeys\x1a2\n" +
+	"\bChainKey\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\fR\x03key\x1ah\n" +
+	"\n" +
+	"MessageKey\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x1c\n" +
+	"\tcipherKey\x18\x02 \x01(\fR\tcipherKey\x12\x16\n" +
+	"\x06macKey\x18\x03 \x01(\fR\x06macKey\x12\x0e\n" +
+	"\x02iv\x18\x04 \x01(\fR\x02iv\x1a\xce\x02\n" +
+	"\x12PendingKeyExchange\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\rR\bsequence\x12\"\n" +
+	"\flocalBaseKey\x18\x02 \x01(\fR\flocalBaseKey\x120\n" +
+	"\x13localBaseKeyPrivate\x18\x03 \x01(\fR\x13localBaseKeyPrivate\x12(\n" +
+	"\x0flocalRatchetKey\x18\x04 \x01(\fR\x0flocalRatchetKey\x126\n" +
+	"\x16localRatchetKeyPrivate\x18\x05 \x01(\fR\x16localRatchetKeyPrivate\x12*\n" +
+	"\x10localIdentityKey\x18\a \x01(\fR\x10localIdentityKey\x128\n" +
+	"\x17localIdentityKeyPrivate\x18\b \x01(\fR\x17localIdentityKeyPrivate\x1am\n" +
+	"\rPendingPreKey\x12\x1a\n" +
+	"\bpreKeyId\x18\x01 \x01(\rR\bpreKeyId\x12&\n" +
+	"\x0esignedPreKeyId\x18\x03 \x01(\x05R\x0esignedPreKeyId\x12\x18\n" +
+	"\abaseKey\x18\x02 \x01(\fR\abaseKey\x1aP\n" +
+	"\x12PendingKyberPreKey\x12\x1a\n" +
+	"\bpreKeyId\x18\x01 \x01(\rR\bpreKeyId\x12\x1e\n" +
+	"\n" +
+	"ciphertext\x18\x02 \x01(\fR\n" +
+	"ciphertext\"\xa1\x01\n" +
+	"\x0fRecordStructure\x12D\n" +
+	"\x0ecurrentSession\x18\x01 \x01(\v2\x1c.textsecure.SessionStructureR\x0ecurrentSession\x12H\n" +
+	"\x10previousSessions\x18\x02 \x03(\v2\x1c.textsecure.SessionStructureR\x10previousSessions\"e\n" +
+	"\x15PreKeyRecordStructure\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1c\n" +
+	"\tpublicKey\x18\x02 \x01(\fR\tpublicKey\x12\x1e\n" +
+	"\n" +
+	"privateKey\x18\x03 \x01(\fR\n" +
+	"privateKey\"\xa7\x01\n" +
+	"\x1bSignedPreKeyRecordStructure\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1c\n" +
+	"\tpublicKey\x18\x02 \x01(\fR\tpublicKey\x12\x1e\n" +
+	"\n" +
+	"privateKey\x18\x03 \x01(\fR\n" +
+	"privateKey\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\fR\tsignature\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x06R\ttimestamp\"X\n" +
+	"\x18IdentityKeyPairStructure\x12\x1c\n" +
+	"\tpublicKey\x18\x01 \x01(\fR\tpublicKey\x12\x1e\n" +
+	"\n" +
+	"privateKey\x18\x02 \x01(\fR\n" +
+	"privateKey\"\xad\x04\n" +
+	"\x17SenderKeyStateStructure\x12 \n" +
+	"\vsenderKeyId\x18\x01 \x01(\rR\vsenderKeyId\x12Z\n" +
+	"\x0esenderChainKey\x18\x02 \x01(\v22.textsecure.SenderKeyStateStructure.SenderChainKeyR\x0esenderChainKey\x12`\n" +
+	"\x10senderSigningKey\x18\x03 \x01(\v24.textsecure.SenderKeyStateStructure.SenderSigningKeyR\x10senderSigningKey\x12b\n" +
+	"\x11senderMessageKeys\x18\x04 \x03(\v24.textsecure.SenderKeyStateStructure.SenderMessageKeyR\x11senderMessageKeys\x1aB\n" +
+	"\x0eSenderChainKey\x12\x1c\n" +
+	"\titeration\x18\x01 \x01(\rR\titeration\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\fR\x04seed\x1aD\n" +
+	"\x10SenderMessageKey\x12\x1c\n" +
+	"\titeration\x18\x01 \x01(\rR\titeration\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\fR\x04seed\x1aD\n" +
+	"\x10SenderSigningKey\x12\x16\n" +
+	"\x06public\x18\x01 \x01(\fR\x06public\x12\x18\n" +
+	"\aprivate\x18\x02 \x01(\fR\aprivate\"i\n" +
+	"\x18SenderKeyRecordStructure\x12M\n" +
+	"\x0fsenderKeyStates\x18\x01 \x03(\v2#.textsecure.SenderKeyStateStructureR\x0fsenderKeyStatesB3\n" +
+	"\"org.whispersystems.libsignal.stateB\rStorageProtos"
 
 var (
 	file_serialize_LocalStorageProtocol_proto_rawDescOnce sync.Once
-	file_serialize_LocalStorageProtocol_proto_rawDescData = file_serialize_LocalStorageProtocol_proto_rawDesc
+	file_serialize_LocalStorageProtocol_proto_rawDescData []byte
 )
 
 func file_serialize_LocalStorageProtocol_proto_rawDescGZIP() []byte {
 	file_serialize_LocalStorageProtocol_proto_rawDescOnce.Do(func() {
-		file_serialize_LocalStorageProtocol_proto_rawDescData = protoimpl.X.CompressGZIP(file_serialize_LocalStorageProtocol_proto_rawDescData)
+		file_serialize_LocalStorageProtocol_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_serialize_LocalStorageProtocol_proto_rawDesc), len(file_serialize_LocalStorageProtocol_proto_rawDesc)))
 	})
 	return file_serialize_LocalStorageProtocol_proto_rawDescData
 }
 
-var file_serialize_LocalStorageProtocol_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
-var file_serialize_LocalStorageProtocol_proto_goTypes = []interface{}{
+var file_serialize_LocalStorageProtocol_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_serialize_LocalStorageProtocol_proto_goTypes = []any{
 	(*SessionStructure)(nil),                         // 0: textsecure.SessionStructure
 	(*RecordStructure)(nil),                          // 1: textsecure.RecordStructure
 	(*PreKeyRecordStructure)(nil),                    // 2: textsecure.PreKeyRecordStructure
@@ -1267,30 +1188,32 @@ var file_serialize_LocalStorageProtocol_proto_goTypes = []interface{}{
 	(*SessionStructure_Chain)(nil),                   // 7: textsecure.SessionStructure.Chain
 	(*SessionStructure_PendingKeyExchange)(nil),      // 8: textsecure.SessionStructure.PendingKeyExchange
 	(*SessionStructure_PendingPreKey)(nil),           // 9: textsecure.SessionStructure.PendingPreKey
-	(*SessionStructure_Chain_ChainKey)(nil),          // 10: textsecure.SessionStructure.Chain.ChainKey
-	(*SessionStructure_Chain_MessageKey)(nil),        // 11: textsecure.SessionStructure.Chain.MessageKey
-	(*SenderKeyStateStructure_SenderChainKey)(nil),   // 12: textsecure.SenderKeyStateStructure.SenderChainKey
-	(*SenderKeyStateStructure_SenderMessageKey)(nil), // 13: textsecure.SenderKeyStateStructure.SenderMessageKey
-	(*SenderKeyStateStructure_SenderSigningKey)(nil), // 14: textsecure.SenderKeyStateStructure.SenderSigningKey
+	(*SessionStructure_PendingKyberPreKey)(nil),      // 10: textsecure.SessionStructure.PendingKyberPreKey
+	(*SessionStructure_Chain_ChainKey)(nil),          // 11: textsecure.SessionStructure.Chain.ChainKey
+	(*SessionStructure_Chain_MessageKey)(nil),        // 12: textsecure.SessionStructure.Chain.MessageKey
+	(*SenderKeyStateStructure_SenderChainKey)(nil),   // 13: textsecure.SenderKeyStateStructure.SenderChainKey
+	(*SenderKeyStateStructure_SenderMessageKey)(nil), // 14: textsecure.SenderKeyStateStructure.SenderMessageKey
+	(*SenderKeyStateStructure_SenderSigningKey)(nil), // 15: textsecure.SenderKeyStateStructure.SenderSigningKey
 }
 var file_serialize_LocalStorageProtocol_proto_depIdxs = []int32{
 	7,  // 0: textsecure.SessionStructure.senderChain:type_name -> textsecure.SessionStructure.Chain
 	7,  // 1: textsecure.SessionStructure.receiverChains:type_name -> textsecure.SessionStructure.Chain
 	8,  // 2: textsecure.SessionStructure.pendingKeyExchange:type_name -> textsecure.SessionStructure.PendingKeyExchange
 	9,  // 3: textsecure.SessionStructure.pendingPreKey:type_name -> textsecure.SessionStructure.PendingPreKey
-	0,  // 4: textsecure.RecordStructure.currentSession:type_name -> textsecure.SessionStructure
-	0,  // 5: textsecure.RecordStructure.previousSessions:type_name -> textsecure.SessionStructure
-	12, // 6: textsecure.SenderKeyStateStructure.senderChainKey:type_name -> textsecure.SenderKeyStateStructure.SenderChainKey
-	14, // 7: textsecure.SenderKeyStateStructure.senderSigningKey:type_name -> textsecure.SenderKeyStateStructure.SenderSigningKey
-	13, // 8: textsecure.SenderKeyStateStructure.senderMessageKeys:type_name -> textsecure.SenderKeyStateStructure.SenderMessageKey
-	5,  // 9: textsecure.SenderKeyRecordStructure.senderKeyStates:type_name -> textsecure.SenderKeyStateStructure
-	10, // 10: textsecure.SessionStructure.Chain.chainKey:type_name -> textsecure.SessionStructure.Chain.ChainKey
-	11, // 11: textsecure.SessionStructure.Chain.messageKeys:type_name -> textsecure.SessionStructure.Chain.MessageKey
-	12, // [12:12] is the sub-list for method output_type
-	12, // [12:12] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	10, // 4: textsecure.SessionStructure.pendingKyberPreKey:type_name -> textsecure.SessionStructure.PendingKyberPreKey
+	0,  // 5: textsecure.RecordStructure.currentSession:type_name -> textsecure.SessionStructure
+	0,  // 6: textsecure.RecordStructure.previousSessions:type_name -> textsecure.SessionStructure
+	13, // 7: textsecure.SenderKeyStateStructure.senderChainKey:type_name -> textsecure.SenderKeyStateStructure.SenderChainKey
+	15, // 8: textsecure.SenderKeyStateStructure.senderSigningKey:type_name -> textsecure.SenderKeyStateStructure.SenderSigningKey
+	14, // 9: textsecure.SenderKeyStateStructure.senderMessageKeys:type_name -> textsecure.SenderKeyStateStructure.SenderMessageKey
+	5,  // 10: textsecure.SenderKeyRecordStructure.senderKeyStates:type_name -> textsecure.SenderKeyStateStructure
+	11, // 11: textsecure.SessionStructure.Chain.chainKey:type_name -> textsecure.SessionStructure.Chain.ChainKey
+	12, // 12: textsecure.SessionStructure.Chain.messageKeys:type_name -> textsecure.SessionStructure.Chain.MessageKey
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_serialize_LocalStorageProtocol_proto_init() }
@@ -1298,195 +1221,13 @@ func file_serialize_LocalStorageProtocol_proto_init() {
 	if File_serialize_LocalStorageProtocol_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_serialize_LocalStorageProtocol_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RecordStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PreKeyRecordStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignedPreKeyRecordStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*IdentityKeyPairStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyStateStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyRecordStructure); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionStructure_Chain); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionStructure_PendingKeyExchange); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionStructure_PendingPreKey); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionStructure_Chain_ChainKey); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionStructure_Chain_MessageKey); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyStateStructure_SenderChainKey); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyStateStructure_SenderMessageKey); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_LocalStorageProtocol_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyStateStructure_SenderSigningKey); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_serialize_LocalStorageProtocol_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_serialize_LocalStorageProtocol_proto_rawDesc), len(file_serialize_LocalStorageProtocol_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -1495,7 +1236,6 @@ func file_serialize_LocalStorageProtocol_proto_init() {
 		MessageInfos:      file_serialize_LocalStorageProtocol_proto_msgTypes,
 	}.Build()
 	File_serialize_LocalStorageProtocol_proto = out.File
-	file_serialize_LocalStorageProtocol_proto_rawDesc = nil
 	file_serialize_LocalStorageProtocol_proto_goTypes = nil
 	file_serialize_LocalStorageProtocol_proto_depIdxs = nil
 }
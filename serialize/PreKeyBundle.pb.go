@@ -0,0 +1,214 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: serialize/PreKeyBundle.proto
+
+package serialize
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PreKeyBundle struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	RegistrationId        *uint32                `protobuf:"varint,1,opt,name=registrationId" json:"registrationId,omitempty"`
+	DeviceId              *uint32                `protobuf:"varint,2,opt,name=deviceId" json:"deviceId,omitempty"`
+	PreKeyId              *uint32                `protobuf:"varint,3,opt,name=preKeyId" json:"preKeyId,omitempty"`
+	PreKey                []byte                 `protobuf:"bytes,4,opt,name=preKey" json:"preKey,omitempty"`
+	SignedPreKeyId        *uint32                `protobuf:"varint,5,opt,name=signedPreKeyId" json:"signedPreKeyId,omitempty"`
+	SignedPreKey          []byte                 `protobuf:"bytes,6,opt,name=signedPreKey" json:"signedPreKey,omitempty"`
+	SignedPreKeySignature []byte                 `protobuf:"bytes,7,opt,name=signedPreKeySignature" json:"signedPreKeySignature,omitempty"`
+	KyberPreKeyId         *uint32                `protobuf:"varint,8,opt,name=kyberPreKeyId" json:"kyberPreKeyId,omitempty"`
+	KyberPreKey           []byte                 `protobuf:"bytes,9,opt,name=kyberPreKey" json:"kyberPreKey,omitempty"`
+	KyberPreKeySignature  []byte                 `protobuf:"bytes,10,opt,name=kyberPreKeySignature" json:"kyberPreKeySignature,omitempty"`
+	IdentityKey           []byte                 `protobuf:"bytes,11,opt,name=identityKey" json:"identityKey,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *PreKeyBundle) Reset() {
+	*x = PreKeyBundle{}
+	mi := &file_serialize_PreKeyBundle_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreKeyBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreKeyBundle) ProtoMessage() {}
+
+func (x *PreKeyBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_PreKeyBundle_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreKeyBundle.ProtoReflect.Descriptor instead.
+func (*PreKeyBundle) Descriptor() ([]byte, []int) {
+	return file_serialize_PreKeyBundle_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PreKeyBundle) GetRegistrationId() uint32 {
+	if x != nil && x.RegistrationId != nil {
+		return *x.RegistrationId
+	}
+	return 0
+}
+
+func (x *PreKeyBundle) GetDeviceId() uint32 {
+	if x != nil && x.DeviceId != nil {
+		return *x.DeviceId
+	}
+	return 0
+}
+
+func (x *PreKeyBundle) GetPreKeyId() uint32 {
+	if x != nil && x.PreKeyId != nil {
+		return *x.PreKeyId
+	}
+	return 0
+}
+
+func (x *PreKeyBundle) GetPreKey() []byte {
+	if x != nil {
+		return x.PreKey
+	}
+	return nil
+}
+
+func (x *PreKeyBundle) GetSignedPreKeyId() uint32 {
+	if x != nil && x.SignedPreKeyId != nil {
+		return *x.SignedPreKeyId
+	}
+	return 0
+}
+
+func (x *PreKeyBundle) GetSignedPreKey() []byte {
+	if x != nil {
+		return x.SignedPreKey
+	}
+	return nil
+}
+
+func (x *PreKeyBundle) GetSignedPreKeySignature() []byte {
+	if x != nil {
+		return x.SignedPreKeySignature
+	}
+	return nil
+}
+
+func (x *PreKeyBundle) GetKyberPreKeyId() uint32 {
+	if x != nil && x.KyberPreKeyId != nil {
+		return *x.KyberPreKeyId
+	}
+	return 0
+}
+
+func (x *PreKeyBundle) GetKyberPreKey() []byte {
+	if x != nil {
+		return x.KyberPreKey
+	}
+	return nil
+}
+
+func (x *PreKeyBundle) GetKyberPreKeySignature() []byte {
+	if x != nil {
+		return x.KyberPreKeySignature
+	}
+	return nil
+}
+
+func (x *PreKeyBundle) GetIdentityKey() []byte {
+	if x != nil {
+		return x.IdentityKey
+	}
+	return nil
+}
+
+var File_serialize_PreKeyBundle_proto protoreflect.FileDescriptor
+
+const file_serialize_PreKeyBundle_proto_rawDesc = "" +
+	"\n" +
+	"\x1cserialize/PreKeyBundle.proto\x12\n" +
+	"textsecure\"\xa6\x03\n" +
+	"\fPreKeyBundle\x12&\n" +
+	"\x0eregistrationId\x18\x01 \x01(\rR\x0eregistrationId\x12\x1a\n" +
+	"\bdeviceId\x18\x02 \x01(\rR\bdeviceId\x12\x1a\n" +
+	"\bpreKeyId\x18\x03 \x01(\rR\bpreKeyId\x12\x16\n" +
+	"\x06preKey\x18\x04 \x01(\fR\x06preKey\x12&\n" +
+	"\x0esignedPreKeyId\x18\x05 \x01(\rR\x0esignedPreKeyId\x12\"\n" +
+	"\fsignedPreKey\x18\x06 \x01(\fR\fsignedPreKey\x124\n" +
+	"\x15signedPreKeySignature\x18\a \x01(\fR\x15signedPreKeySignature\x12$\n" +
+	"\rkyberPreKeyId\x18\b \x01(\rR\rkyberPreKeyId\x12 \n" +
+	"\vkyberPreKey\x18\t \x01(\fR\vkyberPreKey\x122\n" +
+	"\x14kyberPreKeySignature\x18\n" +
+	" \x01(\fR\x14kyberPreKeySignature\x12 \n" +
+	"\videntityKey\x18\v \x01(\fR\videntityKey"
+
+var (
+	file_serialize_PreKeyBundle_proto_rawDescOnce sync.Once
+	file_serialize_PreKeyBundle_proto_rawDescData []byte
+)
+
+func file_serialize_PreKeyBundle_proto_rawDescGZIP() []byte {
+	file_serialize_PreKeyBundle_proto_rawDescOnce.Do(func() {
+		file_serialize_PreKeyBundle_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_serialize_PreKeyBundle_proto_rawDesc), len(file_serialize_PreKeyBundle_proto_rawDesc)))
+	})
+	return file_serialize_PreKeyBundle_proto_rawDescData
+}
+
+var file_serialize_PreKeyBundle_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_serialize_PreKeyBundle_proto_goTypes = []any{
+	(*PreKeyBundle)(nil), // 0: textsecure.PreKeyBundle
+}
+var file_serialize_PreKeyBundle_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_serialize_PreKeyBundle_proto_init() }
+func file_serialize_PreKeyBundle_proto_init() {
+	if File_serialize_PreKeyBundle_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_serialize_PreKeyBundle_proto_rawDesc), len(file_serialize_PreKeyBundle_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_serialize_PreKeyBundle_proto_goTypes,
+		DependencyIndexes: file_serialize_PreKeyBundle_proto_depIdxs,
+		MessageInfos:      file_serialize_PreKeyBundle_proto_msgTypes,
+	}.Build()
+	File_serialize_PreKeyBundle_proto = out.File
+	file_serialize_PreKeyBundle_proto_goTypes = nil
+	file_serialize_PreKeyBundle_proto_depIdxs = nil
+}
@@ -4,8 +4,16 @@ import (
 	"fmt"
 	"strconv"
 
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/groups/ratchet"
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	chainKey "go.mau.fi/libsignal/keys/chain"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/keys/message"
 	"go.mau.fi/libsignal/logger"
 	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/record"
 	"go.mau.fi/libsignal/util/bytehelper"
 	"go.mau.fi/libsignal/util/optional"
 	proto "google.golang.org/protobuf/proto"
@@ -18,14 +26,22 @@ func NewProtoBufSerializer() *Serializer {
 
 	serializer.SignalMessage = &ProtoBufSignalMessageSerializer{}
 	serializer.PreKeySignalMessage = &ProtoBufPreKeySignalMessageSerializer{}
+	serializer.KeyExchangeMessage = &ProtoBufKeyExchangeMessageSerializer{}
 	serializer.SenderKeyMessage = &ProtoBufSenderKeyMessageSerializer{}
 	serializer.SenderKeyDistributionMessage = &ProtoBufSenderKeyDistributionMessageSerializer{}
-	serializer.SignedPreKeyRecord = &JSONSignedPreKeyRecordSerializer{}
-	serializer.PreKeyRecord = &JSONPreKeyRecordSerializer{}
-	serializer.State = &JSONStateSerializer{}
-	serializer.Session = &JSONSessionSerializer{}
-	serializer.SenderKeyRecord = &JSONSenderKeySessionSerializer{}
-	serializer.SenderKeyState = &JSONSenderKeyStateSerializer{}
+	serializer.SignedPreKeyRecord = &ProtoBufSignedPreKeyRecordSerializer{}
+	serializer.PreKeyRecord = &ProtoBufPreKeyRecordSerializer{}
+	serializer.KyberPreKeyRecord = &ProtoBufKyberPreKeyRecordSerializer{}
+	serializer.PreKeyBundle = &ProtoBufPreKeyBundleSerializer{}
+	serializer.IdentityKeyPair = &ProtoBufIdentityKeyPairSerializer{}
+	serializer.State = &ProtoBufStateSerializer{}
+	serializer.Session = &ProtoBufSessionSerializer{}
+	serializer.SenderKeyRecord = &ProtoBufSenderKeySessionSerializer{}
+	serializer.SenderKeyState = &ProtoBufSenderKeyStateSerializer{}
+	serializer.ServerCertificate = &ProtoBufServerCertificateSerializer{}
+	serializer.SenderCertificate = &ProtoBufSenderCertificateSerializer{}
+	serializer.UnidentifiedSenderMessage = &ProtoBufUnidentifiedSenderMessageSerializer{}
+	serializer.UnidentifiedSenderMessageContent = &ProtoBufUnidentifiedSenderMessageContentSerializer{}
 
 	return serializer
 }
@@ -34,6 +50,10 @@ func highBitsToInt(value byte) int {
 	return int((value & 0xFF) >> 4)
 }
 
+func lowBitsToInt(value byte) int {
+	return int(value & 0xF)
+}
+
 func intsToByteHighAndLow(highValue, lowValue int) byte {
 	return byte((highValue<<4 | lowValue) & 0xFF)
 }
@@ -57,7 +77,7 @@ func (j *ProtoBufSignalMessageSerializer) Serialize(signalMessage *protocol.Sign
 	}
 
 	if signalMessage.Version != 0 {
-		serialized = append(serialized, []byte(strconv.Itoa(signalMessage.Version))...)
+		serialized = append(serialized, intsToByteHighAndLow(signalMessage.Version, signalMessage.Version))
 	}
 	serialized = append(serialized, message...)
 
@@ -115,19 +135,27 @@ func (j *ProtoBufPreKeySignalMessageSerializer) Serialize(signalMessage *protoco
 	if signalMessage.PreKeyID != nil && !signalMessage.PreKeyID.IsEmpty {
 		preKeyMessage.PreKeyId = &signalMessage.PreKeyID.Value
 	}
+	if signalMessage.KyberPreKeyID != nil && !signalMessage.KyberPreKeyID.IsEmpty {
+		preKeyMessage.KyberPreKeyId = &signalMessage.KyberPreKeyID.Value
+		preKeyMessage.KyberCiphertext = signalMessage.KyberCiphertext
+	}
 
 	message, err := proto.Marshal(preKeyMessage)
 	if err != nil {
 		logger.Error("Error serializing prekey signal message: ", err)
 	}
 
-	serialized := append([]byte(strconv.Itoa(signalMessage.Version)), message...)
+	version := intsToByteHighAndLow(signalMessage.Version, signalMessage.Version)
+	serialized := append([]byte{version}, message...)
 	logger.Debug("Serialize PreKeySignalMessage result: ", serialized)
 	return serialized
 }
 
 // Deserialize will take in ProtoBuf bytes and return a prekey signal message structure.
 func (j *ProtoBufPreKeySignalMessageSerializer) Deserialize(serialized []byte) (*protocol.PreKeySignalMessageStructure, error) {
+	if len(serialized) == 0 {
+		return nil, fmt.Errorf("%w (prekey message)", signalerror.ErrIncompleteMessage)
+	}
 	version := highBitsToInt(serialized[0])
 	message := serialized[1:]
 	var sm PreKeySignalMessage
@@ -138,23 +166,81 @@ func (j *ProtoBufPreKeySignalMessageSerializer) Deserialize(serialized []byte) (
 	}
 
 	preKeyId := optional.NewEmptyUint32()
-	if sm.GetPreKeyId() != 0 {
+	if sm.PreKeyId != nil {
 		preKeyId = optional.NewOptionalUint32(sm.GetPreKeyId())
 	}
 
+	kyberPreKeyID := optional.NewEmptyUint32()
+	if sm.KyberPreKeyId != nil {
+		kyberPreKeyID = optional.NewOptionalUint32(sm.GetKyberPreKeyId())
+	}
+
 	preKeySignalMessage := protocol.PreKeySignalMessageStructure{
-		Version:        version,
-		RegistrationID: sm.GetRegistrationId(),
-		BaseKey:        sm.GetBaseKey(),
-		IdentityKey:    sm.GetIdentityKey(),
-		SignedPreKeyID: sm.GetSignedPreKeyId(),
-		Message:        sm.GetMessage(),
-		PreKeyID:       preKeyId,
+		Version:         version,
+		RegistrationID:  sm.GetRegistrationId(),
+		BaseKey:         sm.GetBaseKey(),
+		IdentityKey:     sm.GetIdentityKey(),
+		SignedPreKeyID:  sm.GetSignedPreKeyId(),
+		Message:         sm.GetMessage(),
+		PreKeyID:        preKeyId,
+		KyberPreKeyID:   kyberPreKeyID,
+		KyberCiphertext: sm.GetKyberCiphertext(),
 	}
 
 	return &preKeySignalMessage, nil
 }
 
+// ProtoBufKeyExchangeMessageSerializer is a structure for serializing key exchange messages
+// into and from ProtoBuf.
+type ProtoBufKeyExchangeMessageSerializer struct{}
+
+// Serialize will take a key exchange message structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufKeyExchangeMessageSerializer) Serialize(keyExchangeMessage *protocol.KeyExchangeMessageStructure) []byte {
+	// The sequence and flags share the id field, with the flags in the lowest 5 bits.
+	id := (keyExchangeMessage.Sequence << 5) | keyExchangeMessage.Flags
+	message := &KeyExchangeMessage{
+		Id:               &id,
+		BaseKey:          keyExchangeMessage.BaseKey,
+		RatchetKey:       keyExchangeMessage.RatchetKey,
+		IdentityKey:      keyExchangeMessage.IdentityKey,
+		BaseKeySignature: keyExchangeMessage.BaseKeySignature,
+	}
+
+	serialized, err := proto.Marshal(message)
+	if err != nil {
+		logger.Error("Error serializing key exchange message: ", err)
+	}
+
+	version := intsToByteHighAndLow(keyExchangeMessage.Version, keyExchangeMessage.SupportedVersion)
+	return append([]byte{version}, serialized...)
+}
+
+// Deserialize will take in ProtoBuf bytes and return a key exchange message structure.
+func (j *ProtoBufKeyExchangeMessageSerializer) Deserialize(serialized []byte) (*protocol.KeyExchangeMessageStructure, error) {
+	if len(serialized) == 0 {
+		return nil, fmt.Errorf("%w (key exchange message)", signalerror.ErrIncompleteMessage)
+	}
+	var message KeyExchangeMessage
+	err := proto.Unmarshal(serialized[1:], &message)
+	if err != nil {
+		logger.Error("Error deserializing key exchange message: ", err)
+		return nil, err
+	}
+
+	keyExchangeMessage := protocol.KeyExchangeMessageStructure{
+		Version:          highBitsToInt(serialized[0]),
+		SupportedVersion: lowBitsToInt(serialized[0]),
+		Sequence:         message.GetId() >> 5,
+		Flags:            message.GetId() & 0x1f,
+		BaseKey:          message.GetBaseKey(),
+		BaseKeySignature: message.GetBaseKeySignature(),
+		RatchetKey:       message.GetRatchetKey(),
+		IdentityKey:      message.GetIdentityKey(),
+	}
+
+	return &keyExchangeMessage, nil
+}
+
 // ProtoBufSenderKeyDistributionMessageSerializer is a structure for serializing senderkey
 // distribution records to and from ProtoBuf.
 type ProtoBufSenderKeyDistributionMessageSerializer struct{}
@@ -260,3 +346,869 @@ func (j *ProtoBufSenderKeyMessageSerializer) Deserialize(serialized []byte) (*pr
 
 	return &msgStructure, nil
 }
+
+// ProtoBufSignedPreKeyRecordSerializer is a structure for serializing signed prekey records
+// into and from ProtoBuf.
+type ProtoBufSignedPreKeyRecordSerializer struct{}
+
+// Serialize will take a signed prekey record structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufSignedPreKeyRecordSerializer) Serialize(signedPreKey *record.SignedPreKeyStructure) []byte {
+	timestamp := uint64(signedPreKey.Timestamp)
+	signedPreKeyRecord := &SignedPreKeyRecordStructure{
+		Id:         &signedPreKey.ID,
+		PublicKey:  signedPreKey.PublicKey,
+		PrivateKey: signedPreKey.PrivateKey,
+		Signature:  signedPreKey.Signature,
+		Timestamp:  &timestamp,
+	}
+
+	serialized, err := proto.Marshal(signedPreKeyRecord)
+	if err != nil {
+		logger.Error("Error serializing signed prekey record: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a signed prekey record structure.
+func (j *ProtoBufSignedPreKeyRecordSerializer) Deserialize(serialized []byte) (*record.SignedPreKeyStructure, error) {
+	var signedPreKeyRecord SignedPreKeyRecordStructure
+	err := proto.Unmarshal(serialized, &signedPreKeyRecord)
+	if err != nil {
+		logger.Error("Error deserializing signed prekey record: ", err)
+		return nil, err
+	}
+
+	signedPreKeyStructure := record.SignedPreKeyStructure{
+		ID:         signedPreKeyRecord.GetId(),
+		PublicKey:  signedPreKeyRecord.GetPublicKey(),
+		PrivateKey: signedPreKeyRecord.GetPrivateKey(),
+		Signature:  signedPreKeyRecord.GetSignature(),
+		Timestamp:  int64(signedPreKeyRecord.GetTimestamp()),
+	}
+
+	return &signedPreKeyStructure, nil
+}
+
+// ProtoBufKyberPreKeyRecordSerializer is a structure for serializing kyber prekey
+// records into and from ProtoBuf. Kyber prekeys use the same record structure
+// as signed prekeys.
+type ProtoBufKyberPreKeyRecordSerializer struct{}
+
+// Serialize will take a kyber prekey record structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufKyberPreKeyRecordSerializer) Serialize(kyberPreKey *record.KyberPreKeyStructure) []byte {
+	timestamp := uint64(kyberPreKey.Timestamp)
+	kyberPreKeyRecord := &SignedPreKeyRecordStructure{
+		Id:         &kyberPreKey.ID,
+		PublicKey:  kyberPreKey.PublicKey,
+		PrivateKey: kyberPreKey.PrivateKey,
+		Signature:  kyberPreKey.Signature,
+		Timestamp:  &timestamp,
+	}
+
+	serialized, err := proto.Marshal(kyberPreKeyRecord)
+	if err != nil {
+		logger.Error("Error serializing kyber prekey record: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a kyber prekey record structure.
+func (j *ProtoBufKyberPreKeyRecordSerializer) Deserialize(serialized []byte) (*record.KyberPreKeyStructure, error) {
+	var kyberPreKeyRecord SignedPreKeyRecordStructure
+	err := proto.Unmarshal(serialized, &kyberPreKeyRecord)
+	if err != nil {
+		logger.Error("Error deserializing kyber prekey record: ", err)
+		return nil, err
+	}
+
+	kyberPreKeyStructure := record.KyberPreKeyStructure{
+		ID:         kyberPreKeyRecord.GetId(),
+		PublicKey:  kyberPreKeyRecord.GetPublicKey(),
+		PrivateKey: kyberPreKeyRecord.GetPrivateKey(),
+		Signature:  kyberPreKeyRecord.GetSignature(),
+		Timestamp:  int64(kyberPreKeyRecord.GetTimestamp()),
+	}
+
+	return &kyberPreKeyStructure, nil
+}
+
+// ProtoBufPreKeyRecordSerializer is a structure for serializing prekey records
+// into and from ProtoBuf.
+type ProtoBufPreKeyRecordSerializer struct{}
+
+// Serialize will take a prekey record structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufPreKeyRecordSerializer) Serialize(preKey *record.PreKeyStructure) []byte {
+	preKeyRecord := &PreKeyRecordStructure{
+		Id:         &preKey.ID,
+		PublicKey:  preKey.PublicKey,
+		PrivateKey: preKey.PrivateKey,
+	}
+
+	serialized, err := proto.Marshal(preKeyRecord)
+	if err != nil {
+		logger.Error("Error serializing prekey record: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a prekey record structure.
+func (j *ProtoBufPreKeyRecordSerializer) Deserialize(serialized []byte) (*record.PreKeyStructure, error) {
+	var preKeyRecord PreKeyRecordStructure
+	err := proto.Unmarshal(serialized, &preKeyRecord)
+	if err != nil {
+		logger.Error("Error deserializing prekey record: ", err)
+		return nil, err
+	}
+
+	preKeyStructure := record.PreKeyStructure{
+		ID:         preKeyRecord.GetId(),
+		PublicKey:  preKeyRecord.GetPublicKey(),
+		PrivateKey: preKeyRecord.GetPrivateKey(),
+	}
+
+	return &preKeyStructure, nil
+}
+
+// ProtoBufStateSerializer is a structure for serializing session states into
+// and from ProtoBuf.
+type ProtoBufStateSerializer struct{}
+
+// Serialize will take a session state structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufStateSerializer) Serialize(state *record.StateStructure) []byte {
+	serialized, err := proto.Marshal(stateToProto(state))
+	if err != nil {
+		logger.Error("Error serializing session state: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a session state structure.
+func (j *ProtoBufStateSerializer) Deserialize(serialized []byte) (*record.StateStructure, error) {
+	var sessionStructure SessionStructure
+	err := proto.Unmarshal(serialized, &sessionStructure)
+	if err != nil {
+		logger.Error("Error deserializing session state: ", err)
+		return nil, err
+	}
+
+	return stateFromProto(&sessionStructure), nil
+}
+
+// ProtoBufSessionSerializer is a structure for serializing session records into
+// and from ProtoBuf.
+type ProtoBufSessionSerializer struct{}
+
+// Serialize will take a session structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufSessionSerializer) Serialize(session *record.SessionStructure) []byte {
+	recordStructure := &RecordStructure{
+		PreviousSessions: make([]*SessionStructure, len(session.PreviousStates)),
+	}
+	if session.SessionState != nil {
+		recordStructure.CurrentSession = stateToProto(session.SessionState)
+	}
+	for i := range session.PreviousStates {
+		recordStructure.PreviousSessions[i] = stateToProto(session.PreviousStates[i])
+	}
+
+	serialized, err := proto.Marshal(recordStructure)
+	if err != nil {
+		logger.Error("Error serializing session: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a session structure, which can be
+// used to create a new Session Record object.
+func (j *ProtoBufSessionSerializer) Deserialize(serialized []byte) (*record.SessionStructure, error) {
+	var recordStructure RecordStructure
+	err := proto.Unmarshal(serialized, &recordStructure)
+	if err != nil {
+		logger.Error("Error deserializing session: ", err)
+		return nil, err
+	}
+
+	// A record without a current session is still valid, it just has an empty state.
+	sessionStructure := record.SessionStructure{
+		SessionState:   &record.StateStructure{},
+		PreviousStates: make([]*record.StateStructure, len(recordStructure.GetPreviousSessions())),
+	}
+	if recordStructure.GetCurrentSession() != nil {
+		sessionStructure.SessionState = stateFromProto(recordStructure.GetCurrentSession())
+	}
+	for i, previousSession := range recordStructure.GetPreviousSessions() {
+		sessionStructure.PreviousStates[i] = stateFromProto(previousSession)
+	}
+
+	return &sessionStructure, nil
+}
+
+// ProtoBufSenderKeyStateSerializer is a structure for serializing group session states into
+// and from ProtoBuf.
+type ProtoBufSenderKeyStateSerializer struct{}
+
+// Serialize will take a session state structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufSenderKeyStateSerializer) Serialize(state *groupRecord.SenderKeyStateStructure) []byte {
+	serialized, err := proto.Marshal(senderKeyStateToProto(state))
+	if err != nil {
+		logger.Error("Error serializing sender key state: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a session state structure.
+func (j *ProtoBufSenderKeyStateSerializer) Deserialize(serialized []byte) (*groupRecord.SenderKeyStateStructure, error) {
+	var stateStructure SenderKeyStateStructure
+	err := proto.Unmarshal(serialized, &stateStructure)
+	if err != nil {
+		logger.Error("Error deserializing sender key state: ", err)
+		return nil, err
+	}
+
+	return senderKeyStateFromProto(&stateStructure)
+}
+
+// ProtoBufSenderKeySessionSerializer is a structure for serializing session records into
+// and from ProtoBuf.
+type ProtoBufSenderKeySessionSerializer struct{}
+
+// Serialize will take a session structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufSenderKeySessionSerializer) Serialize(session *groupRecord.SenderKeyStructure) []byte {
+	recordStructure := &SenderKeyRecordStructure{
+		SenderKeyStates: make([]*SenderKeyStateStructure, len(session.SenderKeyStates)),
+	}
+	for i := range session.SenderKeyStates {
+		recordStructure.SenderKeyStates[i] = senderKeyStateToProto(session.SenderKeyStates[i])
+	}
+
+	serialized, err := proto.Marshal(recordStructure)
+	if err != nil {
+		logger.Error("Error serializing sender key record: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a session structure, which can be
+// used to create a new Session Record object.
+func (j *ProtoBufSenderKeySessionSerializer) Deserialize(serialized []byte) (*groupRecord.SenderKeyStructure, error) {
+	var recordStructure SenderKeyRecordStructure
+	err := proto.Unmarshal(serialized, &recordStructure)
+	if err != nil {
+		logger.Error("Error deserializing sender key record: ", err)
+		return nil, err
+	}
+
+	sessionStructure := groupRecord.SenderKeyStructure{
+		SenderKeyStates: make([]*groupRecord.SenderKeyStateStructure, len(recordStructure.GetSenderKeyStates())),
+	}
+	for i, state := range recordStructure.GetSenderKeyStates() {
+		sessionStructure.SenderKeyStates[i], err = senderKeyStateFromProto(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &sessionStructure, nil
+}
+
+// ProtoBufServerCertificateSerializer is a structure for serializing server certificates
+// into and from ProtoBuf.
+type ProtoBufServerCertificateSerializer struct{}
+
+// Serialize will take a server certificate structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufServerCertificateSerializer) Serialize(certificate *protocol.ServerCertificateStructure) []byte {
+	if certificate.Signature == nil {
+		return serverCertificateContents(certificate)
+	}
+
+	serialized, err := proto.Marshal(serverCertificateToProto(certificate))
+	if err != nil {
+		logger.Error("Error serializing server certificate: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a server certificate structure.
+func (j *ProtoBufServerCertificateSerializer) Deserialize(serialized []byte) (*protocol.ServerCertificateStructure, error) {
+	var certificate ServerCertificate
+	err := proto.Unmarshal(serialized, &certificate)
+	if err != nil {
+		logger.Error("Error deserializing server certificate: ", err)
+		return nil, err
+	}
+
+	return serverCertificateFromProto(&certificate)
+}
+
+// ProtoBufSenderCertificateSerializer is a structure for serializing sender certificates
+// into and from ProtoBuf.
+type ProtoBufSenderCertificateSerializer struct{}
+
+// Serialize will take a sender certificate structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufSenderCertificateSerializer) Serialize(certificate *protocol.SenderCertificateStructure) []byte {
+	if certificate.Signature == nil {
+		return senderCertificateContents(certificate)
+	}
+
+	serialized, err := proto.Marshal(senderCertificateToProto(certificate))
+	if err != nil {
+		logger.Error("Error serializing sender certificate: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a sender certificate structure.
+func (j *ProtoBufSenderCertificateSerializer) Deserialize(serialized []byte) (*protocol.SenderCertificateStructure, error) {
+	var certificate SenderCertificate
+	err := proto.Unmarshal(serialized, &certificate)
+	if err != nil {
+		logger.Error("Error deserializing sender certificate: ", err)
+		return nil, err
+	}
+
+	return senderCertificateFromProto(&certificate)
+}
+
+// ProtoBufUnidentifiedSenderMessageSerializer is a structure for serializing sealed sender
+// messages into and from ProtoBuf.
+type ProtoBufUnidentifiedSenderMessageSerializer struct{}
+
+// Serialize will take a sealed sender message structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufUnidentifiedSenderMessageSerializer) Serialize(message *protocol.UnidentifiedSenderMessageStructure) []byte {
+	unidentifiedSenderMessage := &UnidentifiedSenderMessage{
+		EphemeralPublic:  message.EphemeralPublic,
+		EncryptedStatic:  message.EncryptedStatic,
+		EncryptedMessage: message.EncryptedMessage,
+	}
+
+	serialized, err := proto.Marshal(unidentifiedSenderMessage)
+	if err != nil {
+		logger.Error("Error serializing unidentified sender message: ", err)
+	}
+
+	version := intsToByteHighAndLow(message.Version, message.Version)
+	return append([]byte{version}, serialized...)
+}
+
+// Deserialize will take in ProtoBuf bytes and return a sealed sender message structure.
+func (j *ProtoBufUnidentifiedSenderMessageSerializer) Deserialize(serialized []byte) (*protocol.UnidentifiedSenderMessageStructure, error) {
+	if len(serialized) == 0 {
+		return nil, fmt.Errorf("%w (unidentified sender message)", signalerror.ErrIncompleteMessage)
+	}
+	var message UnidentifiedSenderMessage
+	err := proto.Unmarshal(serialized[1:], &message)
+	if err != nil {
+		logger.Error("Error deserializing unidentified sender message: ", err)
+		return nil, err
+	}
+
+	unidentifiedSenderMessage := protocol.UnidentifiedSenderMessageStructure{
+		Version:          highBitsToInt(serialized[0]),
+		EphemeralPublic:  message.GetEphemeralPublic(),
+		EncryptedStatic:  message.GetEncryptedStatic(),
+		EncryptedMessage: message.GetEncryptedMessage(),
+	}
+
+	return &unidentifiedSenderMessage, nil
+}
+
+// ProtoBufUnidentifiedSenderMessageContentSerializer is a structure for serializing sealed
+// sender message contents into and from ProtoBuf.
+type ProtoBufUnidentifiedSenderMessageContentSerializer struct{}
+
+// Serialize will take a sealed sender message content structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufUnidentifiedSenderMessageContentSerializer) Serialize(content *protocol.UnidentifiedSenderMessageContentStructure) []byte {
+	message := &UnidentifiedSenderMessage_Message{
+		Content: content.Content,
+	}
+	switch content.Type {
+	case protocol.PREKEY_TYPE:
+		message.Type = UnidentifiedSenderMessage_Message_PREKEY_MESSAGE.Enum()
+	case protocol.WHISPER_TYPE:
+		message.Type = UnidentifiedSenderMessage_Message_MESSAGE.Enum()
+	}
+	if content.SenderCertificate != nil {
+		message.SenderCertificate = senderCertificateToProto(content.SenderCertificate)
+	}
+
+	serialized, err := proto.Marshal(message)
+	if err != nil {
+		logger.Error("Error serializing unidentified sender message content: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a sealed sender message content structure.
+func (j *ProtoBufUnidentifiedSenderMessageContentSerializer) Deserialize(serialized []byte) (*protocol.UnidentifiedSenderMessageContentStructure, error) {
+	var message UnidentifiedSenderMessage_Message
+	err := proto.Unmarshal(serialized, &message)
+	if err != nil {
+		logger.Error("Error deserializing unidentified sender message content: ", err)
+		return nil, err
+	}
+
+	content := &protocol.UnidentifiedSenderMessageContentStructure{
+		Content: message.GetContent(),
+	}
+	switch message.GetType() {
+	case UnidentifiedSenderMessage_Message_PREKEY_MESSAGE:
+		content.Type = protocol.PREKEY_TYPE
+	case UnidentifiedSenderMessage_Message_MESSAGE:
+		content.Type = protocol.WHISPER_TYPE
+	}
+	if message.SenderCertificate != nil {
+		content.SenderCertificate, err = senderCertificateFromProto(message.SenderCertificate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return content, nil
+}
+
+// stateToProto converts the given session state structure into the libsignal
+// SessionStructure protobuf message.
+func stateToProto(state *record.StateStructure) *SessionStructure {
+	sessionVersion := uint32(state.SessionVersion)
+	sessionStructure := &SessionStructure{
+		SessionVersion:       &sessionVersion,
+		LocalIdentityPublic:  state.LocalIdentityPublic,
+		RemoteIdentityPublic: state.RemoteIdentityPublic,
+		RootKey:              state.RootKey,
+		PreviousCounter:      &state.PreviousCounter,
+		ReceiverChains:       make([]*SessionStructure_Chain, len(state.ReceiverChains)),
+		RemoteRegistrationId: &state.RemoteRegistrationID,
+		LocalRegistrationId:  &state.LocalRegistrationID,
+		NeedsRefresh:         &state.NeedsRefresh,
+		AliceBaseKey:         state.SenderBaseKey,
+	}
+
+	if state.SenderChain != nil {
+		sessionStructure.SenderChain = chainToProto(state.SenderChain)
+	}
+	for i := range state.ReceiverChains {
+		sessionStructure.ReceiverChains[i] = chainToProto(state.ReceiverChains[i])
+	}
+
+	if state.PendingKeyExchange != nil {
+		// Pending key exchange public keys are stored without their key type,
+		// but libsignal stores them in their serialized form.
+		pendingKeyExchange := state.PendingKeyExchange
+		sessionStructure.PendingKeyExchange = &SessionStructure_PendingKeyExchange{
+			Sequence:                &pendingKeyExchange.Sequence,
+			LocalBaseKey:            withKeyType(pendingKeyExchange.LocalBaseKeyPublic),
+			LocalBaseKeyPrivate:     pendingKeyExchange.LocalBaseKeyPrivate,
+			LocalRatchetKey:         withKeyType(pendingKeyExchange.LocalRatchetKeyPublic),
+			LocalRatchetKeyPrivate:  pendingKeyExchange.LocalRatchetKeyPrivate,
+			LocalIdentityKey:        withKeyType(pendingKeyExchange.LocalIdentityKeyPublic),
+			LocalIdentityKeyPrivate: pendingKeyExchange.LocalIdentityKeyPrivate,
+		}
+	}
+
+	if state.PendingPreKey != nil {
+		signedPreKeyID := int32(state.PendingPreKey.SignedPreKeyID)
+		sessionStructure.PendingPreKey = &SessionStructure_PendingPreKey{
+			SignedPreKeyId: &signedPreKeyID,
+			BaseKey:        state.PendingPreKey.BaseKey,
+		}
+		if state.PendingPreKey.PreKeyID != nil && !state.PendingPreKey.PreKeyID.IsEmpty {
+			sessionStructure.PendingPreKey.PreKeyId = &state.PendingPreKey.PreKeyID.Value
+		}
+		if state.PendingPreKey.KyberPreKeyID != nil && !state.PendingPreKey.KyberPreKeyID.IsEmpty {
+			sessionStructure.PendingKyberPreKey = &SessionStructure_PendingKyberPreKey{
+				PreKeyId:   &state.PendingPreKey.KyberPreKeyID.Value,
+				Ciphertext: state.PendingPreKey.KyberCiphertext,
+			}
+		}
+	}
+
+	return sessionStructure
+}
+
+// stateFromProto converts the given libsignal SessionStructure protobuf message
+// into a session state structure.
+func stateFromProto(sessionStructure *SessionStructure) *record.StateStructure {
+	state := &record.StateStructure{
+		LocalIdentityPublic:  sessionStructure.GetLocalIdentityPublic(),
+		LocalRegistrationID:  sessionStructure.GetLocalRegistrationId(),
+		NeedsRefresh:         sessionStructure.GetNeedsRefresh(),
+		PreviousCounter:      sessionStructure.GetPreviousCounter(),
+		ReceiverChains:       make([]*record.ChainStructure, len(sessionStructure.GetReceiverChains())),
+		RemoteIdentityPublic: sessionStructure.GetRemoteIdentityPublic(),
+		RemoteRegistrationID: sessionStructure.GetRemoteRegistrationId(),
+		RootKey:              sessionStructure.GetRootKey(),
+		SenderBaseKey:        sessionStructure.GetAliceBaseKey(),
+		SessionVersion:       int(sessionStructure.GetSessionVersion()),
+	}
+
+	if sessionStructure.GetSenderChain() != nil {
+		state.SenderChain = chainFromProto(sessionStructure.GetSenderChain())
+	}
+	for i, receiverChain := range sessionStructure.GetReceiverChains() {
+		state.ReceiverChains[i] = chainFromProto(receiverChain)
+	}
+
+	if pendingKeyExchange := sessionStructure.GetPendingKeyExchange(); pendingKeyExchange != nil {
+		state.PendingKeyExchange = &record.PendingKeyExchangeStructure{
+			Sequence:                pendingKeyExchange.GetSequence(),
+			LocalBaseKeyPublic:      withoutKeyType(pendingKeyExchange.GetLocalBaseKey()),
+			LocalBaseKeyPrivate:     pendingKeyExchange.GetLocalBaseKeyPrivate(),
+			LocalRatchetKeyPublic:   withoutKeyType(pendingKeyExchange.GetLocalRatchetKey()),
+			LocalRatchetKeyPrivate:  pendingKeyExchange.GetLocalRatchetKeyPrivate(),
+			LocalIdentityKeyPublic:  withoutKeyType(pendingKeyExchange.GetLocalIdentityKey()),
+			LocalIdentityKeyPrivate: pendingKeyExchange.GetLocalIdentityKeyPrivate(),
+		}
+	}
+
+	if pendingPreKey := sessionStructure.GetPendingPreKey(); pendingPreKey != nil {
+		preKeyID := optional.NewEmptyUint32()
+		if pendingPreKey.PreKeyId != nil {
+			preKeyID = optional.NewOptionalUint32(pendingPreKey.GetPreKeyId())
+		}
+		state.PendingPreKey = &record.PendingPreKeyStructure{
+			PreKeyID:       preKeyID,
+			SignedPreKeyID: uint32(pendingPreKey.GetSignedPreKeyId()),
+			BaseKey:        pendingPreKey.GetBaseKey(),
+		}
+		if pendingKyberPreKey := sessionStructure.GetPendingKyberPreKey(); pendingKyberPreKey != nil {
+			state.PendingPreKey.KyberPreKeyID = optional.NewOptionalUint32(pendingKyberPreKey.GetPreKeyId())
+			state.PendingPreKey.KyberCiphertext = pendingKyberPreKey.GetCiphertext()
+		}
+	}
+
+	return state
+}
+
+// chainToProto converts the given chain structure into a protobuf chain.
+func chainToProto(chain *record.ChainStructure) *SessionStructure_Chain {
+	protoChain := &SessionStructure_Chain{
+		SenderRatchetKey:        chain.SenderRatchetKeyPublic,
+		SenderRatchetKeyPrivate: chain.SenderRatchetKeyPrivate,
+		MessageKeys:             make([]*SessionStructure_Chain_MessageKey, len(chain.MessageKeys)),
+	}
+	if chain.ChainKey != nil {
+		protoChain.ChainKey = &SessionStructure_Chain_ChainKey{
+			Index: &chain.ChainKey.Index,
+			Key:   chain.ChainKey.Key,
+		}
+	}
+	for i, messageKey := range chain.MessageKeys {
+		protoChain.MessageKeys[i] = &SessionStructure_Chain_MessageKey{
+			Index:     &messageKey.Index,
+			CipherKey: messageKey.CipherKey,
+			MacKey:    messageKey.MacKey,
+			Iv:        messageKey.IV,
+		}
+	}
+
+	return protoChain
+}
+
+// chainFromProto converts the given protobuf chain into a chain structure.
+func chainFromProto(protoChain *SessionStructure_Chain) *record.ChainStructure {
+	chain := &record.ChainStructure{
+		SenderRatchetKeyPublic:  protoChain.GetSenderRatchetKey(),
+		SenderRatchetKeyPrivate: protoChain.GetSenderRatchetKeyPrivate(),
+		ChainKey: &chainKey.KeyStructure{
+			Index: protoChain.GetChainKey().GetIndex(),
+			Key:   protoChain.GetChainKey().GetKey(),
+		},
+		MessageKeys: make([]*message.KeysStructure, len(protoChain.GetMessageKeys())),
+	}
+	for i, messageKey := range protoChain.GetMessageKeys() {
+		chain.MessageKeys[i] = &message.KeysStructure{
+			Index:     messageKey.GetIndex(),
+			CipherKey: messageKey.GetCipherKey(),
+			MacKey:    messageKey.GetMacKey(),
+			IV:        messageKey.GetIv(),
+		}
+	}
+
+	return chain
+}
+
+// senderKeyStateToProto converts the given sender key state structure into
+// the libsignal SenderKeyStateStructure protobuf message.
+func senderKeyStateToProto(state *groupRecord.SenderKeyStateStructure) *SenderKeyStateStructure {
+	stateStructure := &SenderKeyStateStructure{
+		SenderKeyId: &state.KeyID,
+		SenderSigningKey: &SenderKeyStateStructure_SenderSigningKey{
+			Public:  state.SigningKeyPublic,
+			Private: state.SigningKeyPrivate,
+		},
+		SenderMessageKeys: make([]*SenderKeyStateStructure_SenderMessageKey, len(state.Keys)),
+	}
+	if state.SenderChainKey != nil {
+		stateStructure.SenderChainKey = &SenderKeyStateStructure_SenderChainKey{
+			Iteration: &state.SenderChainKey.Iteration,
+			Seed:      state.SenderChainKey.ChainKey,
+		}
+	}
+
+	// Only the seed of each message key is stored, the rest is derived from it.
+	for i, key := range state.Keys {
+		stateStructure.SenderMessageKeys[i] = &SenderKeyStateStructure_SenderMessageKey{
+			Iteration: &key.Iteration,
+			Seed:      key.Seed,
+		}
+	}
+
+	return stateStructure
+}
+
+// senderKeyStateFromProto converts the given libsignal SenderKeyStateStructure
+// protobuf message into a sender key state structure.
+func senderKeyStateFromProto(stateStructure *SenderKeyStateStructure) (*groupRecord.SenderKeyStateStructure, error) {
+	state := &groupRecord.SenderKeyStateStructure{
+		Keys:  make([]*ratchet.SenderMessageKeyStructure, len(stateStructure.GetSenderMessageKeys())),
+		KeyID: stateStructure.GetSenderKeyId(),
+		SenderChainKey: &ratchet.SenderChainKeyStructure{
+			Iteration: stateStructure.GetSenderChainKey().GetIteration(),
+			ChainKey:  stateStructure.GetSenderChainKey().GetSeed(),
+		},
+		SigningKeyPrivate: stateStructure.GetSenderSigningKey().GetPrivate(),
+		SigningKeyPublic:  stateStructure.GetSenderSigningKey().GetPublic(),
+	}
+
+	// Derive the iv and cipher key of each message key from its seed.
+	for i, key := range stateStructure.GetSenderMessageKeys() {
+		senderMessageKey, err := ratchet.NewSenderMessageKey(key.GetIteration(), key.GetSeed())
+		if err != nil {
+			logger.Error("Error deriving sender message key: ", err)
+			return nil, err
+		}
+		state.Keys[i] = ratchet.NewStructFromSenderMessageKey(senderMessageKey)
+	}
+
+	return state, nil
+}
+
+// withKeyType prepends the DJB key type to the given raw public key.
+func withKeyType(publicKey []byte) []byte {
+	if publicKey == nil {
+		return nil
+	}
+	return append([]byte{ecc.DjbType}, publicKey...)
+}
+
+// withoutKeyType strips the key type from the given serialized public key.
+func withoutKeyType(publicKey []byte) []byte {
+	if len(publicKey) == 33 && publicKey[0] == ecc.DjbType {
+		return publicKey[1:]
+	}
+	return publicKey
+}
+
+// serverCertificateContents returns the signed contents of the given server
+// certificate, encoding them if they weren't decoded from bytes.
+func serverCertificateContents(certificate *protocol.ServerCertificateStructure) []byte {
+	if certificate.Certificate != nil {
+		return certificate.Certificate
+	}
+
+	serialized, err := proto.Marshal(&ServerCertificate_Certificate{
+		Id:  &certificate.KeyID,
+		Key: certificate.Key,
+	})
+	if err != nil {
+		logger.Error("Error serializing server certificate contents: ", err)
+	}
+
+	return serialized
+}
+
+// serverCertificateToProto converts the given server certificate structure into
+// the ServerCertificate protobuf message.
+func serverCertificateToProto(certificate *protocol.ServerCertificateStructure) *ServerCertificate {
+	return &ServerCertificate{
+		Certificate: serverCertificateContents(certificate),
+		Signature:   certificate.Signature,
+	}
+}
+
+// serverCertificateFromProto converts the given ServerCertificate protobuf message
+// into a server certificate structure.
+func serverCertificateFromProto(certificate *ServerCertificate) (*protocol.ServerCertificateStructure, error) {
+	var contents ServerCertificate_Certificate
+	err := proto.Unmarshal(certificate.GetCertificate(), &contents)
+	if err != nil {
+		logger.Error("Error deserializing server certificate contents: ", err)
+		return nil, err
+	}
+
+	return &protocol.ServerCertificateStructure{
+		KeyID:       contents.GetId(),
+		Key:         contents.GetKey(),
+		Certificate: certificate.GetCertificate(),
+		Signature:   certificate.GetSignature(),
+	}, nil
+}
+
+// senderCertificateContents returns the signed contents of the given sender
+// certificate, encoding them if they weren't decoded from bytes.
+func senderCertificateContents(certificate *protocol.SenderCertificateStructure) []byte {
+	if certificate.Certificate != nil {
+		return certificate.Certificate
+	}
+
+	contents := &SenderCertificate_Certificate{
+		Sender:       &certificate.Sender,
+		SenderDevice: &certificate.SenderDevice,
+		Expires:      &certificate.Expires,
+		IdentityKey:  certificate.IdentityKey,
+	}
+	if certificate.Signer != nil {
+		contents.Signer = serverCertificateToProto(certificate.Signer)
+	}
+	serialized, err := proto.Marshal(contents)
+	if err != nil {
+		logger.Error("Error serializing sender certificate contents: ", err)
+	}
+
+	return serialized
+}
+
+// senderCertificateToProto converts the given sender certificate structure into
+// the SenderCertificate protobuf message.
+func senderCertificateToProto(certificate *protocol.SenderCertificateStructure) *SenderCertificate {
+	return &SenderCertificate{
+		Certificate: senderCertificateContents(certificate),
+		Signature:   certificate.Signature,
+	}
+}
+
+// senderCertificateFromProto converts the given SenderCertificate protobuf message
+// into a sender certificate structure.
+func senderCertificateFromProto(certificate *SenderCertificate) (*protocol.SenderCertificateStructure, error) {
+	var contents SenderCertificate_Certificate
+	err := proto.Unmarshal(certificate.GetCertificate(), &contents)
+	if err != nil {
+		logger.Error("Error deserializing sender certificate contents: ", err)
+		return nil, err
+	}
+
+	structure := &protocol.SenderCertificateStructure{
+		Sender:       contents.GetSender(),
+		SenderDevice: contents.GetSenderDevice(),
+		Expires:      contents.GetExpires(),
+		IdentityKey:  contents.GetIdentityKey(),
+		Certificate:  certificate.GetCertificate(),
+		Signature:    certificate.GetSignature(),
+	}
+	if contents.Signer != nil {
+		structure.Signer, err = serverCertificateFromProto(contents.Signer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return structure, nil
+}
+
+// ProtoBufPreKeyBundleSerializer is a structure for serializing prekey
+// bundles into and from ProtoBuf.
+type ProtoBufPreKeyBundleSerializer struct{}
+
+// Serialize will take a prekey bundle structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufPreKeyBundleSerializer) Serialize(bundle *protocol.PreKeyBundleStructure) []byte {
+	preKeyBundle := &PreKeyBundle{
+		RegistrationId:        &bundle.RegistrationID,
+		DeviceId:              &bundle.DeviceID,
+		SignedPreKeyId:        &bundle.SignedPreKeyID,
+		SignedPreKey:          bundle.SignedPreKey,
+		SignedPreKeySignature: bundle.SignedPreKeySignature,
+		IdentityKey:           bundle.IdentityKey,
+	}
+	if bundle.PreKeyID != nil && !bundle.PreKeyID.IsEmpty {
+		preKeyBundle.PreKeyId = &bundle.PreKeyID.Value
+		preKeyBundle.PreKey = bundle.PreKey
+	}
+	if bundle.KyberPreKey != nil {
+		preKeyBundle.KyberPreKeyId = &bundle.KyberPreKeyID
+		preKeyBundle.KyberPreKey = bundle.KyberPreKey
+		preKeyBundle.KyberPreKeySignature = bundle.KyberPreKeySignature
+	}
+
+	serialized, err := proto.Marshal(preKeyBundle)
+	if err != nil {
+		logger.Error("Error serializing prekey bundle: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return a prekey bundle structure.
+func (j *ProtoBufPreKeyBundleSerializer) Deserialize(serialized []byte) (*protocol.PreKeyBundleStructure, error) {
+	var preKeyBundle PreKeyBundle
+	err := proto.Unmarshal(serialized, &preKeyBundle)
+	if err != nil {
+		logger.Error("Error deserializing prekey bundle: ", err)
+		return nil, err
+	}
+
+	preKeyID := optional.NewEmptyUint32()
+	if preKeyBundle.PreKeyId != nil {
+		preKeyID = optional.NewOptionalUint32(preKeyBundle.GetPreKeyId())
+	}
+
+	bundle := protocol.PreKeyBundleStructure{
+		RegistrationID:        preKeyBundle.GetRegistrationId(),
+		DeviceID:              preKeyBundle.GetDeviceId(),
+		PreKeyID:              preKeyID,
+		PreKey:                preKeyBundle.GetPreKey(),
+		SignedPreKeyID:        preKeyBundle.GetSignedPreKeyId(),
+		SignedPreKey:          preKeyBundle.GetSignedPreKey(),
+		SignedPreKeySignature: preKeyBundle.GetSignedPreKeySignature(),
+		KyberPreKeyID:         preKeyBundle.GetKyberPreKeyId(),
+		KyberPreKey:           preKeyBundle.GetKyberPreKey(),
+		KyberPreKeySignature:  preKeyBundle.GetKyberPreKeySignature(),
+		IdentityKey:           preKeyBundle.GetIdentityKey(),
+	}
+
+	return &bundle, nil
+}
+
+// ProtoBufIdentityKeyPairSerializer is a structure for serializing identity
+// key pairs into and from ProtoBuf.
+type ProtoBufIdentityKeyPairSerializer struct{}
+
+// Serialize will take an identity key pair structure and convert it to ProtoBuf bytes.
+func (j *ProtoBufIdentityKeyPairSerializer) Serialize(keyPair *identity.KeyPairStructure) []byte {
+	identityKeyPair := &IdentityKeyPairStructure{
+		PublicKey:  keyPair.PublicKey,
+		PrivateKey: keyPair.PrivateKey,
+	}
+
+	serialized, err := proto.Marshal(identityKeyPair)
+	if err != nil {
+		logger.Error("Error serializing identity key pair: ", err)
+	}
+
+	return serialized
+}
+
+// Deserialize will take in ProtoBuf bytes and return an identity key pair structure.
+func (j *ProtoBufIdentityKeyPairSerializer) Deserialize(serialized []byte) (*identity.KeyPairStructure, error) {
+	var identityKeyPair IdentityKeyPairStructure
+	err := proto.Unmarshal(serialized, &identityKeyPair)
+	if err != nil {
+		logger.Error("Error deserializing identity key pair: ", err)
+		return nil, err
+	}
+
+	keyPair := identity.KeyPairStructure{
+		PublicKey:  identityKeyPair.GetPublicKey(),
+		PrivateKey: identityKeyPair.GetPrivateKey(),
+	}
+
+	return &keyPair, nil
+}
@@ -4,6 +4,7 @@ package serialize
 
 import (
 	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/keys/identity"
 	"go.mau.fi/libsignal/protocol"
 	"go.mau.fi/libsignal/state/record"
 )
@@ -22,10 +23,19 @@ type Serializer struct {
 	SenderKeyState               groupRecord.SenderKeyStateSerializer
 	SignalMessage                protocol.SignalMessageSerializer
 	PreKeySignalMessage          protocol.PreKeySignalMessageSerializer
+	KeyExchangeMessage           protocol.KeyExchangeMessageSerializer
 	SenderKeyMessage             protocol.SenderKeyMessageSerializer
 	SenderKeyDistributionMessage protocol.SenderKeyDistributionMessageSerializer
 	SignedPreKeyRecord           record.SignedPreKeySerializer
 	PreKeyRecord                 record.PreKeySerializer
+	KyberPreKeyRecord            record.KyberPreKeySerializer
+	PreKeyBundle                 protocol.PreKeyBundleSerializer
+	IdentityKeyPair              identity.KeyPairSerializer
 	State                        record.StateSerializer
 	Session                      record.SessionSerializer
+
+	ServerCertificate                protocol.ServerCertificateSerializer
+	SenderCertificate                protocol.SenderCertificateSerializer
+	UnidentifiedSenderMessage        protocol.UnidentifiedSenderMessageSerializer
+	UnidentifiedSenderMessageContent protocol.UnidentifiedSenderMessageContentSerializer
 }
@@ -0,0 +1,523 @@
+// From https://github.com/signalapp/libsignal-metadata-java/blob/master/protobuf/UnidentifiedDelivery.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: serialize/UnidentifiedDelivery.proto
+
+package serialize
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UnidentifiedSenderMessage_Message_Type int32
+
+const (
+	UnidentifiedSenderMessage_Message_PREKEY_MESSAGE UnidentifiedSenderMessage_Message_Type = 1
+	UnidentifiedSenderMessage_Message_MESSAGE        UnidentifiedSenderMessage_Message_Type = 2
+)
+
+// Enum value maps for UnidentifiedSenderMessage_Message_Type.
+var (
+	UnidentifiedSenderMessage_Message_Type_name = map[int32]string{
+		1: "PREKEY_MESSAGE",
+		2: "MESSAGE",
+	}
+	UnidentifiedSenderMessage_Message_Type_value = map[string]int32{
+		"PREKEY_MESSAGE": 1,
+		"MESSAGE":        2,
+	}
+)
+
+func (x UnidentifiedSenderMessage_Message_Type) Enum() *UnidentifiedSenderMessage_Message_Type {
+	p := new(UnidentifiedSenderMessage_Message_Type)
+	*p = x
+	return p
+}
+
+func (x UnidentifiedSenderMessage_Message_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UnidentifiedSenderMessage_Message_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_serialize_UnidentifiedDelivery_proto_enumTypes[0].Descriptor()
+}
+
+func (UnidentifiedSenderMessage_Message_Type) Type() protoreflect.EnumType {
+	return &file_serialize_UnidentifiedDelivery_proto_enumTypes[0]
+}
+
+func (x UnidentifiedSenderMessage_Message_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *UnidentifiedSenderMessage_Message_Type) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = UnidentifiedSenderMessage_Message_Type(num)
+	return nil
+}
+
+// Deprecated: Use UnidentifiedSenderMessage_Message_Type.Descriptor instead.
+func (UnidentifiedSenderMessage_Message_Type) EnumDescriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{2, 0, 0}
+}
+
+type ServerCertificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificate   []byte                 `protobuf:"bytes,1,opt,name=certificate" json:"certificate,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerCertificate) Reset() {
+	*x = ServerCertificate{}
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerCertificate) ProtoMessage() {}
+
+func (x *ServerCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerCertificate.ProtoReflect.Descriptor instead.
+func (*ServerCertificate) Descriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ServerCertificate) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *ServerCertificate) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type SenderCertificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificate   []byte                 `protobuf:"bytes,1,opt,name=certificate" json:"certificate,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SenderCertificate) Reset() {
+	*x = SenderCertificate{}
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SenderCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SenderCertificate) ProtoMessage() {}
+
+func (x *SenderCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SenderCertificate.ProtoReflect.Descriptor instead.
+func (*SenderCertificate) Descriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SenderCertificate) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *SenderCertificate) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type UnidentifiedSenderMessage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	EphemeralPublic  []byte                 `protobuf:"bytes,1,opt,name=ephemeralPublic" json:"ephemeralPublic,omitempty"`
+	EncryptedStatic  []byte                 `protobuf:"bytes,2,opt,name=encryptedStatic" json:"encryptedStatic,omitempty"`
+	EncryptedMessage []byte                 `protobuf:"bytes,3,opt,name=encryptedMessage" json:"encryptedMessage,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UnidentifiedSenderMessage) Reset() {
+	*x = UnidentifiedSenderMessage{}
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnidentifiedSenderMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnidentifiedSenderMessage) ProtoMessage() {}
+
+func (x *UnidentifiedSenderMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnidentifiedSenderMessage.ProtoReflect.Descriptor instead.
+func (*UnidentifiedSenderMessage) Descriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UnidentifiedSenderMessage) GetEphemeralPublic() []byte {
+	if x != nil {
+		return x.EphemeralPublic
+	}
+	return nil
+}
+
+func (x *UnidentifiedSenderMessage) GetEncryptedStatic() []byte {
+	if x != nil {
+		return x.EncryptedStatic
+	}
+	return nil
+}
+
+func (x *UnidentifiedSenderMessage) GetEncryptedMessage() []byte {
+	if x != nil {
+		return x.EncryptedMessage
+	}
+	return nil
+}
+
+type ServerCertificate_Certificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *uint32                `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Key           []byte                 `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerCertificate_Certificate) Reset() {
+	*x = ServerCertificate_Certificate{}
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerCertificate_Certificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerCertificate_Certificate) ProtoMessage() {}
+
+func (x *ServerCertificate_Certificate) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerCertificate_Certificate.ProtoReflect.Descriptor instead.
+func (*ServerCertificate_Certificate) Descriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *ServerCertificate_Certificate) GetId() uint32 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *ServerCertificate_Certificate) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type SenderCertificate_Certificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        *string                `protobuf:"bytes,1,opt,name=sender" json:"sender,omitempty"`
+	SenderDevice  *uint32                `protobuf:"varint,2,opt,name=senderDevice" json:"senderDevice,omitempty"`
+	Expires       *uint64                `protobuf:"fixed64,3,opt,name=expires" json:"expires,omitempty"`
+	IdentityKey   []byte                 `protobuf:"bytes,4,opt,name=identityKey" json:"identityKey,omitempty"`
+	Signer        *ServerCertificate     `protobuf:"bytes,5,opt,name=signer" json:"signer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SenderCertificate_Certificate) Reset() {
+	*x = SenderCertificate_Certificate{}
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SenderCertificate_Certificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SenderCertificate_Certificate) ProtoMessage() {}
+
+func (x *SenderCertificate_Certificate) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SenderCertificate_Certificate.ProtoReflect.Descriptor instead.
+func (*SenderCertificate_Certificate) Descriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *SenderCertificate_Certificate) GetSender() string {
+	if x != nil && x.Sender != nil {
+		return *x.Sender
+	}
+	return ""
+}
+
+func (x *SenderCertificate_Certificate) GetSenderDevice() uint32 {
+	if x != nil && x.SenderDevice != nil {
+		return *x.SenderDevice
+	}
+	return 0
+}
+
+func (x *SenderCertificate_Certificate) GetExpires() uint64 {
+	if x != nil && x.Expires != nil {
+		return *x.Expires
+	}
+	return 0
+}
+
+func (x *SenderCertificate_Certificate) GetIdentityKey() []byte {
+	if x != nil {
+		return x.IdentityKey
+	}
+	return nil
+}
+
+func (x *SenderCertificate_Certificate) GetSigner() *ServerCertificate {
+	if x != nil {
+		return x.Signer
+	}
+	return nil
+}
+
+type UnidentifiedSenderMessage_Message struct {
+	state             protoimpl.MessageState                  `protogen:"open.v1"`
+	Type              *UnidentifiedSenderMessage_Message_Type `protobuf:"varint,1,opt,name=type,enum=signal.UnidentifiedSenderMessage_Message_Type" json:"type,omitempty"`
+	SenderCertificate *SenderCertificate                      `protobuf:"bytes,2,opt,name=senderCertificate" json:"senderCertificate,omitempty"`
+	Content           []byte                                  `protobuf:"bytes,3,opt,name=content" json:"content,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *UnidentifiedSenderMessage_Message) Reset() {
+	*x = UnidentifiedSenderMessage_Message{}
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnidentifiedSenderMessage_Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnidentifiedSenderMessage_Message) ProtoMessage() {}
+
+func (x *UnidentifiedSenderMessage_Message) ProtoReflect() protoreflect.Message {
+	mi := &file_serialize_UnidentifiedDelivery_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnidentifiedSenderMessage_Message.ProtoReflect.Descriptor instead.
+func (*UnidentifiedSenderMessage_Message) Descriptor() ([]byte, []int) {
+	return file_serialize_UnidentifiedDelivery_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *UnidentifiedSenderMessage_Message) GetType() UnidentifiedSenderMessage_Message_Type {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return UnidentifiedSenderMessage_Message_PREKEY_MESSAGE
+}
+
+func (x *UnidentifiedSenderMessage_Message) GetSenderCertificate() *SenderCertificate {
+	if x != nil {
+		return x.SenderCertificate
+	}
+	return nil
+}
+
+func (x *UnidentifiedSenderMessage_Message) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+var File_serialize_UnidentifiedDelivery_proto protoreflect.FileDescriptor
+
+const file_serialize_UnidentifiedDelivery_proto_rawDesc = "" +
+	"\n" +
+	"$serialize/UnidentifiedDelivery.proto\x12\x06signal\"\x84\x01\n" +
+	"\x11ServerCertificate\x12 \n" +
+	"\vcertificate\x18\x01 \x01(\fR\vcertificate\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\x1a/\n" +
+	"\vCertificate\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\fR\x03key\"\x8e\x02\n" +
+	"\x11SenderCertificate\x12 \n" +
+	"\vcertificate\x18\x01 \x01(\fR\vcertificate\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\x1a\xb8\x01\n" +
+	"\vCertificate\x12\x16\n" +
+	"\x06sender\x18\x01 \x01(\tR\x06sender\x12\"\n" +
+	"\fsenderDevice\x18\x02 \x01(\rR\fsenderDevice\x12\x18\n" +
+	"\aexpires\x18\x03 \x01(\x06R\aexpires\x12 \n" +
+	"\videntityKey\x18\x04 \x01(\fR\videntityKey\x121\n" +
+	"\x06signer\x18\x05 \x01(\v2\x19.signal.ServerCertificateR\x06signer\"\xf7\x02\n" +
+	"\x19UnidentifiedSenderMessage\x12(\n" +
+	"\x0fephemeralPublic\x18\x01 \x01(\fR\x0fephemeralPublic\x12(\n" +
+	"\x0fencryptedStatic\x18\x02 \x01(\fR\x0fencryptedStatic\x12*\n" +
+	"\x10encryptedMessage\x18\x03 \x01(\fR\x10encryptedMessage\x1a\xd9\x01\n" +
+	"\aMessage\x12B\n" +
+	"\x04type\x18\x01 \x01(\x0e2..signal.UnidentifiedSenderMessage.Message.TypeR\x04type\x12G\n" +
+	"\x11senderCertificate\x18\x02 \x01(\v2\x19.signal.SenderCertificateR\x11senderCertificate\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"'\n" +
+	"\x04Type\x12\x12\n" +
+	"\x0ePREKEY_MESSAGE\x10\x01\x12\v\n" +
+	"\aMESSAGE\x10\x02"
+
+var (
+	file_serialize_UnidentifiedDelivery_proto_rawDescOnce sync.Once
+	file_serialize_UnidentifiedDelivery_proto_rawDescData []byte
+)
+
+func file_serialize_UnidentifiedDelivery_proto_rawDescGZIP() []byte {
+	file_serialize_UnidentifiedDelivery_proto_rawDescOnce.Do(func() {
+		file_serialize_UnidentifiedDelivery_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_serialize_UnidentifiedDelivery_proto_rawDesc), len(file_serialize_UnidentifiedDelivery_proto_rawDesc)))
+	})
+	return file_serialize_UnidentifiedDelivery_proto_rawDescData
+}
+
+var file_serialize_UnidentifiedDelivery_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_serialize_UnidentifiedDelivery_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_serialize_UnidentifiedDelivery_proto_goTypes = []any{
+	(UnidentifiedSenderMessage_Message_Type)(0), // 0: signal.UnidentifiedSenderMessage.Message.Type
+	(*ServerCertificate)(nil),                   // 1: signal.ServerCertificate
+	(*SenderCertificate)(nil),                   // 2: signal.SenderCertificate
+	(*UnidentifiedSenderMessage)(nil),           // 3: signal.UnidentifiedSenderMessage
+	(*ServerCertificate_Certificate)(nil),       // 4: signal.ServerCertificate.Certificate
+	(*SenderCertificate_Certificate)(nil),       // 5: signal.SenderCertificate.Certificate
+	(*UnidentifiedSenderMessage_Message)(nil),   // 6: signal.UnidentifiedSenderMessage.Message
+}
+var file_serialize_UnidentifiedDelivery_proto_depIdxs = []int32{
+	1, // 0: signal.SenderCertificate.Certificate.signer:type_name -> signal.ServerCertificate
+	0, // 1: signal.UnidentifiedSenderMessage.Message.type:type_name -> signal.UnidentifiedSenderMessage.Message.Type
+	2, // 2: signal.UnidentifiedSenderMessage.Message.senderCertificate:type_name -> signal.SenderCertificate
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_serialize_UnidentifiedDelivery_proto_init() }
+func file_serialize_UnidentifiedDelivery_proto_init() {
+	if File_serialize_UnidentifiedDelivery_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_serialize_UnidentifiedDelivery_proto_rawDesc), len(file_serialize_UnidentifiedDelivery_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_serialize_UnidentifiedDelivery_proto_goTypes,
+		DependencyIndexes: file_serialize_UnidentifiedDelivery_proto_depIdxs,
+		EnumInfos:         file_serialize_UnidentifiedDelivery_proto_enumTypes,
+		MessageInfos:      file_serialize_UnidentifiedDelivery_proto_msgTypes,
+	}.Build()
+	File_serialize_UnidentifiedDelivery_proto = out.File
+	file_serialize_UnidentifiedDelivery_proto_goTypes = nil
+	file_serialize_UnidentifiedDelivery_proto_depIdxs = nil
+}
@@ -2,18 +2,18 @@
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
-// 	protoc        v3.12.4
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
 // source: serialize/WhisperTextProtocol.proto
 
 package serialize
 
 import (
-	reflect "reflect"
-	sync "sync"
-
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -24,23 +24,20 @@ const (
 )
 
 type SignalMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	RatchetKey      []byte  `protobuf:"bytes,1,opt,name=ratchetKey" json:"ratchetKey,omitempty"`
-	Counter         *uint32 `protobuf:"varint,2,opt,name=counter" json:"counter,omitempty"`
-	PreviousCounter *uint32 `protobuf:"varint,3,opt,name=previousCounter" json:"previousCounter,omitempty"`
-	Ciphertext      []byte  `protobuf:"bytes,4,opt,name=ciphertext" json:"ciphertext,omitempty"`
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RatchetKey      []byte                 `protobuf:"bytes,1,opt,name=ratchetKey" json:"ratchetKey,omitempty"`
+	Counter         *uint32                `protobuf:"varint,2,opt,name=counter" json:"counter,omitempty"`
+	PreviousCounter *uint32                `protobuf:"varint,3,opt,name=previousCounter" json:"previousCounter,omitempty"`
+	Ciphertext      []byte                 `protobuf:"bytes,4,opt,name=ciphertext" json:"ciphertext,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *SignalMessage) Reset() {
 	*x = SignalMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SignalMessage) String() string {
@@ -51,7 +48,7 @@ func (*SignalMessage) ProtoMessage() {}
 
 func (x *SignalMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -95,25 +92,24 @@ func (x *SignalMessage) GetCiphertext() []byte {
 }
 
 type PreKeySignalMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	RegistrationId *uint32 `protobuf:"varint,5,opt,name=registrationId" json:"registrationId,omitempty"`
-	PreKeyId       *uint32 `protobuf:"varint,1,opt,name=preKeyId" json:"preKeyId,omitempty"`
-	SignedPreKeyId *uint32 `protobuf:"varint,6,opt,name=signedPreKeyId" json:"signedPreKeyId,omitempty"`
-	BaseKey        []byte  `protobuf:"bytes,2,opt,name=baseKey" json:"baseKey,omitempty"`
-	IdentityKey    []byte  `protobuf:"bytes,3,opt,name=identityKey" json:"identityKey,omitempty"`
-	Message        []byte  `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"` // SignalMessage
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RegistrationId  *uint32                `protobuf:"varint,5,opt,name=registrationId" json:"registrationId,omitempty"`
+	PreKeyId        *uint32                `protobuf:"varint,1,opt,name=preKeyId" json:"preKeyId,omitempty"`
+	SignedPreKeyId  *uint32                `protobuf:"varint,6,opt,name=signedPreKeyId" json:"signedPreKeyId,omitempty"`
+	BaseKey         []byte                 `protobuf:"bytes,2,opt,name=baseKey" json:"baseKey,omitempty"`
+	IdentityKey     []byte                 `protobuf:"bytes,3,opt,name=identityKey" json:"identityKey,omitempty"`
+	Message         []byte                 `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"` // SignalMessage
+	KyberPreKeyId   *uint32                `protobuf:"varint,7,opt,name=kyberPreKeyId" json:"kyberPreKeyId,omitempty"`
+	KyberCiphertext []byte                 `protobuf:"bytes,8,opt,name=kyberCiphertext" json:"kyberCiphertext,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *PreKeySignalMessage) Reset() {
 	*x = PreKeySignalMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *PreKeySignalMessage) String() string {
@@ -124,7 +120,7 @@ func (*PreKeySignalMessage) ProtoMessage() {}
 
 func (x *PreKeySignalMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -181,25 +177,36 @@ func (x *PreKeySignalMessage) GetMessage() []byte {
 	return nil
 }
 
-type KeyExchangeMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *PreKeySignalMessage) GetKyberPreKeyId() uint32 {
+	if x != nil && x.KyberPreKeyId != nil {
+		return *x.KyberPreKeyId
+	}
+	return 0
+}
+
+func (x *PreKeySignalMessage) GetKyberCiphertext() []byte {
+	if x != nil {
+		return x.KyberCiphertext
+	}
+	return nil
+}
 
-	Id               *uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	BaseKey          []byte  `protobuf:"bytes,2,opt,name=baseKey" json:"baseKey,omitempty"`
-	RatchetKey       []byte  `protobuf:"bytes,3,opt,name=ratchetKey" json:"ratchetKey,omitempty"`
-	IdentityKey      []byte  `protobuf:"bytes,4,opt,name=identityKey" json:"identityKey,omitempty"`
-	BaseKeySignature []byte  `protobuf:"bytes,5,opt,name=baseKeySignature" json:"baseKeySignature,omitempty"`
+type KeyExchangeMessage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               *uint32                `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	BaseKey          []byte                 `protobuf:"bytes,2,opt,name=baseKey" json:"baseKey,omitempty"`
+	RatchetKey       []byte                 `protobuf:"bytes,3,opt,name=ratchetKey" json:"ratchetKey,omitempty"`
+	IdentityKey      []byte                 `protobuf:"bytes,4,opt,name=identityKey" json:"identityKey,omitempty"`
+	BaseKeySignature []byte                 `protobuf:"bytes,5,opt,name=baseKeySignature" json:"baseKeySignature,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *KeyExchangeMessage) Reset() {
 	*x = KeyExchangeMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *KeyExchangeMessage) String() string {
@@ -210,7 +217,7 @@ func (*KeyExchangeMessage) ProtoMessage() {}
 
 func (x *KeyExchangeMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -261,22 +268,19 @@ func (x *KeyExchangeMessage) GetBaseKeySignature() []byte {
 }
 
 type SenderKeyMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *uint32                `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Iteration     *uint32                `protobuf:"varint,2,opt,name=iteration" json:"iteration,omitempty"`
+	Ciphertext    []byte                 `protobuf:"bytes,3,opt,name=ciphertext" json:"ciphertext,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id         *uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	Iteration  *uint32 `protobuf:"varint,2,opt,name=iteration" json:"iteration,omitempty"`
-	Ciphertext []byte  `protobuf:"bytes,3,opt,name=ciphertext" json:"ciphertext,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SenderKeyMessage) Reset() {
 	*x = SenderKeyMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyMessage) String() string {
@@ -287,7 +291,7 @@ func (*SenderKeyMessage) ProtoMessage() {}
 
 func (x *SenderKeyMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -324,23 +328,20 @@ func (x *SenderKeyMessage) GetCiphertext() []byte {
 }
 
 type SenderKeyDistributionMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            *uint32                `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Iteration     *uint32                `protobuf:"varint,2,opt,name=iteration" json:"iteration,omitempty"`
+	ChainKey      []byte                 `protobuf:"bytes,3,opt,name=chainKey" json:"chainKey,omitempty"`
+	SigningKey    []byte                 `protobuf:"bytes,4,opt,name=signingKey" json:"signingKey,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id         *uint32 `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	Iteration  *uint32 `protobuf:"varint,2,opt,name=iteration" json:"iteration,omitempty"`
-	ChainKey   []byte  `protobuf:"bytes,3,opt,name=chainKey" json:"chainKey,omitempty"`
-	SigningKey []byte  `protobuf:"bytes,4,opt,name=signingKey" json:"signingKey,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SenderKeyDistributionMessage) Reset() {
 	*x = SenderKeyDistributionMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *SenderKeyDistributionMessage) String() string {
@@ -351,7 +352,7 @@ func (*SenderKeyDistributionMessage) ProtoMessage() {}
 
 func (x *SenderKeyDistributionMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -395,21 +396,18 @@ func (x *SenderKeyDistributionMessage) GetSigningKey() []byte {
 }
 
 type DeviceConsistencyCodeMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    *uint32                `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Generation *uint32 `protobuf:"varint,1,opt,name=generation" json:"generation,omitempty"`
-	Signature  []byte  `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeviceConsistencyCodeMessage) Reset() {
 	*x = DeviceConsistencyCodeMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *DeviceConsistencyCodeMessage) String() string {
@@ -420,7 +418,7 @@ func (*DeviceConsistencyCodeMessage) ProtoMessage() {}
 
 func (x *DeviceConsistencyCodeMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_serialize_WhisperTextProtocol_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -451,81 +449,69 @@ func (x *DeviceConsistencyCodeMessage) GetSignature() []byte {
 
 var File_serialize_WhisperTextProtocol_proto protoreflect.FileDescriptor
 
-var file_serialize_WhisperTextProtocol_proto_rawDesc = []byte{
-	0x0a, 0x23, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x2f, 0x57, 0x68, 0x69, 0x73,
-	0x70, 0x65, 0x72, 0x54, 0x65, 0x78, 0x74, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x74, 0x65, 0x78, 0x74, 0x73, 0x65, 0x63, 0x75, 0x72,
-	0x65, 0x22, 0x93, 0x01, 0x0a, 0x0d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b, 0x65,
-	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x72, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74,
-	0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x28, 0x0a,
-	0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73,
-	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65,
-	0x72, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x63, 0x69, 0x70,
-	0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x22, 0xd7, 0x01, 0x0a, 0x13, 0x50, 0x72, 0x65, 0x4b,
-	0x65, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
-	0x26, 0x0a, 0x0e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49,
-	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x4b, 0x65,
-	0x79, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x72, 0x65, 0x4b, 0x65,
-	0x79, 0x49, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x50, 0x72, 0x65,
-	0x4b, 0x65, 0x79, 0x49, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x73, 0x69, 0x67,
-	0x6e, 0x65, 0x64, 0x50, 0x72, 0x65, 0x4b, 0x65, 0x79, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62,
-	0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x62, 0x61,
-	0x73, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
-	0x79, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x69, 0x64, 0x65, 0x6e,
-	0x74, 0x69, 0x74, 0x79, 0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x22, 0xac, 0x01, 0x0a, 0x12, 0x4b, 0x65, 0x79, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67,
-	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x73, 0x65,
-	0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x62, 0x61, 0x73, 0x65, 0x4b,
-	0x65, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b, 0x65, 0x79,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x72, 0x61, 0x74, 0x63, 0x68, 0x65, 0x74, 0x4b,
-	0x65, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x4b, 0x65,
-	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
-	0x79, 0x4b, 0x65, 0x79, 0x12, 0x2a, 0x0a, 0x10, 0x62, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x53,
-	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10,
-	0x62, 0x61, 0x73, 0x65, 0x4b, 0x65, 0x79, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
-	0x22, 0x60, 0x0a, 0x10, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x4d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65,
-	0x78, 0x74, 0x22, 0x88, 0x01, 0x0a, 0x1c, 0x53, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x4b, 0x65, 0x79,
-	0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x69, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x1e, 0x0a,
-	0x0a, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x22, 0x5c, 0x0a,
-	0x1c, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e,
-	0x63, 0x79, 0x43, 0x6f, 0x64, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1e, 0x0a,
-	0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a,
-	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
-}
+const file_serialize_WhisperTextProtocol_proto_rawDesc = "" +
+	"\n" +
+	"#serialize/WhisperTextProtocol.proto\x12\n" +
+	"textsecure\"\x93\x01\n" +
+	"\rSignalMessage\x12\x1e\n" +
+	"\n" +
+	"ratchetKey\x18\x01 \x01(\fR\n" +
+	"ratchetKey\x12\x18\n" +
+	"\acounter\x18\x02 \x01(\rR\acounter\x12(\n" +
+	"\x0fpreviousCounter\x18\x03 \x01(\rR\x0fpreviousCounter\x12\x1e\n" +
+	"\n" +
+	"ciphertext\x18\x04 \x01(\fR\n" +
+	"ciphertext\"\xa7\x02\n" +
+	"\x13PreKeySignalMessage\x12&\n" +
+	"\x0eregistrationId\x18\x05 \x01(\rR\x0eregistrationId\x12\x1a\n" +
+	"\bpreKeyId\x18\x01 \x01(\rR\bpreKeyId\x12&\n" +
+	"\x0esignedPreKeyId\x18\x06 \x01(\rR\x0esignedPreKeyId\x12\x18\n" +
+	"\abaseKey\x18\x02 \x01(\fR\abaseKey\x12 \n" +
+	"\videntityKey\x18\x03 \x01(\fR\videntityKey\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\fR\amessage\x12$\n" +
+	"\rkyberPreKeyId\x18\a \x01(\rR\rkyberPreKeyId\x12(\n" +
+	"\x0fkyberCiphertext\x18\b \x01(\fR\x0fkyberCiphertext\"\xac\x01\n" +
+	"\x12KeyExchangeMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x18\n" +
+	"\abaseKey\x18\x02 \x01(\fR\abaseKey\x12\x1e\n" +
+	"\n" +
+	"ratchetKey\x18\x03 \x01(\fR\n" +
+	"ratchetKey\x12 \n" +
+	"\videntityKey\x18\x04 \x01(\fR\videntityKey\x12*\n" +
+	"\x10baseKeySignature\x18\x05 \x01(\fR\x10baseKeySignature\"`\n" +
+	"\x10SenderKeyMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1c\n" +
+	"\titeration\x18\x02 \x01(\rR\titeration\x12\x1e\n" +
+	"\n" +
+	"ciphertext\x18\x03 \x01(\fR\n" +
+	"ciphertext\"\x88\x01\n" +
+	"\x1cSenderKeyDistributionMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1c\n" +
+	"\titeration\x18\x02 \x01(\rR\titeration\x12\x1a\n" +
+	"\bchainKey\x18\x03 \x01(\fR\bchainKey\x12\x1e\n" +
+	"\n" +
+	"signingKey\x18\x04 \x01(\fR\n" +
+	"signingKey\"\\\n" +
+	"\x1cDeviceConsistencyCodeMessage\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\rR\n" +
+	"generation\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature"
 
 var (
 	file_serialize_WhisperTextProtocol_proto_rawDescOnce sync.Once
-	file_serialize_WhisperTextProtocol_proto_rawDescData = file_serialize_WhisperTextProtocol_proto_rawDesc
+	file_serialize_WhisperTextProtocol_proto_rawDescData []byte
 )
 
 func file_serialize_WhisperTextProtocol_proto_rawDescGZIP() []byte {
 	file_serialize_WhisperTextProtocol_proto_rawDescOnce.Do(func() {
-		file_serialize_WhisperTextProtocol_proto_rawDescData = protoimpl.X.CompressGZIP(file_serialize_WhisperTextProtocol_proto_rawDescData)
+		file_serialize_WhisperTextProtocol_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_serialize_WhisperTextProtocol_proto_rawDesc), len(file_serialize_WhisperTextProtocol_proto_rawDesc)))
 	})
 	return file_serialize_WhisperTextProtocol_proto_rawDescData
 }
 
 var file_serialize_WhisperTextProtocol_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_serialize_WhisperTextProtocol_proto_goTypes = []interface{}{
+var file_serialize_WhisperTextProtocol_proto_goTypes = []any{
 	(*SignalMessage)(nil),                // 0: textsecure.SignalMessage
 	(*PreKeySignalMessage)(nil),          // 1: textsecure.PreKeySignalMessage
 	(*KeyExchangeMessage)(nil),           // 2: textsecure.KeyExchangeMessage
@@ -546,85 +532,11 @@ func file_serialize_WhisperTextProtocol_proto_init() {
 	if File_serialize_WhisperTextProtocol_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_serialize_WhisperTextProtocol_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignalMessage); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_WhisperTextProtocol_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PreKeySignalMessage); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_WhisperTextProtocol_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*KeyExchangeMessage); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_WhisperTextProtocol_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyMessage); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_WhisperTextProtocol_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SenderKeyDistributionMessage); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_serialize_WhisperTextProtocol_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeviceConsistencyCodeMessage); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_serialize_WhisperTextProtocol_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_serialize_WhisperTextProtocol_proto_rawDesc), len(file_serialize_WhisperTextProtocol_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   6,
 			NumExtensions: 0,
@@ -635,7 +547,6 @@ func file_serialize_WhisperTextProtocol_proto_init() {
 		MessageInfos:      file_serialize_WhisperTextProtocol_proto_msgTypes,
 	}.Build()
 	File_serialize_WhisperTextProtocol_proto = out.File
-	file_serialize_WhisperTextProtocol_proto_rawDesc = nil
 	file_serialize_WhisperTextProtocol_proto_goTypes = nil
 	file_serialize_WhisperTextProtocol_proto_depIdxs = nil
 }
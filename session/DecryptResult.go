@@ -0,0 +1,62 @@
+package session
+
+import (
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/keys/message"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/util/optional"
+)
+
+// DecryptResult contains the plaintext of a decrypted message along with
+// information about the session that was used to decrypt it.
+type DecryptResult struct {
+	// Plaintext is the decrypted message.
+	Plaintext []byte
+
+	// MessageKeys are the keys that were used to decrypt the message.
+	MessageKeys *message.Keys
+
+	// SenderIdentityKey is the identity key of the sender of the message.
+	SenderIdentityKey *identity.Key
+
+	// Counter and PreviousCounter are the message counters of the sender's
+	// current and previous sending chains.
+	Counter         uint32
+	PreviousCounter uint32
+
+	// SessionVersion is the version of the session state that was used.
+	SessionVersion int
+
+	// NewSession is true if the message was a prekey message that
+	// established a new session.
+	NewSession bool
+
+	// PreKeyID, SignedPreKeyID and KyberPreKeyID are the IDs of our prekeys
+	// that were used to establish a new session. They are empty if no new
+	// session was established or the message didn't use the key.
+	PreKeyID       *optional.Uint32
+	SignedPreKeyID *optional.Uint32
+	KyberPreKeyID  *optional.Uint32
+
+	// UsedLastResortPreKey is true if the new session was established with
+	// a last resort prekey or kyber prekey.
+	UsedLastResortPreKey bool
+
+	// PromotedArchivedState is true if the message was decrypted with an
+	// archived session state, which has now become the current state.
+	PromotedArchivedState bool
+}
+
+// newDecryptResult returns a result for the given message and the
+// plaintext and keys it was decrypted with. The prekey IDs are empty.
+func newDecryptResult(ciphertextMessage *protocol.SignalMessage, plaintext []byte, messageKeys *message.Keys) *DecryptResult {
+	return &DecryptResult{
+		Plaintext:       plaintext,
+		MessageKeys:     messageKeys,
+		Counter:         ciphertextMessage.Counter(),
+		PreviousCounter: ciphertextMessage.PreviousCounter(),
+		PreKeyID:        optional.NewEmptyUint32(),
+		SignedPreKeyID:  optional.NewEmptyUint32(),
+		KyberPreKeyID:   optional.NewEmptyUint32(),
+	}
+}
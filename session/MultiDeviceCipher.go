@@ -0,0 +1,189 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.mau.fi/libsignal/config"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store"
+	"go.mau.fi/libsignal/util/keylock"
+)
+
+// defaultDeviceID is the device ID of the primary device of a user. Session
+// stores don't include it in GetSubDeviceSessions.
+const defaultDeviceID uint32 = 1
+
+// NewMultiDeviceCipher constructs a cipher that encrypts messages for all
+// devices of a recipient using the given stores. If no config is given, the
+// default limits are used.
+func NewMultiDeviceCipher(sessionStore store.Session, preKeyStore store.PreKey,
+	signedStore store.SignedPreKey, identityStore store.IdentityKey,
+	serializer *serialize.Serializer, cfg ...*config.Config) *MultiDeviceCipher {
+
+	return &MultiDeviceCipher{
+		builder: NewBuilder(sessionStore, preKeyStore, signedStore, identityStore, nil, serializer, cfg...),
+		locker:  keylock.Default,
+	}
+}
+
+// NewMultiDeviceCipherFromSignal constructs a cipher that encrypts messages
+// for all devices of a recipient using a SignalProtocol store. If no config
+// is given, the default limits are used.
+func NewMultiDeviceCipherFromSignal(signalStore store.SignalProtocol,
+	serializer *serialize.Serializer, cfg ...*config.Config) *MultiDeviceCipher {
+
+	return &MultiDeviceCipher{
+		builder: NewBuilderFromSignal(signalStore, nil, serializer, cfg...),
+		locker:  keylock.Default,
+	}
+}
+
+// MultiDeviceCipher encrypts a message for every device of a recipient, using
+// a separate session Cipher for each device. Failing to encrypt for one device
+// doesn't stop encrypting for the others; the error is returned in the result
+// for that device instead.
+type MultiDeviceCipher struct {
+	builder      *Builder
+	localAddress *protocol.SignalAddress
+	locker       keylock.Locker
+}
+
+// SetLocalAddress sets the address of our own device. When encrypting for
+// our own name, the local device will be skipped.
+func (m *MultiDeviceCipher) SetLocalAddress(localAddress *protocol.SignalAddress) {
+	m.localAddress = localAddress
+}
+
+// SetLocker sets the locker that is used by the session ciphers for each
+// device.
+func (m *MultiDeviceCipher) SetLocker(locker keylock.Locker) {
+	m.locker = locker
+}
+
+// Devices will return the IDs of all devices of the given name that we have
+// a session with, in ascending order.
+func (m *MultiDeviceCipher) Devices(ctx context.Context, name string) ([]uint32, error) {
+	deviceIDs, err := m.builder.sessionStore.GetSubDeviceSessions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	deviceIDs = slices.Clone(deviceIDs)
+
+	// The primary device is not included in the sub device sessions.
+	if !slices.Contains(deviceIDs, defaultDeviceID) {
+		contains, err := m.builder.sessionStore.ContainsSession(ctx, protocol.NewSignalAddress(name, defaultDeviceID))
+		if err != nil {
+			return nil, err
+		}
+		if contains {
+			deviceIDs = append(deviceIDs, defaultDeviceID)
+		}
+	}
+	slices.Sort(deviceIDs)
+
+	return deviceIDs, nil
+}
+
+// Encrypt will encrypt the given message for every device of the given name
+// that we have a session with.
+func (m *MultiDeviceCipher) Encrypt(ctx context.Context, name string, plaintext []byte) (DeviceResults, error) {
+	deviceIDs, err := m.Devices(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.EncryptForDevices(ctx, name, deviceIDs, plaintext), nil
+}
+
+// EncryptForDevices will encrypt the given message for the given devices of
+// the given name, such as a device list retrieved from a server. Devices that
+// we don't have a session with will be reported by DeviceResults.NeedsPreKeyBundle.
+func (m *MultiDeviceCipher) EncryptForDevices(ctx context.Context, name string, deviceIDs []uint32,
+	plaintext []byte) DeviceResults {
+
+	results := make(DeviceResults, 0, len(deviceIDs))
+	for _, deviceID := range deviceIDs {
+		address := protocol.NewSignalAddress(name, deviceID)
+		if m.localAddress != nil && *address == *m.localAddress {
+			continue
+		}
+
+		builder := *m.builder
+		builder.remoteAddress = address
+		builder.locker = m.locker
+		cipher := NewCipher(&builder, address)
+
+		message, err := cipher.Encrypt(ctx, plaintext)
+		results = append(results, &DeviceResult{
+			Address: address,
+			Message: message,
+			Err:     err,
+		})
+	}
+
+	return results
+}
+
+// DeviceResult is the result of encrypting a message for a single device.
+// Either Message or Err is set.
+type DeviceResult struct {
+	Address *protocol.SignalAddress
+	Message protocol.CiphertextMessage
+	Err     error
+}
+
+// NeedsPreKeyBundle returns true if encrypting failed because there is no
+// session with the device. A session can be built by processing a fresh
+// prekey.Bundle for the device.
+func (r *DeviceResult) NeedsPreKeyBundle() bool {
+	return errors.Is(r.Err, signalerror.ErrNoSessionForUser)
+}
+
+// IsUntrusted returns true if encrypting failed because the identity key of
+// the device is not trusted.
+func (r *DeviceResult) IsUntrusted() bool {
+	return errors.Is(r.Err, signalerror.ErrUntrustedIdentity)
+}
+
+// DeviceResults is a list of results of encrypting a message for multiple
+// devices.
+type DeviceResults []*DeviceResult
+
+// Messages will return the encrypted messages for the devices that were
+// successfully encrypted for, by device ID.
+func (r DeviceResults) Messages() map[uint32]protocol.CiphertextMessage {
+	messages := make(map[uint32]protocol.CiphertextMessage, len(r))
+	for _, result := range r {
+		if result.Err == nil {
+			messages[result.Address.DeviceID()] = result.Message
+		}
+	}
+	return messages
+}
+
+// Errors will return the errors for the devices that failed, by device ID.
+func (r DeviceResults) Errors() map[uint32]error {
+	errs := make(map[uint32]error)
+	for _, result := range r {
+		if result.Err != nil {
+			errs[result.Address.DeviceID()] = result.Err
+		}
+	}
+	return errs
+}
+
+// NeedsPreKeyBundle will return the addresses of the devices that need a
+// fresh prekey.Bundle to build a session.
+func (r DeviceResults) NeedsPreKeyBundle() []*protocol.SignalAddress {
+	var addresses []*protocol.SignalAddress
+	for _, result := range r {
+		if result.NeedsPreKeyBundle() {
+			addresses = append(addresses, result.Address)
+		}
+	}
+	return addresses
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/identity"
 	"go.mau.fi/libsignal/keys/prekey"
 	"go.mau.fi/libsignal/logger"
 	"go.mau.fi/libsignal/protocol"
@@ -14,14 +16,18 @@ import (
 	"go.mau.fi/libsignal/signalerror"
 	"go.mau.fi/libsignal/state/record"
 	"go.mau.fi/libsignal/state/store"
-	"go.mau.fi/libsignal/util/medium"
+	"go.mau.fi/libsignal/util/keyhelper"
+	"go.mau.fi/libsignal/util/keylock"
 	"go.mau.fi/libsignal/util/optional"
 )
 
-// NewBuilder constructs a session builder.
+// NewBuilder constructs a session builder. If the given prekey store also
+// implements store.KyberPreKey, it will be used to build PQXDH sessions.
+// If no config is given, the default limits are used.
 func NewBuilder(sessionStore store.Session, preKeyStore store.PreKey,
 	signedStore store.SignedPreKey, identityStore store.IdentityKey,
-	remoteAddress *protocol.SignalAddress, serializer *serialize.Serializer) *Builder {
+	remoteAddress *protocol.SignalAddress, serializer *serialize.Serializer,
+	cfg ...*config.Config) *Builder {
 
 	builder := Builder{
 		sessionStore:      sessionStore,
@@ -30,15 +36,21 @@ func NewBuilder(sessionStore store.Session, preKeyStore store.PreKey,
 		identityKeyStore:  identityStore,
 		remoteAddress:     remoteAddress,
 		serializer:        serializer,
+		locker:            keylock.Default,
+		config:            config.Get(cfg...),
 	}
+	builder.kyberPreKeyStore, _ = preKeyStore.(store.KyberPreKey)
 
 	return &builder
 }
 
 // NewBuilderFromSignal Store constructs a session builder using a
-// SignalProtocol Store.
+// SignalProtocol Store. If the store also implements store.KyberPreKey,
+// it will be used to build PQXDH sessions. If no config is given, the
+// default limits are used.
 func NewBuilderFromSignal(signalStore store.SignalProtocol,
-	remoteAddress *protocol.SignalAddress, serializer *serialize.Serializer) *Builder {
+	remoteAddress *protocol.SignalAddress, serializer *serialize.Serializer,
+	cfg ...*config.Config) *Builder {
 
 	builder := Builder{
 		sessionStore:      signalStore,
@@ -47,7 +59,10 @@ func NewBuilderFromSignal(signalStore store.SignalProtocol,
 		identityKeyStore:  signalStore,
 		remoteAddress:     remoteAddress,
 		serializer:        serializer,
+		locker:            keylock.Default,
+		config:            config.Get(cfg...),
 	}
+	builder.kyberPreKeyStore, _ = signalStore.(store.KyberPreKey)
 
 	return &builder
 }
@@ -61,6 +76,14 @@ func NewBuilderFromSignal(signalStore store.SignalProtocol,
 //   - PreKeySignalMessage received from a client.
 //   - KeyExchangeMessage sent to or received from a client.
 //
+// If the session store implements store.Transactional, the store calls of
+// each operation are run in a single transaction. The limits of the builder's
+// config are applied to the session records it loads.
+//
+// Each operation holds the same lock for the remote address as the session
+// ciphers, so sessions aren't built while a message is being encrypted or
+// decrypted with the same session.
+//
 // Sessions are constructed per recipientId + deviceId tuple.
 // Remote logical users are identified by their recipientId,
 // and each logical recipientId can have multiple physical
@@ -69,55 +92,114 @@ type Builder struct {
 	sessionStore      store.Session
 	preKeyStore       store.PreKey
 	signedPreKeyStore store.SignedPreKey
+	kyberPreKeyStore  store.KyberPreKey
 	identityKeyStore  store.IdentityKey
 	remoteAddress     *protocol.SignalAddress
 	serializer        *serialize.Serializer
+	locker            keylock.Locker
+	config            *config.Config
+}
+
+// SetLocker sets the locker used to serialize operations on the session.
+// Ciphers created from the builder afterwards use the same locker. By
+// default, all builders and ciphers share keylock.Default, which is only
+// enough if a single process uses the stores.
+func (b *Builder) SetLocker(locker keylock.Locker) {
+	b.locker = locker
+}
+
+// lock acquires the lock for the remote address.
+func (b *Builder) lock(ctx context.Context) (unlock func(), err error) {
+	return b.locker.Lock(ctx, b.remoteAddress.String())
+}
+
+// processResult contains the IDs of the prekeys that were used to build
+// a session from a pre key signal message. The IDs are empty if the key
+// was not used or should not be removed from the store.
+type processResult struct {
+	preKeyID              *optional.Uint32
+	kyberPreKeyID         *optional.Uint32
+	lastResortPreKey      bool
+	lastResortPreKeyID    uint32
+	lastResortKyberPreKey bool
+	newSession            bool
 }
 
 // Process builds a new session from a session record and pre
 // key signal message.
 func (b *Builder) Process(ctx context.Context, sessionRecord *record.Session, message *protocol.PreKeySignalMessage) (unsignedPreKeyID *optional.Uint32, err error) {
+	unlock, err := b.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	// Check to see if the keys are trusted.
-	theirIdentityKey := message.IdentityKey()
-	trusted, err := b.identityKeyStore.IsTrustedIdentity(ctx, b.remoteAddress, theirIdentityKey)
+	var result *processResult
+	err = store.WithTx(ctx, b.sessionStore, func(ctx context.Context) (err error) {
+		result, err = b.process(ctx, sessionRecord, message)
+		if err != nil {
+			return err
+		}
+		return b.recordLastResortUse(ctx, result)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if !trusted {
-		return nil, signalerror.ErrUntrustedIdentity
+
+	// Return the unsignedPreKeyID
+	return result.preKeyID, nil
+}
+
+// process builds a new session from a session record and pre key signal
+// message, returning the IDs of the prekeys that were used.
+func (b *Builder) process(ctx context.Context, sessionRecord *record.Session, message *protocol.PreKeySignalMessage) (*processResult, error) {
+	if err := b.checkPreKeyMessageIdentity(ctx, message); err != nil {
+		return nil, err
 	}
 
-	// Use version 3 of the signal/axolotl protocol.
-	unsignedPreKeyID, err = b.processV3(ctx, sessionRecord, message)
+	result, err := b.processPreKeyMessage(ctx, sessionRecord, message)
 	if err != nil {
 		return nil, err
 	}
 
 	// Save the identity key to our identity store.
-	if err := b.identityKeyStore.SaveIdentity(ctx, b.remoteAddress, theirIdentityKey); err != nil {
+	if err := b.identityKeyStore.SaveIdentity(ctx, b.remoteAddress, message.IdentityKey()); err != nil {
 		return nil, err
 	}
 
-	// Return the unsignedPreKeyID
-	return unsignedPreKeyID, nil
+	return result, nil
 }
 
-// ProcessV3 builds a new session from a session record and pre key
-// signal message. After a session is constructed in this way, the embedded
-// SignalMessage can be decrypted.
-func (b *Builder) processV3(ctx context.Context, sessionRecord *record.Session,
-	message *protocol.PreKeySignalMessage) (unsignedPreKeyID *optional.Uint32, err error) {
+// checkPreKeyMessageIdentity will check that the identity key of the given
+// pre key signal message is trusted.
+func (b *Builder) checkPreKeyMessageIdentity(ctx context.Context, message *protocol.PreKeySignalMessage) error {
+	theirIdentityKey := message.IdentityKey()
+	trusted, err := store.IsTrustedIdentity(ctx, b.identityKeyStore, b.remoteAddress, theirIdentityKey, store.DirectionReceiving)
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		return newUntrustedIdentityError(ctx, b.identityKeyStore, b.remoteAddress, theirIdentityKey, store.DirectionReceiving, message)
+	}
+	return nil
+}
+
+// processPreKeyMessage builds a new session from a session record and pre
+// key signal message. The session uses the version of the message, so that
+// replies to older clients are sent as version 3 messages. After a session
+// is constructed in this way, the embedded SignalMessage can be decrypted.
+func (b *Builder) processPreKeyMessage(ctx context.Context, sessionRecord *record.Session,
+	message *protocol.PreKeySignalMessage) (*processResult, error) {
 
 	logger.Debug("Processing message with PreKeyID: ", message.PreKeyID())
-	// Check to see if we've already set up a session for this V3 message.
+	// Check to see if we've already set up a session for this message.
 	sessionExists := sessionRecord.HasSessionState(
 		message.MessageVersion(),
 		message.BaseKey().Serialize(),
 	)
 	if sessionExists {
-		logger.Debug("We've already setup a session for this V3 message, letting bundled message fall through...")
-		return optional.NewEmptyUint32(), nil
+		logger.Debug("We've already setup a session for this message, letting bundled message fall through...")
+		return &processResult{preKeyID: optional.NewEmptyUint32(), kyberPreKeyID: optional.NewEmptyUint32()}, nil
 	}
 
 	// Load our signed prekey from our signed prekey store.
@@ -153,6 +235,24 @@ func (b *Builder) processV3(ctx context.Context, sessionRecord *record.Session,
 		parameters.SetOurOneTimePreKey(nil)
 	}
 
+	// Set our kyber pre key with the one from our kyber prekey store
+	// if the message was encrypted to one.
+	if !message.KyberPreKeyID().IsEmpty {
+		kyberPreKeyID := message.KyberPreKeyID().Value
+		if b.kyberPreKeyStore == nil {
+			return nil, fmt.Errorf("%w with ID %d (store doesn't support kyber prekeys)", signalerror.ErrNoKyberPreKey, kyberPreKeyID)
+		}
+		kyberPreKey, err := b.kyberPreKeyStore.LoadKyberPreKey(ctx, kyberPreKeyID)
+		if err != nil {
+			return nil, err
+		}
+		if kyberPreKey == nil {
+			return nil, fmt.Errorf("%w with ID %d", signalerror.ErrNoKyberPreKey, kyberPreKeyID)
+		}
+		parameters.SetOurKyberPreKey(kyberPreKey.KeyPair())
+		parameters.SetTheirKyberCiphertext(message.KyberCiphertext())
+	}
+
 	// If this is a fresh record, archive our current state.
 	if !sessionRecord.IsFresh() {
 		sessionRecord.ArchiveCurrentState()
@@ -164,7 +264,7 @@ func (b *Builder) processV3(ctx context.Context, sessionRecord *record.Session,
 	if sessionErr != nil {
 		return nil, sessionErr
 	}
-	sessionState.SetVersion(protocol.CurrentVersion)
+	sessionState.SetVersion(message.MessageVersion())
 	sessionState.SetRemoteIdentityKey(parameters.TheirIdentityKey())
 	sessionState.SetLocalIdentityKey(parameters.OurIdentityKeyPair().PublicKey())
 	sessionState.SetSenderChain(parameters.OurRatchetKey(), derivedKeys.ChainKey)
@@ -175,23 +275,72 @@ func (b *Builder) processV3(ctx context.Context, sessionRecord *record.Session,
 	sessionState.SetRemoteRegistrationID(message.RegistrationID())
 	sessionState.SetSenderBaseKey(message.BaseKey().Serialize())
 
-	// Remove the PreKey from our store and return the message prekey id if it is valid.
-	if message.PreKeyID() != nil && message.PreKeyID().Value != medium.MaxValue {
-		return message.PreKeyID(), nil
+	// Return the message prekey ids so they can be removed from our stores.
+	// Last resort prekeys are never removed.
+	result := &processResult{preKeyID: optional.NewEmptyUint32(), kyberPreKeyID: optional.NewEmptyUint32(), newSession: true}
+	if message.PreKeyID() != nil && !message.PreKeyID().IsEmpty {
+		if record.IsLastResortPreKeyID(message.PreKeyID().Value) {
+			result.lastResortPreKey = true
+			result.lastResortPreKeyID = message.PreKeyID().Value
+		} else {
+			result.preKeyID = message.PreKeyID()
+		}
+	}
+	if !message.KyberPreKeyID().IsEmpty {
+		if message.KyberPreKeyID().Value == record.LastResortPreKeyID {
+			result.lastResortKyberPreKey = true
+		} else {
+			result.kyberPreKeyID = message.KyberPreKeyID()
+		}
 	}
-	return optional.NewEmptyUint32(), nil
+	return result, nil
+}
+
+// recordLastResortUse will record the use of last resort prekeys in the
+// prekey stores if the session was built with them.
+func (b *Builder) recordLastResortUse(ctx context.Context, result *processResult) error {
+	if result.lastResortPreKey {
+		err := store.RecordLastResortPreKeyUse(ctx, b.preKeyStore, b.remoteAddress, store.PreKeyTypeEC, result.lastResortPreKeyID)
+		if err != nil {
+			return err
+		}
+	}
+	if result.lastResortKyberPreKey {
+		err := store.RecordLastResortPreKeyUse(ctx, b.kyberPreKeyStore, b.remoteAddress, store.PreKeyTypeKyber, record.LastResortPreKeyID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ProcessBundle builds a new session from a PreKeyBundle retrieved
-// from a server.
+// from a server. Sessions are built with PQXDH if the bundle has a kyber
+// prekey, and with X3DH otherwise, unless the builder's config has
+// RequireKyber set, in which case bundles without a kyber prekey are
+// rejected with signalerror.ErrIncompleteBundle. Sessions built with PQXDH
+// have protocol.CurrentVersion as their version.
 func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) error {
+	unlock, err := b.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return store.WithTx(ctx, b.sessionStore, func(ctx context.Context) error {
+		return b.processBundle(ctx, preKey)
+	})
+}
+
+// processBundle builds a new session from the given PreKeyBundle.
+func (b *Builder) processBundle(ctx context.Context, preKey *prekey.Bundle) error {
 	// Check to see if the keys are trusted.
-	trusted, err := b.identityKeyStore.IsTrustedIdentity(ctx, b.remoteAddress, preKey.IdentityKey())
+	trusted, err := store.IsTrustedIdentity(ctx, b.identityKeyStore, b.remoteAddress, preKey.IdentityKey(), store.DirectionSending)
 	if err != nil {
 		return err
 	}
 	if !trusted {
-		return signalerror.ErrUntrustedIdentity
+		return newUntrustedIdentityError(ctx, b.identityKeyStore, b.remoteAddress, preKey.IdentityKey(), store.DirectionSending, nil)
 	}
 
 	// Check to see if the bundle has a signed pre key.
@@ -207,6 +356,16 @@ func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) erro
 		return signalerror.ErrInvalidSignature
 	}
 
+	// Verify the signature of the kyber pre key if the bundle has one.
+	if preKey.KyberPreKey() == nil && b.config.RequireKyber {
+		return fmt.Errorf("%w (kyber prekey)", signalerror.ErrIncompleteBundle)
+	} else if preKey.KyberPreKey() != nil {
+		kyberPreKeyBytes := preKey.KyberPreKey().Serialize()
+		if !ecc.VerifySignature(preKeyPublic, kyberPreKeyBytes, preKey.KyberPreKeySignature()) {
+			return fmt.Errorf("%w (kyber prekey)", signalerror.ErrInvalidSignature)
+		}
+	}
+
 	// Load our session and generate keys.
 	sessionRecord, err := b.sessionStore.LoadSession(ctx, b.remoteAddress)
 	if err != nil {
@@ -215,6 +374,7 @@ func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) erro
 	if sessionRecord == nil {
 		return fmt.Errorf("LoadSession returned nil")
 	}
+	sessionRecord.SetConfig(b.config)
 	ourBaseKey, err := ecc.GenerateKeyPair()
 	if err != nil {
 		return err
@@ -231,6 +391,7 @@ func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) erro
 	parameters.SetTheirSignedPreKey(theirSignedPreKey)
 	parameters.SetTheirRatchetKey(theirSignedPreKey)
 	parameters.SetTheirOneTimePreKey(theirOneTimePreKey)
+	parameters.SetTheirKyberPreKey(preKey.KyberPreKey())
 
 	// If this is a fresh record, archive our current state.
 	if !sessionRecord.IsFresh() {
@@ -257,8 +418,13 @@ func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) erro
 		return chainErr
 	}
 
-	// Calculate the sender session.
-	sessionState.SetVersion(protocol.CurrentVersion)
+	// Calculate the sender session. Sessions that are built with a kyber
+	// prekey use version 4, others use version 3.
+	version := protocol.PreKyberVersion
+	if preKey.KyberPreKey() != nil {
+		version = protocol.CurrentVersion
+	}
+	sessionState.SetVersion(version)
 	sessionState.SetRemoteIdentityKey(parameters.TheirIdentityKey())
 	sessionState.SetLocalIdentityKey(parameters.OurIdentityKey().PublicKey())
 	sessionState.AddReceiverChain(parameters.TheirRatchetKey(), derivedKeys.ChainKey.Current())
@@ -271,6 +437,9 @@ func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) erro
 		preKey.SignedPreKeyID(),
 		ourBaseKey.PublicKey(),
 	)
+	if preKey.KyberPreKey() != nil {
+		sessionState.SetUnacknowledgedKyberPreKey(preKey.KyberPreKeyID(), parameters.KyberCiphertext())
+	}
 
 	// Set the local registration ID based on the registration id in our identity key store.
 	sessionState.SetLocalRegistrationID(
@@ -297,3 +466,266 @@ func (b *Builder) ProcessBundle(ctx context.Context, preKey *prekey.Bundle) erro
 
 	return nil
 }
+
+// InitiateKeyExchange starts an online key exchange with the remote address.
+// The returned message should be sent to the remote user, who can build a
+// session from it with ProcessKeyExchange.
+func (b *Builder) InitiateKeyExchange(ctx context.Context) (*protocol.KeyExchangeMessage, error) {
+	unlock, err := b.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Load our session and generate keys.
+	sessionRecord, err := b.sessionStore.LoadSession(ctx, b.remoteAddress)
+	if err != nil {
+		return nil, err
+	}
+	if sessionRecord == nil {
+		return nil, fmt.Errorf("LoadSession returned nil")
+	}
+	sessionRecord.SetConfig(b.config)
+	sequence := keyhelper.GenerateKeyExchangeSequence()
+	flags := protocol.KeyExchangeInitiateFlag
+	baseKey, err := ecc.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	ratchetKey, err := ecc.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	identityKey := b.identityKeyStore.GetIdentityKeyPair()
+	baseKeySignature := ecc.CalculateSignature(identityKey.PrivateKey(), baseKey.PublicKey().Serialize())
+
+	// Remember our keys so we can finish the session once the response arrives.
+	sessionRecord.SessionState().SetPendingKeyExchange(sequence, baseKey, ratchetKey, identityKey)
+	if err := b.sessionStore.StoreSession(ctx, b.remoteAddress, sessionRecord); err != nil {
+		return nil, err
+	}
+
+	return protocol.NewKeyExchangeMessage(
+		protocol.PreKyberVersion,
+		sequence,
+		flags,
+		baseKey.PublicKey(),
+		baseKeySignature,
+		ratchetKey.PublicKey(),
+		identityKey.PublicKey(),
+		b.serializer.KeyExchangeMessage,
+	), nil
+}
+
+// ProcessKeyExchange builds a new session from a key exchange message
+// received from the remote address. If the message initiates a key exchange,
+// the response that should be sent back is returned. If the message is a
+// response to our own key exchange, the returned message is nil.
+func (b *Builder) ProcessKeyExchange(ctx context.Context, message *protocol.KeyExchangeMessage) (*protocol.KeyExchangeMessage, error) {
+	// Check to see if the keys are trusted.
+	trusted, err := store.IsTrustedIdentity(ctx, b.identityKeyStore, b.remoteAddress, message.IdentityKey(), store.DirectionReceiving)
+	if err != nil {
+		return nil, err
+	}
+	if !trusted {
+		return nil, newUntrustedIdentityError(ctx, b.identityKeyStore, b.remoteAddress, message.IdentityKey(), store.DirectionReceiving, nil)
+	}
+
+	unlock, err := b.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var response *protocol.KeyExchangeMessage
+	err = store.WithTx(ctx, b.sessionStore, func(ctx context.Context) (err error) {
+		if message.IsInitiate() {
+			response, err = b.processInitiate(ctx, message)
+			return err
+		}
+		return b.processResponse(ctx, message)
+	})
+	return response, err
+}
+
+// processInitiate builds a session from a key exchange that was initiated by
+// the remote user and returns the response to send back.
+func (b *Builder) processInitiate(ctx context.Context, message *protocol.KeyExchangeMessage) (*protocol.KeyExchangeMessage, error) {
+	// Verify the signature of the base key.
+	theirIdentityKey := message.IdentityKey()
+	if !ecc.VerifySignature(theirIdentityKey.PublicKey(), message.BaseKey().Serialize(), message.BaseKeySignature()) {
+		return nil, fmt.Errorf("%w (key exchange base key)", signalerror.ErrInvalidSignature)
+	}
+
+	sessionRecord, err := b.sessionStore.LoadSession(ctx, b.remoteAddress)
+	if err != nil {
+		return nil, err
+	}
+	if sessionRecord == nil {
+		return nil, fmt.Errorf("LoadSession returned nil")
+	}
+	sessionRecord.SetConfig(b.config)
+	sessionState := sessionRecord.SessionState()
+	flags := protocol.KeyExchangeResponseFlag
+
+	// If we initiated a key exchange of our own at the same time, reuse those
+	// keys so both sides end up with the same session.
+	var baseKey, ratchetKey *ecc.ECKeyPair
+	var identityKey *identity.KeyPair
+	if sessionState.HasPendingKeyExchange() {
+		flags |= protocol.KeyExchangeSimultaneousInitiateFlag
+		baseKey = sessionState.PendingKeyExchangeBaseKeyPair()
+		ratchetKey = sessionState.PendingKeyExchangeRatchetKeyPair()
+		identityKey = sessionState.PendingKeyExchangeIdentityKeyPair()
+	} else {
+		baseKey, err = ecc.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		ratchetKey, err = ecc.GenerateKeyPair()
+		if err != nil {
+			return nil, err
+		}
+		identityKey = b.identityKeyStore.GetIdentityKeyPair()
+	}
+
+	parameters := &ratchet.SymmetricParameters{
+		OurBaseKey:         baseKey,
+		OurRatchetKey:      ratchetKey,
+		OurIdentityKeyPair: identityKey,
+		TheirBaseKey:       message.BaseKey(),
+		TheirRatchetKey:    message.RatchetKey(),
+		TheirIdentityKey:   theirIdentityKey,
+	}
+	err = b.initializeSymmetricSession(sessionRecord, parameters, message.MaxVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the session in our session store and save the identity in our identity store.
+	if err := b.sessionStore.StoreSession(ctx, b.remoteAddress, sessionRecord); err != nil {
+		return nil, err
+	}
+	if err := b.identityKeyStore.SaveIdentity(ctx, b.remoteAddress, theirIdentityKey); err != nil {
+		return nil, err
+	}
+
+	baseKeySignature := ecc.CalculateSignature(identityKey.PrivateKey(), baseKey.PublicKey().Serialize())
+	return protocol.NewKeyExchangeMessage(
+		sessionRecord.SessionState().Version(),
+		message.Sequence(),
+		flags,
+		baseKey.PublicKey(),
+		baseKeySignature,
+		ratchetKey.PublicKey(),
+		identityKey.PublicKey(),
+		b.serializer.KeyExchangeMessage,
+	), nil
+}
+
+// processResponse finishes building a session from a response to a key
+// exchange that we initiated.
+func (b *Builder) processResponse(ctx context.Context, message *protocol.KeyExchangeMessage) error {
+	sessionRecord, err := b.sessionStore.LoadSession(ctx, b.remoteAddress)
+	if err != nil {
+		return err
+	}
+	if sessionRecord == nil {
+		return fmt.Errorf("LoadSession returned nil")
+	}
+	sessionRecord.SetConfig(b.config)
+	sessionState := sessionRecord.SessionState()
+
+	// Make sure this is a response to the key exchange we initiated.
+	hasPendingKeyExchange := sessionState.HasPendingKeyExchange()
+	isSimultaneousInitiateResponse := message.IsResponseForSimultaneousInitiate()
+	if !hasPendingKeyExchange || sessionState.PendingKeyExchangeSequence() != message.Sequence() {
+		logger.Info("No matching sequence for key exchange response")
+		// If we both initiated at the same time, our session was already built
+		// while processing their initiate message.
+		if isSimultaneousInitiateResponse {
+			return nil
+		}
+		return signalerror.ErrStaleKeyExchange
+	}
+
+	// Verify the signature of the base key.
+	theirIdentityKey := message.IdentityKey()
+	if !ecc.VerifySignature(theirIdentityKey.PublicKey(), message.BaseKey().Serialize(), message.BaseKeySignature()) {
+		return fmt.Errorf("%w (key exchange base key)", signalerror.ErrInvalidSignature)
+	}
+
+	parameters := &ratchet.SymmetricParameters{
+		OurBaseKey:         sessionState.PendingKeyExchangeBaseKeyPair(),
+		OurRatchetKey:      sessionState.PendingKeyExchangeRatchetKeyPair(),
+		OurIdentityKeyPair: sessionState.PendingKeyExchangeIdentityKeyPair(),
+		TheirBaseKey:       message.BaseKey(),
+		TheirRatchetKey:    message.RatchetKey(),
+		TheirIdentityKey:   theirIdentityKey,
+	}
+	err = b.initializeSymmetricSession(sessionRecord, parameters, message.MaxVersion())
+	if err != nil {
+		return err
+	}
+
+	// Store the session in our session store and save the identity in our identity store.
+	if err := b.sessionStore.StoreSession(ctx, b.remoteAddress, sessionRecord); err != nil {
+		return err
+	}
+	return b.identityKeyStore.SaveIdentity(ctx, b.remoteAddress, theirIdentityKey)
+}
+
+// initializeSymmetricSession sets up the current session state of the given
+// record using the given symmetric parameters.
+func (b *Builder) initializeSymmetricSession(sessionRecord *record.Session,
+	parameters *ratchet.SymmetricParameters, theirMaxVersion int) error {
+
+	// If this is a fresh record, archive our current state.
+	if !sessionRecord.IsFresh() {
+		sessionRecord.ArchiveCurrentState()
+	}
+
+	///////// Initialize our session /////////
+	sessionState := sessionRecord.SessionState()
+	derivedKeys, err := ratchet.CalculateSymmetricSession(parameters)
+	if err != nil {
+		return err
+	}
+
+	// The user with the lower base key acts as the sender, the other one as the receiver.
+	isSender := ratchet.IsSymmetricSender(parameters.OurBaseKey.PublicKey(), parameters.TheirBaseKey)
+	if isSender {
+		// Generate an ephemeral "ratchet" key that will be advertised to
+		// the receiving user.
+		sendingRatchetKey, err := ecc.GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+		sendingChain, err := derivedKeys.RootKey.CreateChain(parameters.TheirRatchetKey, sendingRatchetKey)
+		if err != nil {
+			return err
+		}
+		sessionState.AddReceiverChain(parameters.TheirRatchetKey, derivedKeys.ChainKey.Current())
+		sessionState.SetSenderChain(sendingRatchetKey, sendingChain.ChainKey)
+		sessionState.SetRootKey(sendingChain.RootKey)
+		sessionState.SetSenderBaseKey(parameters.OurBaseKey.PublicKey().Serialize())
+	} else {
+		sessionState.SetSenderChain(parameters.OurRatchetKey, derivedKeys.ChainKey)
+		sessionState.SetRootKey(derivedKeys.RootKey)
+		sessionState.SetSenderBaseKey(parameters.TheirBaseKey.Serialize())
+	}
+
+	// Key exchanges don't use kyber prekeys, so the session can't use a
+	// newer version than 3.
+	version := protocol.PreKyberVersion
+	if theirMaxVersion < version {
+		version = theirMaxVersion
+	}
+	sessionState.SetVersion(version)
+	sessionState.SetRemoteIdentityKey(parameters.TheirIdentityKey)
+	sessionState.SetLocalIdentityKey(parameters.OurIdentityKeyPair.PublicKey())
+	sessionState.SetLocalRegistrationID(b.identityKeyStore.GetLocalRegistrationID())
+	sessionState.ClearPendingKeyExchange()
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"go.mau.fi/libsignal/cipher"
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/keys/chain"
 	"go.mau.fi/libsignal/keys/message"
@@ -14,14 +15,19 @@ import (
 	"go.mau.fi/libsignal/state/record"
 	"go.mau.fi/libsignal/state/store"
 	"go.mau.fi/libsignal/util/bytehelper"
+	"go.mau.fi/libsignal/util/keylock"
+	"go.mau.fi/libsignal/util/optional"
 )
 
-const maxFutureMessages = 2000
-
 // NewCipher constructs a session cipher for encrypt/decrypt operations on a
 // session. In order to use the session cipher, a session must have already
-// been created and stored using session.Builder.
-func NewCipher(builder *Builder, remoteAddress *protocol.SignalAddress) *Cipher {
+// been created and stored using session.Builder. If no config is given, the
+// config of the builder is used.
+func NewCipher(builder *Builder, remoteAddress *protocol.SignalAddress, cfg ...*config.Config) *Cipher {
+	cipherConfig := builder.config
+	if len(cfg) > 0 {
+		cipherConfig = config.Get(cfg...)
+	}
 	cipher := &Cipher{
 		sessionStore:            builder.sessionStore,
 		preKeyMessageSerializer: builder.serializer.PreKeySignalMessage,
@@ -30,6 +36,8 @@ func NewCipher(builder *Builder, remoteAddress *protocol.SignalAddress) *Cipher
 		remoteAddress:           remoteAddress,
 		builder:                 builder,
 		identityKeyStore:        builder.identityKeyStore,
+		locker:                  builder.locker,
+		config:                  cipherConfig,
 	}
 
 	return cipher
@@ -38,7 +46,7 @@ func NewCipher(builder *Builder, remoteAddress *protocol.SignalAddress) *Cipher
 func NewCipherFromSession(remoteAddress *protocol.SignalAddress,
 	sessionStore store.Session, preKeyStore store.PreKey, identityKeyStore store.IdentityKey,
 	preKeyMessageSerializer protocol.PreKeySignalMessageSerializer,
-	signalMessageSerializer protocol.SignalMessageSerializer) *Cipher {
+	signalMessageSerializer protocol.SignalMessageSerializer, cfg ...*config.Config) *Cipher {
 	cipher := &Cipher{
 		sessionStore:            sessionStore,
 		preKeyMessageSerializer: preKeyMessageSerializer,
@@ -46,6 +54,8 @@ func NewCipherFromSession(remoteAddress *protocol.SignalAddress,
 		preKeyStore:             preKeyStore,
 		remoteAddress:           remoteAddress,
 		identityKeyStore:        identityKeyStore,
+		locker:                  keylock.Default,
+		config:                  config.Get(cfg...),
 	}
 
 	return cipher
@@ -54,6 +64,15 @@ func NewCipherFromSession(remoteAddress *protocol.SignalAddress,
 // Cipher is the main entry point for Signal Protocol encrypt/decrypt operations.
 // Once a session has been established with session.Builder, this can be used for
 // all encrypt/decrypt operations within that session.
+//
+// Encrypt and decrypt operations hold a lock for the remote address, so
+// ciphers for the same address can be used from multiple goroutines. The
+// DecryptWith* methods don't lock, as they operate on a record the caller
+// has already loaded.
+//
+// If the session store implements store.Transactional, the store calls of
+// each encrypt and decrypt operation are run in a single transaction. The
+// limits of the cipher's config are applied to the session records it loads.
 type Cipher struct {
 	sessionStore            store.Session
 	preKeyMessageSerializer protocol.PreKeySignalMessageSerializer
@@ -62,11 +81,38 @@ type Cipher struct {
 	remoteAddress           *protocol.SignalAddress
 	builder                 *Builder
 	identityKeyStore        store.IdentityKey
+	locker                  keylock.Locker
+	config                  *config.Config
+}
+
+// SetLocker sets the locker used to serialize operations on the session.
+// By default, ciphers use the locker of their builder, and all builders and
+// ciphers share keylock.Default, which is only enough if a single process
+// uses the stores. The cipher's builder should use the same locker.
+func (d *Cipher) SetLocker(locker keylock.Locker) {
+	d.locker = locker
 }
 
 // Encrypt will take the given message in bytes and return an object that follows
 // the CiphertextMessage interface.
 func (d *Cipher) Encrypt(ctx context.Context, plaintext []byte) (protocol.CiphertextMessage, error) {
+	unlock, err := d.locker.Lock(ctx, d.remoteAddress.String())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var ciphertextMessage protocol.CiphertextMessage
+	err = store.WithTx(ctx, d.sessionStore, func(ctx context.Context) (err error) {
+		ciphertextMessage, err = d.encryptMessage(ctx, plaintext)
+		return err
+	})
+	return ciphertextMessage, err
+}
+
+// encryptMessage will encrypt the given message using the stored session. The caller
+// must hold the lock for the remote address.
+func (d *Cipher) encryptMessage(ctx context.Context, plaintext []byte) (protocol.CiphertextMessage, error) {
 	sessionRecord, err := d.sessionStore.LoadSession(ctx, d.remoteAddress)
 	if err != nil {
 		return nil, err
@@ -74,7 +120,11 @@ func (d *Cipher) Encrypt(ctx context.Context, plaintext []byte) (protocol.Cipher
 	if sessionRecord == nil {
 		return nil, fmt.Errorf("LoadSession returned nil")
 	}
+	sessionRecord.SetConfig(d.config)
 	sessionState := sessionRecord.SessionState()
+	if !sessionState.HasSenderChain() {
+		return nil, fmt.Errorf("%w %s", signalerror.ErrNoSessionForUser, d.remoteAddress.String())
+	}
 	chainKey := sessionState.SenderChainKey()
 	messageKeys := chainKey.MessageKeys()
 	senderEphemeral := sessionState.SenderRatchetKey()
@@ -112,11 +162,13 @@ func (d *Cipher) Encrypt(ctx context.Context, plaintext []byte) (protocol.Cipher
 		}
 		localRegistrationID := sessionState.LocalRegistrationID()
 
-		ciphertextMessage, err = protocol.NewPreKeySignalMessage(
+		ciphertextMessage, err = protocol.NewKyberPreKeySignalMessage(
 			sessionVersion,
 			localRegistrationID,
 			items.PreKeyID(),
 			items.SignedPreKeyID(),
+			items.KyberPreKeyID(),
+			items.KyberCiphertext(),
 			items.BaseKey(),
 			sessionState.LocalIdentityKey(),
 			ciphertextMessage.(*protocol.SignalMessage),
@@ -129,12 +181,12 @@ func (d *Cipher) Encrypt(ctx context.Context, plaintext []byte) (protocol.Cipher
 	}
 
 	sessionState.SetSenderChainKey(chainKey.NextKey())
-	trusted, err := d.identityKeyStore.IsTrustedIdentity(ctx, d.remoteAddress, sessionState.RemoteIdentityKey())
+	trusted, err := store.IsTrustedIdentity(ctx, d.identityKeyStore, d.remoteAddress, sessionState.RemoteIdentityKey(), store.DirectionSending)
 	if err != nil {
 		return nil, err
 	}
 	if !trusted {
-		return nil, signalerror.ErrUntrustedIdentity
+		return nil, newUntrustedIdentityError(ctx, d.identityKeyStore, d.remoteAddress, sessionState.RemoteIdentityKey(), store.DirectionSending, nil)
 	}
 	if err := d.identityKeyStore.SaveIdentity(ctx, d.remoteAddress, sessionState.RemoteIdentityKey()); err != nil {
 		return nil, err
@@ -156,43 +208,80 @@ func (d *Cipher) Decrypt(ctx context.Context, ciphertextMessage *protocol.Signal
 // DecryptAndGetKey decrypts the given message using an existing session that
 // is stored in the session store and returns the message keys used for encryption.
 func (d *Cipher) DecryptAndGetKey(ctx context.Context, ciphertextMessage *protocol.SignalMessage) ([]byte, *message.Keys, error) {
-	contains, err := d.sessionStore.ContainsSession(ctx, d.remoteAddress)
+	result, err := d.DecryptWithResult(ctx, ciphertextMessage)
 	if err != nil {
 		return nil, nil, err
 	}
+	return result.Plaintext, result.MessageKeys, nil
+}
+
+// DecryptWithResult decrypts the given message using an existing session that
+// is stored in the session store and returns the plaintext along with
+// information about the session.
+func (d *Cipher) DecryptWithResult(ctx context.Context, ciphertextMessage *protocol.SignalMessage) (*DecryptResult, error) {
+	unlock, err := d.locker.Lock(ctx, d.remoteAddress.String())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var result *DecryptResult
+	err = store.WithTx(ctx, d.sessionStore, func(ctx context.Context) (err error) {
+		result, err = d.decryptWithResult(ctx, ciphertextMessage)
+		return err
+	})
+	return result, err
+}
+
+// decryptWithResult will decrypt the given message using the stored session.
+// The caller must hold the lock for the remote address.
+func (d *Cipher) decryptWithResult(ctx context.Context, ciphertextMessage *protocol.SignalMessage) (*DecryptResult, error) {
+	contains, err := d.sessionStore.ContainsSession(ctx, d.remoteAddress)
+	if err != nil {
+		return nil, err
+	}
 	if !contains {
-		return nil, nil, fmt.Errorf("%w %s", signalerror.ErrNoSessionForUser, d.remoteAddress.String())
+		return nil, fmt.Errorf("%w %s", signalerror.ErrNoSessionForUser, d.remoteAddress.String())
 	}
 
 	// Load the session record from our session store and decrypt the message.
 	sessionRecord, err := d.sessionStore.LoadSession(ctx, d.remoteAddress)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if sessionRecord == nil {
-		return nil, nil, fmt.Errorf("LoadSession returned nil")
+		return nil, fmt.Errorf("LoadSession returned nil")
 	}
-	plaintext, messageKeys, err := d.DecryptWithRecord(ctx, sessionRecord, ciphertextMessage)
+	sessionRecord.SetConfig(d.config)
+
+	// Decrypt the message with a copy of the record, so that the message
+	// keys aren't used up if the identity key isn't trusted and the
+	// message has to be decrypted again after it is approved.
+	candidate := sessionRecord.Clone()
+	result, err := d.decryptWithRecord(ctx, candidate, ciphertextMessage)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	trusted, err := d.identityKeyStore.IsTrustedIdentity(ctx, d.remoteAddress, sessionRecord.SessionState().RemoteIdentityKey())
+	remoteIdentityKey := candidate.SessionState().RemoteIdentityKey()
+	trusted, err := store.IsTrustedIdentity(ctx, d.identityKeyStore, d.remoteAddress, remoteIdentityKey, store.DirectionReceiving)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if !trusted {
-		return nil, nil, signalerror.ErrUntrustedIdentity
+		return nil, newUntrustedIdentityError(ctx, d.identityKeyStore, d.remoteAddress, remoteIdentityKey,
+			store.DirectionReceiving, ciphertextMessage)
 	}
-	if err := d.identityKeyStore.SaveIdentity(ctx, d.remoteAddress, sessionRecord.SessionState().RemoteIdentityKey()); err != nil {
-		return nil, nil, err
+	*sessionRecord = *candidate
+	if err := d.identityKeyStore.SaveIdentity(ctx, d.remoteAddress, remoteIdentityKey); err != nil {
+		return nil, err
 	}
 
 	// Store the session record in our session store.
 	if err := d.sessionStore.StoreSession(ctx, d.remoteAddress, sessionRecord); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return plaintext, messageKeys, nil
+	return result, nil
 }
 
 func (d *Cipher) DecryptMessage(ctx context.Context, ciphertextMessage *protocol.PreKeySignalMessage) ([]byte, error) {
@@ -201,32 +290,93 @@ func (d *Cipher) DecryptMessage(ctx context.Context, ciphertextMessage *protocol
 }
 
 func (d *Cipher) DecryptMessageReturnKey(ctx context.Context, ciphertextMessage *protocol.PreKeySignalMessage) ([]byte, *message.Keys, error) {
+	result, err := d.DecryptMessageWithResult(ctx, ciphertextMessage)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Plaintext, result.MessageKeys, nil
+}
+
+// DecryptMessageWithResult builds a session from the given prekey message if
+// one doesn't exist yet, decrypts the message and returns the plaintext along
+// with information about the session, such as whether it is new and which of
+// our prekeys were used.
+func (d *Cipher) DecryptMessageWithResult(ctx context.Context, ciphertextMessage *protocol.PreKeySignalMessage) (*DecryptResult, error) {
+	unlock, err := d.locker.Lock(ctx, d.remoteAddress.String())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var result *DecryptResult
+	err = store.WithTx(ctx, d.sessionStore, func(ctx context.Context) (err error) {
+		result, err = d.decryptMessageWithResult(ctx, ciphertextMessage)
+		return err
+	})
+	return result, err
+}
+
+// decryptMessageWithResult will build a session from the given prekey message
+// and decrypt it. The caller must hold the lock for the remote address.
+func (d *Cipher) decryptMessageWithResult(ctx context.Context, ciphertextMessage *protocol.PreKeySignalMessage) (*DecryptResult, error) {
 	// Load or create session record for this session.
 	sessionRecord, err := d.sessionStore.LoadSession(ctx, d.remoteAddress)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	if sessionRecord == nil {
-		return nil, nil, fmt.Errorf("LoadSession returned nil")
+		return nil, fmt.Errorf("LoadSession returned nil")
+	}
+	sessionRecord.SetConfig(d.config)
+	if err := d.builder.checkPreKeyMessageIdentity(ctx, ciphertextMessage); err != nil {
+		return nil, err
 	}
-	unsignedPreKeyID, err := d.builder.Process(ctx, sessionRecord, ciphertextMessage)
+
+	// Build the session and decrypt the message with a copy of the record,
+	// so that nothing is changed or saved unless the message is authentic.
+	candidate := sessionRecord.Clone()
+	processed, err := d.builder.processPreKeyMessage(ctx, candidate, ciphertextMessage)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	plaintext, keys, err := d.DecryptWithRecord(ctx, sessionRecord, ciphertextMessage.WhisperMessage())
+	result, err := d.decryptWithRecord(ctx, candidate, ciphertextMessage.WhisperMessage())
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	*sessionRecord = *candidate
+
+	// Save the identity key and store the session record.
+	if err := d.identityKeyStore.SaveIdentity(ctx, d.remoteAddress, ciphertextMessage.IdentityKey()); err != nil {
+		return nil, err
 	}
-	// Store the session record in our session store.
 	if err := d.sessionStore.StoreSession(ctx, d.remoteAddress, sessionRecord); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	if !unsignedPreKeyID.IsEmpty {
-		if err := d.preKeyStore.RemovePreKey(ctx, unsignedPreKeyID.Value); err != nil {
-			return nil, nil, err
+	if !processed.preKeyID.IsEmpty {
+		if err := d.preKeyStore.RemovePreKey(ctx, processed.preKeyID.Value); err != nil {
+			return nil, err
 		}
 	}
-	return plaintext, keys, nil
+	if !processed.kyberPreKeyID.IsEmpty {
+		if err := d.builder.kyberPreKeyStore.MarkKyberPreKeyUsed(ctx, processed.kyberPreKeyID.Value); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.builder.recordLastResortUse(ctx, processed); err != nil {
+		return nil, err
+	}
+
+	// Report the prekeys that were used if a new session was established.
+	if processed.newSession {
+		result.NewSession = true
+		result.UsedLastResortPreKey = processed.lastResortPreKey || processed.lastResortKyberPreKey
+		if ciphertextMessage.PreKeyID() != nil {
+			result.PreKeyID = ciphertextMessage.PreKeyID()
+		}
+		result.SignedPreKeyID = optional.NewOptionalUint32(ciphertextMessage.SignedPreKeyID())
+		result.KyberPreKeyID = ciphertextMessage.KyberPreKeyID()
+	}
+	return result, nil
 }
 
 // DecryptWithKey will decrypt the given message using the given symmetric key. This
@@ -243,43 +393,79 @@ func (d *Cipher) DecryptWithKey(ctx context.Context, ciphertextMessage *protocol
 }
 
 // DecryptWithRecord decrypts the given message using the given session record.
+// The message is decrypted with copies of the session states, so the record is
+// only changed if decryption succeeds.
 func (d *Cipher) DecryptWithRecord(ctx context.Context, sessionRecord *record.Session, ciphertext *protocol.SignalMessage) ([]byte, *message.Keys, error) {
+	result, err := d.decryptWithRecord(ctx, sessionRecord, ciphertext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Plaintext, result.MessageKeys, nil
+}
+
+// decryptWithRecord decrypts the given message using the given session record
+// and returns the result without any prekey information.
+func (d *Cipher) decryptWithRecord(ctx context.Context, sessionRecord *record.Session, ciphertext *protocol.SignalMessage) (*DecryptResult, error) {
 	logger.Debug("Decrypting ciphertext with record: ", sessionRecord)
 	previousStates := sessionRecord.PreviousSessionStates()
-	sessionState := sessionRecord.SessionState()
+	sessionState := sessionRecord.SessionState().Clone()
 
 	// Try and decrypt the message with the current session state.
-	plaintext, messageKeys, err := d.DecryptWithState(ctx, sessionState, ciphertext)
+	plaintext, messageKeys, err := d.decryptWithState(ctx, sessionState, ciphertext)
 
 	// If we received an error using the current session state, loop
 	// through all previous states.
 	if err != nil {
 		logger.Warning(err)
-		for i, state := range previousStates {
+		for i := range previousStates {
 			// Try decrypting the message with previous states
-			plaintext, messageKeys, err = d.DecryptWithState(ctx, state, ciphertext)
+			state := previousStates[i].Clone()
+			plaintext, messageKeys, err = d.decryptWithState(ctx, state, ciphertext)
 			if err != nil {
 				continue
 			}
 
 			// If successful, remove and promote the state.
-			previousStates = append(previousStates[:i], previousStates[i+1:]...)
-			sessionRecord.PromoteState(state)
+			sessionRecord.PromotePreviousState(i, state)
 
-			return plaintext, messageKeys, nil
+			result := newDecryptResult(ciphertext, plaintext, messageKeys)
+			result.SenderIdentityKey = state.RemoteIdentityKey()
+			result.SessionVersion = state.Version()
+			result.PromotedArchivedState = true
+			return result, nil
 		}
 
-		return nil, nil, signalerror.ErrNoValidSessions
+		return nil, signalerror.ErrNoValidSessions
 	}
 
 	// If decryption was successful, set the session state and return the plain text.
 	sessionRecord.SetState(sessionState)
 
-	return plaintext, messageKeys, nil
+	result := newDecryptResult(ciphertext, plaintext, messageKeys)
+	result.SenderIdentityKey = sessionState.RemoteIdentityKey()
+	result.SessionVersion = sessionState.Version()
+	return result, nil
 }
 
 // DecryptWithState decrypts the given message with the given session state.
+// The session state is only changed if decryption succeeds.
 func (d *Cipher) DecryptWithState(ctx context.Context, sessionState *record.State, ciphertextMessage *protocol.SignalMessage) ([]byte, *message.Keys, error) {
+	trialState := sessionState.Clone()
+	plaintext, messageKeys, err := d.decryptWithState(ctx, trialState, ciphertextMessage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Commit the changes to the given session state.
+	*sessionState = *trialState
+
+	return plaintext, messageKeys, nil
+}
+
+// decryptWithState decrypts the given message with the given session state.
+// The session state may be changed even if decryption fails, so callers must
+// pass a copy of the state they want to keep.
+func (d *Cipher) decryptWithState(ctx context.Context, sessionState *record.State, ciphertextMessage *protocol.SignalMessage) ([]byte, *message.Keys, error) {
 	logger.Debug("Decrypting ciphertext with session state: ", sessionState)
 	if !sessionState.HasSenderChain() {
 		logger.Error("Unable to decrypt message with state: ", signalerror.ErrUninitializedSession)
@@ -300,7 +486,7 @@ func (d *Cipher) DecryptWithState(ctx context.Context, sessionState *record.Stat
 		return nil, nil, fmt.Errorf("failed to get or create chain key: %w", chainCreateErr)
 	}
 
-	messageKeys, keysCreateErr := getOrCreateMessageKeys(sessionState, theirEphemeral, chainKey, counter)
+	messageKeys, keysCreateErr := getOrCreateMessageKeys(sessionState, theirEphemeral, chainKey, counter, d.config.MaxFutureMessages)
 	if keysCreateErr != nil {
 		logger.Error("Unable to get or create message keys: ", keysCreateErr)
 		return nil, nil, fmt.Errorf("failed to get or create message keys: %w", keysCreateErr)
@@ -323,7 +509,7 @@ func (d *Cipher) DecryptWithState(ctx context.Context, sessionState *record.Stat
 }
 
 func getOrCreateMessageKeys(sessionState *record.State, theirEphemeral ecc.ECPublicKeyable,
-	chainKey *chain.Key, counter uint32) (*message.Keys, error) {
+	chainKey *chain.Key, counter, maxFutureMessages uint32) (*message.Keys, error) {
 
 	if chainKey.Index() > counter {
 		if sessionState.HasMessageKeys(theirEphemeral, counter) {
@@ -333,7 +519,7 @@ func getOrCreateMessageKeys(sessionState *record.State, theirEphemeral ecc.ECPub
 	}
 
 	if counter-chainKey.Index() > maxFutureMessages {
-		return nil, signalerror.ErrTooFarIntoFuture
+		return nil, fmt.Errorf("%w (index: %d, count: %d, max: %d)", signalerror.ErrTooFarIntoFuture, chainKey.Index(), counter, maxFutureMessages)
 	}
 
 	for chainKey.Index() < counter {
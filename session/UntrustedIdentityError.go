@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// UntrustedIdentityError is returned when the identity key of a remote
+// client is not trusted by the identity store. It wraps
+// signalerror.ErrUntrustedIdentity.
+//
+// If the error was returned when decrypting a message, the message is
+// included so that it can be decrypted again with Cipher.RetryDecrypt after
+// the new identity key has been approved.
+type UntrustedIdentityError struct {
+	// Address is the address of the remote client.
+	Address *protocol.SignalAddress
+
+	// StoredIdentity is the identity key that is saved for the address. It
+	// is nil if no key is saved or the identity store doesn't implement
+	// store.IdentityKeyLoader.
+	StoredIdentity *identity.Key
+
+	// NewIdentity is the identity key that was not trusted.
+	NewIdentity *identity.Key
+
+	// Direction is whether the key was checked for sending or receiving.
+	Direction store.Direction
+
+	// Message is the received message that could not be decrypted. It is
+	// nil for errors that didn't happen while decrypting a message.
+	Message protocol.CiphertextMessage
+}
+
+// newUntrustedIdentityError will return a new untrusted identity error for
+// the given address and identity key. The saved identity key is loaded from
+// the identity store if it implements store.IdentityKeyLoader.
+func newUntrustedIdentityError(ctx context.Context, identityStore store.IdentityKey, address *protocol.SignalAddress,
+	newIdentity *identity.Key, direction store.Direction, message protocol.CiphertextMessage) error {
+
+	identityErr := &UntrustedIdentityError{
+		Address:     address,
+		NewIdentity: newIdentity,
+		Direction:   direction,
+		Message:     message,
+	}
+	if loader, ok := identityStore.(store.IdentityKeyLoader); ok {
+		storedIdentity, err := loader.LoadIdentity(ctx, address)
+		if err != nil {
+			return err
+		}
+		identityErr.StoredIdentity = storedIdentity
+	}
+
+	return identityErr
+}
+
+// Error returns a description of the error.
+func (e *UntrustedIdentityError) Error() string {
+	return fmt.Sprintf("%s for %s when %s", signalerror.ErrUntrustedIdentity, e.Address.String(), e.Direction.String())
+}
+
+// Unwrap returns signalerror.ErrUntrustedIdentity.
+func (e *UntrustedIdentityError) Unwrap() error {
+	return signalerror.ErrUntrustedIdentity
+}
+
+// ApproveIdentity will save the new identity key from the given error to the
+// identity store, so that it is trusted for future messages.
+func (d *Cipher) ApproveIdentity(ctx context.Context, identityErr *UntrustedIdentityError) error {
+	return d.identityKeyStore.SaveIdentity(ctx, identityErr.Address, identityErr.NewIdentity)
+}
+
+// RetryDecrypt will decrypt the message from the given untrusted identity
+// error again. It should be called after the new identity key has been
+// approved, for example with ApproveIdentity.
+func (d *Cipher) RetryDecrypt(ctx context.Context, identityErr *UntrustedIdentityError) (*DecryptResult, error) {
+	if *identityErr.Address != *d.remoteAddress {
+		return nil, fmt.Errorf("can't retry message from %s with cipher for %s", identityErr.Address.String(), d.remoteAddress.String())
+	}
+
+	switch message := identityErr.Message.(type) {
+	case *protocol.PreKeySignalMessage:
+		return d.DecryptMessageWithResult(ctx, message)
+	case *protocol.SignalMessage:
+		return d.DecryptWithResult(ctx, message)
+	default:
+		return nil, fmt.Errorf("%w: error doesn't contain a received message", signalerror.ErrUnknownMessageType)
+	}
+}
@@ -12,13 +12,23 @@ var (
 	ErrNoSignedPreKey    = errors.New("no signed prekey found in bundle")
 	ErrInvalidSignature  = errors.New("invalid signature on device key")
 	ErrNoOneTimeKeyFound = errors.New("prekey store didn't return one-time key")
+	ErrNoKyberPreKey     = errors.New("kyber prekey store didn't return kyber prekey")
+	ErrStaleKeyExchange  = errors.New("received response for unknown key exchange")
 )
 
+var (
+	ErrIncompleteBundle   = errors.New("prekey bundle is missing a key")
+	ErrInvalidBundleKey   = errors.New("invalid key in prekey bundle")
+	ErrInvalidBundleKeyID = errors.New("prekey bundle key ID is out of range")
+)
+
+var ErrNoKeysForAddress = errors.New("no keys uploaded for address")
+
 var (
 	ErrNoValidSessions      = errors.New("no valid sessions")
 	ErrUninitializedSession = errors.New("uninitialized session")
 	ErrWrongMessageVersion  = errors.New("wrong message version")
-	ErrTooFarIntoFuture     = errors.New("message index is over 2000 messages into the future")
+	ErrTooFarIntoFuture     = errors.New("message index is too far into the future")
 	ErrOldCounter           = errors.New("received message with old counter")
 	ErrNoSessionForUser     = errors.New("no session found for user")
 )
@@ -32,6 +42,31 @@ var (
 	ErrOldMessageVersion     = errors.New("too old message version")
 	ErrUnknownMessageVersion = errors.New("unknown message version")
 	ErrIncompleteMessage     = errors.New("incomplete message")
+	ErrUnknownMessageType    = errors.New("unknown message type")
+)
+
+var (
+	ErrInvalidCertificate     = errors.New("invalid signature on certificate")
+	ErrExpiredCertificate     = errors.New("sender certificate is expired")
+	ErrCertificateKeyMismatch = errors.New("sender certificate key does not match message key")
+	ErrSelfSend               = errors.New("received sealed sender message from ourselves")
+	ErrNoCertificateValidator = errors.New("no certificate validator for sealed sender message")
 )
 
 var ErrBadMAC = errors.New("mismatching MAC in signal message")
+
+var (
+	ErrUnknownRecordKey       = errors.New("unknown record encryption key")
+	ErrInvalidRecordEnvelope  = errors.New("invalid encrypted record")
+	ErrRecordDecryptionFailed = errors.New("failed to decrypt stored record")
+)
+
+var (
+	ErrInvalidBackup             = errors.New("invalid backup archive")
+	ErrUnknownBackupVersion      = errors.New("unknown backup archive version")
+	ErrBackupDecryptionFailed    = errors.New("wrong passphrase or corrupted backup archive")
+	ErrBackupMissingKyberSupport = errors.New("backup contains kyber prekeys but the store doesn't support them")
+	ErrBackupIdentityMismatch    = errors.New("backup belongs to a different local identity than the store")
+)
+
+var ErrInvalidIdentityKeyPair = errors.New("invalid identity key pair")
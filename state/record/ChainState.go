@@ -132,6 +132,10 @@ func (c *Chain) PopFirstMessageKeys() *message.Keys {
 
 // structure returns a serializeable structure of the chain state.
 func (c *Chain) structure() *ChainStructure {
+	if c == nil {
+		return nil
+	}
+
 	// Alias to ArrayToSlice
 	getSlice := bytehelper.ArrayToSlice
 
@@ -155,3 +159,16 @@ func (c *Chain) structure() *ChainStructure {
 		MessageKeys:             messageKeys,
 	}
 }
+
+// Clone will return a copy of the chain state. The keys in the chain
+// are never modified in place, so only the list of message keys is copied.
+func (c *Chain) Clone() *Chain {
+	if c == nil {
+		return nil
+	}
+	return NewChain(
+		c.senderRatchetKeyPair,
+		c.chainKey,
+		append([]*message.Keys{}, c.messageKeys...),
+	)
+}
@@ -0,0 +1,122 @@
+package record
+
+import (
+	"go.mau.fi/libsignal/kem"
+	"go.mau.fi/libsignal/util/bytehelper"
+)
+
+// KyberPreKeySerializer is an interface for serializing and deserializing
+// KyberPreKey objects into bytes. An implementation of this interface should be
+// used to encode/decode the object into JSON, Protobuffers, etc.
+type KyberPreKeySerializer interface {
+	Serialize(kyberPreKey *KyberPreKeyStructure) []byte
+	Deserialize(serialized []byte) (*KyberPreKeyStructure, error)
+}
+
+// NewKyberPreKeyFromBytes will return a kyber prekey record from the given
+// bytes using the given serializer.
+func NewKyberPreKeyFromBytes(serialized []byte, serializer KyberPreKeySerializer) (*KyberPreKey, error) {
+	// Use the given serializer to decode the kyber prekey.
+	kyberPreKeyStructure, err := serializer.Deserialize(serialized)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKyberPreKeyFromStruct(kyberPreKeyStructure, serializer)
+}
+
+// NewKyberPreKeyFromStruct returns a KyberPreKey record using the given
+// serializable structure.
+func NewKyberPreKeyFromStruct(structure *KyberPreKeyStructure,
+	serializer KyberPreKeySerializer) (*KyberPreKey, error) {
+
+	// Create the kyber prekey record from the structure.
+	kyberPreKey := &KyberPreKey{
+		structure:  *structure,
+		serializer: serializer,
+		signature:  bytehelper.SliceToArray64(structure.Signature),
+	}
+
+	// Generate the KEM keys from bytes.
+	publicKey, err := kem.DecodePublicKey(structure.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := kem.DecodePrivateKey(structure.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	kyberPreKey.keyPair = kem.NewKeyPair(publicKey, privateKey)
+
+	return kyberPreKey, nil
+}
+
+// NewKyberPreKey record creates a new kyber pre key record
+// with the given properties.
+func NewKyberPreKey(id uint32, timestamp int64, keyPair *kem.KeyPair,
+	sig [64]byte, serializer KyberPreKeySerializer) *KyberPreKey {
+
+	return &KyberPreKey{
+		structure: KyberPreKeyStructure{
+			ID:         id,
+			Timestamp:  timestamp,
+			PublicKey:  keyPair.PublicKey().Serialize(),
+			PrivateKey: keyPair.PrivateKey().Serialize(),
+			Signature:  bytehelper.ArrayToSlice64(sig),
+		},
+		keyPair:    keyPair,
+		signature:  sig,
+		serializer: serializer,
+	}
+}
+
+// KyberPreKeyStructure is a flat structure of a kyber pre key, used
+// for serialization and deserialization.
+type KyberPreKeyStructure struct {
+	ID         uint32
+	PublicKey  []byte
+	PrivateKey []byte
+	Signature  []byte
+	Timestamp  int64
+}
+
+// KyberPreKey record is a structure for storing a post-quantum
+// KEM pre key signed by the identity key in a KyberPreKey store.
+type KyberPreKey struct {
+	structure  KyberPreKeyStructure
+	keyPair    *kem.KeyPair
+	signature  [64]byte
+	serializer KyberPreKeySerializer
+}
+
+// ID returns the record's id.
+func (k *KyberPreKey) ID() uint32 {
+	return k.structure.ID
+}
+
+// IsLastResort returns true if this is the last resort kyber prekey.
+func (k *KyberPreKey) IsLastResort() bool {
+	return k.structure.ID == LastResortPreKeyID
+}
+
+// Timestamp returns the record's timestamp
+func (k *KyberPreKey) Timestamp() int64 {
+	return k.structure.Timestamp
+}
+
+// KeyPair returns the kyber pre key record's key pair.
+func (k *KyberPreKey) KeyPair() *kem.KeyPair {
+	return k.keyPair
+}
+
+// Signature returns the record's kyber prekey signature.
+func (k *KyberPreKey) Signature() [64]byte {
+	return k.signature
+}
+
+// Serialize uses the KyberPreKey serializer to return the KyberPreKey
+// as serialized bytes.
+func (k *KyberPreKey) Serialize() []byte {
+	structure := k.structure
+	return k.serializer.Serialize(&structure)
+}
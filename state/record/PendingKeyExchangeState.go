@@ -89,3 +89,12 @@ func (p *PendingKeyExchange) structure() *PendingKeyExchangeStructure {
 		LocalIdentityKeyPrivate: getSlice(p.localIdentityKeyPair.PrivateKey().Serialize()),
 	}
 }
+
+// clone will return a copy of the pending key exchange.
+func (p *PendingKeyExchange) clone() *PendingKeyExchange {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	return &clone
+}
@@ -1,6 +1,8 @@
 package record
 
 import (
+	"bytes"
+
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/util/optional"
 )
@@ -29,6 +31,8 @@ func NewPendingPreKeyFromStruct(preKey *PendingPreKeyStructure) (*PendingPreKey,
 		preKey.SignedPreKeyID,
 		baseKey,
 	)
+	pendingPreKey.kyberPreKeyID = preKey.KyberPreKeyID
+	pendingPreKey.kyberCiphertext = preKey.KyberCiphertext
 
 	return pendingPreKey, nil
 }
@@ -36,27 +40,43 @@ func NewPendingPreKeyFromStruct(preKey *PendingPreKeyStructure) (*PendingPreKey,
 // PendingPreKeyStructure is a serializeable structure for pending
 // prekeys.
 type PendingPreKeyStructure struct {
-	PreKeyID       *optional.Uint32
-	SignedPreKeyID uint32
-	BaseKey        []byte
+	PreKeyID        *optional.Uint32
+	SignedPreKeyID  uint32
+	BaseKey         []byte
+	KyberPreKeyID   *optional.Uint32
+	KyberCiphertext []byte
 }
 
 // PendingPreKey is a structure for pending pre keys
 // for a session state.
 type PendingPreKey struct {
-	preKeyID       *optional.Uint32
-	signedPreKeyID uint32
-	baseKey        ecc.ECPublicKeyable
+	preKeyID        *optional.Uint32
+	signedPreKeyID  uint32
+	baseKey         ecc.ECPublicKeyable
+	kyberPreKeyID   *optional.Uint32
+	kyberCiphertext []byte
 }
 
 // structure will return a serializeable structure of the pending prekey.
 func (p *PendingPreKey) structure() *PendingPreKeyStructure {
 	if p != nil {
 		return &PendingPreKeyStructure{
-			PreKeyID:       p.preKeyID,
-			SignedPreKeyID: p.signedPreKeyID,
-			BaseKey:        p.baseKey.Serialize(),
+			PreKeyID:        p.preKeyID,
+			SignedPreKeyID:  p.signedPreKeyID,
+			BaseKey:         p.baseKey.Serialize(),
+			KyberPreKeyID:   p.kyberPreKeyID,
+			KyberCiphertext: p.kyberCiphertext,
 		}
 	}
 	return nil
 }
+
+// clone will return a copy of the pending prekey.
+func (p *PendingPreKey) clone() *PendingPreKey {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.kyberCiphertext = bytes.Clone(p.kyberCiphertext)
+	return &clone
+}
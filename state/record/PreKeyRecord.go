@@ -3,9 +3,26 @@ package record
 import (
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/util/bytehelper"
+	"go.mau.fi/libsignal/util/medium"
 	"go.mau.fi/libsignal/util/optional"
 )
 
+// LastResortPreKeyID is the ID of the last resort PreKey and kyber PreKey.
+// Last resort keys are used when the server has run out of one-time keys,
+// so they are never removed after a session is built with them.
+const LastResortPreKeyID = medium.MaxValue
+
+// LegacyLastResortPreKeyID is the ID that last resort PreKeys were generated
+// with before LastResortPreKeyID. PreKeys with this ID are still treated as
+// last resort keys, so that existing installs keep their last resort key.
+const LegacyLastResortPreKeyID = 0
+
+// IsLastResortPreKeyID returns true if the given PreKey ID belongs to a
+// last resort PreKey, including ones generated with the legacy ID.
+func IsLastResortPreKeyID(preKeyID uint32) bool {
+	return preKeyID == LastResortPreKeyID || preKeyID == LegacyLastResortPreKeyID
+}
+
 // PreKeySerializer is an interface for serializing and deserializing
 // PreKey objects into bytes. An implementation of this interface should be
 // used to encode/decode the object into JSON, Protobuffers, etc.
@@ -34,7 +51,10 @@ func NewPreKeyFromStruct(structure *PreKeyStructure, serializer PreKeySerializer
 	}
 
 	// Generate the ECC key from bytes.
-	publicKey := ecc.NewDjbECPublicKey(bytehelper.SliceToArray(structure.PublicKey))
+	publicKey, err := ecc.DecodePoint(structure.PublicKey, 0)
+	if err != nil {
+		return nil, err
+	}
 	privateKey := ecc.NewDjbECPrivateKey(bytehelper.SliceToArray(structure.PrivateKey))
 	keyPair := ecc.NewECKeyPair(publicKey, privateKey)
 	preKey.keyPair = keyPair
@@ -77,6 +97,11 @@ func (p *PreKey) ID() *optional.Uint32 {
 	return optional.NewOptionalUint32(p.structure.ID)
 }
 
+// IsLastResort returns true if this is the last resort pre key.
+func (p *PreKey) IsLastResort() bool {
+	return IsLastResortPreKeyID(p.structure.ID)
+}
+
 // KeyPair returns the pre key record's key pair.
 func (p *PreKey) KeyPair() *ecc.ECKeyPair {
 	return p.keyPair
@@ -2,11 +2,9 @@ package record
 
 import (
 	"bytes"
-)
 
-// archivedStatesMaxLength describes how many previous session
-// states we should keep track of.
-const archivedStatesMaxLength int = 40
+	"go.mau.fi/libsignal/config"
+)
 
 // SessionSerializer is an interface for serializing and deserializing
 // a Signal Session into bytes. An implementation of this interface should be
@@ -17,47 +15,54 @@ type SessionSerializer interface {
 }
 
 // NewSessionFromBytes will return a Signal Session from the given
-// bytes using the given serializer.
-func NewSessionFromBytes(serialized []byte, serializer SessionSerializer, stateSerializer StateSerializer) (*Session, error) {
+// bytes using the given serializer and optional config.
+func NewSessionFromBytes(serialized []byte, serializer SessionSerializer, stateSerializer StateSerializer,
+	cfg ...*config.Config) (*Session, error) {
+
 	// Use the given serializer to decode the session.
 	sessionStructure, err := serializer.Deserialize(serialized)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewSessionFromStructure(sessionStructure, serializer, stateSerializer)
+	return NewSessionFromStructure(sessionStructure, serializer, stateSerializer, cfg...)
 }
 
 // NewSession creates a new session record and uses the given session and state
-// serializers to convert the object into storeable bytes.
-func NewSession(serializer SessionSerializer, stateSerializer StateSerializer) *Session {
+// serializers to convert the object into storeable bytes. If no config is given,
+// the default limits are used.
+func NewSession(serializer SessionSerializer, stateSerializer StateSerializer, cfg ...*config.Config) *Session {
+	sessionConfig := config.Get(cfg...)
 	record := Session{
-		sessionState:   NewState(stateSerializer),
+		sessionState:   NewState(stateSerializer, sessionConfig),
 		previousStates: []*State{},
 		fresh:          true,
 		serializer:     serializer,
+		config:         sessionConfig,
 	}
 
 	return &record
 }
 
 // NewSessionFromStructure will return a new Signal Session from the given
-// session structure and serializer.
+// session structure, serializer and optional config.
 func NewSessionFromStructure(structure *SessionStructure, serializer SessionSerializer,
-	stateSerializer StateSerializer) (*Session, error) {
+	stateSerializer StateSerializer, cfg ...*config.Config) (*Session, error) {
+
+	sessionConfig := config.Get(cfg...)
 
 	// Build our previous states from structure.
 	previousStates := make([]*State, len(structure.PreviousStates))
 	for i := range structure.PreviousStates {
 		var err error
-		previousStates[i], err = NewStateFromStructure(structure.PreviousStates[i], stateSerializer)
+		previousStates[i], err = NewStateFromStructure(structure.PreviousStates[i], stateSerializer, sessionConfig)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// Build our current state from structure.
-	sessionState, err := NewStateFromStructure(structure.SessionState, stateSerializer)
+	sessionState, err := NewStateFromStructure(structure.SessionState, stateSerializer, sessionConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -68,19 +73,21 @@ func NewSessionFromStructure(structure *SessionStructure, serializer SessionSeri
 		sessionState:   sessionState,
 		serializer:     serializer,
 		fresh:          false,
+		config:         sessionConfig,
 	}
 
 	return session, nil
 }
 
 // NewSessionFromState creates a new session record from the given
-// session state.
-func NewSessionFromState(sessionState *State, serializer SessionSerializer) *Session {
+// session state and optional config.
+func NewSessionFromState(sessionState *State, serializer SessionSerializer, cfg ...*config.Config) *Session {
 	record := Session{
 		sessionState:   sessionState,
 		previousStates: []*State{},
 		fresh:          false,
 		serializer:     serializer,
+		config:         config.Get(cfg...),
 	}
 
 	return &record
@@ -100,6 +107,17 @@ type Session struct {
 	sessionState   *State
 	previousStates []*State
 	fresh          bool
+	config         *config.Config
+}
+
+// SetConfig sets the limits that are used for this session record and
+// all of its session states.
+func (r *Session) SetConfig(cfg *config.Config) {
+	r.config = config.Get(cfg)
+	r.sessionState.config = r.config
+	for _, state := range r.previousStates {
+		state.config = r.config
+	}
 }
 
 // SetState sets the session record's current state to the given
@@ -149,7 +167,7 @@ func (r *Session) HasSessionState(version int, senderBaseKey []byte) bool {
 // of "previous" session states, and replaces the current session state
 // with a fresh reset instance.
 func (r *Session) ArchiveCurrentState() {
-	r.PromoteState(NewState(r.sessionState.serializer))
+	r.PromoteState(NewState(r.sessionState.serializer, r.config))
 }
 
 // PromoteState takes the given session state and replaces it with the
@@ -159,11 +177,35 @@ func (r *Session) PromoteState(promotedState *State) {
 	r.sessionState = promotedState
 
 	// Remove the last state if it has reached our maximum length
-	if len(r.previousStates) > archivedStatesMaxLength {
+	if len(r.previousStates) > r.config.MaxArchivedStates {
 		r.previousStates = r.removeLastState(r.previousStates)
 	}
 }
 
+// PromotePreviousState removes the previous session state at the given
+// index and replaces the current state with the given one, pushing the
+// current state to "previousStates".
+func (r *Session) PromotePreviousState(index int, promotedState *State) {
+	r.previousStates = append(r.previousStates[:index:index], r.previousStates[index+1:]...)
+	r.PromoteState(promotedState)
+}
+
+// Clone will return a deep copy of the session record, including the
+// current and previous session states.
+func (r *Session) Clone() *Session {
+	previousStates := make([]*State, len(r.previousStates))
+	for i := range r.previousStates {
+		previousStates[i] = r.previousStates[i].Clone()
+	}
+	return &Session{
+		serializer:     r.serializer,
+		sessionState:   r.sessionState.Clone(),
+		previousStates: previousStates,
+		fresh:          r.fresh,
+		config:         r.config,
+	}
+}
+
 // Serialize will return the session as serialized bytes so it can be
 // persistently stored.
 func (r *Session) Serialize() []byte {
@@ -1,6 +1,7 @@
 package record
 
 import (
+	"go.mau.fi/libsignal/config"
 	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/kdf"
 	"go.mau.fi/libsignal/keys/chain"
@@ -13,9 +14,6 @@ import (
 	"go.mau.fi/libsignal/util/optional"
 )
 
-const maxMessageKeys int = 2000
-const maxReceiverChains int = 5
-
 // StateSerializer is an interface for serializing and deserializing
 // a Signal State into bytes. An implementation of this interface should be
 // used to encode/decode the object into JSON, Protobuffers, etc.
@@ -25,42 +23,63 @@ type StateSerializer interface {
 }
 
 // NewStateFromBytes will return a Signal State from the given
-// bytes using the given serializer.
-func NewStateFromBytes(serialized []byte, serializer StateSerializer) (*State, error) {
+// bytes using the given serializer and optional config.
+func NewStateFromBytes(serialized []byte, serializer StateSerializer, cfg ...*config.Config) (*State, error) {
 	// Use the given serializer to decode the signal message.
 	stateStructure, err := serializer.Deserialize(serialized)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewStateFromStructure(stateStructure, serializer)
+	return NewStateFromStructure(stateStructure, serializer, cfg...)
 }
 
-// NewState returns a new session state.
-func NewState(serializer StateSerializer) *State {
-	return &State{serializer: serializer}
+// NewState returns a new session state with the given optional config.
+func NewState(serializer StateSerializer, cfg ...*config.Config) *State {
+	return &State{serializer: serializer, config: config.Get(cfg...)}
 }
 
 // NewStateFromStructure will return a new session state with the
-// given state structure.
-func NewStateFromStructure(structure *StateStructure, serializer StateSerializer) (*State, error) {
+// given state structure and optional config.
+func NewStateFromStructure(structure *StateStructure, serializer StateSerializer, cfg ...*config.Config) (*State, error) {
 	// Keep a list of errors, so they can be handled once.
 	errors := errorhelper.NewMultiError()
 
-	// Convert our ecc keys from bytes into object form.
-	localIdentityPublic, err := ecc.DecodePoint(structure.LocalIdentityPublic, 0)
-	errors.Add(err)
-	remoteIdentityPublic, err := ecc.DecodePoint(structure.RemoteIdentityPublic, 0)
-	errors.Add(err)
-	senderBaseKey, err := ecc.DecodePoint(structure.SenderBaseKey, 0)
-	errors.Add(err)
+	// Convert our ecc keys from bytes into object form. A state that was never
+	// initialized (e.g. one only holding a pending key exchange) has no keys.
+	var localIdentityPublic, remoteIdentityPublic *identity.Key
+	var senderBaseKey ecc.ECPublicKeyable
+	var err error
+	if len(structure.LocalIdentityPublic) > 0 {
+		var key ecc.ECPublicKeyable
+		key, err = ecc.DecodePoint(structure.LocalIdentityPublic, 0)
+		errors.Add(err)
+		localIdentityPublic = identity.NewKey(key)
+	}
+	if len(structure.RemoteIdentityPublic) > 0 {
+		var key ecc.ECPublicKeyable
+		key, err = ecc.DecodePoint(structure.RemoteIdentityPublic, 0)
+		errors.Add(err)
+		remoteIdentityPublic = identity.NewKey(key)
+	}
+	if len(structure.SenderBaseKey) > 0 {
+		senderBaseKey, err = ecc.DecodePoint(structure.SenderBaseKey, 0)
+		errors.Add(err)
+	}
+	var rootKey *root.Key
+	if structure.RootKey != nil {
+		rootKey = root.NewKey(kdf.DeriveSecrets, structure.RootKey)
+	}
 	var pendingPreKey *PendingPreKey
 	if structure.PendingPreKey != nil {
 		pendingPreKey, err = NewPendingPreKeyFromStruct(structure.PendingPreKey)
 		errors.Add(err)
 	}
-	senderChain, err := NewChainFromStructure(structure.SenderChain)
-	errors.Add(err)
+	var senderChain *Chain
+	if structure.SenderChain != nil {
+		senderChain, err = NewChainFromStructure(structure.SenderChain)
+		errors.Add(err)
+	}
 
 	// Build our receiver chains from structure.
 	receiverChains := make([]*Chain, len(structure.ReceiverChains))
@@ -76,20 +95,21 @@ func NewStateFromStructure(structure *StateStructure, serializer StateSerializer
 
 	// Build our state object.
 	state := &State{
-		localIdentityPublic:  identity.NewKey(localIdentityPublic),
+		localIdentityPublic:  localIdentityPublic,
 		localRegistrationID:  structure.LocalRegistrationID,
 		needsRefresh:         structure.NeedsRefresh,
 		pendingKeyExchange:   NewPendingKeyExchangeFromStruct(structure.PendingKeyExchange),
 		pendingPreKey:        pendingPreKey,
 		previousCounter:      structure.PreviousCounter,
 		receiverChains:       receiverChains,
-		remoteIdentityPublic: identity.NewKey(remoteIdentityPublic),
+		remoteIdentityPublic: remoteIdentityPublic,
 		remoteRegistrationID: structure.RemoteRegistrationID,
-		rootKey:              root.NewKey(kdf.DeriveSecrets, structure.RootKey),
+		rootKey:              rootKey,
 		senderBaseKey:        senderBaseKey,
 		senderChain:          senderChain,
 		serializer:           serializer,
 		sessionVersion:       structure.SessionVersion,
+		config:               config.Get(cfg...),
 	}
 
 	return state, nil
@@ -132,6 +152,12 @@ type State struct {
 	senderChain          *Chain
 	serializer           StateSerializer
 	sessionVersion       int
+	config               *config.Config
+}
+
+// SetConfig sets the limits that are used for this session state.
+func (s *State) SetConfig(cfg *config.Config) {
+	s.config = config.Get(cfg)
 }
 
 // SenderBaseKey returns the sender's base key in bytes.
@@ -275,7 +301,7 @@ func (s *State) AddReceiverChain(senderRatchetKey ecc.ECPublicKeyable, chainKey
 	s.receiverChains = append(s.receiverChains, chain)
 
 	// If our list of receiver chains is too big, delete the oldest entry.
-	if len(s.receiverChains) > maxReceiverChains {
+	if len(s.receiverChains) > s.config.MaxReceiverChains {
 		i := 0
 		s.receiverChains = append(s.receiverChains[:i], s.receiverChains[i+1:]...)
 	}
@@ -386,7 +412,7 @@ func (s *State) SetMessageKeys(senderEphemeral ecc.ECPublicKeyable, messageKeys
 		),
 	)
 
-	if len(chainState.MessageKeys()) > maxMessageKeys {
+	if len(chainState.MessageKeys()) > s.config.MaxMessageKeys {
 		chainState.PopFirstMessageKeys()
 	}
 }
@@ -435,6 +461,11 @@ func (s *State) PendingKeyExchangeIdentityKeyPair() *identity.KeyPair {
 	return s.pendingKeyExchange.localIdentityKeyPair
 }
 
+// ClearPendingKeyExchange will remove the session's pending key exchange state.
+func (s *State) ClearPendingKeyExchange() {
+	s.pendingKeyExchange = nil
+}
+
 // HasPendingKeyExchange will return true if there is a valid pending key exchange waiting.
 func (s *State) HasPendingKeyExchange() bool {
 	return s.pendingKeyExchange != nil
@@ -450,6 +481,14 @@ func (s *State) SetUnacknowledgedPreKeyMessage(preKeyID *optional.Uint32, signed
 	)
 }
 
+// SetUnacknowledgedKyberPreKey will add the given kyber prekey id and KEM
+// ciphertext to the session's unacknowledged pre key message. It must be
+// called after SetUnacknowledgedPreKeyMessage.
+func (s *State) SetUnacknowledgedKyberPreKey(kyberPreKeyID uint32, ciphertext []byte) {
+	s.pendingPreKey.kyberPreKeyID = optional.NewOptionalUint32(kyberPreKeyID)
+	s.pendingPreKey.kyberCiphertext = ciphertext
+}
+
 // HasUnacknowledgedPreKeyMessage will return true if this session has an unacknowledged
 // pre key message.
 func (s *State) HasUnacknowledgedPreKeyMessage() bool {
@@ -464,7 +503,10 @@ func (s *State) UnackPreKeyMessageItems() (*UnackPreKeyMessageItems, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewUnackPreKeyMessageItems(preKeyID, signedPreKeyID, baseKey), nil
+	items := NewUnackPreKeyMessageItems(preKeyID, signedPreKeyID, baseKey)
+	items.kyberPreKeyID = s.pendingPreKey.kyberPreKeyID
+	items.kyberCiphertext = s.pendingPreKey.kyberCiphertext
+	return items, nil
 }
 
 // ClearUnackPreKeyMessage will clear the session's pending pre key.
@@ -492,6 +534,21 @@ func (s *State) LocalRegistrationID() uint32 {
 	return s.localRegistrationID
 }
 
+// Clone will return a deep copy of the session state. Changes made to the
+// copy, such as advancing chains or removing message keys, don't affect
+// the original state.
+func (s *State) Clone() *State {
+	clone := *s
+	clone.pendingKeyExchange = s.pendingKeyExchange.clone()
+	clone.pendingPreKey = s.pendingPreKey.clone()
+	clone.senderChain = s.senderChain.Clone()
+	clone.receiverChains = make([]*Chain, len(s.receiverChains))
+	for i := range s.receiverChains {
+		clone.receiverChains[i] = s.receiverChains[i].Clone()
+	}
+	return &clone
+}
+
 // Serialize will return the state as bytes using the given serializer.
 func (s *State) Serialize() []byte {
 	return s.serializer.Serialize(s.structure())
@@ -512,20 +569,32 @@ func (s *State) structure() *StateStructure {
 		pendingKeyExchange = s.pendingKeyExchange.structure()
 	}
 
-	// Build and return our state structure.
-	return &StateStructure{
-		LocalIdentityPublic:  s.localIdentityPublic.Serialize(),
+	// Build our state structure.
+	structure := &StateStructure{
 		LocalRegistrationID:  s.localRegistrationID,
 		NeedsRefresh:         s.needsRefresh,
 		PendingKeyExchange:   pendingKeyExchange,
 		PendingPreKey:        s.pendingPreKey.structure(),
 		PreviousCounter:      s.previousCounter,
 		ReceiverChains:       receiverChains,
-		RemoteIdentityPublic: s.remoteIdentityPublic.Serialize(),
 		RemoteRegistrationID: s.remoteRegistrationID,
-		RootKey:              s.rootKey.Bytes(),
-		SenderBaseKey:        s.senderBaseKey.Serialize(),
 		SenderChain:          s.senderChain.structure(),
 		SessionVersion:       s.sessionVersion,
 	}
+
+	// Keys are only set once the state has been initialized.
+	if s.localIdentityPublic != nil {
+		structure.LocalIdentityPublic = s.localIdentityPublic.Serialize()
+	}
+	if s.remoteIdentityPublic != nil {
+		structure.RemoteIdentityPublic = s.remoteIdentityPublic.Serialize()
+	}
+	if s.rootKey != nil {
+		structure.RootKey = s.rootKey.Bytes()
+	}
+	if s.senderBaseKey != nil {
+		structure.SenderBaseKey = s.senderBaseKey.Serialize()
+	}
+
+	return structure
 }
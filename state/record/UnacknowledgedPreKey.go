@@ -20,27 +20,34 @@ func NewUnackPreKeyMessageItems(preKeyID *optional.Uint32, signedPreKeyID uint32
 // message items object from the given structure.
 func NewUnackPreKeyMessageItemsFromStruct(structure *UnackPreKeyMessageItemsStructure) *UnackPreKeyMessageItems {
 	baseKey, _ := ecc.DecodePoint(structure.BaseKey, 0)
-	return NewUnackPreKeyMessageItems(
+	items := NewUnackPreKeyMessageItems(
 		structure.PreKeyID,
 		structure.SignedPreKeyID,
 		baseKey,
 	)
+	items.kyberPreKeyID = structure.KyberPreKeyID
+	items.kyberCiphertext = structure.KyberCiphertext
+	return items
 }
 
 // UnackPreKeyMessageItemsStructure is a serializable structure for unackowledged
 // prekey message items.
 type UnackPreKeyMessageItemsStructure struct {
-	PreKeyID       *optional.Uint32
-	SignedPreKeyID uint32
-	BaseKey        []byte
+	PreKeyID        *optional.Uint32
+	SignedPreKeyID  uint32
+	BaseKey         []byte
+	KyberPreKeyID   *optional.Uint32
+	KyberCiphertext []byte
 }
 
 // UnackPreKeyMessageItems is a structure for messages that have not been
 // acknowledged.
 type UnackPreKeyMessageItems struct {
-	preKeyID       *optional.Uint32
-	signedPreKeyID uint32
-	baseKey        ecc.ECPublicKeyable
+	preKeyID        *optional.Uint32
+	signedPreKeyID  uint32
+	baseKey         ecc.ECPublicKeyable
+	kyberPreKeyID   *optional.Uint32
+	kyberCiphertext []byte
 }
 
 // PreKeyID returns the prekey id of the unacknowledged message.
@@ -58,12 +65,28 @@ func (u *UnackPreKeyMessageItems) BaseKey() ecc.ECPublicKeyable {
 	return u.baseKey
 }
 
+// KyberPreKeyID returns the kyber prekey id of the unacknowledged message.
+// It is empty if the session was built without a kyber prekey.
+func (u *UnackPreKeyMessageItems) KyberPreKeyID() *optional.Uint32 {
+	if u.kyberPreKeyID == nil {
+		return optional.NewEmptyUint32()
+	}
+	return u.kyberPreKeyID
+}
+
+// KyberCiphertext returns the KEM ciphertext of the unacknowledged message.
+func (u *UnackPreKeyMessageItems) KyberCiphertext() []byte {
+	return u.kyberCiphertext
+}
+
 // structure will return a serializable base structure
 // for unacknowledged prekey message items.
 func (u *UnackPreKeyMessageItems) structure() *UnackPreKeyMessageItemsStructure {
 	return &UnackPreKeyMessageItemsStructure{
-		PreKeyID:       u.preKeyID,
-		SignedPreKeyID: u.signedPreKeyID,
-		BaseKey:        u.baseKey.Serialize(),
+		PreKeyID:        u.preKeyID,
+		SignedPreKeyID:  u.signedPreKeyID,
+		BaseKey:         u.baseKey.Serialize(),
+		KyberPreKeyID:   u.kyberPreKeyID,
+		KyberCiphertext: u.kyberCiphertext,
 	}
 }
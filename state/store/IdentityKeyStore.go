@@ -26,6 +26,63 @@ type IdentityKey interface {
 	// Determine whether a remote client's identity is trusted. Trust is based on
 	// 'trust on first use'. This means that an identity key is considered 'trusted'
 	// if there is no entry for the recipient in the local store, or if it matches the
-	// saved key for a recipient in the local store.
+	// saved key for a recipient in the local store. Stores that need to know
+	// whether a message is being sent or received can implement
+	// DirectionalIdentityKey.
 	IsTrustedIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) (bool, error)
 }
+
+// Direction is the direction of the message that an identity key is
+// being checked for.
+type Direction int
+
+// Directions for identity trust checks.
+const (
+	DirectionSending Direction = iota
+	DirectionReceiving
+)
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	switch d {
+	case DirectionSending:
+		return "sending"
+	case DirectionReceiving:
+		return "receiving"
+	default:
+		return "unknown"
+	}
+}
+
+// DirectionalIdentityKey is an optional extension of the IdentityKey store
+// for stores that apply a different trust policy depending on whether a
+// message is being sent or received. For example, libsignal clients accept
+// a changed identity key when receiving, but refuse to send to it until the
+// user has approved the new key.
+type DirectionalIdentityKey interface {
+	// Verify a remote client's identity key for a message in the given
+	// direction.
+	IsTrustedIdentityForDirection(ctx context.Context, address *protocol.SignalAddress,
+		identityKey *identity.Key, direction Direction) (bool, error)
+}
+
+// IsTrustedIdentity will check whether the given identity key is trusted for
+// a message in the given direction. If the store implements
+// DirectionalIdentityKey, the direction is passed to it, and otherwise the
+// store's IsTrustedIdentity method is used for both directions.
+func IsTrustedIdentity(ctx context.Context, identityStore IdentityKey, address *protocol.SignalAddress,
+	identityKey *identity.Key, direction Direction) (bool, error) {
+
+	if directionalStore, ok := identityStore.(DirectionalIdentityKey); ok {
+		return directionalStore.IsTrustedIdentityForDirection(ctx, address, identityKey, direction)
+	}
+	return identityStore.IsTrustedIdentity(ctx, address, identityKey)
+}
+
+// IdentityKeyLoader is an optional extension of the IdentityKey store for
+// stores that can return the saved identity key of a remote client. It is
+// used to include the saved key in untrusted identity errors.
+type IdentityKeyLoader interface {
+	// Load a remote client's saved identity key, or nil if there is none.
+	LoadIdentity(ctx context.Context, address *protocol.SignalAddress) (*identity.Key, error)
+}
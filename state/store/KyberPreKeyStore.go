@@ -0,0 +1,23 @@
+package store
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/state/record"
+)
+
+// KyberPreKey store is an interface that describes how to persistently
+// store post-quantum KEM PreKeys. It is optional: stores that implement it
+// alongside the PreKey store interface enable PQXDH session setup.
+type KyberPreKey interface {
+	// LoadKyberPreKey loads a local KyberPreKeyRecord
+	LoadKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (*record.KyberPreKey, error)
+	// Store a local KyberPreKeyRecord
+	StoreKyberPreKey(ctx context.Context, kyberPreKeyID uint32, record *record.KyberPreKey) error
+	// Check to see if store contains the given record
+	ContainsKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (bool, error)
+	// Mark a KyberPreKeyRecord as used after a session was built with it.
+	// One-time kyber prekeys should be removed. This is not called for the
+	// last resort kyber prekey.
+	MarkKyberPreKeyUsed(ctx context.Context, kyberPreKeyID uint32) error
+}
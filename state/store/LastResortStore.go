@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// PreKeyType is the type of a local PreKey.
+type PreKeyType int
+
+// PreKey types
+const (
+	PreKeyTypeEC PreKeyType = iota
+	PreKeyTypeKyber
+)
+
+// String returns the name of the prekey type.
+func (t PreKeyType) String() string {
+	switch t {
+	case PreKeyTypeEC:
+		return "ec"
+	case PreKeyTypeKyber:
+		return "kyber"
+	default:
+		return "unknown"
+	}
+}
+
+// LastResortUsage store is an optional interface for PreKey and kyber
+// PreKey stores that keep track of how often last resort prekeys are used.
+// Frequent use of last resort prekeys means that the one-time prekeys on
+// the server have run out, or that someone is exhausting them on purpose.
+type LastResortUsage interface {
+	// RecordLastResortPreKeyUse is called every time a session is built
+	// with a last resort prekey of the given type.
+	RecordLastResortPreKeyUse(ctx context.Context, address *protocol.SignalAddress, keyType PreKeyType, preKeyID uint32) error
+}
+
+// RecordLastResortPreKeyUse records a use of a last resort prekey if the
+// given store implements LastResortUsage, and does nothing otherwise.
+func RecordLastResortPreKeyUse(ctx context.Context, store any, address *protocol.SignalAddress, keyType PreKeyType, preKeyID uint32) error {
+	if usageStore, ok := store.(LastResortUsage); ok {
+		return usageStore.RecordLastResortPreKeyUse(ctx, address, keyType, preKeyID)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package store
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// RecordLister is an optional interface for stores that can list the keys
+// of all the records they contain. It is used to walk a whole store, for
+// example to back it up.
+type RecordLister interface {
+	// ListIdentities returns the addresses of all saved remote identity keys.
+	ListIdentities(ctx context.Context) ([]*protocol.SignalAddress, error)
+	// ListPreKeys returns the IDs of all local prekeys.
+	ListPreKeys(ctx context.Context) ([]uint32, error)
+	// ListKyberPreKeys returns the IDs of all local kyber prekeys.
+	ListKyberPreKeys(ctx context.Context) ([]uint32, error)
+	// ListSessions returns the addresses of all session records.
+	ListSessions(ctx context.Context) ([]*protocol.SignalAddress, error)
+	// ListSenderKeys returns the names of all sender key records.
+	ListSenderKeys(ctx context.Context) ([]*protocol.SenderKeyName, error)
+}
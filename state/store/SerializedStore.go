@@ -0,0 +1,22 @@
+package store
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// SerializedLoader is an optional interface for stores that keep records
+// as the bytes returned by their Serialize methods. It returns the stored
+// bytes without deserializing them, which lets store wrappers that change
+// the serialized form (such as encstore) work on top of the store. Each
+// method returns nil if there is no record.
+type SerializedLoader interface {
+	LoadSerializedSession(ctx context.Context, address *protocol.SignalAddress) ([]byte, error)
+	LoadSerializedPreKey(ctx context.Context, preKeyID uint32) ([]byte, error)
+	LoadSerializedSignedPreKey(ctx context.Context, signedPreKeyID uint32) ([]byte, error)
+	// LoadSerializedSignedPreKeys returns all signed prekeys by ID.
+	LoadSerializedSignedPreKeys(ctx context.Context) (map[uint32][]byte, error)
+	LoadSerializedKyberPreKey(ctx context.Context, kyberPreKeyID uint32) ([]byte, error)
+	LoadSerializedSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) ([]byte, error)
+}
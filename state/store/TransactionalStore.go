@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+)
+
+// Transactional store is an optional interface for stores that can run
+// multiple operations atomically. If the session store (or the sender key
+// store for groups) implements it, session builders and ciphers will run
+// all the store calls of a single operation inside one transaction.
+type Transactional interface {
+	// WithTx runs the given function inside a transaction. Store methods
+	// called with the context passed to the function must use the transaction.
+	// The transaction must be committed if the function returns nil and
+	// rolled back otherwise. If the given context is already inside a
+	// transaction, the function should run in that transaction.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// WithTx runs the given function inside a transaction if the given store
+// implements Transactional, and calls it directly otherwise.
+func WithTx(ctx context.Context, store any, fn func(ctx context.Context) error) error {
+	if txStore, ok := store.(Transactional); ok {
+		return txStore.WithTx(ctx, fn)
+	}
+	return fn(ctx)
+}
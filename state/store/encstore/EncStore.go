@@ -0,0 +1,150 @@
+// Package encstore provides a store wrapper that encrypts records at rest.
+// Session records, prekeys and sender keys are encrypted with an AEAD
+// before they are given to the wrapped store, so the underlying database
+// only ever contains encrypted key material.
+//
+// The wrapper needs more from the wrapped store than store.SignalProtocol:
+// it must also store kyber prekeys, return the stored bytes of records
+// without deserializing them (store.SerializedLoader) and list its records
+// (store.RecordLister), as described by Backend. memstore and sqlstore
+// implement all of these. Other stores can't be wrapped until they do, as
+// the encrypted envelopes can't be decoded by the serializer that the
+// store would otherwise use to load records.
+package encstore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// Backend is the interface that a wrapped store must implement. The store
+// must keep the bytes returned by the Serialize method of each record it
+// is given, and return them from the SerializedLoader methods, like
+// memstore and sqlstore do.
+type Backend interface {
+	store.SignalProtocol
+	store.KyberPreKey
+	store.SerializedLoader
+	store.RecordLister
+}
+
+// NewStore returns a store that encrypts records with keys from the given
+// provider and stores them in the given backend. Decrypted records are
+// deserialized with the given serializer.
+func NewStore(backend Backend, keys KeyProvider, serializer *serialize.Serializer) *Store {
+	return &Store{
+		backend:    backend,
+		keys:       keys,
+		serializer: serializer,
+	}
+}
+
+// Store is an implementation of store.SignalProtocol and the optional kyber
+// prekey, transaction, identity loading, last resort usage and record
+// listing interfaces that encrypts the records of its backend.
+//
+// Each record is bound to its address or key ID, so records that are moved
+// to another row in the database can't be decrypted. Loading a record never
+// writes to the backend: after the current key is rotated, records that
+// were encrypted with an older key stay as they are until they are stored
+// again or Rekey is called. Remote identity keys are public, so they are
+// stored as is.
+type Store struct {
+	backend    Backend
+	keys       KeyProvider
+	serializer *serialize.Serializer
+}
+
+// WithTx runs the given function inside a transaction of the backend, if
+// it supports transactions.
+func (s *Store) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return store.WithTx(ctx, s.backend, fn)
+}
+
+// currentSealer returns a sealer for the given record with the current
+// key.
+func (s *Store) currentSealer(ctx context.Context, kind, id string) (*sealer, error) {
+	keyID, key, err := s.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get record encryption key: %w", err)
+	}
+	return newSealer(keyID, key, kind, id)
+}
+
+// open decrypts the given envelope of a record. It returns nil if the
+// envelope is nil.
+func (s *Store) open(ctx context.Context, envelope []byte, kind, id string) ([]byte, error) {
+	if envelope == nil {
+		return nil, nil
+	}
+	plaintext, err := s.openEnvelope(ctx, envelope, kind, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s %s: %w", kind, id, err)
+	}
+	return plaintext, nil
+}
+
+func (s *Store) openEnvelope(ctx context.Context, envelope []byte, kind, id string) ([]byte, error) {
+	keyID, err := envelopeKeyID(envelope)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.keys.Key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := newSealer(keyID, key, kind, id)
+	if err != nil {
+		return nil, err
+	}
+	return sealer.open(envelope)
+}
+
+// GetIdentityKeyPair returns the local identity key pair of the backend.
+func (s *Store) GetIdentityKeyPair() *identity.KeyPair {
+	return s.backend.GetIdentityKeyPair()
+}
+
+// GetLocalRegistrationID returns the local registration ID of the backend.
+func (s *Store) GetLocalRegistrationID() uint32 {
+	return s.backend.GetLocalRegistrationID()
+}
+
+// SaveIdentity saves the identity key of a remote client in the backend.
+func (s *Store) SaveIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) error {
+	return s.backend.SaveIdentity(ctx, address, identityKey)
+}
+
+// IsTrustedIdentity checks the identity key of a remote client with the
+// backend.
+func (s *Store) IsTrustedIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) (bool, error) {
+	return s.backend.IsTrustedIdentity(ctx, address, identityKey)
+}
+
+// IsTrustedIdentityForDirection checks the identity key of a remote client
+// with the backend, passing the direction on if the backend supports it.
+func (s *Store) IsTrustedIdentityForDirection(ctx context.Context, address *protocol.SignalAddress,
+	identityKey *identity.Key, direction store.Direction) (bool, error) {
+
+	return store.IsTrustedIdentity(ctx, s.backend, address, identityKey, direction)
+}
+
+// LoadIdentity returns the saved identity key of a remote client, or nil if
+// there is none or the backend can't load identity keys.
+func (s *Store) LoadIdentity(ctx context.Context, address *protocol.SignalAddress) (*identity.Key, error) {
+	if loader, ok := s.backend.(store.IdentityKeyLoader); ok {
+		return loader.LoadIdentity(ctx, address)
+	}
+	return nil, nil
+}
+
+// RecordLastResortPreKeyUse records a use of a last resort prekey if the
+// backend keeps track of them.
+func (s *Store) RecordLastResortPreKeyUse(ctx context.Context, address *protocol.SignalAddress, keyType store.PreKeyType, preKeyID uint32) error {
+	return store.RecordLastResortPreKeyUse(ctx, s.backend, address, keyType, preKeyID)
+}
@@ -0,0 +1,122 @@
+package encstore
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// Encrypted records are stored as an envelope with the following format:
+//
+//	version (1 byte) | key ID (4 bytes, big endian) | nonce (24 bytes) | ciphertext
+//
+// The ciphertext is encrypted with XChaCha20-Poly1305. The associated data
+// is the version and key ID followed by the kind and ID of the record, so
+// a record can't be decrypted under another address or key ID.
+const (
+	envelopeVersion = 1
+	headerSize      = 1 + 4
+	envelopeSize    = headerSize + chacha20poly1305.NonceSizeX + chacha20poly1305.Overhead
+)
+
+// Kinds of encrypted records
+const (
+	kindSession      = "session"
+	kindPreKey       = "prekey"
+	kindSignedPreKey = "signed-prekey"
+	kindKyberPreKey  = "kyber-prekey"
+	kindSenderKey    = "sender-key"
+)
+
+// envelopeKeyID returns the ID of the key that the given envelope was
+// encrypted with.
+func envelopeKeyID(envelope []byte) (uint32, error) {
+	if len(envelope) < envelopeSize {
+		return 0, fmt.Errorf("%w (too short)", signalerror.ErrInvalidRecordEnvelope)
+	} else if envelope[0] != envelopeVersion {
+		return 0, fmt.Errorf("%w (unknown version %d)", signalerror.ErrInvalidRecordEnvelope, envelope[0])
+	}
+	return binary.BigEndian.Uint32(envelope[1:headerSize]), nil
+}
+
+// sealer encrypts and decrypts a single record with a single key.
+type sealer struct {
+	keyID uint32
+	aead  cipher.AEAD
+	ad    []byte
+}
+
+// newSealer returns a sealer for the record with the given kind and ID.
+func newSealer(keyID uint32, key []byte, kind, id string) (*sealer, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize, headerSize+len(kind)+1+len(id))
+	header[0] = envelopeVersion
+	binary.BigEndian.PutUint32(header[1:], keyID)
+	ad := append(append(append(header, kind...), 0), id...)
+
+	return &sealer{keyID: keyID, aead: aead, ad: ad}, nil
+}
+
+// seal encrypts the given serialized record into an envelope.
+func (s *sealer) seal(plaintext []byte) []byte {
+	envelope := make([]byte, headerSize+chacha20poly1305.NonceSizeX, envelopeSize+len(plaintext))
+	copy(envelope, s.ad[:headerSize])
+	nonce := envelope[headerSize:]
+	// crypto/rand.Read never returns an error.
+	rand.Read(nonce)
+	return s.aead.Seal(envelope, nonce, plaintext, s.ad)
+}
+
+// open decrypts the given envelope.
+func (s *sealer) open(envelope []byte) ([]byte, error) {
+	keyID, err := envelopeKeyID(envelope)
+	if err != nil {
+		return nil, err
+	} else if keyID != s.keyID {
+		return nil, fmt.Errorf("%w (encrypted with key %d instead of %d)", signalerror.ErrRecordDecryptionFailed, keyID, s.keyID)
+	}
+	nonce := envelope[headerSize : headerSize+chacha20poly1305.NonceSizeX]
+	ciphertext := envelope[headerSize+chacha20poly1305.NonceSizeX:]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, s.ad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", signalerror.ErrRecordDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
+
+// recordSerializer is implemented by all the record serializers in
+// serialize.Serializer.
+type recordSerializer[T any] interface {
+	Serialize(structure *T) []byte
+	Deserialize(serialized []byte) (*T, error)
+}
+
+// sealingSerializer is a record serializer that encrypts the output of
+// another serializer. Records that are given to the wrapped store use it,
+// so that the store only ever sees the encrypted envelope.
+type sealingSerializer[T any] struct {
+	base   recordSerializer[T]
+	sealer *sealer
+}
+
+// Serialize encrypts the serialized structure.
+func (s *sealingSerializer[T]) Serialize(structure *T) []byte {
+	return s.sealer.seal(s.base.Serialize(structure))
+}
+
+// Deserialize decrypts the envelope and deserializes the structure.
+func (s *sealingSerializer[T]) Deserialize(serialized []byte) (*T, error) {
+	plaintext, err := s.sealer.open(serialized)
+	if err != nil {
+		return nil, err
+	}
+	return s.base.Deserialize(plaintext)
+}
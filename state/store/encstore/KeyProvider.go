@@ -0,0 +1,93 @@
+package encstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// KeySize is the size of record encryption keys.
+const KeySize = chacha20poly1305.KeySize
+
+// KeyProvider provides the keys that records are encrypted with. Every
+// encrypted record contains the ID of its key, so records encrypted with
+// older keys can still be decrypted after the current key is rotated.
+type KeyProvider interface {
+	// CurrentKey returns the ID and value of the key that records are
+	// encrypted with.
+	CurrentKey(ctx context.Context) (keyID uint32, key []byte, err error)
+
+	// Key returns the key with the given ID. If the key is not known, the
+	// error should wrap signalerror.ErrUnknownRecordKey.
+	Key(ctx context.Context, keyID uint32) ([]byte, error)
+}
+
+// NewKeyRing returns a key ring containing the given key, which is used as
+// the current key.
+func NewKeyRing(keyID uint32, key []byte) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[uint32][]byte)}
+	if err := ring.AddKey(keyID, key); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// KeyRing is a KeyProvider that keeps keys in memory. Keys are rotated by
+// adding a new key, and old keys can be removed once no records are
+// encrypted with them anymore, i.e. after Store.Rekey has been called. It
+// is safe for concurrent use.
+type KeyRing struct {
+	keys    map[uint32][]byte
+	current uint32
+	mutex   sync.RWMutex
+}
+
+// AddKey adds a key to the ring and makes it the current key.
+func (r *KeyRing) AddKey(keyID uint32, key []byte) error {
+	if len(key) != KeySize {
+		return fmt.Errorf("record encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.keys[keyID] = key
+	r.current = keyID
+	return nil
+}
+
+// RemoveKey removes an old key from the ring. The current key can't be
+// removed.
+func (r *KeyRing) RemoveKey(keyID uint32) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if keyID == r.current {
+		return fmt.Errorf("can't remove current record encryption key %d", keyID)
+	}
+	delete(r.keys, keyID)
+	return nil
+}
+
+// CurrentKey returns the ID and value of the most recently added key.
+func (r *KeyRing) CurrentKey(ctx context.Context) (uint32, []byte, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.current, r.keys[r.current], nil
+}
+
+// Key returns the key with the given ID.
+func (r *KeyRing) Key(ctx context.Context, keyID uint32) ([]byte, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key, ok := r.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", signalerror.ErrUnknownRecordKey, keyID)
+	}
+	return key, nil
+}
@@ -0,0 +1,36 @@
+package encstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// ListIdentities returns the addresses of all saved remote identity keys
+// in the backend.
+func (s *Store) ListIdentities(ctx context.Context) ([]*protocol.SignalAddress, error) {
+	return s.backend.ListIdentities(ctx)
+}
+
+// ListPreKeys returns the IDs of all local prekeys in the backend.
+func (s *Store) ListPreKeys(ctx context.Context) ([]uint32, error) {
+	return s.backend.ListPreKeys(ctx)
+}
+
+// ListKyberPreKeys returns the IDs of all local kyber prekeys in the
+// backend.
+func (s *Store) ListKyberPreKeys(ctx context.Context) ([]uint32, error) {
+	return s.backend.ListKyberPreKeys(ctx)
+}
+
+// ListSessions returns the addresses of all session records in the
+// backend.
+func (s *Store) ListSessions(ctx context.Context) ([]*protocol.SignalAddress, error) {
+	return s.backend.ListSessions(ctx)
+}
+
+// ListSenderKeys returns the names of all sender key records in the
+// backend.
+func (s *Store) ListSenderKeys(ctx context.Context) ([]*protocol.SenderKeyName, error) {
+	return s.backend.ListSenderKeys(ctx)
+}
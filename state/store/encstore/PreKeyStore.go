@@ -0,0 +1,170 @@
+package encstore
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+
+	"go.mau.fi/libsignal/state/record"
+)
+
+// LoadPreKey loads and decrypts a local prekey, or returns nil if there is
+// none with the given ID.
+func (s *Store) LoadPreKey(ctx context.Context, preKeyID uint32) (*record.PreKey, error) {
+	envelope, err := s.backend.LoadSerializedPreKey(ctx, preKeyID)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.open(ctx, envelope, kindPreKey, formatKeyID(preKeyID))
+	if err != nil || plaintext == nil {
+		return nil, err
+	}
+	preKey, err := record.NewPreKeyFromBytes(plaintext, s.serializer.PreKeyRecord)
+	return preKey, err
+}
+
+// StorePreKey encrypts and stores a local prekey.
+func (s *Store) StorePreKey(ctx context.Context, preKeyID uint32, preKeyRecord *record.PreKey) error {
+	sealer, err := s.currentSealer(ctx, kindPreKey, formatKeyID(preKeyID))
+	if err != nil {
+		return err
+	}
+	structure, err := s.serializer.PreKeyRecord.Deserialize(preKeyRecord.Serialize())
+	if err != nil {
+		return err
+	}
+	sealed, err := record.NewPreKeyFromStruct(structure,
+		&sealingSerializer[record.PreKeyStructure]{s.serializer.PreKeyRecord, sealer})
+	if err != nil {
+		return err
+	}
+	return s.backend.StorePreKey(ctx, preKeyID, sealed)
+}
+
+// ContainsPreKey returns true if the backend has a prekey with the given
+// ID.
+func (s *Store) ContainsPreKey(ctx context.Context, preKeyID uint32) (bool, error) {
+	return s.backend.ContainsPreKey(ctx, preKeyID)
+}
+
+// RemovePreKey removes a local prekey.
+func (s *Store) RemovePreKey(ctx context.Context, preKeyID uint32) error {
+	return s.backend.RemovePreKey(ctx, preKeyID)
+}
+
+// LoadSignedPreKey loads and decrypts a local signed prekey, or returns nil
+// if there is none with the given ID.
+func (s *Store) LoadSignedPreKey(ctx context.Context, signedPreKeyID uint32) (*record.SignedPreKey, error) {
+	envelope, err := s.backend.LoadSerializedSignedPreKey(ctx, signedPreKeyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.openSignedPreKey(ctx, signedPreKeyID, envelope)
+}
+
+// LoadSignedPreKeys loads and decrypts all local signed prekeys sorted by
+// ID.
+func (s *Store) LoadSignedPreKeys(ctx context.Context) ([]*record.SignedPreKey, error) {
+	envelopes, err := s.backend.LoadSerializedSignedPreKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var signedPreKeys []*record.SignedPreKey
+	for _, signedPreKeyID := range slices.Sorted(maps.Keys(envelopes)) {
+		signedPreKey, err := s.openSignedPreKey(ctx, signedPreKeyID, envelopes[signedPreKeyID])
+		if err != nil {
+			return nil, err
+		}
+		signedPreKeys = append(signedPreKeys, signedPreKey)
+	}
+	return signedPreKeys, nil
+}
+
+// openSignedPreKey decrypts the envelope of a signed prekey.
+func (s *Store) openSignedPreKey(ctx context.Context, signedPreKeyID uint32, envelope []byte) (*record.SignedPreKey, error) {
+	plaintext, err := s.open(ctx, envelope, kindSignedPreKey, formatKeyID(signedPreKeyID))
+	if err != nil || plaintext == nil {
+		return nil, err
+	}
+	signedPreKey, err := record.NewSignedPreKeyFromBytes(plaintext, s.serializer.SignedPreKeyRecord)
+	return signedPreKey, err
+}
+
+// StoreSignedPreKey encrypts and stores a local signed prekey.
+func (s *Store) StoreSignedPreKey(ctx context.Context, signedPreKeyID uint32, signedPreKeyRecord *record.SignedPreKey) error {
+	sealer, err := s.currentSealer(ctx, kindSignedPreKey, formatKeyID(signedPreKeyID))
+	if err != nil {
+		return err
+	}
+	structure, err := s.serializer.SignedPreKeyRecord.Deserialize(signedPreKeyRecord.Serialize())
+	if err != nil {
+		return err
+	}
+	sealed, err := record.NewSignedPreKeyFromStruct(structure,
+		&sealingSerializer[record.SignedPreKeyStructure]{s.serializer.SignedPreKeyRecord, sealer})
+	if err != nil {
+		return err
+	}
+	return s.backend.StoreSignedPreKey(ctx, signedPreKeyID, sealed)
+}
+
+// ContainsSignedPreKey returns true if the backend has a signed prekey with
+// the given ID.
+func (s *Store) ContainsSignedPreKey(ctx context.Context, signedPreKeyID uint32) (bool, error) {
+	return s.backend.ContainsSignedPreKey(ctx, signedPreKeyID)
+}
+
+// RemoveSignedPreKey removes a local signed prekey.
+func (s *Store) RemoveSignedPreKey(ctx context.Context, signedPreKeyID uint32) error {
+	return s.backend.RemoveSignedPreKey(ctx, signedPreKeyID)
+}
+
+// LoadKyberPreKey loads and decrypts a local kyber prekey, or returns nil
+// if there is none with the given ID.
+func (s *Store) LoadKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (*record.KyberPreKey, error) {
+	envelope, err := s.backend.LoadSerializedKyberPreKey(ctx, kyberPreKeyID)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.open(ctx, envelope, kindKyberPreKey, formatKeyID(kyberPreKeyID))
+	if err != nil || plaintext == nil {
+		return nil, err
+	}
+	kyberPreKey, err := record.NewKyberPreKeyFromBytes(plaintext, s.serializer.KyberPreKeyRecord)
+	return kyberPreKey, err
+}
+
+// StoreKyberPreKey encrypts and stores a local kyber prekey.
+func (s *Store) StoreKyberPreKey(ctx context.Context, kyberPreKeyID uint32, kyberPreKeyRecord *record.KyberPreKey) error {
+	sealer, err := s.currentSealer(ctx, kindKyberPreKey, formatKeyID(kyberPreKeyID))
+	if err != nil {
+		return err
+	}
+	structure, err := s.serializer.KyberPreKeyRecord.Deserialize(kyberPreKeyRecord.Serialize())
+	if err != nil {
+		return err
+	}
+	sealed, err := record.NewKyberPreKeyFromStruct(structure,
+		&sealingSerializer[record.KyberPreKeyStructure]{s.serializer.KyberPreKeyRecord, sealer})
+	if err != nil {
+		return err
+	}
+	return s.backend.StoreKyberPreKey(ctx, kyberPreKeyID, sealed)
+}
+
+// ContainsKyberPreKey returns true if the backend has a kyber prekey with
+// the given ID.
+func (s *Store) ContainsKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (bool, error) {
+	return s.backend.ContainsKyberPreKey(ctx, kyberPreKeyID)
+}
+
+// MarkKyberPreKeyUsed marks a kyber prekey as used in the backend.
+func (s *Store) MarkKyberPreKeyUsed(ctx context.Context, kyberPreKeyID uint32) error {
+	return s.backend.MarkKyberPreKeyUsed(ctx, kyberPreKeyID)
+}
+
+// formatKeyID returns the ID that a prekey record is bound to.
+func formatKeyID(keyID uint32) string {
+	return strconv.FormatUint(uint64(keyID), 10)
+}
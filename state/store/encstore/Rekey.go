@@ -0,0 +1,185 @@
+package encstore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mau.fi/libsignal/state/store"
+)
+
+// Rekey re-encrypts every record that was encrypted with an older key with
+// the current key, and returns the number of re-encrypted records. It
+// should be called after the current key is rotated, and before the older
+// keys are removed from the key provider.
+//
+// Each record is checked and re-encrypted in its own transaction, so the
+// store can be used while it is being rekeyed, and an interrupted call can
+// simply be run again.
+func (s *Store) Rekey(ctx context.Context) (int, error) {
+	currentKeyID, _, err := s.keys.CurrentKey(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get record encryption key: %w", err)
+	}
+	r := &rekeying{store: s, currentKeyID: currentKeyID}
+	for _, step := range []func(ctx context.Context) error{
+		r.preKeys,
+		r.signedPreKeys,
+		r.kyberPreKeys,
+		r.sessions,
+		r.senderKeys,
+	} {
+		if err = step(ctx); err != nil {
+			return r.count, err
+		}
+	}
+	return r.count, nil
+}
+
+// rekeying holds the state of a single Rekey call.
+type rekeying struct {
+	store        *Store
+	currentKeyID uint32
+	count        int
+}
+
+// rekey re-encrypts a single record inside its own transaction if the
+// envelope returned by load was encrypted with an older key. The reencrypt
+// function should load the record through the store and store it again.
+func (r *rekeying) rekey(ctx context.Context, kind string, load func(ctx context.Context) ([]byte, error),
+	reencrypt func(ctx context.Context) error) error {
+
+	rekeyed := false
+	err := store.WithTx(ctx, r.store.backend, func(ctx context.Context) error {
+		envelope, err := load(ctx)
+		if err != nil || envelope == nil {
+			return err
+		}
+		keyID, err := envelopeKeyID(envelope)
+		if err != nil || keyID == r.currentKeyID {
+			return err
+		}
+		rekeyed = true
+		return reencrypt(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rekey %s: %w", kind, err)
+	} else if rekeyed {
+		r.count++
+	}
+	return nil
+}
+
+func (r *rekeying) preKeys(ctx context.Context) error {
+	s := r.store
+	preKeyIDs, err := s.backend.ListPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, preKeyID := range preKeyIDs {
+		err = r.rekey(ctx, kindPreKey, func(ctx context.Context) ([]byte, error) {
+			return s.backend.LoadSerializedPreKey(ctx, preKeyID)
+		}, func(ctx context.Context) error {
+			preKey, err := s.LoadPreKey(ctx, preKeyID)
+			if err != nil {
+				return err
+			}
+			return s.StorePreKey(ctx, preKeyID, preKey)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rekeying) signedPreKeys(ctx context.Context) error {
+	s := r.store
+	envelopes, err := s.backend.LoadSerializedSignedPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for signedPreKeyID := range envelopes {
+		err = r.rekey(ctx, kindSignedPreKey, func(ctx context.Context) ([]byte, error) {
+			return s.backend.LoadSerializedSignedPreKey(ctx, signedPreKeyID)
+		}, func(ctx context.Context) error {
+			signedPreKey, err := s.LoadSignedPreKey(ctx, signedPreKeyID)
+			if err != nil {
+				return err
+			}
+			return s.StoreSignedPreKey(ctx, signedPreKeyID, signedPreKey)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rekeying) kyberPreKeys(ctx context.Context) error {
+	s := r.store
+	kyberPreKeyIDs, err := s.backend.ListKyberPreKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, kyberPreKeyID := range kyberPreKeyIDs {
+		err = r.rekey(ctx, kindKyberPreKey, func(ctx context.Context) ([]byte, error) {
+			return s.backend.LoadSerializedKyberPreKey(ctx, kyberPreKeyID)
+		}, func(ctx context.Context) error {
+			kyberPreKey, err := s.LoadKyberPreKey(ctx, kyberPreKeyID)
+			if err != nil {
+				return err
+			}
+			return s.StoreKyberPreKey(ctx, kyberPreKeyID, kyberPreKey)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rekeying) sessions(ctx context.Context) error {
+	s := r.store
+	addresses, err := s.backend.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		err = r.rekey(ctx, kindSession, func(ctx context.Context) ([]byte, error) {
+			return s.backend.LoadSerializedSession(ctx, address)
+		}, func(ctx context.Context) error {
+			sessionRecord, err := s.LoadSession(ctx, address)
+			if err != nil {
+				return err
+			}
+			return s.StoreSession(ctx, address, sessionRecord)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *rekeying) senderKeys(ctx context.Context) error {
+	s := r.store
+	senderKeyNames, err := s.backend.ListSenderKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, senderKeyName := range senderKeyNames {
+		err = r.rekey(ctx, kindSenderKey, func(ctx context.Context) ([]byte, error) {
+			return s.backend.LoadSerializedSenderKey(ctx, senderKeyName)
+		}, func(ctx context.Context) error {
+			keyRecord, err := s.LoadSenderKey(ctx, senderKeyName)
+			if err != nil {
+				return err
+			}
+			return s.StoreSenderKey(ctx, senderKeyName, keyRecord)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package encstore
+
+import (
+	"context"
+
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/protocol"
+)
+
+// LoadSenderKey loads and decrypts the sender key record for the given
+// group and sender, or returns a new empty record if there is none.
+func (s *Store) LoadSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) (*groupRecord.SenderKey, error) {
+	envelope, err := s.backend.LoadSerializedSenderKey(ctx, senderKeyName)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.open(ctx, envelope, kindSenderKey, senderKeyID(senderKeyName))
+	if err != nil {
+		return nil, err
+	} else if plaintext == nil {
+		return groupRecord.NewSenderKey(s.serializer.SenderKeyRecord, s.serializer.SenderKeyState), nil
+	}
+	keyRecord, err := groupRecord.NewSenderKeyFromBytes(plaintext, s.serializer.SenderKeyRecord, s.serializer.SenderKeyState)
+	return keyRecord, err
+}
+
+// StoreSenderKey encrypts and stores the sender key record for the given
+// group and sender.
+func (s *Store) StoreSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName, keyRecord *groupRecord.SenderKey) error {
+	sealer, err := s.currentSealer(ctx, kindSenderKey, senderKeyID(senderKeyName))
+	if err != nil {
+		return err
+	}
+	sealed, err := groupRecord.NewSenderKeyFromStruct(keyRecord.Structure(),
+		&sealingSerializer[groupRecord.SenderKeyStructure]{s.serializer.SenderKeyRecord, sealer}, s.serializer.SenderKeyState)
+	if err != nil {
+		return err
+	}
+	return s.backend.StoreSenderKey(ctx, senderKeyName, sealed)
+}
+
+// senderKeyID returns the ID that a sender key record is bound to.
+func senderKeyID(senderKeyName *protocol.SenderKeyName) string {
+	return senderKeyName.GroupID() + "\x00" + senderKeyName.Sender().String()
+}
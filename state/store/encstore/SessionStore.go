@@ -0,0 +1,62 @@
+package encstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// LoadSession loads and decrypts the session record for the given address,
+// or returns a new empty record if there is no session.
+func (s *Store) LoadSession(ctx context.Context, address *protocol.SignalAddress) (*record.Session, error) {
+	envelope, err := s.backend.LoadSerializedSession(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.open(ctx, envelope, kindSession, address.String())
+	if err != nil {
+		return nil, err
+	} else if plaintext == nil {
+		return record.NewSession(s.serializer.Session, s.serializer.State), nil
+	}
+	sessionRecord, err := record.NewSessionFromBytes(plaintext, s.serializer.Session, s.serializer.State)
+	return sessionRecord, err
+}
+
+// StoreSession encrypts and stores the session record for the given
+// address.
+func (s *Store) StoreSession(ctx context.Context, remoteAddress *protocol.SignalAddress, sessionRecord *record.Session) error {
+	sealer, err := s.currentSealer(ctx, kindSession, remoteAddress.String())
+	if err != nil {
+		return err
+	}
+	sealed, err := record.NewSessionFromStructure(sessionRecord.Structure(),
+		&sealingSerializer[record.SessionStructure]{s.serializer.Session, sealer}, s.serializer.State)
+	if err != nil {
+		return err
+	}
+	return s.backend.StoreSession(ctx, remoteAddress, sealed)
+}
+
+// GetSubDeviceSessions returns the device IDs of the sessions with the
+// given name from the backend.
+func (s *Store) GetSubDeviceSessions(ctx context.Context, name string) ([]uint32, error) {
+	return s.backend.GetSubDeviceSessions(ctx, name)
+}
+
+// ContainsSession returns true if the backend has a session record for the
+// given address.
+func (s *Store) ContainsSession(ctx context.Context, remoteAddress *protocol.SignalAddress) (bool, error) {
+	return s.backend.ContainsSession(ctx, remoteAddress)
+}
+
+// DeleteSession removes the session record for the given address.
+func (s *Store) DeleteSession(ctx context.Context, remoteAddress *protocol.SignalAddress) error {
+	return s.backend.DeleteSession(ctx, remoteAddress)
+}
+
+// DeleteAllSessions removes all session records.
+func (s *Store) DeleteAllSessions(ctx context.Context) error {
+	return s.backend.DeleteAllSessions(ctx)
+}
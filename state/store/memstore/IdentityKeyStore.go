@@ -0,0 +1,48 @@
+package memstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+)
+
+// GetIdentityKeyPair returns the local identity key pair.
+func (s *Store) GetIdentityKeyPair() *identity.KeyPair {
+	return s.identityKeyPair
+}
+
+// GetLocalRegistrationID returns the local registration ID.
+func (s *Store) GetLocalRegistrationID() uint32 {
+	return s.registrationID
+}
+
+// SaveIdentity saves the identity key of a remote client, replacing the
+// previously trusted key.
+func (s *Store) SaveIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) error {
+	defer s.lock()()
+
+	set(ctx, s, s.data.identities, *address, identityKey)
+	return nil
+}
+
+// IsTrustedIdentity returns true if no identity key has been saved for the
+// remote client yet (trust on first use) or if the given key is the saved
+// one.
+func (s *Store) IsTrustedIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) (bool, error) {
+	defer s.lock()()
+
+	trusted, ok := s.data.identities[*address]
+	if !ok {
+		return true, nil
+	}
+	return trusted.Fingerprint() == identityKey.Fingerprint(), nil
+}
+
+// LoadIdentity returns the saved identity key of a remote client, or nil if
+// there is none.
+func (s *Store) LoadIdentity(ctx context.Context, address *protocol.SignalAddress) (*identity.Key, error) {
+	defer s.lock()()
+
+	return s.data.identities[*address], nil
+}
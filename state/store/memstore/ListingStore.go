@@ -0,0 +1,68 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"maps"
+	"slices"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// ListIdentities returns the addresses of all saved remote identity keys.
+func (s *Store) ListIdentities(ctx context.Context) ([]*protocol.SignalAddress, error) {
+	defer s.lock()()
+
+	return sortedAddresses(maps.Keys(s.data.identities)), nil
+}
+
+// ListPreKeys returns the sorted IDs of all local prekeys.
+func (s *Store) ListPreKeys(ctx context.Context) ([]uint32, error) {
+	defer s.lock()()
+
+	return slices.Sorted(maps.Keys(s.data.preKeys)), nil
+}
+
+// ListKyberPreKeys returns the sorted IDs of all local kyber prekeys.
+func (s *Store) ListKyberPreKeys(ctx context.Context) ([]uint32, error) {
+	defer s.lock()()
+
+	return slices.Sorted(maps.Keys(s.data.kyberPreKeys)), nil
+}
+
+// ListSessions returns the addresses of all session records.
+func (s *Store) ListSessions(ctx context.Context) ([]*protocol.SignalAddress, error) {
+	defer s.lock()()
+
+	return sortedAddresses(maps.Keys(s.data.sessions)), nil
+}
+
+// ListSenderKeys returns the names of all sender key records.
+func (s *Store) ListSenderKeys(ctx context.Context) ([]*protocol.SenderKeyName, error) {
+	defer s.lock()()
+
+	ids := slices.SortedFunc(maps.Keys(s.data.senderKeys), func(a, b senderKeyID) int {
+		return cmp.Or(cmp.Compare(a.groupID, b.groupID), compareAddresses(a.sender, b.sender))
+	})
+	senderKeyNames := make([]*protocol.SenderKeyName, len(ids))
+	for i, id := range ids {
+		sender := id.sender
+		senderKeyNames[i] = protocol.NewSenderKeyName(id.groupID, &sender)
+	}
+	return senderKeyNames, nil
+}
+
+// sortedAddresses returns pointers to the given addresses sorted by name
+// and device ID.
+func sortedAddresses(addresses iter.Seq[protocol.SignalAddress]) []*protocol.SignalAddress {
+	var sorted []*protocol.SignalAddress
+	for _, address := range slices.SortedFunc(addresses, compareAddresses) {
+		sorted = append(sorted, &address)
+	}
+	return sorted
+}
+
+func compareAddresses(a, b protocol.SignalAddress) int {
+	return cmp.Or(cmp.Compare(a.Name(), b.Name()), cmp.Compare(a.DeviceID(), b.DeviceID()))
+}
@@ -0,0 +1,147 @@
+// Package memstore provides a thread-safe in-memory implementation of all
+// the Signal protocol store interfaces. It is useful for tests and for
+// clients that persist the whole state themselves using snapshots.
+package memstore
+
+import (
+	"context"
+	"sync"
+
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// NewStore returns a new empty store for the given local identity. Records
+// are kept serialized with the given serializer, so records returned by
+// the store can be modified without affecting the stored copy.
+func NewStore(identityKeyPair *identity.KeyPair, registrationID uint32, serializer *serialize.Serializer) *Store {
+	return &Store{
+		identityKeyPair: identityKeyPair,
+		registrationID:  registrationID,
+		serializer:      serializer,
+		data:            newData(),
+	}
+}
+
+// Store is an in-memory implementation of store.SignalProtocol and the
+// optional kyber prekey, transaction, identity loading, last resort usage,
+// serialized loading and record listing interfaces. It is safe for
+// concurrent use.
+type Store struct {
+	identityKeyPair *identity.KeyPair
+	registrationID  uint32
+	serializer      *serialize.Serializer
+
+	data     *data
+	dataLock sync.Mutex
+}
+
+// data contains the contents of a store.
+type data struct {
+	identities     map[protocol.SignalAddress]*identity.Key
+	preKeys        map[uint32][]byte
+	signedPreKeys  map[uint32][]byte
+	kyberPreKeys   map[uint32][]byte
+	sessions       map[protocol.SignalAddress][]byte
+	senderKeys     map[senderKeyID][]byte
+	lastResortUses map[store.PreKeyType]int
+}
+
+// senderKeyID is the map key of a sender key record.
+type senderKeyID struct {
+	groupID string
+	sender  protocol.SignalAddress
+}
+
+func newData() *data {
+	return &data{
+		identities:     make(map[protocol.SignalAddress]*identity.Key),
+		preKeys:        make(map[uint32][]byte),
+		signedPreKeys:  make(map[uint32][]byte),
+		kyberPreKeys:   make(map[uint32][]byte),
+		sessions:       make(map[protocol.SignalAddress][]byte),
+		senderKeys:     make(map[senderKeyID][]byte),
+		lastResortUses: make(map[store.PreKeyType]int),
+	}
+}
+
+type txContextKey struct {
+	store *Store
+}
+
+// transaction is the undo log of a running transaction.
+type transaction struct {
+	undo []func()
+}
+
+// lock locks the data of the store for a single operation and returns a
+// function to unlock it.
+func (s *Store) lock() func() {
+	s.dataLock.Lock()
+	return s.dataLock.Unlock
+}
+
+// WithTx runs the given function inside a transaction. All changes made
+// inside it are rolled back if the function returns an error.
+//
+// Only the records that the transaction changes are logged and restored, so
+// transactions don't block each other. They aren't isolated either: other
+// operations see the changes before the transaction is done, and a rollback
+// restores the records it changed even if they were changed again by
+// another operation in the meantime. The session and group ciphers hold a
+// lock for the address or sender key name during their transactions, so
+// they never change the same records at the same time.
+func (s *Store) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txContextKey{s}) != nil {
+		return fn(ctx)
+	}
+
+	tx := &transaction{}
+	err := fn(context.WithValue(ctx, txContextKey{s}, tx))
+	if err != nil {
+		defer s.lock()()
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+	}
+	return err
+}
+
+// onRollback records a function that undoes a change if the transaction of
+// the given context is rolled back. The caller must hold the data lock.
+func (s *Store) onRollback(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(txContextKey{s}).(*transaction); ok {
+		tx.undo = append(tx.undo, undo)
+	}
+}
+
+// set sets the value of a key in one of the maps of the store's data, and
+// records how to restore the previous value if the transaction is rolled
+// back. The caller must hold the data lock.
+func set[K comparable, V any](ctx context.Context, s *Store, m map[K]V, key K, value V) {
+	recordPrevious(ctx, s, m, key)
+	m[key] = value
+}
+
+// remove deletes a key from one of the maps of the store's data, and
+// records how to restore it if the transaction is rolled back. The caller
+// must hold the data lock.
+func remove[K comparable, V any](ctx context.Context, s *Store, m map[K]V, key K) {
+	recordPrevious(ctx, s, m, key)
+	delete(m, key)
+}
+
+// recordPrevious records how to restore the current value of the given key
+// if the transaction of the given context is rolled back.
+func recordPrevious[K comparable, V any](ctx context.Context, s *Store, m map[K]V, key K) {
+	previous, existed := m[key]
+	s.onRollback(ctx, func() {
+		if existed {
+			m[key] = previous
+		} else {
+			delete(m, key)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package memstore
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// LoadPreKey loads a local prekey, or returns nil if there is none with
+// the given ID.
+func (s *Store) LoadPreKey(ctx context.Context, preKeyID uint32) (*record.PreKey, error) {
+	defer s.lock()()
+
+	serialized, ok := s.data.preKeys[preKeyID]
+	if !ok {
+		return nil, nil
+	}
+	return record.NewPreKeyFromBytes(serialized, s.serializer.PreKeyRecord)
+}
+
+// StorePreKey stores a local prekey.
+func (s *Store) StorePreKey(ctx context.Context, preKeyID uint32, preKeyRecord *record.PreKey) error {
+	defer s.lock()()
+
+	set(ctx, s, s.data.preKeys, preKeyID, preKeyRecord.Serialize())
+	return nil
+}
+
+// ContainsPreKey returns true if the store has a prekey with the given ID.
+func (s *Store) ContainsPreKey(ctx context.Context, preKeyID uint32) (bool, error) {
+	defer s.lock()()
+
+	_, ok := s.data.preKeys[preKeyID]
+	return ok, nil
+}
+
+// RemovePreKey removes a local prekey.
+func (s *Store) RemovePreKey(ctx context.Context, preKeyID uint32) error {
+	defer s.lock()()
+
+	remove(ctx, s, s.data.preKeys, preKeyID)
+	return nil
+}
+
+// LoadSignedPreKey loads a local signed prekey, or returns nil if there is
+// none with the given ID.
+func (s *Store) LoadSignedPreKey(ctx context.Context, signedPreKeyID uint32) (*record.SignedPreKey, error) {
+	defer s.lock()()
+
+	serialized, ok := s.data.signedPreKeys[signedPreKeyID]
+	if !ok {
+		return nil, nil
+	}
+	return record.NewSignedPreKeyFromBytes(serialized, s.serializer.SignedPreKeyRecord)
+}
+
+// LoadSignedPreKeys loads all local signed prekeys sorted by ID.
+func (s *Store) LoadSignedPreKeys(ctx context.Context) ([]*record.SignedPreKey, error) {
+	defer s.lock()()
+
+	var signedPreKeys []*record.SignedPreKey
+	for _, signedPreKeyID := range slices.Sorted(maps.Keys(s.data.signedPreKeys)) {
+		signedPreKey, err := record.NewSignedPreKeyFromBytes(s.data.signedPreKeys[signedPreKeyID], s.serializer.SignedPreKeyRecord)
+		if err != nil {
+			return nil, err
+		}
+		signedPreKeys = append(signedPreKeys, signedPreKey)
+	}
+	return signedPreKeys, nil
+}
+
+// StoreSignedPreKey stores a local signed prekey.
+func (s *Store) StoreSignedPreKey(ctx context.Context, signedPreKeyID uint32, signedPreKeyRecord *record.SignedPreKey) error {
+	defer s.lock()()
+
+	set(ctx, s, s.data.signedPreKeys, signedPreKeyID, signedPreKeyRecord.Serialize())
+	return nil
+}
+
+// ContainsSignedPreKey returns true if the store has a signed prekey with
+// the given ID.
+func (s *Store) ContainsSignedPreKey(ctx context.Context, signedPreKeyID uint32) (bool, error) {
+	defer s.lock()()
+
+	_, ok := s.data.signedPreKeys[signedPreKeyID]
+	return ok, nil
+}
+
+// RemoveSignedPreKey removes a local signed prekey.
+func (s *Store) RemoveSignedPreKey(ctx context.Context, signedPreKeyID uint32) error {
+	defer s.lock()()
+
+	remove(ctx, s, s.data.signedPreKeys, signedPreKeyID)
+	return nil
+}
+
+// LoadKyberPreKey loads a local kyber prekey, or returns nil if there is
+// none with the given ID.
+func (s *Store) LoadKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (*record.KyberPreKey, error) {
+	defer s.lock()()
+
+	serialized, ok := s.data.kyberPreKeys[kyberPreKeyID]
+	if !ok {
+		return nil, nil
+	}
+	return record.NewKyberPreKeyFromBytes(serialized, s.serializer.KyberPreKeyRecord)
+}
+
+// StoreKyberPreKey stores a local kyber prekey.
+func (s *Store) StoreKyberPreKey(ctx context.Context, kyberPreKeyID uint32, kyberPreKeyRecord *record.KyberPreKey) error {
+	defer s.lock()()
+
+	set(ctx, s, s.data.kyberPreKeys, kyberPreKeyID, kyberPreKeyRecord.Serialize())
+	return nil
+}
+
+// ContainsKyberPreKey returns true if the store has a kyber prekey with the
+// given ID.
+func (s *Store) ContainsKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (bool, error) {
+	defer s.lock()()
+
+	_, ok := s.data.kyberPreKeys[kyberPreKeyID]
+	return ok, nil
+}
+
+// MarkKyberPreKeyUsed removes a one-time kyber prekey after a session was
+// built with it.
+func (s *Store) MarkKyberPreKeyUsed(ctx context.Context, kyberPreKeyID uint32) error {
+	defer s.lock()()
+
+	remove(ctx, s, s.data.kyberPreKeys, kyberPreKeyID)
+	return nil
+}
+
+// RecordLastResortPreKeyUse counts a use of a last resort prekey.
+func (s *Store) RecordLastResortPreKeyUse(ctx context.Context, address *protocol.SignalAddress, keyType store.PreKeyType, preKeyID uint32) error {
+	defer s.lock()()
+
+	// The count is decremented on rollback instead of restored, so that
+	// uses recorded by other transactions are kept.
+	lastResortUses := s.data.lastResortUses
+	lastResortUses[keyType]++
+	s.onRollback(ctx, func() {
+		lastResortUses[keyType]--
+	})
+	return nil
+}
+
+// LastResortPreKeyUses returns how many sessions have been built with the
+// last resort prekey of the given type.
+func (s *Store) LastResortPreKeyUses(keyType store.PreKeyType) int {
+	defer s.lock()()
+
+	return s.data.lastResortUses[keyType]
+}
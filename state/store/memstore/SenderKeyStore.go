@@ -0,0 +1,33 @@
+package memstore
+
+import (
+	"context"
+
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/protocol"
+)
+
+// StoreSenderKey stores the sender key record for the given group and
+// sender.
+func (s *Store) StoreSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName, keyRecord *groupRecord.SenderKey) error {
+	defer s.lock()()
+
+	set(ctx, s, s.data.senderKeys, newSenderKeyID(senderKeyName), keyRecord.Serialize())
+	return nil
+}
+
+// LoadSenderKey loads the sender key record for the given group and sender,
+// or returns a new empty record if there is none.
+func (s *Store) LoadSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) (*groupRecord.SenderKey, error) {
+	defer s.lock()()
+
+	serialized, ok := s.data.senderKeys[newSenderKeyID(senderKeyName)]
+	if !ok {
+		return groupRecord.NewSenderKey(s.serializer.SenderKeyRecord, s.serializer.SenderKeyState), nil
+	}
+	return groupRecord.NewSenderKeyFromBytes(serialized, s.serializer.SenderKeyRecord, s.serializer.SenderKeyState)
+}
+
+func newSenderKeyID(senderKeyName *protocol.SenderKeyName) senderKeyID {
+	return senderKeyID{groupID: senderKeyName.GroupID(), sender: *senderKeyName.Sender()}
+}
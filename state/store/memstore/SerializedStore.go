@@ -0,0 +1,61 @@
+package memstore
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// LoadSerializedSession returns the stored bytes of the session record for
+// the given address, or nil if there is no session.
+func (s *Store) LoadSerializedSession(ctx context.Context, address *protocol.SignalAddress) ([]byte, error) {
+	defer s.lock()()
+
+	return slices.Clone(s.data.sessions[*address]), nil
+}
+
+// LoadSerializedPreKey returns the stored bytes of a local prekey, or nil
+// if there is none with the given ID.
+func (s *Store) LoadSerializedPreKey(ctx context.Context, preKeyID uint32) ([]byte, error) {
+	defer s.lock()()
+
+	return slices.Clone(s.data.preKeys[preKeyID]), nil
+}
+
+// LoadSerializedSignedPreKey returns the stored bytes of a local signed
+// prekey, or nil if there is none with the given ID.
+func (s *Store) LoadSerializedSignedPreKey(ctx context.Context, signedPreKeyID uint32) ([]byte, error) {
+	defer s.lock()()
+
+	return slices.Clone(s.data.signedPreKeys[signedPreKeyID]), nil
+}
+
+// LoadSerializedSignedPreKeys returns the stored bytes of all local signed
+// prekeys by ID.
+func (s *Store) LoadSerializedSignedPreKeys(ctx context.Context) (map[uint32][]byte, error) {
+	defer s.lock()()
+
+	signedPreKeys := maps.Clone(s.data.signedPreKeys)
+	for signedPreKeyID, serialized := range signedPreKeys {
+		signedPreKeys[signedPreKeyID] = slices.Clone(serialized)
+	}
+	return signedPreKeys, nil
+}
+
+// LoadSerializedKyberPreKey returns the stored bytes of a local kyber
+// prekey, or nil if there is none with the given ID.
+func (s *Store) LoadSerializedKyberPreKey(ctx context.Context, kyberPreKeyID uint32) ([]byte, error) {
+	defer s.lock()()
+
+	return slices.Clone(s.data.kyberPreKeys[kyberPreKeyID]), nil
+}
+
+// LoadSerializedSenderKey returns the stored bytes of the sender key
+// record for the given group and sender, or nil if there is none.
+func (s *Store) LoadSerializedSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) ([]byte, error) {
+	defer s.lock()()
+
+	return slices.Clone(s.data.senderKeys[newSenderKeyID(senderKeyName)]), nil
+}
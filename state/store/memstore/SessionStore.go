@@ -0,0 +1,75 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// defaultDeviceID is the device ID that is not included in sub device
+// sessions.
+const defaultDeviceID = 1
+
+// LoadSession loads the session record for the given address, or returns
+// a new empty record if there is no session.
+func (s *Store) LoadSession(ctx context.Context, address *protocol.SignalAddress) (*record.Session, error) {
+	defer s.lock()()
+
+	serialized, ok := s.data.sessions[*address]
+	if !ok {
+		return record.NewSession(s.serializer.Session, s.serializer.State), nil
+	}
+	return record.NewSessionFromBytes(serialized, s.serializer.Session, s.serializer.State)
+}
+
+// GetSubDeviceSessions returns the sorted device IDs of the sessions with
+// the given name, except for the default device.
+func (s *Store) GetSubDeviceSessions(ctx context.Context, name string) ([]uint32, error) {
+	defer s.lock()()
+
+	var deviceIDs []uint32
+	for address := range s.data.sessions {
+		if address.Name() == name && address.DeviceID() != defaultDeviceID {
+			deviceIDs = append(deviceIDs, address.DeviceID())
+		}
+	}
+	slices.Sort(deviceIDs)
+	return deviceIDs, nil
+}
+
+// StoreSession stores the session record for the given address.
+func (s *Store) StoreSession(ctx context.Context, remoteAddress *protocol.SignalAddress, sessionRecord *record.Session) error {
+	defer s.lock()()
+
+	set(ctx, s, s.data.sessions, *remoteAddress, sessionRecord.Serialize())
+	return nil
+}
+
+// ContainsSession returns true if there is a session record for the given
+// address.
+func (s *Store) ContainsSession(ctx context.Context, remoteAddress *protocol.SignalAddress) (bool, error) {
+	defer s.lock()()
+
+	_, ok := s.data.sessions[*remoteAddress]
+	return ok, nil
+}
+
+// DeleteSession removes the session record for the given address.
+func (s *Store) DeleteSession(ctx context.Context, remoteAddress *protocol.SignalAddress) error {
+	defer s.lock()()
+
+	remove(ctx, s, s.data.sessions, *remoteAddress)
+	return nil
+}
+
+// DeleteAllSessions removes all session records.
+func (s *Store) DeleteAllSessions(ctx context.Context) error {
+	defer s.lock()()
+
+	for address := range s.data.sessions {
+		remove(ctx, s, s.data.sessions, address)
+	}
+	return nil
+}
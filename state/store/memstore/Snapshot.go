@@ -0,0 +1,42 @@
+package memstore
+
+import (
+	"maps"
+)
+
+// Snapshot is a copy of the contents of a store at one point in time.
+type Snapshot struct {
+	data *data
+}
+
+// clone returns a copy of the data. The stored records are never modified
+// in place, so the maps can be copied shallowly.
+func (d *data) clone() *data {
+	return &data{
+		identities:     maps.Clone(d.identities),
+		preKeys:        maps.Clone(d.preKeys),
+		signedPreKeys:  maps.Clone(d.signedPreKeys),
+		kyberPreKeys:   maps.Clone(d.kyberPreKeys),
+		sessions:       maps.Clone(d.sessions),
+		senderKeys:     maps.Clone(d.senderKeys),
+		lastResortUses: maps.Clone(d.lastResortUses),
+	}
+}
+
+// Snapshot returns a copy of the current contents of the store, including
+// the changes of transactions that haven't finished yet.
+func (s *Store) Snapshot() *Snapshot {
+	defer s.lock()()
+
+	return &Snapshot{data: s.data.clone()}
+}
+
+// Restore replaces the contents of the store with the given snapshot. The
+// snapshot can be restored multiple times. It should not be called while a
+// transaction is running, as rolling back the transaction wouldn't undo
+// its changes in the restored contents.
+func (s *Store) Restore(snapshot *Snapshot) {
+	defer s.lock()()
+
+	s.data = snapshot.data.clone()
+}
@@ -0,0 +1,56 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/protocol"
+)
+
+// GetIdentityKeyPair returns the local identity key pair.
+func (s *Store) GetIdentityKeyPair() *identity.KeyPair {
+	return s.identityKeyPair
+}
+
+// GetLocalRegistrationID returns the local registration ID.
+func (s *Store) GetLocalRegistrationID() uint32 {
+	return s.registrationID
+}
+
+// SaveIdentity saves the identity key of a remote client, replacing the
+// previously trusted key.
+func (s *Store) SaveIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) error {
+	return s.exec(ctx, `
+		INSERT INTO signal_identity_keys (account_id, their_name, their_device_id, identity_key) VALUES (?, ?, ?, ?)
+		ON CONFLICT (account_id, their_name, their_device_id) DO UPDATE SET identity_key=excluded.identity_key
+	`, s.accountID, address.Name(), address.DeviceID(), identityKey.Serialize())
+}
+
+// IsTrustedIdentity returns true if no identity key has been saved for the
+// remote client yet (trust on first use) or if the given key is the saved
+// one.
+func (s *Store) IsTrustedIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) (bool, error) {
+	trusted, err := s.LoadIdentity(ctx, address)
+	if err != nil || trusted == nil {
+		return err == nil, err
+	}
+	return trusted.Fingerprint() == identityKey.Fingerprint(), nil
+}
+
+// LoadIdentity returns the saved identity key of a remote client, or nil if
+// there is none.
+func (s *Store) LoadIdentity(ctx context.Context, address *protocol.SignalAddress) (*identity.Key, error) {
+	serialized, err := s.loadBytes(ctx, `
+		SELECT identity_key FROM signal_identity_keys WHERE account_id=? AND their_name=? AND their_device_id=?
+	`, s.accountID, address.Name(), address.DeviceID())
+	if err != nil || serialized == nil {
+		return nil, err
+	}
+	publicKey, err := ecc.DecodePoint(serialized, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode identity key of %s: %w", address, err)
+	}
+	return identity.NewKey(publicKey), nil
+}
@@ -0,0 +1,95 @@
+package sqlstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// ListIdentities returns the addresses of all saved remote identity keys.
+func (s *Store) ListIdentities(ctx context.Context) ([]*protocol.SignalAddress, error) {
+	return s.listAddresses(ctx, `
+		SELECT their_name, their_device_id FROM signal_identity_keys
+		WHERE account_id=? ORDER BY their_name, their_device_id
+	`)
+}
+
+// ListPreKeys returns the sorted IDs of all local prekeys.
+func (s *Store) ListPreKeys(ctx context.Context) ([]uint32, error) {
+	return s.listKeys(ctx, "signal_prekeys")
+}
+
+// ListKyberPreKeys returns the sorted IDs of all local kyber prekeys.
+func (s *Store) ListKyberPreKeys(ctx context.Context) ([]uint32, error) {
+	return s.listKeys(ctx, "signal_kyber_prekeys")
+}
+
+// ListSessions returns the addresses of all session records.
+func (s *Store) ListSessions(ctx context.Context) ([]*protocol.SignalAddress, error) {
+	return s.listAddresses(ctx, `
+		SELECT their_name, their_device_id FROM signal_sessions
+		WHERE account_id=? ORDER BY their_name, their_device_id
+	`)
+}
+
+// ListSenderKeys returns the names of all sender key records.
+func (s *Store) ListSenderKeys(ctx context.Context) ([]*protocol.SenderKeyName, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.query(`
+		SELECT group_id, sender_name, sender_device_id FROM signal_sender_keys
+		WHERE account_id=? ORDER BY group_id, sender_name, sender_device_id
+	`), s.accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var senderKeyNames []*protocol.SenderKeyName
+	for rows.Next() {
+		var groupID, senderName string
+		var senderDeviceID uint32
+		if err = rows.Scan(&groupID, &senderName, &senderDeviceID); err != nil {
+			return nil, err
+		}
+		senderKeyNames = append(senderKeyNames, protocol.NewSenderKeyName(groupID, protocol.NewSignalAddress(senderName, senderDeviceID)))
+	}
+	return senderKeyNames, rows.Err()
+}
+
+func (s *Store) listKeys(ctx context.Context, table string) ([]uint32, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.query(
+		"SELECT key_id FROM "+table+" WHERE account_id=? ORDER BY key_id",
+	), s.accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keyIDs []uint32
+	for rows.Next() {
+		var keyID uint32
+		if err = rows.Scan(&keyID); err != nil {
+			return nil, err
+		}
+		keyIDs = append(keyIDs, keyID)
+	}
+	return keyIDs, rows.Err()
+}
+
+func (s *Store) listAddresses(ctx context.Context, query string) ([]*protocol.SignalAddress, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.query(query), s.accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []*protocol.SignalAddress
+	for rows.Next() {
+		var name string
+		var deviceID uint32
+		if err = rows.Scan(&name, &deviceID); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, protocol.NewSignalAddress(name, deviceID))
+	}
+	return addresses, rows.Err()
+}
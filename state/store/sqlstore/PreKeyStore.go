@@ -0,0 +1,132 @@
+package sqlstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/state/record"
+)
+
+// LoadPreKey loads a local prekey, or returns nil if there is none with
+// the given ID.
+func (s *Store) LoadPreKey(ctx context.Context, preKeyID uint32) (*record.PreKey, error) {
+	serialized, err := s.LoadSerializedPreKey(ctx, preKeyID)
+	if err != nil || serialized == nil {
+		return nil, err
+	}
+	return record.NewPreKeyFromBytes(serialized, s.serializer.PreKeyRecord)
+}
+
+// StorePreKey stores a local prekey.
+func (s *Store) StorePreKey(ctx context.Context, preKeyID uint32, preKeyRecord *record.PreKey) error {
+	return s.storeKey(ctx, "signal_prekeys", preKeyID, preKeyRecord.Serialize())
+}
+
+// ContainsPreKey returns true if the store has a prekey with the given ID.
+func (s *Store) ContainsPreKey(ctx context.Context, preKeyID uint32) (bool, error) {
+	return s.containsKey(ctx, "signal_prekeys", preKeyID)
+}
+
+// RemovePreKey removes a local prekey.
+func (s *Store) RemovePreKey(ctx context.Context, preKeyID uint32) error {
+	return s.removeKey(ctx, "signal_prekeys", preKeyID)
+}
+
+// LoadSignedPreKey loads a local signed prekey, or returns nil if there is
+// none with the given ID.
+func (s *Store) LoadSignedPreKey(ctx context.Context, signedPreKeyID uint32) (*record.SignedPreKey, error) {
+	serialized, err := s.LoadSerializedSignedPreKey(ctx, signedPreKeyID)
+	if err != nil || serialized == nil {
+		return nil, err
+	}
+	return record.NewSignedPreKeyFromBytes(serialized, s.serializer.SignedPreKeyRecord)
+}
+
+// LoadSignedPreKeys loads all local signed prekeys sorted by ID.
+func (s *Store) LoadSignedPreKeys(ctx context.Context) ([]*record.SignedPreKey, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.query(
+		"SELECT record FROM signal_signed_prekeys WHERE account_id=? ORDER BY key_id",
+	), s.accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signedPreKeys []*record.SignedPreKey
+	for rows.Next() {
+		var serialized []byte
+		if err = rows.Scan(&serialized); err != nil {
+			return nil, err
+		}
+		signedPreKey, err := record.NewSignedPreKeyFromBytes(serialized, s.serializer.SignedPreKeyRecord)
+		if err != nil {
+			return nil, err
+		}
+		signedPreKeys = append(signedPreKeys, signedPreKey)
+	}
+	return signedPreKeys, rows.Err()
+}
+
+// StoreSignedPreKey stores a local signed prekey.
+func (s *Store) StoreSignedPreKey(ctx context.Context, signedPreKeyID uint32, signedPreKeyRecord *record.SignedPreKey) error {
+	return s.storeKey(ctx, "signal_signed_prekeys", signedPreKeyID, signedPreKeyRecord.Serialize())
+}
+
+// ContainsSignedPreKey returns true if the store has a signed prekey with
+// the given ID.
+func (s *Store) ContainsSignedPreKey(ctx context.Context, signedPreKeyID uint32) (bool, error) {
+	return s.containsKey(ctx, "signal_signed_prekeys", signedPreKeyID)
+}
+
+// RemoveSignedPreKey removes a local signed prekey.
+func (s *Store) RemoveSignedPreKey(ctx context.Context, signedPreKeyID uint32) error {
+	return s.removeKey(ctx, "signal_signed_prekeys", signedPreKeyID)
+}
+
+// LoadKyberPreKey loads a local kyber prekey, or returns nil if there is
+// none with the given ID.
+func (s *Store) LoadKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (*record.KyberPreKey, error) {
+	serialized, err := s.LoadSerializedKyberPreKey(ctx, kyberPreKeyID)
+	if err != nil || serialized == nil {
+		return nil, err
+	}
+	return record.NewKyberPreKeyFromBytes(serialized, s.serializer.KyberPreKeyRecord)
+}
+
+// StoreKyberPreKey stores a local kyber prekey.
+func (s *Store) StoreKyberPreKey(ctx context.Context, kyberPreKeyID uint32, kyberPreKeyRecord *record.KyberPreKey) error {
+	return s.storeKey(ctx, "signal_kyber_prekeys", kyberPreKeyID, kyberPreKeyRecord.Serialize())
+}
+
+// ContainsKyberPreKey returns true if the store has a kyber prekey with the
+// given ID.
+func (s *Store) ContainsKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (bool, error) {
+	return s.containsKey(ctx, "signal_kyber_prekeys", kyberPreKeyID)
+}
+
+// MarkKyberPreKeyUsed removes a one-time kyber prekey after a session was
+// built with it.
+func (s *Store) MarkKyberPreKeyUsed(ctx context.Context, kyberPreKeyID uint32) error {
+	return s.removeKey(ctx, "signal_kyber_prekeys", kyberPreKeyID)
+}
+
+// The prekey tables all have the same columns, so the queries only differ
+// in the table name, which is always a constant.
+
+func (s *Store) loadKey(ctx context.Context, table string, keyID uint32) ([]byte, error) {
+	return s.loadBytes(ctx, "SELECT record FROM "+table+" WHERE account_id=? AND key_id=?", s.accountID, keyID)
+}
+
+func (s *Store) storeKey(ctx context.Context, table string, keyID uint32, serialized []byte) error {
+	return s.exec(ctx, `
+		INSERT INTO `+table+` (account_id, key_id, record) VALUES (?, ?, ?)
+		ON CONFLICT (account_id, key_id) DO UPDATE SET record=excluded.record
+	`, s.accountID, keyID, serialized)
+}
+
+func (s *Store) containsKey(ctx context.Context, table string, keyID uint32) (bool, error) {
+	return s.exists(ctx, "SELECT 1 FROM "+table+" WHERE account_id=? AND key_id=?", s.accountID, keyID)
+}
+
+func (s *Store) removeKey(ctx context.Context, table string, keyID uint32) error {
+	return s.exec(ctx, "DELETE FROM "+table+" WHERE account_id=? AND key_id=?", s.accountID, keyID)
+}
@@ -0,0 +1,170 @@
+// Package sqlstore provides an implementation of the Signal protocol store
+// interfaces on database/sql. It supports SQLite and PostgreSQL, and keeps
+// the data of multiple accounts in the same tables.
+//
+// Upgrade must be called before the store is used, to create or update the
+// database schema. Concurrent transactions on SQLite should use immediate
+// locking (e.g. _txlock=immediate with mattn/go-sqlite3) and a busy timeout,
+// so that they wait for each other instead of failing.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/serialize"
+)
+
+// Dialect is the SQL dialect of a database.
+type Dialect int
+
+// Supported dialects
+const (
+	DialectSQLite Dialect = iota
+	DialectPostgres
+)
+
+// ParseDialect returns the dialect for the given database/sql driver name.
+func ParseDialect(driverName string) (Dialect, error) {
+	switch driverName {
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	case "postgres", "pgx":
+		return DialectPostgres, nil
+	default:
+		return 0, fmt.Errorf("unsupported database driver %q", driverName)
+	}
+}
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case DialectSQLite:
+		return "sqlite"
+	case DialectPostgres:
+		return "postgres"
+	default:
+		return "unknown"
+	}
+}
+
+// NewStore returns a new store for the given account in the given
+// database. Records are serialized with the given serializer.
+func NewStore(db *sql.DB, dialect Dialect, accountID string, identityKeyPair *identity.KeyPair,
+	registrationID uint32, serializer *serialize.Serializer) *Store {
+
+	return &Store{
+		db:              db,
+		dialect:         dialect,
+		accountID:       accountID,
+		identityKeyPair: identityKeyPair,
+		registrationID:  registrationID,
+		serializer:      serializer,
+	}
+}
+
+// Store is an implementation of store.SignalProtocol and the optional kyber
+// prekey, transaction, identity loading, serialized loading and record
+// listing interfaces on database/sql.
+type Store struct {
+	db              *sql.DB
+	dialect         Dialect
+	accountID       string
+	identityKeyPair *identity.KeyPair
+	registrationID  uint32
+	serializer      *serialize.Serializer
+}
+
+// execable is the part of the sql.DB and sql.Tx interfaces that is used
+// by the store.
+type execable interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txContextKey struct {
+	db *sql.DB
+}
+
+// WithTx runs the given function inside a database transaction, which is
+// committed if the function returns nil and rolled back otherwise.
+func (s *Store) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txContextKey{s.db}) != nil {
+		return fn(ctx)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	err = fn(context.WithValue(ctx, txContextKey{s.db}, tx))
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback also failed: %w)", err, rollbackErr)
+		}
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// conn returns the transaction of the given context, or the database if
+// the context is not inside a transaction.
+func (s *Store) conn(ctx context.Context) execable {
+	if tx, ok := ctx.Value(txContextKey{s.db}).(*sql.Tx); ok {
+		return tx
+	}
+	return s.db
+}
+
+// query converts the ? placeholders in the given query to the format of
+// the store's dialect.
+func (s *Store) query(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+	var builder strings.Builder
+	n := 0
+	for _, char := range query {
+		if char == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+		} else {
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
+
+// exec runs a query that doesn't return rows.
+func (s *Store) exec(ctx context.Context, query string, args ...any) error {
+	_, err := s.conn(ctx).ExecContext(ctx, s.query(query), args...)
+	return err
+}
+
+// loadBytes returns the single bytes value returned by the given query, or
+// nil if there are no rows.
+func (s *Store) loadBytes(ctx context.Context, query string, args ...any) ([]byte, error) {
+	var value []byte
+	err := s.conn(ctx).QueryRowContext(ctx, s.query(query), args...).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return value, err
+}
+
+// exists returns true if the given query returns any rows.
+func (s *Store) exists(ctx context.Context, query string, args ...any) (bool, error) {
+	var one int
+	err := s.conn(ctx).QueryRowContext(ctx, s.query(query), args...).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
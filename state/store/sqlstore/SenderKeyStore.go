@@ -0,0 +1,30 @@
+package sqlstore
+
+import (
+	"context"
+
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
+	"go.mau.fi/libsignal/protocol"
+)
+
+// StoreSenderKey stores the sender key record for the given group and
+// sender.
+func (s *Store) StoreSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName, keyRecord *groupRecord.SenderKey) error {
+	sender := senderKeyName.Sender()
+	return s.exec(ctx, `
+		INSERT INTO signal_sender_keys (account_id, group_id, sender_name, sender_device_id, record) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (account_id, group_id, sender_name, sender_device_id) DO UPDATE SET record=excluded.record
+	`, s.accountID, senderKeyName.GroupID(), sender.Name(), sender.DeviceID(), keyRecord.Serialize())
+}
+
+// LoadSenderKey loads the sender key record for the given group and sender,
+// or returns a new empty record if there is none.
+func (s *Store) LoadSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) (*groupRecord.SenderKey, error) {
+	serialized, err := s.LoadSerializedSenderKey(ctx, senderKeyName)
+	if err != nil {
+		return nil, err
+	} else if serialized == nil {
+		return groupRecord.NewSenderKey(s.serializer.SenderKeyRecord, s.serializer.SenderKeyState), nil
+	}
+	return groupRecord.NewSenderKeyFromBytes(serialized, s.serializer.SenderKeyRecord, s.serializer.SenderKeyState)
+}
@@ -0,0 +1,66 @@
+package sqlstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+)
+
+// LoadSerializedSession returns the stored bytes of the session record for
+// the given address, or nil if there is no session.
+func (s *Store) LoadSerializedSession(ctx context.Context, address *protocol.SignalAddress) ([]byte, error) {
+	return s.loadBytes(ctx, `
+		SELECT record FROM signal_sessions WHERE account_id=? AND their_name=? AND their_device_id=?
+	`, s.accountID, address.Name(), address.DeviceID())
+}
+
+// LoadSerializedPreKey returns the stored bytes of a local prekey, or nil
+// if there is none with the given ID.
+func (s *Store) LoadSerializedPreKey(ctx context.Context, preKeyID uint32) ([]byte, error) {
+	return s.loadKey(ctx, "signal_prekeys", preKeyID)
+}
+
+// LoadSerializedSignedPreKey returns the stored bytes of a local signed
+// prekey, or nil if there is none with the given ID.
+func (s *Store) LoadSerializedSignedPreKey(ctx context.Context, signedPreKeyID uint32) ([]byte, error) {
+	return s.loadKey(ctx, "signal_signed_prekeys", signedPreKeyID)
+}
+
+// LoadSerializedSignedPreKeys returns the stored bytes of all local signed
+// prekeys by ID.
+func (s *Store) LoadSerializedSignedPreKeys(ctx context.Context) (map[uint32][]byte, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.query(
+		"SELECT key_id, record FROM signal_signed_prekeys WHERE account_id=?",
+	), s.accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signedPreKeys := make(map[uint32][]byte)
+	for rows.Next() {
+		var signedPreKeyID uint32
+		var serialized []byte
+		if err = rows.Scan(&signedPreKeyID, &serialized); err != nil {
+			return nil, err
+		}
+		signedPreKeys[signedPreKeyID] = serialized
+	}
+	return signedPreKeys, rows.Err()
+}
+
+// LoadSerializedKyberPreKey returns the stored bytes of a local kyber
+// prekey, or nil if there is none with the given ID.
+func (s *Store) LoadSerializedKyberPreKey(ctx context.Context, kyberPreKeyID uint32) ([]byte, error) {
+	return s.loadKey(ctx, "signal_kyber_prekeys", kyberPreKeyID)
+}
+
+// LoadSerializedSenderKey returns the stored bytes of the sender key
+// record for the given group and sender, or nil if there is none.
+func (s *Store) LoadSerializedSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) ([]byte, error) {
+	sender := senderKeyName.Sender()
+	return s.loadBytes(ctx, `
+		SELECT record FROM signal_sender_keys
+		WHERE account_id=? AND group_id=? AND sender_name=? AND sender_device_id=?
+	`, s.accountID, senderKeyName.GroupID(), sender.Name(), sender.DeviceID())
+}
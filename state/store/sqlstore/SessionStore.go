@@ -0,0 +1,76 @@
+package sqlstore
+
+import (
+	"context"
+
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// defaultDeviceID is the device ID that is not included in sub device
+// sessions.
+const defaultDeviceID = 1
+
+// LoadSession loads the session record for the given address, or returns
+// a new empty record if there is no session.
+func (s *Store) LoadSession(ctx context.Context, address *protocol.SignalAddress) (*record.Session, error) {
+	serialized, err := s.LoadSerializedSession(ctx, address)
+	if err != nil {
+		return nil, err
+	} else if serialized == nil {
+		return record.NewSession(s.serializer.Session, s.serializer.State), nil
+	}
+	return record.NewSessionFromBytes(serialized, s.serializer.Session, s.serializer.State)
+}
+
+// GetSubDeviceSessions returns the sorted device IDs of the sessions with
+// the given name, except for the default device.
+func (s *Store) GetSubDeviceSessions(ctx context.Context, name string) ([]uint32, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, s.query(`
+		SELECT their_device_id FROM signal_sessions
+		WHERE account_id=? AND their_name=? AND their_device_id<>?
+		ORDER BY their_device_id
+	`), s.accountID, name, defaultDeviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deviceIDs []uint32
+	for rows.Next() {
+		var deviceID uint32
+		if err = rows.Scan(&deviceID); err != nil {
+			return nil, err
+		}
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+	return deviceIDs, rows.Err()
+}
+
+// StoreSession stores the session record for the given address.
+func (s *Store) StoreSession(ctx context.Context, remoteAddress *protocol.SignalAddress, sessionRecord *record.Session) error {
+	return s.exec(ctx, `
+		INSERT INTO signal_sessions (account_id, their_name, their_device_id, record) VALUES (?, ?, ?, ?)
+		ON CONFLICT (account_id, their_name, their_device_id) DO UPDATE SET record=excluded.record
+	`, s.accountID, remoteAddress.Name(), remoteAddress.DeviceID(), sessionRecord.Serialize())
+}
+
+// ContainsSession returns true if there is a session record for the given
+// address.
+func (s *Store) ContainsSession(ctx context.Context, remoteAddress *protocol.SignalAddress) (bool, error) {
+	return s.exists(ctx, `
+		SELECT 1 FROM signal_sessions WHERE account_id=? AND their_name=? AND their_device_id=?
+	`, s.accountID, remoteAddress.Name(), remoteAddress.DeviceID())
+}
+
+// DeleteSession removes the session record for the given address.
+func (s *Store) DeleteSession(ctx context.Context, remoteAddress *protocol.SignalAddress) error {
+	return s.exec(ctx, `
+		DELETE FROM signal_sessions WHERE account_id=? AND their_name=? AND their_device_id=?
+	`, s.accountID, remoteAddress.Name(), remoteAddress.DeviceID())
+}
+
+// DeleteAllSessions removes all session records of the account.
+func (s *Store) DeleteAllSessions(ctx context.Context) error {
+	return s.exec(ctx, "DELETE FROM signal_sessions WHERE account_id=?", s.accountID)
+}
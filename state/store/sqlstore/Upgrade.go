@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// migrations are the schema changes of each database version. The first
+// migration creates the initial schema. New migrations must only be added
+// to the end.
+var migrations = []string{
+	`CREATE TABLE signal_identity_keys (
+		account_id      TEXT   NOT NULL,
+		their_name      TEXT   NOT NULL,
+		their_device_id BIGINT NOT NULL,
+		identity_key    $BYTES NOT NULL,
+		PRIMARY KEY (account_id, their_name, their_device_id)
+	);
+	CREATE TABLE signal_prekeys (
+		account_id TEXT   NOT NULL,
+		key_id     BIGINT NOT NULL,
+		record     $BYTES NOT NULL,
+		PRIMARY KEY (account_id, key_id)
+	);
+	CREATE TABLE signal_signed_prekeys (
+		account_id TEXT   NOT NULL,
+		key_id     BIGINT NOT NULL,
+		record     $BYTES NOT NULL,
+		PRIMARY KEY (account_id, key_id)
+	);
+	CREATE TABLE signal_kyber_prekeys (
+		account_id TEXT   NOT NULL,
+		key_id     BIGINT NOT NULL,
+		record     $BYTES NOT NULL,
+		PRIMARY KEY (account_id, key_id)
+	);
+	CREATE TABLE signal_sessions (
+		account_id      TEXT   NOT NULL,
+		their_name      TEXT   NOT NULL,
+		their_device_id BIGINT NOT NULL,
+		record          $BYTES NOT NULL,
+		PRIMARY KEY (account_id, their_name, their_device_id)
+	);
+	CREATE TABLE signal_sender_keys (
+		account_id       TEXT   NOT NULL,
+		group_id         TEXT   NOT NULL,
+		sender_name      TEXT   NOT NULL,
+		sender_device_id BIGINT NOT NULL,
+		record           $BYTES NOT NULL,
+		PRIMARY KEY (account_id, group_id, sender_name, sender_device_id)
+	);`,
+}
+
+// LatestVersion returns the database schema version that Upgrade migrates to.
+func LatestVersion() int {
+	return len(migrations)
+}
+
+// Upgrade creates the database schema or migrates it to the latest
+// version. Each migration runs in its own transaction.
+func (s *Store) Upgrade(ctx context.Context) error {
+	err := s.exec(ctx, "CREATE TABLE IF NOT EXISTS signal_version (version INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create version table: %w", err)
+	}
+	version, err := s.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version > LatestVersion() {
+		return fmt.Errorf("unsupported database schema version %d (latest known is %d)", version, LatestVersion())
+	}
+
+	for ; version < LatestVersion(); version++ {
+		err = s.WithTx(ctx, func(ctx context.Context) error {
+			return s.migrate(ctx, version)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upgrade database to version %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
+
+// Version returns the current database schema version, or 0 if the schema
+// hasn't been created yet.
+func (s *Store) Version(ctx context.Context) (int, error) {
+	var version int
+	err := s.conn(ctx).QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM signal_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database version: %w", err)
+	}
+	return version, nil
+}
+
+// migrate runs the migration from the given version to the next one.
+func (s *Store) migrate(ctx context.Context, version int) error {
+	bytesType := "BLOB"
+	if s.dialect == DialectPostgres {
+		bytesType = "BYTEA"
+	}
+	for _, statement := range strings.Split(migrations[version], ";") {
+		statement = strings.TrimSpace(strings.ReplaceAll(statement, "$BYTES", bytesType))
+		if statement == "" {
+			continue
+		}
+		if err := s.exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if err := s.exec(ctx, "DELETE FROM signal_version"); err != nil {
+		return err
+	}
+	return s.exec(ctx, "INSERT INTO signal_version (version) VALUES (?)", version+1)
+}
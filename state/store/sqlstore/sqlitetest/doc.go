@@ -0,0 +1,4 @@
+// Package sqlitetest tests sqlstore with a real SQLite database. It is a
+// separate module, so that the main module doesn't depend on the cgo
+// SQLite driver.
+package sqlitetest
@@ -0,0 +1,19 @@
+module go.mau.fi/libsignal/state/store/sqlstore/sqlitetest
+
+go 1.24.0
+
+toolchain go1.24.3
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	go.mau.fi/libsignal v0.0.0
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	golang.org/x/crypto v0.38.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
+
+replace go.mau.fi/libsignal => ../../../../
@@ -0,0 +1,238 @@
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"go.mau.fi/libsignal/groups"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store/sqlstore"
+	"go.mau.fi/libsignal/util/keyhelper"
+)
+
+// openSQLiteDB opens a new SQLite database in a temporary directory.
+func openSQLiteDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "signal.db")+"?_txlock=immediate&_busy_timeout=5000")
+	if err != nil {
+		logger.Error("Unable to open database: ", err)
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// account is an account in a SQL store with the keys that were generated
+// for it.
+type account struct {
+	address         *protocol.SignalAddress
+	identityKeyPair *identity.KeyPair
+	registrationID  uint32
+	preKeys         []*record.PreKey
+	signedPreKey    *record.SignedPreKey
+	kyberPreKey     *record.KyberPreKey
+	store           *sqlstore.Store
+}
+
+// newAccount generates the keys of a new account and returns it with an
+// upgraded SQL store that contains them.
+func newAccount(t *testing.T, db *sql.DB, name string, deviceID uint32, serializer *serialize.Serializer) *account {
+	ctx := context.Background()
+	a := &account{address: protocol.NewSignalAddress(name, deviceID)}
+	a.identityKeyPair, _ = keyhelper.GenerateIdentityKeyPair()
+	a.registrationID = keyhelper.GenerateRegistrationID()
+	a.preKeys, _ = keyhelper.GeneratePreKeys(1, 10, serializer.PreKeyRecord)
+	a.signedPreKey, _ = keyhelper.GenerateSignedPreKey(a.identityKeyPair, 0, serializer.SignedPreKeyRecord)
+	a.kyberPreKey, _ = keyhelper.GenerateKyberPreKey(a.identityKeyPair, 0, serializer.KyberPreKeyRecord)
+
+	a.store = sqlstore.NewStore(db, sqlstore.DialectSQLite, name, a.identityKeyPair, a.registrationID, serializer)
+	if err := a.store.Upgrade(ctx); err != nil {
+		logger.Error("Unable to upgrade database: ", err)
+		t.FailNow()
+	}
+	for _, preKey := range a.preKeys {
+		a.store.StorePreKey(ctx, preKey.ID().Value, preKey)
+	}
+	a.store.StoreSignedPreKey(ctx, a.signedPreKey.ID(), a.signedPreKey)
+	a.store.StoreKyberPreKey(ctx, a.kyberPreKey.ID(), a.kyberPreKey)
+	return a
+}
+
+// bundle returns a prekey bundle with the first prekey of the account.
+func (a *account) bundle() *prekey.Bundle {
+	return prekey.NewBundleWithKyber(
+		a.registrationID,
+		a.address.DeviceID(),
+		a.preKeys[0].ID(),
+		a.signedPreKey.ID(),
+		a.preKeys[0].KeyPair().PublicKey(),
+		a.signedPreKey.KeyPair().PublicKey(),
+		a.signedPreKey.Signature(),
+		a.kyberPreKey.ID(),
+		a.kyberPreKey.KeyPair().PublicKey(),
+		a.kyberPreKey.Signature(),
+		a.identityKeyPair.PublicKey(),
+	)
+}
+
+// exchangeMessages encrypts a few messages with the sender and checks that
+// the receiver decrypts them.
+func exchangeMessages(sender, receiver *session.Cipher, t *testing.T) {
+	ctx := context.Background()
+	for _, text := range []string{"Hello!", "How are you?", "Bye!"} {
+		message, err := sender.Encrypt(ctx, []byte(text))
+		if err != nil {
+			logger.Error("Unable to encrypt message: ", err)
+			t.FailNow()
+		}
+		var plaintext []byte
+		switch message := message.(type) {
+		case *protocol.PreKeySignalMessage:
+			plaintext, err = receiver.DecryptMessage(ctx, message)
+		case *protocol.SignalMessage:
+			plaintext, err = receiver.Decrypt(ctx, message)
+		}
+		if err != nil || string(plaintext) != text {
+			logger.Error("Unable to decrypt message: ", err)
+			t.FailNow()
+		}
+	}
+}
+
+// TestSQLStore checks building sessions, sending messages and group
+// messages with two accounts in the same SQLite database.
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+	serializer := serialize.NewProtoBufSerializer()
+	db := openSQLiteDB(t)
+	alice := newAccount(t, db, "Alice", 1, serializer)
+	bob := newAccount(t, db, "Bob", 2, serializer)
+
+	aliceBuilder := session.NewBuilderFromSignal(alice.store, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, bob.bundle()); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	bobBuilder := session.NewBuilderFromSignal(bob.store, alice.address, serializer)
+	aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bobBuilder, alice.address)
+	exchangeMessages(aliceSessionCipher, bobSessionCipher, t)
+	exchangeMessages(bobSessionCipher, aliceSessionCipher, t)
+
+	// The used prekeys should only be removed from Bob's account.
+	if ok, _ := bob.store.ContainsPreKey(ctx, bob.preKeys[0].ID().Value); ok {
+		logger.Error("Used prekey was not removed")
+		t.FailNow()
+	}
+	if ok, _ := bob.store.ContainsKyberPreKey(ctx, bob.kyberPreKey.ID()); ok {
+		logger.Error("Used kyber prekey was not removed")
+		t.FailNow()
+	}
+	if ok, _ := alice.store.ContainsPreKey(ctx, alice.preKeys[0].ID().Value); !ok {
+		logger.Error("Prekey of another account was removed")
+		t.FailNow()
+	}
+	if identityKey, _ := bob.store.LoadIdentity(ctx, alice.address); identityKey == nil ||
+		identityKey.Fingerprint() != alice.identityKeyPair.PublicKey().Fingerprint() {
+		logger.Error("Identity key was not saved")
+		t.FailNow()
+	}
+
+	// Group messages should work with the sender key store.
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	aliceGroupBuilder := groups.NewGroupSessionBuilder(alice.store, serializer)
+	bobGroupBuilder := groups.NewGroupSessionBuilder(bob.store, serializer)
+	skdm, err := aliceGroupBuilder.Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	if err = bobGroupBuilder.Process(ctx, senderKeyName, skdm); err != nil {
+		logger.Error("Unable to process group session: ", err)
+		t.FailNow()
+	}
+	message, err := groups.NewGroupCipher(aliceGroupBuilder, senderKeyName, alice.store).Encrypt(ctx, []byte("Hello group!"))
+	if err != nil {
+		logger.Error("Unable to encrypt group message: ", err)
+		t.FailNow()
+	}
+	plaintext, err := groups.NewGroupCipher(bobGroupBuilder, senderKeyName, bob.store).Decrypt(ctx, message.(*protocol.SenderKeyMessage))
+	if err != nil || string(plaintext) != "Hello group!" {
+		logger.Error("Unable to decrypt group message: ", err)
+		t.FailNow()
+	}
+}
+
+// TestSQLStoreUpgradeAndTx checks that upgrading is idempotent and that
+// failed transactions are rolled back.
+func TestSQLStoreUpgradeAndTx(t *testing.T) {
+	ctx := context.Background()
+	serializer := serialize.NewProtoBufSerializer()
+	db := openSQLiteDB(t)
+	alice := newAccount(t, db, "Alice", 1, serializer)
+	bob := newAccount(t, db, "Bob", 2, serializer)
+
+	// Upgrading an up to date database should do nothing.
+	if err := alice.store.Upgrade(ctx); err != nil {
+		logger.Error("Unable to upgrade database again: ", err)
+		t.FailNow()
+	}
+	if version, err := alice.store.Version(ctx); err != nil || version != sqlstore.LatestVersion() {
+		logger.Error("Unexpected database version: ", version, err)
+		t.FailNow()
+	}
+
+	aliceBuilder := session.NewBuilderFromSignal(alice.store, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, bob.bundle()); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	sessionRecord, _ := alice.store.LoadSession(ctx, bob.address)
+	if !sessionRecord.SessionState().HasSenderChain() {
+		logger.Error("Session was not stored")
+		t.FailNow()
+	}
+
+	// Changes made in a failed transaction should be rolled back.
+	subDevice := protocol.NewSignalAddress("Bob", 3)
+	err := alice.store.WithTx(ctx, func(ctx context.Context) error {
+		alice.store.StoreSession(ctx, subDevice, sessionRecord)
+		alice.store.RemovePreKey(ctx, alice.preKeys[0].ID().Value)
+		return errors.New("transaction failed")
+	})
+	if ok, _ := alice.store.ContainsSession(ctx, subDevice); err == nil || ok {
+		logger.Error("Session stored in a failed transaction was not rolled back")
+		t.FailNow()
+	}
+	if ok, _ := alice.store.ContainsPreKey(ctx, alice.preKeys[0].ID().Value); !ok {
+		logger.Error("Prekey removed in a failed transaction was not rolled back")
+		t.FailNow()
+	}
+
+	// Committed changes should be visible.
+	err = alice.store.WithTx(ctx, func(ctx context.Context) error {
+		return alice.store.StoreSession(ctx, subDevice, sessionRecord)
+	})
+	if deviceIDs, _ := alice.store.GetSubDeviceSessions(ctx, "Bob"); err != nil || len(deviceIDs) != 2 || deviceIDs[1] != 3 {
+		logger.Error("Unexpected sub device sessions: ", deviceIDs, err)
+		t.FailNow()
+	}
+	if err = alice.store.DeleteAllSessions(ctx); err != nil {
+		logger.Error("Unable to delete sessions: ", err)
+		t.FailNow()
+	}
+	if ok, _ := alice.store.ContainsSession(ctx, bob.address); ok {
+		logger.Error("Session was not deleted")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mau.fi/libsignal/backup"
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/groups"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store/memstore"
+)
+
+// TestBackup checks that a conversation can be continued after Bob's state
+// is exported from an in-memory store and restored into a new one.
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceStore := newMemStore(alice, serializer)
+	bobStore := newMemStore(bob, serializer)
+
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(session.NewBuilderFromSignal(bobStore, alice.address, serializer), alice.address)
+	messageStrings, messages := sendMessages(5, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+
+	// Bob receives a sender key for Alice's group.
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	aliceGroupBuilder := groups.NewGroupSessionBuilder(aliceStore, serializer)
+	skdm, err := aliceGroupBuilder.Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	if err = groups.NewGroupSessionBuilder(bobStore, serializer).Process(ctx, senderKeyName, skdm); err != nil {
+		logger.Error("Unable to process group session: ", err)
+		t.FailNow()
+	}
+
+	// Export Bob's state.
+	archive, err := backup.Export(ctx, bobStore, []byte("correct horse battery staple"))
+	if err != nil {
+		logger.Error("Unable to export backup: ", err)
+		t.FailNow()
+	}
+	if _, err = backup.Decrypt(archive, []byte("wrong passphrase"), serializer); !errors.Is(err, signalerror.ErrBackupDecryptionFailed) {
+		logger.Error("Backup should not decrypt with a wrong passphrase: ", err)
+		t.FailNow()
+	}
+	archive[len(archive)-1] ^= 1
+	if _, err = backup.Decrypt(archive, []byte("correct horse battery staple"), serializer); !errors.Is(err, signalerror.ErrBackupDecryptionFailed) {
+		logger.Error("Modified backup should not decrypt: ", err)
+		t.FailNow()
+	}
+	archive[len(archive)-1] ^= 1
+
+	// Import it into a new store.
+	restored, err := backup.Decrypt(archive, []byte("correct horse battery staple"), serializer)
+	if err != nil {
+		logger.Error("Unable to decrypt backup: ", err)
+		t.FailNow()
+	}
+	if restored.RegistrationID != bob.registrationID ||
+		restored.IdentityKeyPair.PublicKey().Fingerprint() != bob.identityKeyPair.PublicKey().Fingerprint() {
+		logger.Error("Restored identity does not match")
+		t.FailNow()
+	}
+	if len(restored.PreKeys) != len(bob.preKeys)-1 || len(restored.SignedPreKeys) != 1 ||
+		len(restored.Sessions) != 1 || len(restored.SenderKeys) != 1 || len(restored.Identities) != 1 {
+		logger.Error("Unexpected number of restored records")
+		t.FailNow()
+	}
+	// Restoring into a store of another identity should fail without
+	// writing anything.
+	otherStores := []*memstore.Store{
+		memstore.NewStore(alice.identityKeyPair, restored.RegistrationID, serializer),
+		memstore.NewStore(restored.IdentityKeyPair, restored.RegistrationID+1, serializer),
+	}
+	for _, otherStore := range otherStores {
+		if err = restored.Restore(ctx, otherStore); !errors.Is(err, signalerror.ErrBackupIdentityMismatch) {
+			logger.Error("Expected identity mismatch error, got: ", err)
+			t.FailNow()
+		}
+		if ok, _ := otherStore.ContainsSession(ctx, alice.address); ok {
+			logger.Error("Session was restored into a store of another identity")
+			t.FailNow()
+		}
+	}
+
+	newBobStore := memstore.NewStore(restored.IdentityKeyPair, restored.RegistrationID, serializer)
+	if err = restored.Restore(ctx, newBobStore); err != nil {
+		logger.Error("Unable to restore backup: ", err)
+		t.FailNow()
+	}
+
+	// The conversation should continue with the restored store.
+	bobSessionCipher = session.NewCipher(session.NewBuilderFromSignal(newBobStore, alice.address, serializer), alice.address)
+	messageStrings, messages = sendMessages(5, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+	messageStrings, messages = sendMessages(5, bobSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+
+	message, err := groups.NewGroupCipher(aliceGroupBuilder, senderKeyName, aliceStore).Encrypt(ctx, []byte("Hello group!"))
+	if err != nil {
+		logger.Error("Unable to encrypt group message: ", err)
+		t.FailNow()
+	}
+	newBobGroupBuilder := groups.NewGroupSessionBuilder(newBobStore, serializer)
+	plaintext, err := groups.NewGroupCipher(newBobGroupBuilder, senderKeyName, newBobStore).Decrypt(ctx, message.(*protocol.SenderKeyMessage))
+	if err != nil || string(plaintext) != "Hello group!" {
+		logger.Error("Unable to decrypt group message after restoring: ", err)
+		t.FailNow()
+	}
+}
+
+// emptyPublicKey is a public key that serializes to no bytes.
+type emptyPublicKey struct{}
+
+func (emptyPublicKey) Serialize() []byte   { return []byte{} }
+func (emptyPublicKey) Type() int           { return ecc.DjbType }
+func (emptyPublicKey) PublicKey() [32]byte { return [32]byte{} }
+
+// TestBackupEmptyIdentityKey checks that a backup with an empty remote
+// identity key is rejected instead of causing a panic.
+func TestBackupEmptyIdentityKey(t *testing.T) {
+	bob := newUser("Bob", 2, newSerializer())
+	archive, err := (&backup.Backup{
+		IdentityKeyPair: bob.identityKeyPair,
+		RegistrationID:  bob.registrationID,
+		CreatedAt:       time.Now(),
+		Identities: []*backup.Identity{{
+			Address:     protocol.NewSignalAddress("Alice", 1),
+			IdentityKey: identity.NewKey(emptyPublicKey{}),
+		}},
+	}).Encrypt([]byte("correct horse battery staple"))
+	if err != nil {
+		logger.Error("Unable to encrypt backup: ", err)
+		t.FailNow()
+	}
+	_, err = backup.Decrypt(archive, []byte("correct horse battery staple"), newSerializer())
+	if !errors.Is(err, signalerror.ErrInvalidBackup) {
+		logger.Error("Expected invalid backup error, got: ", err)
+		t.FailNow()
+	}
+}
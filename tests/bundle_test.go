@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/medium"
+	"go.mau.fi/libsignal/util/optional"
+)
+
+// TestBundleSerialization checks serializing prekey bundles with both
+// serializers and building a session with the deserialized bundle.
+func TestBundleSerialization(t *testing.T) {
+	ctx := context.Background()
+
+	for _, serializer := range []*serialize.Serializer{serialize.NewProtoBufSerializer(), serialize.NewJSONSerializer()} {
+		alice := newUser("Alice", 1, serializer)
+		bob := newUser("Bob", 2, serializer)
+
+		serialized := newKyberBundle(bob).Serialize(serializer.PreKeyBundle)
+		bundle, err := prekey.NewBundleFromBytes(serialized, serializer.PreKeyBundle)
+		if err != nil {
+			logger.Error("Unable to deserialize prekey bundle: ", err)
+			t.FailNow()
+		}
+		if err = bundle.Validate(); err != nil {
+			logger.Error("Deserialized prekey bundle is invalid: ", err)
+			t.FailNow()
+		}
+		if bundle.PreKeyID().Value != bob.preKeys[0].ID().Value || bundle.KyberPreKey() == nil ||
+			bundle.IdentityKey().Fingerprint() != bob.identityKeyPair.PublicKey().Fingerprint() {
+			logger.Error("Deserialized prekey bundle doesn't match the original")
+			t.FailNow()
+		}
+
+		// The deserialized bundle should be usable for building a session.
+		alice.sessionBuilder = session.NewBuilderFromSignal(alice.signalStore, bob.address, serializer)
+		if err = alice.sessionBuilder.ProcessBundle(ctx, bundle); err != nil {
+			logger.Error("Unable to process deserialized prekey bundle: ", err)
+			t.FailNow()
+		}
+		bob.sessionBuilder = session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+		aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+		bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+		messageStrings, messages := sendMessages(1, aliceSessionCipher, serializer, t)
+		receiveMessages(messages, messageStrings, bobSessionCipher, t)
+
+		// A bundle without a one-time prekey or kyber prekey should also
+		// survive serialization.
+		bundle = prekey.NewBundle(bob.registrationID, bob.deviceID, optional.NewEmptyUint32(), bob.signedPreKey.ID(), nil,
+			bob.signedPreKey.KeyPair().PublicKey(), bob.signedPreKey.Signature(), bob.identityKeyPair.PublicKey())
+		bundle, err = prekey.NewBundleFromBytes(bundle.Serialize(serializer.PreKeyBundle), serializer.PreKeyBundle)
+		if err != nil || bundle.Validate() != nil || !bundle.PreKeyID().IsEmpty || bundle.KyberPreKey() != nil {
+			logger.Error("Unable to serialize bundle without optional keys: ", err)
+			t.FailNow()
+		}
+	}
+}
+
+// TestBundleValidation checks that invalid prekey bundles are rejected.
+func TestBundleValidation(t *testing.T) {
+	serializer := newSerializer()
+	bob := newUser("Bob", 2, serializer)
+	mallory := newUser("Mallory", 1, serializer)
+
+	tests := []struct {
+		name     string
+		modify   func(bundle *protocol.PreKeyBundleStructure)
+		expected error
+	}{
+		{"missing identity key", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.IdentityKey = nil
+		}, signalerror.ErrIncompleteBundle},
+		{"missing signed prekey", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.SignedPreKey = nil
+		}, signalerror.ErrIncompleteBundle},
+		{"bad prekey type", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.PreKey = append([]byte{0x0A}, bundle.PreKey[1:]...)
+		}, signalerror.ErrInvalidBundleKey},
+		{"bad kyber prekey", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.KyberPreKey = bundle.KyberPreKey[:100]
+		}, signalerror.ErrInvalidBundleKey},
+		{"wrong identity key", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.IdentityKey = mallory.identityKeyPair.PublicKey().Serialize()
+		}, signalerror.ErrInvalidSignature},
+		{"bad kyber signature", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.KyberPreKeySignature = bundle.SignedPreKeySignature
+		}, signalerror.ErrInvalidSignature},
+		{"prekey ID out of range", func(bundle *protocol.PreKeyBundleStructure) {
+			bundle.PreKeyID.Value = medium.MaxValue + 1
+		}, signalerror.ErrInvalidBundleKeyID},
+	}
+
+	for _, test := range tests {
+		structure := newKyberBundle(bob).Structure()
+		test.modify(structure)
+		bundle, err := prekey.NewBundleFromStruct(structure)
+		if err == nil {
+			err = bundle.Validate()
+		}
+		if !errors.Is(err, test.expected) {
+			logger.Error("Expected ", test.expected, " for ", test.name, ", got: ", err)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/util/keylock"
+)
+
+// TestConcurrentSessionCipher checks encrypting and decrypting messages for
+// the same address from multiple goroutines.
+func TestConcurrentSessionCipher(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+
+	retrievedPreKey := prekey.NewBundle(
+		bob.registrationID,
+		bob.deviceID,
+		bob.preKeys[0].ID(),
+		bob.signedPreKey.ID(),
+		bob.preKeys[0].KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+	err := alice.sessionBuilder.ProcessBundle(ctx, retrievedPreKey)
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+
+	// Finish setting up the session so all following messages are regular messages.
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	messageStrings, messages := sendMessages(1, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+	messageStrings, messages = sendMessages(1, bobSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+
+	// Encrypt messages from many goroutines at once.
+	const count = 50
+	encrypted := make([]*protocol.SignalMessage, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			message, err := aliceSessionCipher.Encrypt(ctx, []byte("Hello!"))
+			if err != nil {
+				t.Error("Unable to encrypt message: ", err)
+				return
+			}
+			encrypted[i] = message.(*protocol.SignalMessage)
+		}(i)
+	}
+	wg.Wait()
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	// Every message should have been encrypted with its own message key.
+	counters := make(map[uint32]bool)
+	for _, message := range encrypted {
+		if counters[message.Counter()] {
+			logger.Error("Message key was reused for counter ", message.Counter())
+			t.FailNow()
+		}
+		counters[message.Counter()] = true
+	}
+
+	// Decrypt the messages from many goroutines at once.
+	for _, message := range encrypted {
+		wg.Add(1)
+		go func(message *protocol.SignalMessage) {
+			defer wg.Done()
+			plaintext, err := bobSessionCipher.Decrypt(ctx, message)
+			if err != nil || string(plaintext) != "Hello!" {
+				t.Error("Unable to decrypt message: ", err)
+			}
+		}(message)
+	}
+	wg.Wait()
+}
+
+// TestKeyLock checks the in-memory key locker.
+func TestKeyLock(t *testing.T) {
+	ctx := context.Background()
+	locker := keylock.NewLocker()
+
+	unlock, err := locker.Lock(ctx, "Alice:1")
+	if err != nil {
+		logger.Error("Unable to acquire lock: ", err)
+		t.FailNow()
+	}
+
+	// Other keys should not be blocked.
+	unlockOther, err := locker.Lock(ctx, "Bob:1")
+	if err != nil {
+		logger.Error("Unable to acquire lock for other key: ", err)
+		t.FailNow()
+	}
+	unlockOther()
+
+	// The same key should block until the context is done.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = locker.Lock(timeoutCtx, "Alice:1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		logger.Error("Expected deadline exceeded error, got: ", err)
+		t.FailNow()
+	}
+
+	// Once unlocked, the key can be locked again.
+	unlock()
+	unlock, err = locker.Lock(ctx, "Alice:1")
+	if err != nil {
+		logger.Error("Unable to acquire lock after unlocking: ", err)
+		t.FailNow()
+	}
+	unlock()
+}
+
+// TestSessionBuilderLock checks that the session builder takes the same lock
+// for the remote address as the session cipher, and that decrypting prekey
+// messages, which builds a session while holding the lock, doesn't deadlock.
+func TestSessionBuilderLock(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceBuilder := session.NewBuilderFromSignal(alice.signalStore, bob.address, serializer)
+	bobBuilder := session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+	locker := keylock.NewLocker()
+	aliceBuilder.SetLocker(locker)
+	bobBuilder.SetLocker(locker)
+
+	// Processing a bundle should block while the address is locked.
+	unlock, err := locker.Lock(ctx, bob.address.String())
+	if err != nil {
+		logger.Error("Unable to acquire lock: ", err)
+		t.FailNow()
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err = aliceBuilder.ProcessBundle(timeoutCtx, newKyberBundle(bob))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		logger.Error("Expected deadline exceeded error, got: ", err)
+		t.FailNow()
+	}
+	unlock()
+
+	// Once unlocked, the session can be built and used.
+	if err = aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bobBuilder, alice.address)
+	messageStrings, messages := sendMessages(1, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/config"
+	"go.mau.fi/libsignal/groups"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// TestSessionConfig checks the configurable limits of session ciphers.
+func TestSessionConfig(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+
+	err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	bob.sessionBuilder = session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	messageStrings, messages := sendMessages(10, aliceSessionCipher, serializer, t)
+
+	// A cipher with a small limit should reject messages too far ahead.
+	limitedCipher := session.NewCipher(bob.sessionBuilder, alice.address, &config.Config{
+		MaxFutureMessages: 5,
+		MaxMessageKeys:    3,
+	})
+	receiveMessages(messages[:1], messageStrings[:1], limitedCipher, t)
+	_, err = limitedCipher.Decrypt(ctx, messages[9].(*protocol.PreKeySignalMessage).WhisperMessage())
+	if !errors.Is(err, signalerror.ErrNoValidSessions) {
+		logger.Error("Expected message to be rejected, got: ", err)
+		t.FailNow()
+	}
+	receiveMessages(messages[6:7], messageStrings[6:7], limitedCipher, t)
+
+	// Only the newest skipped message keys should be kept.
+	receiveMessages(messages[3:6], messageStrings[3:6], limitedCipher, t)
+	_, err = limitedCipher.Decrypt(ctx, messages[1].(*protocol.PreKeySignalMessage).WhisperMessage())
+	if !errors.Is(err, signalerror.ErrNoValidSessions) {
+		logger.Error("Expected old message key to be removed, got: ", err)
+		t.FailNow()
+	}
+
+	// The default limits should allow the rest of the messages.
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	receiveMessages(messages[7:], messageStrings[7:], bobSessionCipher, t)
+}
+
+// TestGroupConfig checks the configurable limits of group ciphers.
+func TestGroupConfig(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+
+	// Alice creates a sender key and Bob processes the distribution message.
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	skdm, err := alice.groupBuilder.Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	if err = bob.groupBuilder.Process(ctx, senderKeyName, skdm); err != nil {
+		logger.Error("Unable to process group session: ", err)
+		t.FailNow()
+	}
+
+	// Skip more iterations than the default limit allows.
+	aliceGroupCipher := groups.NewGroupCipher(alice.groupBuilder, senderKeyName, alice.senderKeyStore)
+	var message protocol.GroupCiphertextMessage
+	for i := uint32(0); i <= config.DefaultMaxSenderKeyFutureMessages+1; i++ {
+		message, err = aliceGroupCipher.Encrypt(ctx, []byte("Hello group!"))
+		if err != nil {
+			logger.Error("Unable to encrypt group message: ", err)
+			t.FailNow()
+		}
+	}
+	senderKeyMessage := message.(*protocol.SenderKeyMessage)
+
+	bobGroupCipher := groups.NewGroupCipher(bob.groupBuilder, senderKeyName, bob.senderKeyStore)
+	_, err = bobGroupCipher.Decrypt(ctx, senderKeyMessage)
+	if !errors.Is(err, signalerror.ErrTooFarIntoFuture) {
+		logger.Error("Expected too far into future error, got: ", err)
+		t.FailNow()
+	}
+
+	// A cipher with a larger limit should accept the message.
+	largeCipher := groups.NewGroupCipher(bob.groupBuilder, senderKeyName, bob.senderKeyStore, &config.Config{
+		MaxSenderKeyFutureMessages: 10000,
+	})
+	plaintext, err := largeCipher.Decrypt(ctx, senderKeyMessage)
+	if err != nil || string(plaintext) != "Hello group!" {
+		logger.Error("Unable to decrypt group message: ", err)
+		t.FailNow()
+	}
+}
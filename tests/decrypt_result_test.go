@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// TestDecryptResult checks the session information returned when
+// decrypting messages.
+func TestDecryptResult(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.sessionBuilder = session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+
+	err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	_, messages := sendMessages(2, aliceSessionCipher, serializer, t)
+
+	// The first message should establish a new session with our prekeys.
+	result, err := bobSessionCipher.DecryptMessageWithResult(ctx, messages[0].(*protocol.PreKeySignalMessage))
+	if err != nil {
+		logger.Error("Unable to decrypt prekey message: ", err)
+		t.FailNow()
+	}
+	if !result.NewSession || result.PreKeyID.IsEmpty || result.PreKeyID.Value != bob.preKeys[0].ID().Value ||
+		result.SignedPreKeyID.IsEmpty || result.SignedPreKeyID.Value != bob.signedPreKey.ID() ||
+		result.KyberPreKeyID.IsEmpty || result.KyberPreKeyID.Value != bob.kyberPreKey.ID() {
+		logger.Error("Expected a new session with prekey IDs, got: ", result)
+		t.FailNow()
+	}
+	if result.SenderIdentityKey.Fingerprint() != alice.identityKeyPair.PublicKey().Fingerprint() ||
+		result.Counter != 0 || result.SessionVersion != protocol.CurrentVersion || result.PromotedArchivedState {
+		logger.Error("Unexpected session information: ", result)
+		t.FailNow()
+	}
+
+	// Later prekey messages should use the existing session.
+	result, err = bobSessionCipher.DecryptMessageWithResult(ctx, messages[1].(*protocol.PreKeySignalMessage))
+	if err != nil {
+		logger.Error("Unable to decrypt prekey message: ", err)
+		t.FailNow()
+	}
+	if result.NewSession || !result.PreKeyID.IsEmpty || !result.SignedPreKeyID.IsEmpty || result.Counter != 1 {
+		logger.Error("Expected an existing session, got: ", result)
+		t.FailNow()
+	}
+
+	// Alice starts a new session, so Bob's reply is decrypted with an archived
+	// state. The record is reloaded first, as the in-memory store would keep
+	// returning it as a fresh record that is replaced instead of archived.
+	reply := encryptMessage("Hello Alice!", bobSessionCipher, serializer, t).(*protocol.SignalMessage)
+	sessionRecord, _ := alice.sessionStore.LoadSession(ctx, bob.address)
+	sessionRecord, err = record.NewSessionFromBytes(sessionRecord.Serialize(), serializer.Session, serializer.State)
+	if err != nil {
+		logger.Error("Unable to deserialize session: ", err)
+		t.FailNow()
+	}
+	alice.sessionStore.StoreSession(ctx, bob.address, sessionRecord)
+	err = alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	result, err = aliceSessionCipher.DecryptWithResult(ctx, reply)
+	if err != nil || string(result.Plaintext) != "Hello Alice!" {
+		logger.Error("Unable to decrypt message: ", err)
+		t.FailNow()
+	}
+	if !result.PromotedArchivedState || result.NewSession || result.MessageKeys == nil ||
+		result.SenderIdentityKey.Fingerprint() != bob.identityKeyPair.PublicKey().Fingerprint() {
+		logger.Error("Expected message to be decrypted with an archived state, got: ", result)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,260 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/groups"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store/encstore"
+	"go.mau.fi/libsignal/state/store/memstore"
+)
+
+// newKeyRing returns a key ring with a key filled with the given byte.
+func newKeyRing(keyID uint32, fill byte, t *testing.T) *encstore.KeyRing {
+	keys, err := encstore.NewKeyRing(keyID, bytes.Repeat([]byte{fill}, encstore.KeySize))
+	if err != nil {
+		logger.Error("Unable to create key ring: ", err)
+		t.FailNow()
+	}
+	return keys
+}
+
+// newEncStore returns an encrypting store around the given backend with
+// the identity and prekeys of the given user.
+func newEncStore(u *user, backend encstore.Backend, keys encstore.KeyProvider, serializer *serialize.Serializer, t *testing.T) *encstore.Store {
+	ctx := context.Background()
+	signalStore := encstore.NewStore(backend, keys, serializer)
+	for _, preKey := range u.preKeys {
+		if err := signalStore.StorePreKey(ctx, preKey.ID().Value, preKey); err != nil {
+			logger.Error("Unable to store prekey: ", err)
+			t.FailNow()
+		}
+	}
+	signalStore.StoreSignedPreKey(ctx, u.signedPreKey.ID(), u.signedPreKey)
+	signalStore.StoreKyberPreKey(ctx, u.kyberPreKey.ID(), u.kyberPreKey)
+	return signalStore
+}
+
+// TestEncStore checks building sessions, sending messages and group
+// messages with encrypted in-memory stores.
+func TestEncStore(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceBackend := newMemStore(alice, serializer)
+	bobBackend := newMemStore(bob, serializer)
+	aliceStore := newEncStore(alice, aliceBackend, newKeyRing(1, 0xaa, t), serializer, t)
+	bobStore := newEncStore(bob, bobBackend, newKeyRing(1, 0xbb, t), serializer, t)
+
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	bobBuilder := session.NewBuilderFromSignal(bobStore, alice.address, serializer)
+	aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bobBuilder, alice.address)
+
+	messageStrings, messages := sendMessages(5, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+	messageStrings, messages = sendMessages(5, bobSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+
+	// The backends should only contain encrypted records.
+	plainSession, _ := bobStore.LoadSession(ctx, alice.address)
+	storedSession, _ := bobBackend.LoadSerializedSession(ctx, alice.address)
+	if storedSession == nil || bytes.Equal(storedSession, plainSession.Serialize()) {
+		logger.Error("Session was not stored encrypted")
+		t.FailNow()
+	}
+	preKey := alice.preKeys[1]
+	storedPreKey, _ := aliceBackend.LoadSerializedPreKey(ctx, preKey.ID().Value)
+	privateKey := preKey.KeyPair().PrivateKey().Serialize()
+	if storedPreKey == nil || bytes.Contains(storedPreKey, privateKey[:]) {
+		logger.Error("Prekey private key was stored in plaintext")
+		t.FailNow()
+	}
+	if _, err := aliceBackend.LoadPreKey(ctx, preKey.ID().Value); err == nil {
+		logger.Error("Encrypted prekey was readable without the key")
+		t.FailNow()
+	}
+
+	// Group messages should work with the sender key store.
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	aliceGroupBuilder := groups.NewGroupSessionBuilder(aliceStore, serializer)
+	bobGroupBuilder := groups.NewGroupSessionBuilder(bobStore, serializer)
+	skdm, err := aliceGroupBuilder.Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	if err = bobGroupBuilder.Process(ctx, protocol.NewSenderKeyName("123", alice.address), skdm); err != nil {
+		logger.Error("Unable to process group session: ", err)
+		t.FailNow()
+	}
+	message, err := groups.NewGroupCipher(aliceGroupBuilder, senderKeyName, aliceStore).Encrypt(ctx, []byte("Hello group!"))
+	if err != nil {
+		logger.Error("Unable to encrypt group message: ", err)
+		t.FailNow()
+	}
+	plaintext, err := groups.NewGroupCipher(bobGroupBuilder, senderKeyName, bobStore).Decrypt(ctx, message.(*protocol.SenderKeyMessage))
+	if err != nil || string(plaintext) != "Hello group!" {
+		logger.Error("Unable to decrypt group message: ", err)
+		t.FailNow()
+	}
+}
+
+// movedBackend is a backend that returns the records of other rows for the
+// moved prekey IDs and addresses, as if they were moved in the database.
+type movedBackend struct {
+	*memstore.Store
+	preKeys  map[uint32]uint32
+	sessions map[protocol.SignalAddress]*protocol.SignalAddress
+}
+
+func (b *movedBackend) LoadSerializedPreKey(ctx context.Context, preKeyID uint32) ([]byte, error) {
+	if movedFrom, ok := b.preKeys[preKeyID]; ok {
+		preKeyID = movedFrom
+	}
+	return b.Store.LoadSerializedPreKey(ctx, preKeyID)
+}
+
+func (b *movedBackend) LoadSerializedSession(ctx context.Context, address *protocol.SignalAddress) ([]byte, error) {
+	if movedFrom, ok := b.sessions[*address]; ok {
+		address = movedFrom
+	}
+	return b.Store.LoadSerializedSession(ctx, address)
+}
+
+// TestEncStoreBinding checks that encrypted records can't be moved to
+// another key ID or address in the database.
+func TestEncStoreBinding(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	mallory := protocol.NewSignalAddress("Mallory", bob.address.DeviceID())
+
+	// Move a prekey and the session to another ID and address.
+	backend := &movedBackend{
+		Store:    newMemStore(alice, serializer),
+		preKeys:  map[uint32]uint32{1000: alice.preKeys[0].ID().Value},
+		sessions: map[protocol.SignalAddress]*protocol.SignalAddress{*mallory: bob.address},
+	}
+	aliceStore := newEncStore(alice, backend, newKeyRing(1, 0xaa, t), serializer, t)
+
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+
+	if _, err := aliceStore.LoadPreKey(ctx, 1000); !errors.Is(err, signalerror.ErrRecordDecryptionFailed) {
+		logger.Error("Moved prekey should not be decryptable: ", err)
+		t.FailNow()
+	}
+	if _, err := aliceStore.LoadSession(ctx, mallory); !errors.Is(err, signalerror.ErrRecordDecryptionFailed) {
+		logger.Error("Moved session should not be decryptable: ", err)
+		t.FailNow()
+	}
+}
+
+// TestEncStoreKeyRotation checks that loading records encrypted with an
+// old key doesn't write to the backend, and that Rekey re-encrypts them
+// with the current key.
+func TestEncStoreKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	backend := newMemStore(alice, serializer)
+	keys := newKeyRing(1, 0xaa, t)
+	aliceStore := newEncStore(alice, backend, keys, serializer, t)
+	preKeyID := alice.preKeys[0].ID().Value
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+
+	envelopeKeyID := func(preKeyID uint32) uint32 {
+		envelope, _ := backend.LoadSerializedPreKey(ctx, preKeyID)
+		return binary.BigEndian.Uint32(envelope[1:5])
+	}
+	if envelopeKeyID(preKeyID) != 1 {
+		logger.Error("Prekey was not encrypted with the first key")
+		t.FailNow()
+	}
+
+	// After rotating, loading a record should not re-encrypt it.
+	if err := keys.AddKey(2, bytes.Repeat([]byte{0xcc}, encstore.KeySize)); err != nil {
+		logger.Error("Unable to add key: ", err)
+		t.FailNow()
+	}
+	preKey, err := aliceStore.LoadPreKey(ctx, preKeyID)
+	if err != nil || preKey.ID().Value != preKeyID {
+		logger.Error("Unable to load prekey after rotation: ", err)
+		t.FailNow()
+	}
+	if envelopeKeyID(preKeyID) != 1 {
+		logger.Error("Loading a prekey should not re-encrypt it")
+		t.FailNow()
+	}
+
+	// Rekey should re-encrypt every record, and do nothing the second time.
+	count, err := aliceStore.Rekey(ctx)
+	expected := len(alice.preKeys) + 3
+	if err != nil || count != expected {
+		logger.Error("Unexpected number of rekeyed records: ", count, " (expected ", expected, ") ", err)
+		t.FailNow()
+	}
+	if count, err = aliceStore.Rekey(ctx); err != nil || count != 0 {
+		logger.Error("Records were rekeyed twice: ", count, " ", err)
+		t.FailNow()
+	}
+	if envelopeKeyID(preKeyID) != 2 {
+		logger.Error("Prekey was not re-encrypted with the new key")
+		t.FailNow()
+	}
+
+	// Once the old key is removed, the rekeyed records should still load.
+	if err = keys.RemoveKey(1); err != nil {
+		logger.Error("Unable to remove old key: ", err)
+		t.FailNow()
+	}
+	if _, err = aliceStore.LoadPreKey(ctx, preKeyID); err != nil {
+		logger.Error("Unable to load re-encrypted prekey: ", err)
+		t.FailNow()
+	}
+	if sessionRecord, err := aliceStore.LoadSession(ctx, bob.address); err != nil || sessionRecord.IsFresh() {
+		logger.Error("Unable to load re-encrypted session: ", err)
+		t.FailNow()
+	}
+
+	// Records encrypted with a removed key can't be loaded.
+	if err = keys.AddKey(3, bytes.Repeat([]byte{0xdd}, encstore.KeySize)); err != nil {
+		logger.Error("Unable to add key: ", err)
+		t.FailNow()
+	}
+	if err = keys.RemoveKey(2); err != nil {
+		logger.Error("Unable to remove old key: ", err)
+		t.FailNow()
+	}
+	if _, err = aliceStore.LoadPreKey(ctx, preKeyID); !errors.Is(err, signalerror.ErrUnknownRecordKey) {
+		logger.Error("Prekey encrypted with a removed key should not be loadable: ", err)
+		t.FailNow()
+	}
+}
@@ -1,10 +1,27 @@
 package tests
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"testing"
 
+	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/fingerprint"
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/logger"
+)
+
+// Identity keys and fingerprints from the libsignal test vectors.
+const (
+	fingerprintAliceIdentity   = "0506863bc66d02b40d27b8d49ca7c09e9239236f9d7d25d6fcca5ce13c7064d868"
+	fingerprintBobIdentity     = "05f781b6fb32fed9ba1cf2de978d4d5da28dc34046ae814402b5c0dbd96fda907b"
+	fingerprintDisplayable     = "300354477692869396892869876765458257569162576843440918079131"
+	fingerprintAliceScannable  = "080112220a201e301a0353dce3dbe7684cb8336e85136cdc0ee96219494ada305d62a7bd61df1a220a20d62cbf73a11592015b6b9f1682ac306fea3aaf3885b84d12bca631e9d4fb3a4d"
+	fingerprintBobScannable    = "080112220a20d62cbf73a11592015b6b9f1682ac306fea3aaf3885b84d12bca631e9d4fb3a4d1a220a201e301a0353dce3dbe7684cb8336e85136cdc0ee96219494ada305d62a7bd61df"
+	fingerprintAliceIdentifier = "+14152222222"
+	fingerprintBobIdentifier   = "+14153333333"
+	fingerprintIterations      = 5200
 )
 
 // TestFingerprint will test printing key fingerprints.
@@ -25,3 +42,117 @@ func TestFingerprint(t *testing.T) {
 	fmt.Println(fp.DisplayText())
 
 }
+
+// TestNumericFingerprint checks generated fingerprints against the libsignal
+// test vectors.
+func TestNumericFingerprint(t *testing.T) {
+	aliceIdentityKey := decodeTestIdentityKey(fingerprintAliceIdentity, t)
+	bobIdentityKey := decodeTestIdentityKey(fingerprintBobIdentity, t)
+
+	generator := fingerprint.NewNumericFingerprintGenerator(fingerprintIterations, 1)
+	aliceFingerprint := generator.CreateFor(fingerprintAliceIdentifier, fingerprintBobIdentifier, aliceIdentityKey, bobIdentityKey)
+	bobFingerprint := generator.CreateFor(fingerprintBobIdentifier, fingerprintAliceIdentifier, bobIdentityKey, aliceIdentityKey)
+
+	// Both users should see the same safety number.
+	if aliceFingerprint.Display().DisplayText() != fingerprintDisplayable ||
+		bobFingerprint.Display().DisplayText() != fingerprintDisplayable {
+		logger.Error("Displayable fingerprint does not match - Alice: ", aliceFingerprint.Display().DisplayText(),
+			" Bob: ", bobFingerprint.Display().DisplayText())
+		t.FailNow()
+	}
+
+	// The scannable fingerprints should have the local fingerprint first.
+	if hex.EncodeToString([]byte(aliceFingerprint.Scan())) != fingerprintAliceScannable ||
+		hex.EncodeToString([]byte(bobFingerprint.Scan())) != fingerprintBobScannable {
+		logger.Error("Scannable fingerprint does not match")
+		t.FailNow()
+	}
+
+	// A different identity key should result in a different safety number.
+	otherKeyPair, _ := ecc.GenerateKeyPair()
+	otherFingerprint := generator.CreateFor(fingerprintAliceIdentifier, fingerprintBobIdentifier,
+		identity.NewKey(otherKeyPair.PublicKey()), bobIdentityKey)
+	if otherFingerprint.Display().DisplayText() == fingerprintDisplayable {
+		logger.Error("Fingerprints for different keys should not match")
+		t.FailNow()
+	}
+}
+
+// TestScannableFingerprint checks comparing scanned fingerprints.
+func TestScannableFingerprint(t *testing.T) {
+	aliceIdentityKey := decodeTestIdentityKey(fingerprintAliceIdentity, t)
+	bobIdentityKey := decodeTestIdentityKey(fingerprintBobIdentity, t)
+
+	generator := fingerprint.NewNumericFingerprintGenerator(fingerprintIterations, 1)
+	aliceFingerprint := generator.CreateFor(fingerprintAliceIdentifier, fingerprintBobIdentifier, aliceIdentityKey, bobIdentityKey)
+	bobFingerprint := generator.CreateFor(fingerprintBobIdentifier, fingerprintAliceIdentifier, bobIdentityKey, aliceIdentityKey)
+
+	// Both users should accept the fingerprint scanned from the other device.
+	if err := aliceFingerprint.Scannable().CompareTo([]byte(bobFingerprint.Scan())); err != nil {
+		logger.Error("Alice was unable to verify Bob's fingerprint: ", err)
+		t.FailNow()
+	}
+	if err := bobFingerprint.Scannable().CompareTo([]byte(aliceFingerprint.Scan())); err != nil {
+		logger.Error("Bob was unable to verify Alice's fingerprint: ", err)
+		t.FailNow()
+	}
+
+	// Scanning our own fingerprint should not match.
+	var keyMismatch *fingerprint.KeyMismatchError
+	err := aliceFingerprint.Scannable().CompareTo([]byte(aliceFingerprint.Scan()))
+	if !errors.As(err, &keyMismatch) {
+		logger.Error("Expected key mismatch error, got: ", err)
+		t.FailNow()
+	}
+
+	// A fingerprint for a different key should not match.
+	otherKeyPair, _ := ecc.GenerateKeyPair()
+	otherFingerprint := generator.CreateFor(fingerprintBobIdentifier, fingerprintAliceIdentifier,
+		identity.NewKey(otherKeyPair.PublicKey()), aliceIdentityKey)
+	err = aliceFingerprint.Scannable().CompareTo([]byte(otherFingerprint.Scan()))
+	if !errors.As(err, &keyMismatch) {
+		logger.Error("Expected key mismatch error, got: ", err)
+		t.FailNow()
+	}
+
+	// A fingerprint with a different version should not match.
+	var versionMismatch *fingerprint.VersionMismatchError
+	newerGenerator := fingerprint.NewNumericFingerprintGenerator(fingerprintIterations, 2)
+	newerFingerprint := newerGenerator.CreateFor(fingerprintBobIdentifier, fingerprintAliceIdentifier, bobIdentityKey, aliceIdentityKey)
+	err = aliceFingerprint.Scannable().CompareTo([]byte(newerFingerprint.Scan()))
+	if !errors.As(err, &versionMismatch) || versionMismatch.OurVersion != 1 || versionMismatch.TheirVersion != 2 {
+		logger.Error("Expected version mismatch error, got: ", err)
+		t.FailNow()
+	}
+
+	// A fingerprint for different identifiers should not match.
+	var identifierMismatch *fingerprint.IdentifierMismatchError
+	aliceScannable := fingerprint.NewScannableFingerprintWithIdentifiers(0,
+		[]byte(fingerprintAliceIdentifier), aliceIdentityKey.Serialize(),
+		[]byte(fingerprintBobIdentifier), bobIdentityKey.Serialize())
+	malloryScannable := fingerprint.NewScannableFingerprintWithIdentifiers(0,
+		[]byte("+14154444444"), bobIdentityKey.Serialize(),
+		[]byte(fingerprintAliceIdentifier), aliceIdentityKey.Serialize())
+	err = aliceScannable.CompareTo(malloryScannable.Serialize())
+	if !errors.As(err, &identifierMismatch) {
+		logger.Error("Expected identifier mismatch error, got: ", err)
+		t.FailNow()
+	}
+
+	// Garbage should fail to parse.
+	if err = aliceFingerprint.Scannable().CompareTo([]byte{0xFF, 0xFF}); err == nil {
+		logger.Error("Expected an error for an invalid scanned fingerprint")
+		t.FailNow()
+	}
+}
+
+// decodeTestIdentityKey will return an identity key from the given hex string.
+func decodeTestIdentityKey(encoded string, t *testing.T) *identity.Key {
+	serialized, _ := hex.DecodeString(encoded)
+	key, err := ecc.DecodePoint(serialized, 0)
+	if err != nil {
+		logger.Error("Unable to decode identity key: ", err)
+		t.FailNow()
+	}
+	return identity.NewKey(key)
+}
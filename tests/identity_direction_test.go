@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.mau.fi/libsignal/keys/identity"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// directionalIdentityStore is an in-memory signal store that accepts changed
+// identity keys when receiving, but refuses to send to them until the new key
+// has been approved.
+type directionalIdentityStore struct {
+	*InMemorySignalProtocol
+	unapproved map[protocol.SignalAddress]bool
+	directions []store.Direction
+}
+
+func (s *directionalIdentityStore) SaveIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) error {
+	existing := s.trustedKeys[*address]
+	if existing != nil && existing.Fingerprint() != identityKey.Fingerprint() {
+		s.unapproved[*address] = true
+	}
+	return s.InMemorySignalProtocol.SaveIdentity(ctx, address, identityKey)
+}
+
+func (s *directionalIdentityStore) IsTrustedIdentityForDirection(ctx context.Context, address *protocol.SignalAddress,
+	identityKey *identity.Key, direction store.Direction) (bool, error) {
+
+	s.directions = append(s.directions, direction)
+	if direction == store.DirectionReceiving {
+		return true, nil
+	}
+	trusted, err := s.IsTrustedIdentity(ctx, address, identityKey)
+	return trusted && !s.unapproved[*address], err
+}
+
+// TestDirectionalIdentityTrust checks that changed identity keys can be
+// accepted when receiving while sending is blocked.
+func TestDirectionalIdentityTrust(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other. Alice uses a store with
+	// a direction-aware trust policy.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceStore := &directionalIdentityStore{
+		InMemorySignalProtocol: alice.signalStore,
+		unapproved:             make(map[protocol.SignalAddress]bool),
+	}
+	alice.sessionBuilder = session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	bob.sessionBuilder = session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+
+	err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	messageStrings, messages := sendMessages(1, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+	messageStrings, messages = sendMessages(1, bobSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+	if !slices.Contains(aliceStore.directions, store.DirectionSending) ||
+		!slices.Contains(aliceStore.directions, store.DirectionReceiving) {
+		logger.Error("Expected trust checks for both directions, got: ", aliceStore.directions)
+		t.FailNow()
+	}
+
+	// Bob reinstalls with a new identity key and sends a new message.
+	newBob := newUser("Bob", 2, serializer)
+	newBob.sessionBuilder = session.NewBuilderFromSignal(newBob.signalStore, alice.address, serializer)
+	err = newBob.sessionBuilder.ProcessBundle(ctx, newKyberBundle(alice))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	newBobSessionCipher := session.NewCipher(newBob.sessionBuilder, alice.address)
+	messageStrings, messages = sendMessages(1, newBobSessionCipher, serializer, t)
+
+	// Alice should accept the message, but not send to the new key yet.
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+	_, err = aliceSessionCipher.Encrypt(ctx, []byte("Hello!"))
+	if !errors.Is(err, signalerror.ErrUntrustedIdentity) {
+		logger.Error("Expected untrusted identity error, got: ", err)
+		t.FailNow()
+	}
+
+	// Once the new key is approved, Alice can send again.
+	delete(aliceStore.unapproved, *bob.address)
+	messageStrings, messages = sendMessages(1, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, newBobSessionCipher, t)
+}
@@ -1,10 +1,15 @@
 package tests
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/identity"
 	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/signalerror"
 	"go.mau.fi/libsignal/util/keyhelper"
 )
 
@@ -46,3 +51,88 @@ func TestIdentityKeys(t *testing.T) {
 	}
 
 }
+
+// TestIdentityKeyPairSerializing checks that identity key pairs survive a
+// round trip through the JSON and protobuf serializers, and that
+// mismatched or invalid key pairs are rejected.
+func TestIdentityKeyPairSerializing(t *testing.T) {
+	identityKeyPair, err := keyhelper.GenerateIdentityKeyPair()
+	if err != nil {
+		logger.Error("Error generating identity keys: ", err)
+		t.FailNow()
+	}
+	otherKeyPair, err := keyhelper.GenerateIdentityKeyPair()
+	if err != nil {
+		logger.Error("Error generating identity keys: ", err)
+		t.FailNow()
+	}
+
+	serializers := map[string]*serialize.Serializer{
+		"JSON":     serialize.NewJSONSerializer(),
+		"ProtoBuf": serialize.NewProtoBufSerializer(),
+	}
+	for name, serializer := range serializers {
+		logger.Info("Testing identity key pair serialization with ", name, " serializer...")
+
+		serialized := identityKeyPair.Serialize(serializer.IdentityKeyPair)
+		deserialized, err := identity.NewKeyPairFromBytes(serialized, serializer.IdentityKeyPair)
+		if err != nil {
+			logger.Error("Failed to deserialize identity key pair: ", err)
+			t.FailNow()
+		}
+		if deserialized.PublicKey().Fingerprint() != identityKeyPair.PublicKey().Fingerprint() {
+			logger.Error("Deserialized public key does not match the original.")
+			t.FailNow()
+		}
+		if deserialized.PrivateKey().Serialize() != identityKeyPair.PrivateKey().Serialize() {
+			logger.Error("Deserialized private key does not match the original.")
+			t.FailNow()
+		}
+		if !bytes.Equal(deserialized.Serialize(serializer.IdentityKeyPair), serialized) {
+			logger.Error("Reserialized identity key pair does not match the original.")
+			t.FailNow()
+		}
+
+		// The key pair should still be able to sign messages.
+		message := []byte("Hello")
+		signature := ecc.CalculateSignature(deserialized.PrivateKey(), message)
+		if !ecc.VerifySignature(identityKeyPair.PublicKey().PublicKey(), message, signature) {
+			logger.Error("Signature from deserialized key pair could not be verified.")
+			t.FailNow()
+		}
+
+		// A public key that doesn't belong to the private key should be rejected.
+		mismatched := identityKeyPair.Structure()
+		mismatched.PublicKey = otherKeyPair.PublicKey().Serialize()
+		_, err = identity.NewKeyPairFromBytes(serializer.IdentityKeyPair.Serialize(mismatched), serializer.IdentityKeyPair)
+		if !errors.Is(err, signalerror.ErrInvalidIdentityKeyPair) {
+			logger.Error("Mismatched identity key pair should have been rejected, got: ", err)
+			t.FailNow()
+		}
+
+		// A truncated private key should be rejected.
+		truncated := identityKeyPair.Structure()
+		truncated.PrivateKey = truncated.PrivateKey[:16]
+		_, err = identity.NewKeyPairFromBytes(serializer.IdentityKeyPair.Serialize(truncated), serializer.IdentityKeyPair)
+		if !errors.Is(err, signalerror.ErrInvalidIdentityKeyPair) {
+			logger.Error("Truncated identity key pair should have been rejected, got: ", err)
+			t.FailNow()
+		}
+
+		// An empty public key should be rejected rather than panicking.
+		emptyPublic := identityKeyPair.Structure()
+		emptyPublic.PublicKey = []byte{}
+		_, err = identity.NewKeyPairFromBytes(serializer.IdentityKeyPair.Serialize(emptyPublic), serializer.IdentityKeyPair)
+		if !errors.Is(err, signalerror.ErrInvalidIdentityKeyPair) {
+			logger.Error("Identity key pair with empty public key should have been rejected, got: ", err)
+			t.FailNow()
+		}
+
+		// Bytes that can't be decoded at all should return an error.
+		_, err = identity.NewKeyPairFromBytes([]byte{0xff, 0xff, 0xff}, serializer.IdentityKeyPair)
+		if err == nil {
+			logger.Error("Invalid identity key pair bytes should have returned an error.")
+			t.FailNow()
+		}
+	}
+}
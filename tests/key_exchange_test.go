@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+)
+
+// TestKeyExchange checks building a session using key exchange messages.
+func TestKeyExchange(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+
+	// Let Alice initiate a key exchange with Bob.
+	initiate, err := alice.sessionBuilder.InitiateKeyExchange(ctx)
+	if err != nil {
+		logger.Error("Unable to initiate key exchange: ", err)
+		t.FailNow()
+	}
+	receivedInitiate := receiveKeyExchange(initiate, serializer, t)
+	if !receivedInitiate.IsInitiate() || receivedInitiate.Sequence() != initiate.Sequence() {
+		logger.Error("Key exchange message did not survive serialization")
+		t.FailNow()
+	}
+
+	// Let Bob process the key exchange and respond.
+	response, err := bob.sessionBuilder.ProcessKeyExchange(ctx, receivedInitiate)
+	if err != nil {
+		logger.Error("Unable to process key exchange: ", err)
+		t.FailNow()
+	}
+	if response == nil || !response.IsResponse() || response.IsResponseForSimultaneousInitiate() {
+		logger.Error("Expected a key exchange response")
+		t.FailNow()
+	}
+
+	// Let Alice process Bob's response to finish the session.
+	receivedResponse := receiveKeyExchange(response, serializer, t)
+	reply, err := alice.sessionBuilder.ProcessKeyExchange(ctx, receivedResponse)
+	if err != nil {
+		logger.Error("Unable to process key exchange response: ", err)
+		t.FailNow()
+	}
+	if reply != nil {
+		logger.Error("Did not expect a reply to a key exchange response")
+		t.FailNow()
+	}
+
+	// Processing the same response again should fail, since the key exchange is done.
+	_, err = alice.sessionBuilder.ProcessKeyExchange(ctx, receivedResponse)
+	if !errors.Is(err, signalerror.ErrStaleKeyExchange) {
+		logger.Error("Expected stale key exchange error, got: ", err)
+		t.FailNow()
+	}
+
+	checkKeyExchangeSession(alice, bob, serializer, t)
+}
+
+// TestSimultaneousKeyExchange checks building a session when both users
+// initiate a key exchange at the same time.
+func TestSimultaneousKeyExchange(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+
+	// Let both users initiate a key exchange before seeing the other one.
+	aliceInitiate, err := alice.sessionBuilder.InitiateKeyExchange(ctx)
+	if err != nil {
+		logger.Error("Unable to initiate key exchange: ", err)
+		t.FailNow()
+	}
+	bobInitiate, err := bob.sessionBuilder.InitiateKeyExchange(ctx)
+	if err != nil {
+		logger.Error("Unable to initiate key exchange: ", err)
+		t.FailNow()
+	}
+
+	// Let both users process the other user's initiate message.
+	aliceResponse, err := alice.sessionBuilder.ProcessKeyExchange(ctx, receiveKeyExchange(bobInitiate, serializer, t))
+	if err != nil {
+		logger.Error("Unable to process key exchange: ", err)
+		t.FailNow()
+	}
+	bobResponse, err := bob.sessionBuilder.ProcessKeyExchange(ctx, receiveKeyExchange(aliceInitiate, serializer, t))
+	if err != nil {
+		logger.Error("Unable to process key exchange: ", err)
+		t.FailNow()
+	}
+	if !aliceResponse.IsResponseForSimultaneousInitiate() || !bobResponse.IsResponseForSimultaneousInitiate() {
+		logger.Error("Expected simultaneous initiate responses")
+		t.FailNow()
+	}
+
+	// The responses should be accepted without changing the sessions.
+	_, err = alice.sessionBuilder.ProcessKeyExchange(ctx, receiveKeyExchange(bobResponse, serializer, t))
+	if err != nil {
+		logger.Error("Unable to process key exchange response: ", err)
+		t.FailNow()
+	}
+	_, err = bob.sessionBuilder.ProcessKeyExchange(ctx, receiveKeyExchange(aliceResponse, serializer, t))
+	if err != nil {
+		logger.Error("Unable to process key exchange response: ", err)
+		t.FailNow()
+	}
+
+	checkKeyExchangeSession(alice, bob, serializer, t)
+}
+
+// receiveKeyExchange emulates sending the given key exchange message over the network.
+func receiveKeyExchange(message *protocol.KeyExchangeMessage, serializer *serialize.Serializer, t *testing.T) *protocol.KeyExchangeMessage {
+	received, err := protocol.NewKeyExchangeMessageFromBytes(message.Serialize(), serializer.KeyExchangeMessage)
+	if err != nil {
+		logger.Error("Unable to emulate receiving key exchange message: ", err)
+		t.FailNow()
+	}
+	return received
+}
+
+// checkKeyExchangeSession sends messages back and forth between the given users.
+func checkKeyExchangeSession(alice, bob *user, serializer *serialize.Serializer, t *testing.T) {
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+
+	aliceMessageStrings, aliceMessages := sendMessages(10, aliceSessionCipher, serializer, t)
+	receiveMessages(aliceMessages, aliceMessageStrings, bobSessionCipher, t)
+
+	bobMessageStrings, bobMessages := sendMessages(10, bobSessionCipher, serializer, t)
+	receiveMessages(bobMessages, bobMessageStrings, aliceSessionCipher, t)
+
+	aliceMessageStrings, aliceMessages = sendMessages(10, aliceSessionCipher, serializer, t)
+	receiveMessages(aliceMessages, aliceMessageStrings, bobSessionCipher, t)
+}
+
+// TestKeyExchangeEmptyKeys checks that key exchange messages with empty
+// keys are rejected instead of causing a panic.
+func TestKeyExchangeEmptyKeys(t *testing.T) {
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	initiate, err := alice.sessionBuilder.InitiateKeyExchange(context.Background())
+	if err != nil {
+		logger.Error("Unable to initiate key exchange: ", err)
+		t.FailNow()
+	}
+
+	for _, clear := range []func(*protocol.KeyExchangeMessageStructure){
+		func(s *protocol.KeyExchangeMessageStructure) { s.BaseKey = []byte{} },
+		func(s *protocol.KeyExchangeMessageStructure) { s.RatchetKey = []byte{} },
+		func(s *protocol.KeyExchangeMessageStructure) { s.IdentityKey = []byte{} },
+	} {
+		structure, err := serializer.KeyExchangeMessage.Deserialize(initiate.Serialize())
+		if err != nil {
+			logger.Error("Unable to deserialize key exchange message: ", err)
+			t.FailNow()
+		}
+		clear(structure)
+		_, err = protocol.NewKeyExchangeMessageFromBytes(serializer.KeyExchangeMessage.Serialize(structure), serializer.KeyExchangeMessage)
+		if !errors.Is(err, signalerror.ErrIncompleteMessage) {
+			logger.Error("Expected incomplete message error, got: ", err)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/keyserver"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/keyhelper"
+)
+
+// TestKeyServer checks uploading keys to the key server and building
+// sessions with the bundles it hands out.
+func TestKeyServer(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	server := httptest.NewServer(keyserver.NewServer(serializer))
+	defer server.Close()
+	client := keyserver.NewClient(server.URL, serializer)
+
+	// Bob uploads one one-time prekey along with his last resort keys.
+	bob := newUser("Bob", 2, serializer)
+	lastResortKey, _ := keyhelper.GenerateLastResortKey(serializer.PreKeyRecord)
+	lastResortKyberKey, _ := keyhelper.GenerateLastResortKyberPreKey(bob.identityKeyPair, serializer.KyberPreKeyRecord)
+	bob.signalStore.StorePreKey(ctx, lastResortKey.ID().Value, lastResortKey)
+	bob.signalStore.StoreKyberPreKey(ctx, lastResortKyberKey.ID(), lastResortKyberKey)
+
+	upload := keyserver.NewUpload(bob.registrationID, bob.identityKeyPair.PublicKey(), &prekey.Upload{
+		PreKeys:      bob.preKeys[:1],
+		SignedPreKey: bob.signedPreKey,
+	})
+	upload.AddPreKey(lastResortKey)
+	upload.AddKyberPreKey(bob.kyberPreKey)
+	upload.AddKyberPreKey(lastResortKyberKey)
+	if err := client.Upload(ctx, bob.address, upload); err != nil {
+		logger.Error("Unable to upload keys: ", err)
+		t.FailNow()
+	}
+	deviceIDs, err := client.Devices(ctx, "Bob")
+	if err != nil || !slices.Equal(deviceIDs, []uint32{bob.deviceID}) {
+		logger.Error("Unexpected device list: ", deviceIDs, err)
+		t.FailNow()
+	}
+
+	// The first sender gets the one-time keys, the second gets the last
+	// resort keys. Bob should be able to decrypt messages from both.
+	for i, sender := range []*user{newUser("Alice", 1, serializer), newUser("Carol", 1, serializer)} {
+		sender.sessionBuilder = session.NewBuilderFromSignal(sender.signalStore, bob.address, serializer)
+		if err = client.ProcessBundle(ctx, sender.sessionBuilder, bob.address); err != nil {
+			logger.Error("Unable to build session with fetched bundle: ", err)
+			t.FailNow()
+		}
+		senderCipher := session.NewCipher(sender.sessionBuilder, bob.address)
+		message := encryptMessage("Hello Bob!", senderCipher, serializer, t).(*protocol.PreKeySignalMessage)
+		expectedPreKeyID := []uint32{bob.preKeys[0].ID().Value, lastResortKey.ID().Value}[i]
+		if message.PreKeyID().IsEmpty || message.PreKeyID().Value != expectedPreKeyID {
+			logger.Error("Unexpected prekey ID in message: ", message.PreKeyID())
+			t.FailNow()
+		}
+
+		bobBuilder := session.NewBuilderFromSignal(bob.signalStore, sender.address, serializer)
+		plaintext, err := session.NewCipher(bobBuilder, sender.address).DecryptMessage(ctx, message)
+		if err != nil || string(plaintext) != "Hello Bob!" {
+			logger.Error("Unable to decrypt message: ", err)
+			t.FailNow()
+		}
+	}
+
+	// Unknown addresses and invalid uploads should be rejected.
+	_, err = client.FetchBundle(ctx, protocol.NewSignalAddress("Mallory", 1))
+	if !errors.Is(err, signalerror.ErrNoKeysForAddress) {
+		logger.Error("Expected no keys error, got: ", err)
+		t.FailNow()
+	}
+	mallory := newUser("Mallory", 1, serializer)
+	upload = keyserver.NewUpload(mallory.registrationID, mallory.identityKeyPair.PublicKey(), &prekey.Upload{
+		SignedPreKey: bob.signedPreKey,
+	})
+	if err = client.Upload(ctx, mallory.address, upload); err == nil {
+		logger.Error("Expected upload with an invalid signature to be rejected")
+		t.FailNow()
+	}
+}
+
+// TestKeyServerUploadLimit checks that the key server rejects upload bodies
+// that are larger than MaxUploadSize.
+func TestKeyServerUploadLimit(t *testing.T) {
+	server := httptest.NewServer(keyserver.NewServer(newSerializer()))
+	defer server.Close()
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"RegistrationID": 1, "IdentityKey": "` + strings.Repeat("A", keyserver.MaxUploadSize) + `"}`, http.StatusRequestEntityTooLarge},
+		{`{"RegistrationID": 1`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		request, err := http.NewRequest(http.MethodPut, server.URL+"/v1/keys/Bob/2", strings.NewReader(test.body))
+		if err != nil {
+			logger.Error("Unable to create request: ", err)
+			t.FailNow()
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			logger.Error("Unable to upload keys: ", err)
+			t.FailNow()
+		}
+		response.Body.Close()
+		if response.StatusCode != test.status {
+			logger.Error("Unexpected status code: ", response.StatusCode, " (expected ", test.status, ")")
+			t.FailNow()
+		}
+	}
+}
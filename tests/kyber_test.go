@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/config"
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/record"
+)
+
+// TestKyberSession checks building a session with a kyber prekey bundle.
+func TestKyberSession(t *testing.T) {
+	ctx := context.Background()
+
+	for _, serializer := range []*serialize.Serializer{serialize.NewJSONSerializer(), serialize.NewProtoBufSerializer()} {
+		// Create our users who will talk to each other. Bob uses the combined
+		// signal store, which also stores kyber prekeys.
+		alice := newUser("Alice", 1, serializer)
+		bob := newUser("Bob", 2, serializer)
+		alice.buildSession(bob.address, serializer)
+		bob.sessionBuilder = session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+
+		// Process Bob's retrieved prekey bundle to establish a session.
+		err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+		if err != nil {
+			logger.Error("Unable to process retrieved prekey bundle: ", err)
+			t.FailNow()
+		}
+
+		// The pending kyber prekey should survive serializing the session.
+		sessionRecord, _ := alice.sessionStore.LoadSession(ctx, bob.address)
+		sessionRecord, err = record.NewSessionFromBytes(sessionRecord.Serialize(), serializer.Session, serializer.State)
+		if err != nil {
+			logger.Error("Unable to deserialize session: ", err)
+			t.FailNow()
+		}
+		items, err := sessionRecord.SessionState().UnackPreKeyMessageItems()
+		if err != nil || items.KyberPreKeyID().IsEmpty || items.KyberPreKeyID().Value != bob.kyberPreKey.ID() ||
+			items.KyberCiphertext() == nil {
+			logger.Error("Session state is missing the pending kyber prekey: ", err)
+			t.FailNow()
+		}
+
+		// Send messages from Alice to Bob, which should include the kyber prekey.
+		aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+		bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+		aliceMessageStrings, aliceMessages := sendMessages(5, aliceSessionCipher, serializer, t)
+		preKeyMessage, ok := aliceMessages[0].(*protocol.PreKeySignalMessage)
+		if !ok || preKeyMessage.KyberPreKeyID().IsEmpty || preKeyMessage.KyberPreKeyID().Value != bob.kyberPreKey.ID() {
+			logger.Error("Expected a prekey message with a kyber prekey")
+			t.FailNow()
+		}
+		receiveMessages(aliceMessages, aliceMessageStrings, bobSessionCipher, t)
+
+		// Bob's kyber prekey should be marked as used.
+		if ok, _ := bob.kyberPreKeyStore.ContainsKyberPreKey(ctx, bob.kyberPreKey.ID()); ok {
+			logger.Error("Kyber prekey was not marked as used")
+			t.FailNow()
+		}
+
+		// Send messages back from Bob to Alice.
+		bobMessageStrings, bobMessages := sendMessages(5, bobSessionCipher, serializer, t)
+		receiveMessages(bobMessages, bobMessageStrings, aliceSessionCipher, t)
+	}
+}
+
+// TestKyberSessionErrors checks that invalid kyber prekeys are rejected.
+func TestKyberSessionErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+
+	// A kyber prekey signed by someone else should be rejected.
+	mallory := newUser("Mallory", 3, serializer)
+	badBundle := prekey.NewBundleWithKyber(
+		bob.registrationID,
+		bob.deviceID,
+		bob.preKeys[0].ID(),
+		bob.signedPreKey.ID(),
+		bob.preKeys[0].KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		mallory.kyberPreKey.ID(),
+		mallory.kyberPreKey.KeyPair().PublicKey(),
+		mallory.kyberPreKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+	err := alice.sessionBuilder.ProcessBundle(ctx, badBundle)
+	if !errors.Is(err, signalerror.ErrInvalidSignature) {
+		logger.Error("Expected invalid signature error, got: ", err)
+		t.FailNow()
+	}
+
+	// A builder that requires kyber should reject bundles without it.
+	requireKyber := &config.Config{RequireKyber: true}
+	strictBuilder := session.NewBuilderFromSignal(alice.signalStore, bob.address, serializer, requireKyber)
+	plainBundle := prekey.NewBundle(
+		bob.registrationID,
+		bob.deviceID,
+		bob.preKeys[0].ID(),
+		bob.signedPreKey.ID(),
+		bob.preKeys[0].KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+	err = strictBuilder.ProcessBundle(ctx, plainBundle)
+	if !errors.Is(err, signalerror.ErrIncompleteBundle) {
+		logger.Error("Expected incomplete bundle error, got: ", err)
+		t.FailNow()
+	}
+	if ok, _ := alice.signalStore.ContainsSession(ctx, bob.address); ok {
+		logger.Error("Session should not have been built from a bundle without kyber")
+		t.FailNow()
+	}
+	if err = strictBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process kyber prekey bundle with kyber required: ", err)
+		t.FailNow()
+	}
+
+	// A receiver without a kyber prekey store can't decrypt PQXDH messages.
+	err = alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	message := encryptMessage("Hello!", aliceSessionCipher, serializer, t)
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	_, err = bobSessionCipher.DecryptMessage(ctx, message.(*protocol.PreKeySignalMessage))
+	if !errors.Is(err, signalerror.ErrNoKyberPreKey) {
+		logger.Error("Expected missing kyber prekey error, got: ", err)
+		t.FailNow()
+	}
+}
+
+// TestKyberPreKeySerializing checks kyber prekey records after a round trip
+// through each serializer.
+func TestKyberPreKeySerializing(t *testing.T) {
+	for _, serializer := range []*serialize.Serializer{serialize.NewJSONSerializer(), serialize.NewProtoBufSerializer()} {
+		alice := newUser("Alice", 1, serializer)
+
+		deserialized, err := record.NewKyberPreKeyFromBytes(alice.kyberPreKey.Serialize(), serializer.KyberPreKeyRecord)
+		if err != nil {
+			logger.Error("Unable to deserialize kyber prekey: ", err)
+			t.FailNow()
+		}
+		if deserialized.ID() != alice.kyberPreKey.ID() || deserialized.Timestamp() != alice.kyberPreKey.Timestamp() ||
+			deserialized.Signature() != alice.kyberPreKey.Signature() ||
+			!bytes.Equal(deserialized.KeyPair().PublicKey().Serialize(), alice.kyberPreKey.KeyPair().PublicKey().Serialize()) ||
+			!bytes.Equal(deserialized.KeyPair().PrivateKey().Serialize(), alice.kyberPreKey.KeyPair().PrivateKey().Serialize()) {
+			logger.Error("Deserialized kyber prekey does not match")
+			t.FailNow()
+		}
+	}
+}
+
+// newKyberBundle returns a prekey bundle with the given user's kyber prekey.
+func newKyberBundle(u *user) *prekey.Bundle {
+	return prekey.NewBundleWithKyber(
+		u.registrationID,
+		u.deviceID,
+		u.preKeys[0].ID(),
+		u.signedPreKey.ID(),
+		u.preKeys[0].KeyPair().PublicKey(),
+		u.signedPreKey.KeyPair().PublicKey(),
+		u.signedPreKey.Signature(),
+		u.kyberPreKey.ID(),
+		u.kyberPreKey.KeyPair().PublicKey(),
+		u.kyberPreKey.Signature(),
+		u.identityKeyPair.PublicKey(),
+	)
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store"
+	"go.mau.fi/libsignal/util/keyhelper"
+)
+
+// lastResortStore is an in-memory signal store that counts the uses of
+// last resort prekeys.
+type lastResortStore struct {
+	*InMemorySignalProtocol
+	uses    map[store.PreKeyType]int
+	lastIDs map[store.PreKeyType]uint32
+}
+
+func (s *lastResortStore) RecordLastResortPreKeyUse(ctx context.Context, address *protocol.SignalAddress, keyType store.PreKeyType, preKeyID uint32) error {
+	s.uses[keyType]++
+	s.lastIDs[keyType] = preKeyID
+	return nil
+}
+
+// TestLastResortPreKeys checks that last resort prekeys are kept after use
+// and that every use is recorded.
+func TestLastResortPreKeys(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other. Bob only has last
+	// resort prekeys left.
+	alice := newUser("Alice", 1, serializer)
+	carol := newUser("Carol", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	bobStore := &lastResortStore{InMemorySignalProtocol: bob.signalStore, uses: make(map[store.PreKeyType]int), lastIDs: make(map[store.PreKeyType]uint32)}
+
+	lastResortKey, _ := keyhelper.GenerateLastResortKey(serializer.PreKeyRecord)
+	lastResortKyberKey, _ := keyhelper.GenerateLastResortKyberPreKey(bob.identityKeyPair, serializer.KyberPreKeyRecord)
+	if !lastResortKey.IsLastResort() || !lastResortKyberKey.IsLastResort() {
+		logger.Error("Generated keys don't have the last resort prekey ID")
+		t.FailNow()
+	}
+	bobStore.StorePreKey(ctx, record.LastResortPreKeyID, lastResortKey)
+	bobStore.StoreKyberPreKey(ctx, record.LastResortPreKeyID, lastResortKyberKey)
+	bundle := prekey.NewBundleWithKyber(
+		bob.registrationID,
+		bob.deviceID,
+		lastResortKey.ID(),
+		bob.signedPreKey.ID(),
+		lastResortKey.KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		lastResortKyberKey.ID(),
+		lastResortKyberKey.KeyPair().PublicKey(),
+		lastResortKyberKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+
+	// Both senders should be able to build a session with the same keys.
+	for i, sender := range []*user{alice, carol} {
+		sender.sessionBuilder = session.NewBuilderFromSignal(sender.signalStore, bob.address, serializer)
+		if err := sender.sessionBuilder.ProcessBundle(ctx, bundle); err != nil {
+			logger.Error("Unable to process retrieved prekey bundle: ", err)
+			t.FailNow()
+		}
+		senderCipher := session.NewCipher(sender.sessionBuilder, bob.address)
+		message := encryptMessage("Hello Bob!", senderCipher, serializer, t).(*protocol.PreKeySignalMessage)
+
+		bobBuilder := session.NewBuilderFromSignal(bobStore, sender.address, serializer)
+		result, err := session.NewCipher(bobBuilder, sender.address).DecryptMessageWithResult(ctx, message)
+		if err != nil || string(result.Plaintext) != "Hello Bob!" {
+			logger.Error("Unable to decrypt message: ", err)
+			t.FailNow()
+		}
+		if !result.UsedLastResortPreKey {
+			logger.Error("Expected decrypt result to report last resort prekey use")
+			t.FailNow()
+		}
+		if bobStore.uses[store.PreKeyTypeEC] != i+1 || bobStore.uses[store.PreKeyTypeKyber] != i+1 {
+			logger.Error("Unexpected number of recorded last resort uses: ", bobStore.uses)
+			t.FailNow()
+		}
+	}
+
+	// The last resort keys should still be in the stores.
+	if ok, _ := bobStore.ContainsPreKey(ctx, record.LastResortPreKeyID); !ok {
+		logger.Error("Last resort prekey was removed")
+		t.FailNow()
+	}
+	if ok, _ := bobStore.ContainsKyberPreKey(ctx, record.LastResortPreKeyID); !ok {
+		logger.Error("Last resort kyber prekey was removed")
+		t.FailNow()
+	}
+}
+
+// TestLegacyLastResortPreKey checks that last resort prekeys generated with
+// the legacy ID 0 are still kept after use.
+func TestLegacyLastResortPreKey(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	bobStore := &lastResortStore{InMemorySignalProtocol: bob.signalStore, uses: make(map[store.PreKeyType]int), lastIDs: make(map[store.PreKeyType]uint32)}
+
+	keyPair, _ := ecc.GenerateKeyPair()
+	legacyKey := record.NewPreKey(record.LegacyLastResortPreKeyID, keyPair, serializer.PreKeyRecord)
+	if !legacyKey.IsLastResort() {
+		logger.Error("Prekey with the legacy ID should be a last resort prekey")
+		t.FailNow()
+	}
+	bobStore.StorePreKey(ctx, record.LegacyLastResortPreKeyID, legacyKey)
+	bundle := prekey.NewBundleWithKyber(
+		bob.registrationID,
+		bob.deviceID,
+		legacyKey.ID(),
+		bob.signedPreKey.ID(),
+		legacyKey.KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		bob.kyberPreKey.ID(),
+		bob.kyberPreKey.KeyPair().PublicKey(),
+		bob.kyberPreKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+
+	alice.sessionBuilder = session.NewBuilderFromSignal(alice.signalStore, bob.address, serializer)
+	if err := alice.sessionBuilder.ProcessBundle(ctx, bundle); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	message := encryptMessage("Hello Bob!", session.NewCipher(alice.sessionBuilder, bob.address), serializer, t).(*protocol.PreKeySignalMessage)
+	bobBuilder := session.NewBuilderFromSignal(bobStore, alice.address, serializer)
+	result, err := session.NewCipher(bobBuilder, alice.address).DecryptMessageWithResult(ctx, message)
+	if err != nil || string(result.Plaintext) != "Hello Bob!" {
+		logger.Error("Unable to decrypt message: ", err)
+		t.FailNow()
+	}
+	if !result.UsedLastResortPreKey || bobStore.uses[store.PreKeyTypeEC] != 1 ||
+		bobStore.lastIDs[store.PreKeyTypeEC] != record.LegacyLastResortPreKeyID {
+		logger.Error("Legacy last resort prekey use was not recorded: ", bobStore.uses, bobStore.lastIDs)
+		t.FailNow()
+	}
+	if ok, _ := bobStore.ContainsPreKey(ctx, record.LegacyLastResortPreKeyID); !ok {
+		logger.Error("Legacy last resort prekey was removed")
+		t.FailNow()
+	}
+}
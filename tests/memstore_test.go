@@ -0,0 +1,254 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mau.fi/libsignal/groups"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store"
+	"go.mau.fi/libsignal/state/store/memstore"
+)
+
+// newMemStore returns a memstore with the identity and prekeys of the
+// given user.
+func newMemStore(u *user, serializer *serialize.Serializer) *memstore.Store {
+	ctx := context.Background()
+	signalStore := memstore.NewStore(u.identityKeyPair, u.registrationID, serializer)
+	for _, preKey := range u.preKeys {
+		signalStore.StorePreKey(ctx, preKey.ID().Value, preKey)
+	}
+	signalStore.StoreSignedPreKey(ctx, u.signedPreKey.ID(), u.signedPreKey)
+	signalStore.StoreKyberPreKey(ctx, u.kyberPreKey.ID(), u.kyberPreKey)
+	return signalStore
+}
+
+// TestMemStore checks building sessions, sending messages and group
+// messages with the in-memory store.
+func TestMemStore(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceStore := newMemStore(alice, serializer)
+	bobStore := newMemStore(bob, serializer)
+
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	bobBuilder := session.NewBuilderFromSignal(bobStore, alice.address, serializer)
+	aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bobBuilder, alice.address)
+
+	messageStrings, messages := sendMessages(5, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+	messageStrings, messages = sendMessages(5, bobSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+
+	// The used prekeys should be removed.
+	if ok, _ := bobStore.ContainsPreKey(ctx, bob.preKeys[0].ID().Value); ok {
+		logger.Error("Used prekey was not removed")
+		t.FailNow()
+	}
+	if ok, _ := bobStore.ContainsKyberPreKey(ctx, bob.kyberPreKey.ID()); ok {
+		logger.Error("Used kyber prekey was not removed")
+		t.FailNow()
+	}
+
+	// Group messages should work with the sender key store.
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	aliceGroupBuilder := groups.NewGroupSessionBuilder(aliceStore, serializer)
+	bobGroupBuilder := groups.NewGroupSessionBuilder(bobStore, serializer)
+	skdm, err := aliceGroupBuilder.Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	if err = bobGroupBuilder.Process(ctx, protocol.NewSenderKeyName("123", alice.address), skdm); err != nil {
+		logger.Error("Unable to process group session: ", err)
+		t.FailNow()
+	}
+	message, err := groups.NewGroupCipher(aliceGroupBuilder, senderKeyName, aliceStore).Encrypt(ctx, []byte("Hello group!"))
+	if err != nil {
+		logger.Error("Unable to encrypt group message: ", err)
+		t.FailNow()
+	}
+	plaintext, err := groups.NewGroupCipher(bobGroupBuilder, senderKeyName, bobStore).Decrypt(ctx, message.(*protocol.SenderKeyMessage))
+	if err != nil || string(plaintext) != "Hello group!" {
+		logger.Error("Unable to decrypt group message: ", err)
+		t.FailNow()
+	}
+}
+
+// TestMemStoreIdentityTrust checks that the in-memory store trusts the
+// first identity key it sees and nothing else afterwards.
+func TestMemStoreIdentityTrust(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	mallory := newUser("Mallory", 1, serializer)
+	aliceStore := newMemStore(alice, serializer)
+
+	if trusted, _ := aliceStore.IsTrustedIdentity(ctx, bob.address, bob.identityKeyPair.PublicKey()); !trusted {
+		logger.Error("Unknown identity should be trusted on first use")
+		t.FailNow()
+	}
+	aliceStore.SaveIdentity(ctx, bob.address, bob.identityKeyPair.PublicKey())
+	if trusted, _ := aliceStore.IsTrustedIdentity(ctx, bob.address, bob.identityKeyPair.PublicKey()); !trusted {
+		logger.Error("Saved identity should be trusted")
+		t.FailNow()
+	}
+	if trusted, _ := aliceStore.IsTrustedIdentity(ctx, bob.address, mallory.identityKeyPair.PublicKey()); trusted {
+		logger.Error("Changed identity should not be trusted")
+		t.FailNow()
+	}
+}
+
+// TestMemStoreSnapshot checks restoring snapshots and that loaded records
+// don't share state with the store.
+func TestMemStoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceStore := newMemStore(alice, serializer)
+
+	snapshot := aliceStore.Snapshot()
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+
+	// Modifying a loaded record should not change the stored one.
+	sessionRecord, _ := aliceStore.LoadSession(ctx, bob.address)
+	sessionRecord.ArchiveCurrentState()
+	sessionRecord, _ = aliceStore.LoadSession(ctx, bob.address)
+	if !sessionRecord.SessionState().HasSenderChain() {
+		logger.Error("Loaded session record shares state with the store")
+		t.FailNow()
+	}
+
+	// Restoring the snapshot should remove the session and identity.
+	aliceStore.Restore(snapshot)
+	if ok, _ := aliceStore.ContainsSession(ctx, bob.address); ok {
+		logger.Error("Session was not removed by restoring the snapshot")
+		t.FailNow()
+	}
+	if identityKey, _ := aliceStore.LoadIdentity(ctx, bob.address); identityKey != nil {
+		logger.Error("Identity was not removed by restoring the snapshot")
+		t.FailNow()
+	}
+
+	// Changes made in a failed transaction should be rolled back.
+	err := aliceStore.WithTx(ctx, func(ctx context.Context) error {
+		aliceStore.StoreSession(ctx, bob.address, sessionRecord)
+		return errors.New("transaction failed")
+	})
+	if ok, _ := aliceStore.ContainsSession(ctx, bob.address); err == nil || ok {
+		logger.Error("Session stored in a failed transaction was not rolled back")
+		t.FailNow()
+	}
+
+	// The store should be usable from multiple goroutines.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			address := protocol.NewSignalAddress(fmt.Sprint("User", i), 1)
+			aliceStore.StoreSession(ctx, address, sessionRecord)
+			aliceStore.LoadSession(ctx, address)
+			aliceStore.Snapshot()
+		}(i)
+	}
+	wg.Wait()
+	if deviceIDs, _ := aliceStore.GetSubDeviceSessions(ctx, "User1"); len(deviceIDs) != 0 {
+		logger.Error("Unexpected sub device sessions: ", deviceIDs)
+		t.FailNow()
+	}
+	if ok, _ := aliceStore.ContainsSession(ctx, protocol.NewSignalAddress("User19", 1)); !ok {
+		logger.Error("Session stored from another goroutine is missing")
+		t.FailNow()
+	}
+}
+
+// TestMemStoreConcurrentTx checks that transactions for different addresses
+// don't block each other, and that a rollback only restores the records
+// that its transaction changed.
+func TestMemStoreConcurrentTx(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	carol := protocol.NewSignalAddress("Carol", 1)
+	aliceStore := newMemStore(alice, serializer)
+	sessionRecord := record.NewSession(serializer.Session, serializer.State)
+	preKeyID := alice.preKeys[0].ID().Value
+
+	// Bob's transaction waits until Carol's transaction is done.
+	bobStarted := make(chan struct{})
+	carolDone := make(chan struct{})
+	bobErr := make(chan error)
+	go func() {
+		bobErr <- aliceStore.WithTx(ctx, func(ctx context.Context) error {
+			aliceStore.StoreSession(ctx, bob.address, sessionRecord)
+			aliceStore.RemovePreKey(ctx, preKeyID)
+			aliceStore.RecordLastResortPreKeyUse(ctx, bob.address, store.PreKeyTypeEC, record.LastResortPreKeyID)
+			close(bobStarted)
+			select {
+			case <-carolDone:
+				return errors.New("transaction failed")
+			case <-time.After(time.Second):
+				return errors.New("transaction for another address was blocked")
+			}
+		})
+	}()
+	<-bobStarted
+	err := aliceStore.WithTx(ctx, func(ctx context.Context) error {
+		aliceStore.StoreSession(ctx, carol, sessionRecord)
+		return aliceStore.RecordLastResortPreKeyUse(ctx, carol, store.PreKeyTypeEC, record.LastResortPreKeyID)
+	})
+	close(carolDone)
+	if err != nil {
+		logger.Error("Unable to store session: ", err)
+		t.FailNow()
+	}
+	if err = <-bobErr; err == nil || err.Error() != "transaction failed" {
+		logger.Error("Unexpected transaction error: ", err)
+		t.FailNow()
+	}
+
+	// Only the changes of Bob's transaction should be rolled back.
+	if ok, _ := aliceStore.ContainsSession(ctx, bob.address); ok {
+		logger.Error("Session stored in a failed transaction was not rolled back")
+		t.FailNow()
+	}
+	if ok, _ := aliceStore.ContainsPreKey(ctx, preKeyID); !ok {
+		logger.Error("Prekey removed in a failed transaction was not rolled back")
+		t.FailNow()
+	}
+	if ok, _ := aliceStore.ContainsSession(ctx, carol); !ok {
+		logger.Error("Session stored in another transaction was rolled back")
+		t.FailNow()
+	}
+	if uses := aliceStore.LastResortPreKeyUses(store.PreKeyTypeEC); uses != 1 {
+		logger.Error("Unexpected last resort prekey uses: ", uses)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mau.fi/libsignal/groups"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/migrate"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/state/store/memstore"
+	"go.mau.fi/libsignal/util/keylock"
+)
+
+// TestMigrateRecords checks that stores with JSON records can be read with
+// the detecting serializer, rewritten as ProtoBuf records, and used to
+// continue a conversation afterwards.
+func TestMigrateRecords(t *testing.T) {
+	ctx := context.Background()
+
+	// Start with both users' records written by the JSON serializer.
+	jsonSerializer := serialize.NewJSONSerializer()
+	alice := newUser("Alice", 1, jsonSerializer)
+	bob := newUser("Bob", 2, jsonSerializer)
+	aliceStore := newMemStore(alice, jsonSerializer)
+	bobStore := newMemStore(bob, jsonSerializer)
+
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, jsonSerializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(session.NewBuilderFromSignal(bobStore, alice.address, jsonSerializer), alice.address)
+	messageStrings, messages := sendMessages(3, aliceSessionCipher, jsonSerializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	skdm, err := groups.NewGroupSessionBuilder(aliceStore, jsonSerializer).Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	if err = groups.NewGroupSessionBuilder(bobStore, jsonSerializer).Process(ctx, senderKeyName, skdm); err != nil {
+		logger.Error("Unable to process group session: ", err)
+		t.FailNow()
+	}
+	if serialized, _ := bobStore.LoadSerializedSession(ctx, alice.address); !serialize.IsJSON(serialized) {
+		logger.Error("Session should have been stored as JSON")
+		t.FailNow()
+	}
+
+	// Reopen the stores with the detecting serializer and migrate them.
+	serializer := serialize.NewDetectingSerializer(serialize.NewProtoBufSerializer())
+	aliceSnapshot, bobSnapshot := aliceStore.Snapshot(), bobStore.Snapshot()
+	aliceStore = memstore.NewStore(alice.identityKeyPair, alice.registrationID, serializer)
+	bobStore = memstore.NewStore(bob.identityKeyPair, bob.registrationID, serializer)
+	aliceStore.Restore(aliceSnapshot)
+	bobStore.Restore(bobSnapshot)
+	if sessionRecord, err := bobStore.LoadSession(ctx, alice.address); err != nil || sessionRecord.IsFresh() {
+		logger.Error("Unable to load JSON session with detecting serializer: ", err)
+		t.FailNow()
+	}
+
+	progress := make(map[string]migrate.Progress)
+	if err = migrate.Records(ctx, bobStore, serializer, nil, func(p migrate.Progress) {
+		progress[p.Kind] = p
+	}); err != nil {
+		logger.Error("Unable to migrate Bob's records: ", err)
+		t.FailNow()
+	}
+	expected := map[string]int{
+		migrate.PreKeys:       len(bob.preKeys) - 1,
+		migrate.SignedPreKeys: 1,
+		migrate.Sessions:      1,
+		migrate.SenderKeys:    1,
+	}
+	for kind, total := range expected {
+		if progress[kind].Done != total || progress[kind].Total != total {
+			logger.Error("Unexpected progress for ", kind, ": ", progress[kind])
+			t.FailNow()
+		}
+	}
+	if err = migrate.Records(ctx, aliceStore, serializer, nil, nil); err != nil {
+		logger.Error("Unable to migrate Alice's records: ", err)
+		t.FailNow()
+	}
+
+	// All of Bob's records should now be ProtoBuf.
+	sessionRecord, _ := bobStore.LoadSerializedSession(ctx, alice.address)
+	preKey, _ := bobStore.LoadSerializedPreKey(ctx, bob.preKeys[1].ID().Value)
+	signedPreKey, _ := bobStore.LoadSerializedSignedPreKey(ctx, bob.signedPreKey.ID())
+	senderKey, _ := bobStore.LoadSerializedSenderKey(ctx, senderKeyName)
+	for i, record := range [][]byte{sessionRecord, preKey, signedPreKey, senderKey} {
+		if len(record) == 0 || serialize.IsJSON(record) {
+			logger.Error("Record ", i, " was not rewritten as ProtoBuf")
+			t.FailNow()
+		}
+	}
+
+	// The conversation should continue with the migrated records.
+	aliceBuilder = session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	bobBuilder := session.NewBuilderFromSignal(bobStore, alice.address, serializer)
+	aliceSessionCipher = session.NewCipher(aliceBuilder, bob.address)
+	bobSessionCipher = session.NewCipher(bobBuilder, alice.address)
+	messageStrings, messages = sendMessages(3, bobSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, aliceSessionCipher, t)
+	messageStrings, messages = sendMessages(3, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+
+	aliceGroupBuilder := groups.NewGroupSessionBuilder(aliceStore, serializer)
+	bobGroupBuilder := groups.NewGroupSessionBuilder(bobStore, serializer)
+	message, err := groups.NewGroupCipher(aliceGroupBuilder, senderKeyName, aliceStore).Encrypt(ctx, []byte("Hello group!"))
+	if err != nil {
+		logger.Error("Unable to encrypt group message: ", err)
+		t.FailNow()
+	}
+	plaintext, err := groups.NewGroupCipher(bobGroupBuilder, senderKeyName, bobStore).Decrypt(ctx, message.(*protocol.SenderKeyMessage))
+	if err != nil || string(plaintext) != "Hello group!" {
+		logger.Error("Unable to decrypt group message: ", err)
+		t.FailNow()
+	}
+}
+
+// TestMigrateRecordsLock checks that sessions are migrated while holding
+// the lock for their address.
+func TestMigrateRecordsLock(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	aliceStore := newMemStore(alice, serializer)
+	aliceBuilder := session.NewBuilderFromSignal(aliceStore, bob.address, serializer)
+	if err := aliceBuilder.ProcessBundle(ctx, newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+
+	// Migrating should wait while a cipher holds the lock for Bob.
+	locker := keylock.NewLocker()
+	unlock, err := locker.Lock(ctx, bob.address.String())
+	if err != nil {
+		logger.Error("Unable to acquire lock: ", err)
+		t.FailNow()
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err = migrate.Records(timeoutCtx, aliceStore, serializer, locker, nil); !errors.Is(err, context.DeadlineExceeded) {
+		logger.Error("Expected deadline exceeded error, got: ", err)
+		t.FailNow()
+	}
+	unlock()
+	if err = migrate.Records(ctx, aliceStore, serializer, locker, nil); err != nil {
+		logger.Error("Unable to migrate records: ", err)
+		t.FailNow()
+	}
+}
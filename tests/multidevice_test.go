@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+)
+
+// TestMultiDeviceCipher checks encrypting a message for all devices of a
+// recipient.
+func TestMultiDeviceCipher(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other. Alice and Bob both have
+	// multiple devices.
+	alice := newUser("Alice", 1, serializer)
+	aliceLaptop := newUser("Alice", 2, serializer)
+	bobDevices := []*user{
+		newUser("Bob", 1, serializer),
+		newUser("Bob", 2, serializer),
+		newUser("Bob", 3, serializer),
+	}
+
+	// Build sessions with Bob's first two devices and Alice's laptop.
+	for _, device := range []*user{bobDevices[0], bobDevices[1], aliceLaptop} {
+		builder := session.NewBuilderFromSignal(alice.signalStore, device.address, serializer)
+		if err := builder.ProcessBundle(ctx, newKyberBundle(device)); err != nil {
+			logger.Error("Unable to process retrieved prekey bundle: ", err)
+			t.FailNow()
+		}
+	}
+
+	multiCipher := session.NewMultiDeviceCipherFromSignal(alice.signalStore, serializer)
+	multiCipher.SetLocalAddress(alice.address)
+
+	// Every device of Bob we have a session with should get the message.
+	results, err := multiCipher.Encrypt(ctx, "Bob", []byte("Hello Bob!"))
+	if err != nil {
+		logger.Error("Unable to encrypt message for all devices: ", err)
+		t.FailNow()
+	}
+	messages := results.Messages()
+	if len(results) != 2 || len(messages) != 2 {
+		logger.Error("Expected messages for two devices, got: ", len(messages))
+		t.FailNow()
+	}
+	for _, device := range bobDevices[:2] {
+		device.sessionBuilder = session.NewBuilderFromSignal(device.signalStore, alice.address, serializer)
+		cipher := session.NewCipher(device.sessionBuilder, alice.address)
+		plaintext, err := cipher.DecryptMessage(ctx, messages[device.deviceID].(*protocol.PreKeySignalMessage))
+		if err != nil || string(plaintext) != "Hello Bob!" {
+			logger.Error("Device ", device.deviceID, " was unable to decrypt message: ", err)
+			t.FailNow()
+		}
+	}
+
+	// Devices without a session should be reported as needing a bundle.
+	results = multiCipher.EncryptForDevices(ctx, "Bob", []uint32{1, 2, 3}, []byte("Hello again!"))
+	needsBundle := results.NeedsPreKeyBundle()
+	if len(results.Messages()) != 2 || len(needsBundle) != 1 || *needsBundle[0] != *bobDevices[2].address {
+		logger.Error("Expected Bob's third device to need a prekey bundle: ", results.Errors())
+		t.FailNow()
+	}
+
+	// Devices with a changed identity should be reported as untrusted. The
+	// in-memory store keeps the empty session loaded for the third device.
+	mallory := newUser("Mallory", 1, serializer)
+	alice.identityStore.SaveIdentity(ctx, bobDevices[1].address, mallory.identityKeyPair.PublicKey())
+	results, err = multiCipher.Encrypt(ctx, "Bob", []byte("Hello again!"))
+	if err != nil || len(results) != 3 {
+		logger.Error("Unable to encrypt message for all devices: ", err)
+		t.FailNow()
+	}
+	for _, result := range results {
+		if result.Address.DeviceID() == 2 && !result.IsUntrusted() {
+			logger.Error("Expected untrusted identity error, got: ", result.Err)
+			t.FailNow()
+		}
+	}
+
+	// Messages to our own name should skip the local device.
+	results, err = multiCipher.Encrypt(ctx, "Alice", []byte("Hello me!"))
+	if err != nil || len(results) != 1 || results[0].Address.DeviceID() != aliceLaptop.deviceID || results[0].Err != nil {
+		logger.Error("Expected a message only for Alice's laptop: ", err)
+		t.FailNow()
+	}
+}
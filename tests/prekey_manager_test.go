@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mau.fi/libsignal/config"
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/util/keyhelper"
+	"go.mau.fi/libsignal/util/medium"
+)
+
+// TestPreKeyManager checks refilling one-time prekeys and rotating signed
+// prekeys with the prekey manager.
+func TestPreKeyManager(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	identityKeyPair, _ := keyhelper.GenerateIdentityKeyPair()
+	signalStore := NewInMemorySignalProtocol(identityKeyPair, keyhelper.GenerateRegistrationID(), serializer)
+	cfg := &config.Config{PreKeyLowWaterMark: 5, PreKeyBatchSize: 10}
+
+	// Start near the end of the ID range to check that IDs wrap around.
+	manager := prekey.NewManagerFromSignal(signalStore, serializer, &prekey.ManagerState{NextPreKeyID: medium.MaxValue - 2}, cfg)
+
+	// The first refresh should generate a full batch and a signed prekey.
+	upload, err := manager.Refresh(ctx)
+	if err != nil {
+		logger.Error("Unable to refresh prekeys: ", err)
+		t.FailNow()
+	}
+	if len(upload.PreKeys) != 10 || upload.SignedPreKey == nil || manager.RemainingPreKeys() != 10 {
+		logger.Error("Expected a full batch of prekeys and a signed prekey, got: ", upload)
+		t.FailNow()
+	}
+	if upload.PreKeys[0].ID().Value != medium.MaxValue-2 || upload.PreKeys[1].ID().Value != medium.MaxValue-1 ||
+		upload.PreKeys[2].ID().Value != 1 {
+		logger.Error("Prekey IDs did not wrap around before the last resort prekey ID")
+		t.FailNow()
+	}
+
+	// Nothing should be generated while enough prekeys are left.
+	for _, preKey := range upload.PreKeys[:5] {
+		signalStore.RemovePreKey(ctx, preKey.ID().Value)
+	}
+	upload, err = manager.Refresh(ctx)
+	if err != nil || !upload.IsEmpty() || manager.RemainingPreKeys() != 5 {
+		logger.Error("Expected nothing to upload, got: ", upload, err)
+		t.FailNow()
+	}
+
+	// Below the low-water mark, the prekeys should be refilled.
+	signalStore.RemovePreKey(ctx, manager.State().PreKeyIDs[0])
+	upload, err = manager.Refresh(ctx)
+	if err != nil || len(upload.PreKeys) != 6 || upload.SignedPreKey != nil || manager.RemainingPreKeys() != 10 {
+		logger.Error("Expected six new prekeys, got: ", upload, err)
+		t.FailNow()
+	}
+
+	// A restored manager should continue with the next prekey ID.
+	restored := prekey.NewManagerFromSignal(signalStore, serializer, manager.State(), cfg)
+	if restored.State().NextPreKeyID != 15 || restored.RemainingPreKeys() != 10 {
+		logger.Error("Restored manager has a different state: ", restored.State())
+		t.FailNow()
+	}
+
+	// An old signed prekey should be rotated but kept for the grace period.
+	signedPreKeys, _ := signalStore.LoadSignedPreKeys(ctx)
+	oldSignedPreKey := ageSignedPreKey(signalStore, signedPreKeys[0], 3*24*time.Hour, serializer)
+	upload, err = restored.Refresh(ctx)
+	if err != nil || upload.SignedPreKey == nil || upload.SignedPreKey.ID() != oldSignedPreKey.ID()+1 {
+		logger.Error("Expected the signed prekey to be rotated, got: ", upload, err)
+		t.FailNow()
+	}
+	if ok, _ := signalStore.ContainsSignedPreKey(ctx, oldSignedPreKey.ID()); !ok {
+		logger.Error("Replaced signed prekey was removed before the grace period ended")
+		t.FailNow()
+	}
+
+	// Once the grace period is over, the replaced signed prekey is removed.
+	ageSignedPreKey(signalStore, oldSignedPreKey, 40*24*time.Hour, serializer)
+	ageSignedPreKey(signalStore, upload.SignedPreKey, 31*24*time.Hour, serializer)
+	upload, err = restored.Refresh(ctx)
+	if err != nil || upload.SignedPreKey == nil {
+		logger.Error("Expected the signed prekey to be rotated, got: ", upload, err)
+		t.FailNow()
+	}
+	if ok, _ := signalStore.ContainsSignedPreKey(ctx, oldSignedPreKey.ID()); ok {
+		logger.Error("Replaced signed prekey was not removed after the grace period")
+		t.FailNow()
+	}
+	if ok, _ := signalStore.ContainsSignedPreKey(ctx, oldSignedPreKey.ID()+1); !ok {
+		logger.Error("Recently replaced signed prekey was removed")
+		t.FailNow()
+	}
+}
+
+// ageSignedPreKey replaces the given signed prekey in the store with a copy
+// that was created the given time ago.
+func ageSignedPreKey(signalStore *InMemorySignalProtocol, signedPreKey *record.SignedPreKey,
+	age time.Duration, serializer *serialize.Serializer) *record.SignedPreKey {
+
+	aged := record.NewSignedPreKey(signedPreKey.ID(), time.Now().Add(-age).Unix(), signedPreKey.KeyPair(),
+		signedPreKey.Signature(), serializer.SignedPreKeyRecord)
+	signalStore.StoreSignedPreKey(context.Background(), aged.ID(), aged)
+	return aged
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/sealedsender"
+	"go.mau.fi/libsignal/serialize"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// TestSealedSender checks sending sealed sender messages back and forth.
+func TestSealedSender(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+
+	// Create the server's trust root and certificates for both users.
+	trustRoot, _ := ecc.GenerateKeyPair()
+	serverKey, _ := ecc.GenerateKeyPair()
+	serverCertificate := protocol.NewServerCertificate(1, serverKey.PublicKey(), trustRoot.PrivateKey(), serializer.ServerCertificate)
+	expires := time.Now().Add(24 * time.Hour)
+	aliceCertificate := protocol.NewSenderCertificate(alice.address, alice.identityKeyPair.PublicKey().PublicKey(),
+		expires, serverCertificate, serverKey.PrivateKey(), serializer.SenderCertificate)
+	bobCertificate := protocol.NewSenderCertificate(bob.address, bob.identityKeyPair.PublicKey().PublicKey(),
+		expires, serverCertificate, serverKey.PrivateKey(), serializer.SenderCertificate)
+	validator := sealedsender.NewCertificateValidator(trustRoot.PublicKey())
+
+	// Emulate receiving the certificate from the server.
+	aliceCertificate, err := protocol.NewSenderCertificateFromBytes(aliceCertificate.Serialize(),
+		serializer.SenderCertificate, serializer.ServerCertificate)
+	if err != nil {
+		logger.Error("Unable to deserialize sender certificate: ", err)
+		t.FailNow()
+	}
+
+	// Build a session from Bob's prekey bundle.
+	retrievedPreKey := prekey.NewBundle(
+		bob.registrationID,
+		bob.deviceID,
+		bob.preKeys[0].ID(),
+		bob.signedPreKey.ID(),
+		bob.preKeys[0].KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+	err = alice.sessionBuilder.ProcessBundle(ctx, retrievedPreKey)
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+
+	// Send a sealed prekey message from Alice to Bob.
+	aliceCipher := sealedsender.NewCipher(alice.signalStore, alice.address, serializer)
+	bobCipher := sealedsender.NewCipher(bob.signalStore, bob.address, serializer)
+	plaintext := []byte("Hello!")
+	ciphertext, err := aliceCipher.Encrypt(ctx, bob.address, aliceCertificate, plaintext)
+	if err != nil {
+		logger.Error("Unable to encrypt sealed sender message: ", err)
+		t.FailNow()
+	}
+
+	// Tampered messages should be rejected without revealing the sender.
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 0x01
+	_, _, err = bobCipher.Decrypt(ctx, validator, tampered, time.Now())
+	if !errors.Is(err, signalerror.ErrBadMAC) {
+		logger.Error("Expected bad MAC error, got: ", err)
+		t.FailNow()
+	}
+
+	// Messages should only be accepted with the right trust root.
+	otherTrustRoot, _ := ecc.GenerateKeyPair()
+	otherValidator := sealedsender.NewCertificateValidator(otherTrustRoot.PublicKey())
+	_, _, err = bobCipher.Decrypt(ctx, otherValidator, ciphertext, time.Now())
+	if !errors.Is(err, signalerror.ErrInvalidCertificate) {
+		logger.Error("Expected invalid certificate error, got: ", err)
+		t.FailNow()
+	}
+
+	// Expired certificates should be rejected.
+	_, _, err = bobCipher.Decrypt(ctx, validator, ciphertext, expires.Add(time.Minute))
+	if !errors.Is(err, signalerror.ErrExpiredCertificate) {
+		logger.Error("Expected expired certificate error, got: ", err)
+		t.FailNow()
+	}
+
+	sender, decrypted, err := bobCipher.Decrypt(ctx, validator, ciphertext, time.Now())
+	if err != nil {
+		logger.Error("Unable to decrypt sealed sender message: ", err)
+		t.FailNow()
+	}
+	if sender.String() != alice.address.String() || string(decrypted) != string(plaintext) {
+		logger.Error("Decrypted message does not match - Sender: ", sender, " Decrypted: ", string(decrypted))
+		t.FailNow()
+	}
+
+	// Send a sealed response from Bob to Alice.
+	response := []byte("oui!")
+	ciphertext, err = bobCipher.Encrypt(ctx, alice.address, bobCertificate, response)
+	if err != nil {
+		logger.Error("Unable to encrypt sealed sender response: ", err)
+		t.FailNow()
+	}
+	sender, decrypted, err = aliceCipher.Decrypt(ctx, validator, ciphertext, time.Now())
+	if err != nil {
+		logger.Error("Unable to decrypt sealed sender response: ", err)
+		t.FailNow()
+	}
+	if sender.String() != bob.address.String() || string(decrypted) != string(response) {
+		logger.Error("Decrypted response does not match - Sender: ", sender, " Decrypted: ", string(decrypted))
+		t.FailNow()
+	}
+
+	// A certificate for a different identity key than the one that sealed the
+	// message should be rejected.
+	mallory := newUser("Mallory", 3, serializer)
+	malloryCertificate := protocol.NewSenderCertificate(alice.address, mallory.identityKeyPair.PublicKey().PublicKey(),
+		expires, serverCertificate, serverKey.PrivateKey(), serializer.SenderCertificate)
+	ciphertext, err = aliceCipher.Encrypt(ctx, bob.address, malloryCertificate, plaintext)
+	if err != nil {
+		logger.Error("Unable to encrypt sealed sender message: ", err)
+		t.FailNow()
+	}
+	_, _, err = bobCipher.Decrypt(ctx, validator, ciphertext, time.Now())
+	if !errors.Is(err, signalerror.ErrCertificateKeyMismatch) {
+		logger.Error("Expected certificate key mismatch error, got: ", err)
+		t.FailNow()
+	}
+
+	// Decrypting without a validator should fail instead of skipping the
+	// certificate check.
+	_, _, err = bobCipher.Decrypt(ctx, nil, ciphertext, time.Now())
+	if !errors.Is(err, signalerror.ErrNoCertificateValidator) {
+		logger.Error("Expected no certificate validator error, got: ", err)
+		t.FailNow()
+	}
+
+	// Encrypting for an untrusted identity should fail without advancing the
+	// session.
+	alice.signalStore.SaveIdentity(ctx, bob.address, mallory.identityKeyPair.PublicKey())
+	sessionRecord, _ := alice.signalStore.LoadSession(ctx, bob.address)
+	sessionBytes := sessionRecord.Serialize()
+	_, err = aliceCipher.Encrypt(ctx, bob.address, aliceCertificate, plaintext)
+	var identityErr *session.UntrustedIdentityError
+	if !errors.As(err, &identityErr) || identityErr.Direction != store.DirectionSending {
+		logger.Error("Expected untrusted identity error, got: ", err)
+		t.FailNow()
+	}
+	sessionRecord, _ = alice.signalStore.LoadSession(ctx, bob.address)
+	if !bytes.Equal(sessionRecord.Serialize(), sessionBytes) {
+		logger.Error("Session was advanced for an untrusted identity")
+		t.FailNow()
+	}
+}
+
+// TestSenderCertificateSerializing checks that sender certificates can be
+// validated after a round trip through each serializer.
+func TestSenderCertificateSerializing(t *testing.T) {
+	for _, serializer := range []*serialize.Serializer{serialize.NewJSONSerializer(), serialize.NewProtoBufSerializer()} {
+		trustRoot, _ := ecc.GenerateKeyPair()
+		serverKey, _ := ecc.GenerateKeyPair()
+		identityKey, _ := ecc.GenerateKeyPair()
+		serverCertificate := protocol.NewServerCertificate(7, serverKey.PublicKey(), trustRoot.PrivateKey(), serializer.ServerCertificate)
+		certificate := protocol.NewSenderCertificate(protocol.NewSignalAddress("Alice", 1), identityKey.PublicKey(),
+			time.Now().Add(time.Hour), serverCertificate, serverKey.PrivateKey(), serializer.SenderCertificate)
+
+		deserialized, err := protocol.NewSenderCertificateFromBytes(certificate.Serialize(),
+			serializer.SenderCertificate, serializer.ServerCertificate)
+		if err != nil {
+			logger.Error("Unable to deserialize sender certificate: ", err)
+			t.FailNow()
+		}
+		if deserialized.Sender().String() != "Alice:1" || deserialized.Signer().KeyID() != 7 ||
+			!bytes.Equal(deserialized.IdentityKey().Serialize(), identityKey.PublicKey().Serialize()) {
+			logger.Error("Deserialized sender certificate does not match")
+			t.FailNow()
+		}
+
+		validator := sealedsender.NewCertificateValidator(trustRoot.PublicKey())
+		if err := validator.Validate(deserialized, time.Now()); err != nil {
+			logger.Error("Unable to validate deserialized sender certificate: ", err)
+			t.FailNow()
+		}
+	}
+}
+
+// TestSealedSenderEmptyKeys checks that sealed sender messages and
+// certificates with empty keys are rejected instead of causing a panic.
+func TestSealedSenderEmptyKeys(t *testing.T) {
+	serializer := newSerializer()
+	trustRoot, _ := ecc.GenerateKeyPair()
+	serverKey, _ := ecc.GenerateKeyPair()
+	identityKey, _ := ecc.GenerateKeyPair()
+	serverCertificate := protocol.NewServerCertificate(7, serverKey.PublicKey(), trustRoot.PrivateKey(), serializer.ServerCertificate)
+	certificate := protocol.NewSenderCertificate(protocol.NewSignalAddress("Alice", 1), identityKey.PublicKey(),
+		time.Now().Add(time.Hour), serverCertificate, serverKey.PrivateKey(), serializer.SenderCertificate)
+
+	_, err := protocol.NewUnidentifiedSenderMessageFromStruct(&protocol.UnidentifiedSenderMessageStructure{
+		Version:          protocol.UnidentifiedSenderVersion,
+		EphemeralPublic:  []byte{},
+		EncryptedStatic:  []byte{1},
+		EncryptedMessage: []byte{1},
+	}, serializer.UnidentifiedSenderMessage)
+	if !errors.Is(err, signalerror.ErrIncompleteMessage) {
+		logger.Error("Expected incomplete message error for empty ephemeral key, got: ", err)
+		t.FailNow()
+	}
+
+	serverStructure := *serverCertificate.Structure()
+	serverStructure.Key = []byte{}
+	_, err = protocol.NewServerCertificateFromStruct(&serverStructure, serializer.ServerCertificate)
+	if !errors.Is(err, signalerror.ErrIncompleteMessage) {
+		logger.Error("Expected incomplete message error for empty server key, got: ", err)
+		t.FailNow()
+	}
+
+	senderStructure := *certificate.Structure()
+	senderStructure.IdentityKey = []byte{}
+	_, err = protocol.NewSenderCertificateFromStruct(&senderStructure, serializer.SenderCertificate, serializer.ServerCertificate)
+	if !errors.Is(err, signalerror.ErrIncompleteMessage) {
+		logger.Error("Expected incomplete message error for empty identity key, got: ", err)
+		t.FailNow()
+	}
+}
@@ -1,12 +1,17 @@
 package tests
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"testing"
 
+	"go.mau.fi/libsignal/groups"
+	groupRecord "go.mau.fi/libsignal/groups/state/record"
 	"go.mau.fi/libsignal/keys/prekey"
 	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/serialize"
 	"go.mau.fi/libsignal/session"
 	"go.mau.fi/libsignal/state/record"
 )
@@ -68,3 +73,107 @@ func TestSerializing(t *testing.T) {
 	fmt.Printf("Deserialized Session Record: %+v\n", deserializedSession)
 
 }
+
+// TestProtoBufRecordSerializing tests that session, prekey and sender key records
+// survive a round trip through the libsignal protobuf record format.
+func TestProtoBufRecordSerializing(t *testing.T) {
+	ctx := context.Background()
+	serializer := serialize.NewProtoBufSerializer()
+
+	// Create our users and establish a session between them.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+	bundle := prekey.NewBundle(
+		bob.registrationID,
+		bob.deviceID,
+		bob.preKeys[0].ID(),
+		bob.signedPreKey.ID(),
+		bob.preKeys[0].KeyPair().PublicKey(),
+		bob.signedPreKey.KeyPair().PublicKey(),
+		bob.signedPreKey.Signature(),
+		bob.identityKeyPair.PublicKey(),
+	)
+	err := alice.sessionBuilder.ProcessBundle(ctx, bundle)
+	if err != nil {
+		logger.Error("Unable to process prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	encrypted, err := aliceCipher.Encrypt(ctx, []byte("Hello!"))
+	if err != nil {
+		logger.Error("Unable to encrypt message: ", err)
+		t.FailNow()
+	}
+
+	// Round trip Alice's session record and make sure nothing was lost.
+	sessionRecord, _ := alice.sessionStore.LoadSession(ctx, bob.address)
+	serializedSession := sessionRecord.Serialize()
+	deserializedSession, err := record.NewSessionFromBytes(serializedSession, serializer.Session, serializer.State)
+	if err != nil {
+		logger.Error("Failed to deserialize session: ", err)
+		t.FailNow()
+	}
+	if !bytes.Equal(serializedSession, deserializedSession.Serialize()) {
+		logger.Error("Session record changed after a protobuf round trip")
+		t.FailNow()
+	}
+	if !deserializedSession.SessionState().HasUnacknowledgedPreKeyMessage() {
+		logger.Error("Pending prekey was lost after a protobuf round trip")
+		t.FailNow()
+	}
+
+	// Bob should be able to decrypt the message with his deserialized prekeys.
+	deserializedPreKey, err := record.NewPreKeyFromBytes(bob.preKeys[0].Serialize(), serializer.PreKeyRecord)
+	if err != nil {
+		logger.Error("Failed to deserialize prekey: ", err)
+		t.FailNow()
+	}
+	bob.preKeyStore.StorePreKey(ctx, bob.preKeys[0].ID().Value, deserializedPreKey)
+	deserializedSignedPreKey, err := record.NewSignedPreKeyFromBytes(bob.signedPreKey.Serialize(), serializer.SignedPreKeyRecord)
+	if err != nil {
+		logger.Error("Failed to deserialize signed prekey: ", err)
+		t.FailNow()
+	}
+	if deserializedSignedPreKey.Timestamp() != bob.signedPreKey.Timestamp() {
+		logger.Error("Signed prekey timestamp changed after a protobuf round trip")
+		t.FailNow()
+	}
+	bob.signedPreKeyStore.StoreSignedPreKey(ctx, bob.signedPreKey.ID(), deserializedSignedPreKey)
+
+	receivedMessage, err := protocol.NewPreKeySignalMessageFromBytes(encrypted.Serialize(), serializer.PreKeySignalMessage, serializer.SignalMessage)
+	if err != nil {
+		logger.Error("Unable to deserialize prekey message: ", err)
+		t.FailNow()
+	}
+	bobCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	plaintext, err := bobCipher.DecryptMessage(ctx, receivedMessage)
+	if err != nil || string(plaintext) != "Hello!" {
+		logger.Error("Unable to decrypt message with deserialized prekeys: ", err)
+		t.FailNow()
+	}
+
+	// Round trip a sender key record after a few messages have been skipped.
+	senderKeyName := protocol.NewSenderKeyName("123", alice.address)
+	skdm, err := alice.groupBuilder.Create(ctx, senderKeyName)
+	if err != nil {
+		logger.Error("Unable to create group session: ", err)
+		t.FailNow()
+	}
+	bob.groupBuilder.Process(ctx, senderKeyName, skdm)
+	plainMessages, encryptedMessages := sendGroupMessages(5, groups.NewGroupCipher(alice.groupBuilder, senderKeyName, alice.senderKeyStore), serializer, t)
+	bobGroupCipher := groups.NewGroupCipher(bob.groupBuilder, senderKeyName, bob.senderKeyStore)
+	receiveGroupMessages(encryptedMessages[4:], plainMessages[4:], bobGroupCipher, t)
+
+	senderKeyRecord, _ := bob.senderKeyStore.LoadSenderKey(ctx, senderKeyName)
+	deserializedSenderKey, err := groupRecord.NewSenderKeyFromBytes(senderKeyRecord.Serialize(), serializer.SenderKeyRecord, serializer.SenderKeyState)
+	if err != nil {
+		logger.Error("Failed to deserialize sender key record: ", err)
+		t.FailNow()
+	}
+	bob.senderKeyStore.StoreSenderKey(ctx, senderKeyName, deserializedSenderKey)
+
+	// The skipped message keys are rebuilt from their seeds.
+	receiveGroupMessages(encryptedMessages[:4], plainMessages[:4], bobGroupCipher, t)
+}
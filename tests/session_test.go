@@ -1,14 +1,19 @@
 package tests
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"testing"
 
+	"go.mau.fi/libsignal/ecc"
 	"go.mau.fi/libsignal/keys/prekey"
 	"go.mau.fi/libsignal/logger"
 	"go.mau.fi/libsignal/protocol"
 	"go.mau.fi/libsignal/serialize"
 	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/optional"
 )
 
 // TestSessionBuilder checks building of a session.
@@ -256,6 +261,115 @@ func TestSessionOutOfOrder(t *testing.T) {
 
 }
 
+// TestSessionForgedMessage checks that decrypting an invalid message does
+// not change the session state.
+func TestSessionForgedMessage(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bob.buildSession(alice.address, serializer)
+
+	err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	bob.sessionBuilder = session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	messageStrings, messages := sendMessages(1, aliceSessionCipher, serializer, t)
+	receiveMessages(messages, messageStrings, bobSessionCipher, t)
+
+	// Bob's reply uses a new ratchet key, so decrypting it creates a new chain.
+	reply := encryptMessage("Hello Alice!", bobSessionCipher, serializer, t).(*protocol.SignalMessage)
+	forged := bytes.Clone(reply.Serialize())
+	forged[len(forged)-1] ^= 0xFF
+	forgedMessage, err := protocol.NewSignalMessageFromBytes(forged, serializer.SignalMessage)
+	if err != nil {
+		logger.Error("Unable to deserialize forged message: ", err)
+		t.FailNow()
+	}
+
+	// The forged message should be rejected without changing the record.
+	sessionRecord, _ := alice.sessionStore.LoadSession(ctx, bob.address)
+	before := sessionRecord.Serialize()
+	clone := sessionRecord.Clone()
+	if !bytes.Equal(clone.Serialize(), before) {
+		logger.Error("Cloned session record does not match")
+		t.FailNow()
+	}
+	_, _, err = aliceSessionCipher.DecryptWithRecord(ctx, sessionRecord, forgedMessage)
+	if !errors.Is(err, signalerror.ErrNoValidSessions) {
+		logger.Error("Expected no valid sessions error, got: ", err)
+		t.FailNow()
+	}
+	if !bytes.Equal(sessionRecord.Serialize(), before) {
+		logger.Error("Session record was changed by a forged message")
+		t.FailNow()
+	}
+	_, _, err = aliceSessionCipher.DecryptWithState(ctx, sessionRecord.SessionState(), forgedMessage)
+	if err == nil || !bytes.Equal(sessionRecord.Serialize(), before) {
+		logger.Error("Session state was changed by a forged message: ", err)
+		t.FailNow()
+	}
+
+	// The real message should still decrypt, and only change the record.
+	plaintext, _, err := aliceSessionCipher.DecryptWithRecord(ctx, sessionRecord, reply)
+	if err != nil || string(plaintext) != "Hello Alice!" {
+		logger.Error("Unable to decrypt message after a forged message: ", err)
+		t.FailNow()
+	}
+	if !bytes.Equal(clone.Serialize(), before) {
+		logger.Error("Decrypting changed the cloned session record")
+		t.FailNow()
+	}
+
+	// A forged prekey message with Alice's public identity key should be
+	// rejected without changing Bob's session or saving the identity key.
+	baseKey, _ := ecc.GenerateKeyPair()
+	ratchetKey, _ := ecc.GenerateKeyPair()
+	signalMessage, err := protocol.NewSignalMessage(protocol.PreKyberVersion, 0, 0, make([]byte, 32), ratchetKey.PublicKey(),
+		make([]byte, 32), alice.identityKeyPair.PublicKey(), bob.identityKeyPair.PublicKey(), serializer.SignalMessage)
+	if err != nil {
+		logger.Error("Unable to create forged signal message: ", err)
+		t.FailNow()
+	}
+	forgedPreKeyMessage, err := protocol.NewPreKeySignalMessage(protocol.PreKyberVersion, alice.registrationID, optional.NewEmptyUint32(),
+		bob.signedPreKey.ID(), baseKey.PublicKey(), alice.identityKeyPair.PublicKey(), signalMessage,
+		serializer.PreKeySignalMessage, serializer.SignalMessage)
+	if err != nil {
+		logger.Error("Unable to create forged prekey message: ", err)
+		t.FailNow()
+	}
+	bobRecord, _ := bob.sessionStore.LoadSession(ctx, alice.address)
+	bobBefore := bobRecord.Serialize()
+	if _, err = bobSessionCipher.DecryptMessage(ctx, forgedPreKeyMessage); err == nil {
+		logger.Error("Forged prekey message was decrypted")
+		t.FailNow()
+	}
+	bobRecord, _ = bob.sessionStore.LoadSession(ctx, alice.address)
+	if !bytes.Equal(bobRecord.Serialize(), bobBefore) {
+		logger.Error("Session record was changed by a forged prekey message")
+		t.FailNow()
+	}
+	malloryAddress := protocol.NewSignalAddress("Mallory", 1)
+	malloryCipher := session.NewCipher(session.NewBuilderFromSignal(bob.signalStore, malloryAddress, serializer), malloryAddress)
+	if _, err = malloryCipher.DecryptMessage(ctx, forgedPreKeyMessage); err == nil {
+		logger.Error("Forged prekey message was decrypted")
+		t.FailNow()
+	}
+	if savedIdentity, _ := bob.identityStore.LoadIdentity(ctx, malloryAddress); savedIdentity != nil {
+		logger.Error("Forged prekey message saved an identity key")
+		t.FailNow()
+	}
+}
+
 // sendMessages will generate and return a list of plaintext and encrypted messages.
 func sendMessages(count int, cipher *session.Cipher, serializer *serialize.Serializer, t *testing.T) ([]string, []protocol.CiphertextMessage) {
 	texts := []string{
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/state/record"
+	"go.mau.fi/libsignal/state/store/sqlstore"
+)
+
+// recordingConnector is a database/sql connector that records the queries
+// it receives instead of running them.
+type recordingConnector struct {
+	queries []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn{c}, nil
+}
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+type recordingConn struct {
+	connector *recordingConnector
+}
+
+func (c recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.ErrUnsupported }
+func (c recordingConn) Close() error                        { return nil }
+func (c recordingConn) Begin() (driver.Tx, error)           { return nil, errors.ErrUnsupported }
+
+func (c recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.connector.queries = append(c.connector.queries, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.connector.queries = append(c.connector.queries, query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"value"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+// TestSQLStoreDialects checks that queries use numbered placeholders with
+// the Postgres dialect and question marks with the SQLite dialect.
+func TestSQLStoreDialects(t *testing.T) {
+	ctx := context.Background()
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	sessionRecord := record.NewSession(serializer.Session, serializer.State)
+
+	tests := []struct {
+		dialect  sqlstore.Dialect
+		expected []string
+	}{
+		{sqlstore.DialectPostgres, []string{
+			"SELECT 1 FROM signal_prekeys WHERE account_id=$1 AND key_id=$2",
+			"INSERT INTO signal_sessions (account_id, their_name, their_device_id, record) VALUES ($1, $2, $3, $4)",
+		}},
+		{sqlstore.DialectSQLite, []string{
+			"SELECT 1 FROM signal_prekeys WHERE account_id=? AND key_id=?",
+			"INSERT INTO signal_sessions (account_id, their_name, their_device_id, record) VALUES (?, ?, ?, ?)",
+		}},
+	}
+	for _, test := range tests {
+		logger.Info("Testing ", test.dialect, " queries...")
+		connector := &recordingConnector{}
+		db := sql.OpenDB(connector)
+		signalStore := sqlstore.NewStore(db, test.dialect, alice.name, alice.identityKeyPair, alice.registrationID, serializer)
+		if _, err := signalStore.ContainsPreKey(ctx, 1); err != nil {
+			logger.Error("Unable to check prekey: ", err)
+			t.FailNow()
+		}
+		if err := signalStore.StoreSession(ctx, bob.address, sessionRecord); err != nil {
+			logger.Error("Unable to store session: ", err)
+			t.FailNow()
+		}
+		db.Close()
+
+		if len(connector.queries) != len(test.expected) {
+			logger.Error("Unexpected queries: ", connector.queries)
+			t.FailNow()
+		}
+		for i, expected := range test.expected {
+			if !strings.Contains(connector.queries[i], expected) {
+				logger.Error("Query ", connector.queries[i], " does not contain ", expected)
+				t.FailNow()
+			}
+		}
+	}
+}
@@ -15,14 +15,14 @@ import (
 // IdentityKeyStore
 func NewInMemoryIdentityKey(identityKey *identity.KeyPair, localRegistrationID uint32) *InMemoryIdentityKey {
 	return &InMemoryIdentityKey{
-		trustedKeys:         make(map[*protocol.SignalAddress]*identity.Key),
+		trustedKeys:         make(map[protocol.SignalAddress]*identity.Key),
 		identityKeyPair:     identityKey,
 		localRegistrationID: localRegistrationID,
 	}
 }
 
 type InMemoryIdentityKey struct {
-	trustedKeys         map[*protocol.SignalAddress]*identity.Key
+	trustedKeys         map[protocol.SignalAddress]*identity.Key
 	identityKeyPair     *identity.KeyPair
 	localRegistrationID uint32
 }
@@ -36,15 +36,19 @@ func (i *InMemoryIdentityKey) GetLocalRegistrationID() uint32 {
 }
 
 func (i *InMemoryIdentityKey) SaveIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) error {
-	i.trustedKeys[address] = identityKey
+	i.trustedKeys[*address] = identityKey
 	return nil
 }
 
 func (i *InMemoryIdentityKey) IsTrustedIdentity(ctx context.Context, address *protocol.SignalAddress, identityKey *identity.Key) (bool, error) {
-	trusted := i.trustedKeys[address]
+	trusted := i.trustedKeys[*address]
 	return (trusted == nil || trusted.Fingerprint() == identityKey.Fingerprint()), nil
 }
 
+func (i *InMemoryIdentityKey) LoadIdentity(ctx context.Context, address *protocol.SignalAddress) (*identity.Key, error) {
+	return i.trustedKeys[*address], nil
+}
+
 // PreKeyStore
 func NewInMemoryPreKey() *InMemoryPreKey {
 	return &InMemoryPreKey{
@@ -78,13 +82,13 @@ func (i *InMemoryPreKey) RemovePreKey(ctx context.Context, preKeyID uint32) erro
 // SessionStore
 func NewInMemorySession(serializer *serialize.Serializer) *InMemorySession {
 	return &InMemorySession{
-		sessions:   make(map[*protocol.SignalAddress]*record.Session),
+		sessions:   make(map[protocol.SignalAddress]*record.Session),
 		serializer: serializer,
 	}
 }
 
 type InMemorySession struct {
-	sessions   map[*protocol.SignalAddress]*record.Session
+	sessions   map[protocol.SignalAddress]*record.Session
 	serializer *serialize.Serializer
 }
 
@@ -94,10 +98,10 @@ func (i *InMemorySession) LoadSession(ctx context.Context, address *protocol.Sig
 		return nil, err
 	}
 	if contains {
-		return i.sessions[address], nil
+		return i.sessions[*address], nil
 	}
 	sessionRecord := record.NewSession(i.serializer.Session, i.serializer.State)
-	i.sessions[address] = sessionRecord
+	i.sessions[*address] = sessionRecord
 
 	return sessionRecord, nil
 }
@@ -115,22 +119,22 @@ func (i *InMemorySession) GetSubDeviceSessions(ctx context.Context, name string)
 }
 
 func (i *InMemorySession) StoreSession(ctx context.Context, remoteAddress *protocol.SignalAddress, record *record.Session) error {
-	i.sessions[remoteAddress] = record
+	i.sessions[*remoteAddress] = record
 	return nil
 }
 
 func (i *InMemorySession) ContainsSession(ctx context.Context, remoteAddress *protocol.SignalAddress) (bool, error) {
-	_, ok := i.sessions[remoteAddress]
+	_, ok := i.sessions[*remoteAddress]
 	return ok, nil
 }
 
 func (i *InMemorySession) DeleteSession(ctx context.Context, remoteAddress *protocol.SignalAddress) error {
-	delete(i.sessions, remoteAddress)
+	delete(i.sessions, *remoteAddress)
 	return nil
 }
 
 func (i *InMemorySession) DeleteAllSessions(ctx context.Context) error {
-	i.sessions = make(map[*protocol.SignalAddress]*record.Session)
+	i.sessions = make(map[protocol.SignalAddress]*record.Session)
 	return nil
 }
 
@@ -174,6 +178,36 @@ func (i *InMemorySignedPreKey) RemoveSignedPreKey(ctx context.Context, signedPre
 	return nil
 }
 
+// KyberPreKeyStore
+func NewInMemoryKyberPreKey() *InMemoryKyberPreKey {
+	return &InMemoryKyberPreKey{
+		store: make(map[uint32]*record.KyberPreKey),
+	}
+}
+
+type InMemoryKyberPreKey struct {
+	store map[uint32]*record.KyberPreKey
+}
+
+func (i *InMemoryKyberPreKey) LoadKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (*record.KyberPreKey, error) {
+	return i.store[kyberPreKeyID], nil
+}
+
+func (i *InMemoryKyberPreKey) StoreKyberPreKey(ctx context.Context, kyberPreKeyID uint32, record *record.KyberPreKey) error {
+	i.store[kyberPreKeyID] = record
+	return nil
+}
+
+func (i *InMemoryKyberPreKey) ContainsKyberPreKey(ctx context.Context, kyberPreKeyID uint32) (bool, error) {
+	_, ok := i.store[kyberPreKeyID]
+	return ok, nil
+}
+
+func (i *InMemoryKyberPreKey) MarkKyberPreKeyUsed(ctx context.Context, kyberPreKeyID uint32) error {
+	delete(i.store, kyberPreKeyID)
+	return nil
+}
+
 func NewInMemorySenderKey() *InMemorySenderKey {
 	return &InMemorySenderKey{
 		store: make(map[*protocol.SenderKeyName]*groupRecord.SenderKey),
@@ -192,3 +226,24 @@ func (i *InMemorySenderKey) StoreSenderKey(ctx context.Context, senderKeyName *p
 func (i *InMemorySenderKey) LoadSenderKey(ctx context.Context, senderKeyName *protocol.SenderKeyName) (*groupRecord.SenderKey, error) {
 	return i.store[senderKeyName], nil
 }
+
+// SignalProtocolStore
+func NewInMemorySignalProtocol(identityKey *identity.KeyPair, localRegistrationID uint32, serializer *serialize.Serializer) *InMemorySignalProtocol {
+	return &InMemorySignalProtocol{
+		InMemoryIdentityKey:  NewInMemoryIdentityKey(identityKey, localRegistrationID),
+		InMemoryPreKey:       NewInMemoryPreKey(),
+		InMemorySession:      NewInMemorySession(serializer),
+		InMemorySignedPreKey: NewInMemorySignedPreKey(),
+		InMemoryKyberPreKey:  NewInMemoryKyberPreKey(),
+		InMemorySenderKey:    NewInMemorySenderKey(),
+	}
+}
+
+type InMemorySignalProtocol struct {
+	*InMemoryIdentityKey
+	*InMemoryPreKey
+	*InMemorySession
+	*InMemorySignedPreKey
+	*InMemoryKyberPreKey
+	*InMemorySenderKey
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+)
+
+var errRemovePreKey = errors.New("remove prekey failed")
+
+type txContextKey struct{}
+
+// transactionalStore is an in-memory signal store that snapshots sessions,
+// identities and prekeys at the start of a transaction and restores them
+// if the transaction fails.
+type transactionalStore struct {
+	*InMemorySignalProtocol
+	failRemovePreKey bool
+	transactions     int
+}
+
+func (s *transactionalStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txContextKey{}) != nil {
+		return fn(ctx)
+	}
+	s.transactions++
+
+	sessions := maps.Clone(s.InMemorySession.sessions)
+	trustedKeys := maps.Clone(s.InMemoryIdentityKey.trustedKeys)
+	preKeys := maps.Clone(s.InMemoryPreKey.store)
+
+	err := fn(context.WithValue(ctx, txContextKey{}, true))
+	if err != nil {
+		s.InMemorySession.sessions = sessions
+		s.InMemoryIdentityKey.trustedKeys = trustedKeys
+		s.InMemoryPreKey.store = preKeys
+	}
+	return err
+}
+
+func (s *transactionalStore) RemovePreKey(ctx context.Context, preKeyID uint32) error {
+	if s.failRemovePreKey {
+		return errRemovePreKey
+	}
+	return s.InMemorySignalProtocol.RemovePreKey(ctx, preKeyID)
+}
+
+// TestTransactionalStore checks that a failed decryption rolls back the
+// session that was created for it.
+func TestTransactionalStore(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other. Bob uses a store that
+	// supports transactions.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	bobStore := &transactionalStore{InMemorySignalProtocol: bob.signalStore, failRemovePreKey: true}
+	bob.sessionBuilder = session.NewBuilderFromSignal(bobStore, alice.address, serializer)
+
+	err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	if err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	message := encryptMessage("Hello!", aliceSessionCipher, serializer, t).(*protocol.PreKeySignalMessage)
+
+	// Removing the prekey fails, so the new session should not be kept.
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	_, err = bobSessionCipher.DecryptMessage(ctx, message)
+	if !errors.Is(err, errRemovePreKey) {
+		logger.Error("Expected remove prekey error, got: ", err)
+		t.FailNow()
+	}
+	if bobStore.transactions != 1 {
+		logger.Error("Expected decryption to run in one transaction, got: ", bobStore.transactions)
+		t.FailNow()
+	}
+	if ok, _ := bobStore.ContainsSession(ctx, alice.address); ok {
+		logger.Error("Session was not rolled back after a failed decryption")
+		t.FailNow()
+	}
+	if _, ok := bobStore.trustedKeys[*alice.address]; ok {
+		logger.Error("Identity was not rolled back after a failed decryption")
+		t.FailNow()
+	}
+
+	// The same message should decrypt once the store works again.
+	bobStore.failRemovePreKey = false
+	plaintext, err := bobSessionCipher.DecryptMessage(ctx, message)
+	if err != nil || string(plaintext) != "Hello!" {
+		logger.Error("Unable to decrypt message after retrying: ", err)
+		t.FailNow()
+	}
+	if ok, _ := bobStore.ContainsPreKey(ctx, bob.preKeys[0].ID().Value); ok {
+		logger.Error("Prekey was not removed after decrypting")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/state/store"
+)
+
+// TestUntrustedIdentityError checks that untrusted identity errors contain
+// the changed identity key and that the message can be decrypted again
+// after the key is approved.
+func TestUntrustedIdentityError(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	// Create our users who will talk to each other.
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.sessionBuilder = session.NewBuilderFromSignal(alice.signalStore, bob.address, serializer)
+
+	// Alice has seen a different identity key for Bob before.
+	mallory := newUser("Mallory", 1, serializer)
+	oldIdentity := mallory.identityKeyPair.PublicKey()
+	alice.identityStore.SaveIdentity(ctx, bob.address, oldIdentity)
+
+	// Sending to Bob's new identity should fail.
+	err := alice.sessionBuilder.ProcessBundle(ctx, newKyberBundle(bob))
+	var identityErr *session.UntrustedIdentityError
+	if !errors.As(err, &identityErr) || identityErr.Direction != store.DirectionSending || identityErr.Message != nil {
+		logger.Error("Expected untrusted identity error when sending, got: ", err)
+		t.FailNow()
+	}
+
+	// Bob sends a message to Alice, which should fail with the new key.
+	bob.buildSession(alice.address, serializer)
+	if err = bob.sessionBuilder.ProcessBundle(ctx, newKyberBundle(alice)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	bobSessionCipher := session.NewCipher(bob.sessionBuilder, alice.address)
+	message := encryptMessage("Hello Alice!", bobSessionCipher, serializer, t).(*protocol.PreKeySignalMessage)
+
+	aliceSessionCipher := session.NewCipher(alice.sessionBuilder, bob.address)
+	_, err = aliceSessionCipher.DecryptMessage(ctx, message)
+	if !errors.As(err, &identityErr) {
+		logger.Error("Expected untrusted identity error when receiving, got: ", err)
+		t.FailNow()
+	}
+	if *identityErr.Address != *bob.address || identityErr.Direction != store.DirectionReceiving ||
+		identityErr.StoredIdentity.Fingerprint() != oldIdentity.Fingerprint() ||
+		identityErr.NewIdentity.Fingerprint() != bob.identityKeyPair.PublicKey().Fingerprint() ||
+		identityErr.Message != message {
+		logger.Error("Unexpected untrusted identity error: ", identityErr)
+		t.FailNow()
+	}
+
+	// Retrying without approving should still fail.
+	if _, err = aliceSessionCipher.RetryDecrypt(ctx, identityErr); !errors.As(err, &identityErr) {
+		logger.Error("Expected untrusted identity error when retrying, got: ", err)
+		t.FailNow()
+	}
+
+	// After approving the new key, the message should be decrypted.
+	if err = aliceSessionCipher.ApproveIdentity(ctx, identityErr); err != nil {
+		logger.Error("Unable to approve identity: ", err)
+		t.FailNow()
+	}
+	result, err := aliceSessionCipher.RetryDecrypt(ctx, identityErr)
+	if err != nil || string(result.Plaintext) != "Hello Alice!" {
+		logger.Error("Unable to decrypt message after approving identity: ", err)
+		t.FailNow()
+	}
+
+	// Once the session is acknowledged, Bob sends regular messages, which
+	// should also be decrypted after the key is approved again.
+	reply := encryptMessage("Hello Bob!", aliceSessionCipher, serializer, t)
+	if decryptMessage(reply, bobSessionCipher, t) != "Hello Bob!" {
+		logger.Error("Unable to decrypt reply")
+		t.FailNow()
+	}
+	alice.identityStore.SaveIdentity(ctx, bob.address, oldIdentity)
+	signalMessage := encryptMessage("Hello again!", bobSessionCipher, serializer, t).(*protocol.SignalMessage)
+	_, err = aliceSessionCipher.Decrypt(ctx, signalMessage)
+	if !errors.As(err, &identityErr) || identityErr.Message != signalMessage {
+		logger.Error("Expected untrusted identity error for signal message, got: ", err)
+		t.FailNow()
+	}
+	if err = aliceSessionCipher.ApproveIdentity(ctx, identityErr); err != nil {
+		logger.Error("Unable to approve identity: ", err)
+		t.FailNow()
+	}
+	result, err = aliceSessionCipher.RetryDecrypt(ctx, identityErr)
+	if err != nil || string(result.Plaintext) != "Hello again!" {
+		logger.Error("Unable to decrypt signal message after approving identity: ", err)
+		t.FailNow()
+	}
+}
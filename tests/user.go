@@ -23,10 +23,13 @@ type user struct {
 
 	preKeys      []*record.PreKey
 	signedPreKey *record.SignedPreKey
+	kyberPreKey  *record.KyberPreKey
 
+	signalStore       *InMemorySignalProtocol
 	sessionStore      *InMemorySession
 	preKeyStore       *InMemoryPreKey
 	signedPreKeyStore *InMemorySignedPreKey
+	kyberPreKeyStore  *InMemoryKyberPreKey
 	identityStore     *InMemoryIdentityKey
 	senderKeyStore    *InMemorySenderKey
 
@@ -67,12 +70,17 @@ func newUser(name string, deviceID uint32, serializer *serialize.Serializer) *us
 	// Generate Signed PreKey
 	signalUser.signedPreKey, _ = keyhelper.GenerateSignedPreKey(signalUser.identityKeyPair, 0, serializer.SignedPreKeyRecord)
 
+	// Generate Kyber PreKey
+	signalUser.kyberPreKey, _ = keyhelper.GenerateKyberPreKey(signalUser.identityKeyPair, 0, serializer.KyberPreKeyRecord)
+
 	// Create all our record stores using an in-memory implementation.
-	signalUser.sessionStore = NewInMemorySession(serializer)
-	signalUser.preKeyStore = NewInMemoryPreKey()
-	signalUser.signedPreKeyStore = NewInMemorySignedPreKey()
-	signalUser.identityStore = NewInMemoryIdentityKey(signalUser.identityKeyPair, signalUser.registrationID)
-	signalUser.senderKeyStore = NewInMemorySenderKey()
+	signalUser.signalStore = NewInMemorySignalProtocol(signalUser.identityKeyPair, signalUser.registrationID, serializer)
+	signalUser.sessionStore = signalUser.signalStore.InMemorySession
+	signalUser.preKeyStore = signalUser.signalStore.InMemoryPreKey
+	signalUser.signedPreKeyStore = signalUser.signalStore.InMemorySignedPreKey
+	signalUser.kyberPreKeyStore = signalUser.signalStore.InMemoryKyberPreKey
+	signalUser.identityStore = signalUser.signalStore.InMemoryIdentityKey
+	signalUser.senderKeyStore = signalUser.signalStore.InMemorySenderKey
 
 	// Put all our pre keys in our local stores.
 	ctx := context.Background()
@@ -97,6 +105,9 @@ func newUser(name string, deviceID uint32, serializer *serialize.Serializer) *us
 		),
 	)
 
+	// Store our own kyber prekey
+	signalUser.kyberPreKeyStore.StoreKyberPreKey(ctx, signalUser.kyberPreKey.ID(), signalUser.kyberPreKey)
+
 	// Create a remote address that we'll be building our session with.
 	signalUser.name = name
 	signalUser.deviceID = deviceID
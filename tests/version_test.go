@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mau.fi/libsignal/keys/prekey"
+	"go.mau.fi/libsignal/logger"
+	"go.mau.fi/libsignal/protocol"
+	"go.mau.fi/libsignal/session"
+	"go.mau.fi/libsignal/signalerror"
+	"go.mau.fi/libsignal/util/optional"
+)
+
+// TestMessageVersions checks that sessions built with a kyber prekey use
+// version 4 messages, that sessions without one use version 3, and that
+// version 3 messages from older clients are still accepted.
+func TestMessageVersions(t *testing.T) {
+	ctx := context.Background()
+
+	// Create a serializer object that will be used to encode/decode data.
+	serializer := newSerializer()
+
+	tests := []struct {
+		name        string
+		kyber       bool
+		olderClient bool
+		version     int
+	}{
+		{name: "kyber bundle", kyber: true, version: protocol.CurrentVersion},
+		{name: "bundle without kyber", version: protocol.PreKyberVersion},
+		{name: "older client", kyber: true, olderClient: true, version: protocol.PreKyberVersion},
+	}
+	for _, test := range tests {
+		logger.Info("Testing message versions with ", test.name, "...")
+
+		// Create our users who will talk to each other.
+		alice := newUser("Alice", 1, serializer)
+		bob := newUser("Bob", 2, serializer)
+		aliceBuilder := session.NewBuilderFromSignal(alice.signalStore, bob.address, serializer)
+		bobBuilder := session.NewBuilderFromSignal(bob.signalStore, alice.address, serializer)
+
+		bundle := newKyberBundle(bob)
+		if !test.kyber {
+			bundle = prekey.NewBundle(
+				bob.registrationID,
+				bob.deviceID,
+				bob.preKeys[0].ID(),
+				bob.signedPreKey.ID(),
+				bob.preKeys[0].KeyPair().PublicKey(),
+				bob.signedPreKey.KeyPair().PublicKey(),
+				bob.signedPreKey.Signature(),
+				bob.identityKeyPair.PublicKey(),
+			)
+		}
+		if err := aliceBuilder.ProcessBundle(ctx, bundle); err != nil {
+			logger.Error("Unable to process retrieved prekey bundle: ", err)
+			t.FailNow()
+		}
+
+		// Older clients sent version 3 messages even if the session was
+		// built with a kyber prekey.
+		if test.olderClient {
+			sessionRecord, _ := alice.signalStore.LoadSession(ctx, bob.address)
+			sessionRecord.SessionState().SetVersion(protocol.PreKyberVersion)
+			alice.signalStore.StoreSession(ctx, bob.address, sessionRecord)
+		}
+
+		aliceSessionCipher := session.NewCipher(aliceBuilder, bob.address)
+		bobSessionCipher := session.NewCipher(bobBuilder, alice.address)
+		aliceMessageStrings, aliceMessages := sendMessages(3, aliceSessionCipher, serializer, t)
+		receiveMessages(aliceMessages, aliceMessageStrings, bobSessionCipher, t)
+		bobMessageStrings, bobMessages := sendMessages(3, bobSessionCipher, serializer, t)
+		receiveMessages(bobMessages, bobMessageStrings, aliceSessionCipher, t)
+
+		// Both sessions and all messages should use the negotiated version.
+		aliceSession, _ := alice.signalStore.LoadSession(ctx, bob.address)
+		bobSession, _ := bob.signalStore.LoadSession(ctx, alice.address)
+		if aliceSession.SessionState().Version() != test.version || bobSession.SessionState().Version() != test.version {
+			logger.Error("Unexpected session versions: ", aliceSession.SessionState().Version(), " ", bobSession.SessionState().Version())
+			t.FailNow()
+		}
+		for _, message := range append(aliceMessages, bobMessages...) {
+			// Alice's messages are prekey messages until Bob has replied.
+			if preKeyMessage, ok := message.(*protocol.PreKeySignalMessage); ok {
+				if preKeyMessage.MessageVersion() != test.version {
+					logger.Error("Unexpected prekey message version: ", preKeyMessage.MessageVersion())
+					t.FailNow()
+				}
+				message = preKeyMessage.WhisperMessage()
+			}
+			signalMessage := message.(*protocol.SignalMessage)
+			serialized := signalMessage.Serialize()
+			if signalMessage.MessageVersion() != test.version || int(serialized[0]>>4) != test.version {
+				logger.Error("Unexpected message version: ", signalMessage.MessageVersion(), " ", serialized[0])
+				t.FailNow()
+			}
+		}
+	}
+}
+
+// TestMessageVersionErrors checks that messages with an unknown version or
+// a version 4 prekey message without a kyber prekey are rejected.
+func TestMessageVersionErrors(t *testing.T) {
+	serializer := newSerializer()
+	alice := newUser("Alice", 1, serializer)
+	bob := newUser("Bob", 2, serializer)
+	alice.buildSession(bob.address, serializer)
+	if err := alice.sessionBuilder.ProcessBundle(context.Background(), newKyberBundle(bob)); err != nil {
+		logger.Error("Unable to process retrieved prekey bundle: ", err)
+		t.FailNow()
+	}
+	message := encryptMessage("Hello!", session.NewCipher(alice.sessionBuilder, bob.address), serializer, t)
+	preKeyMessage := message.(*protocol.PreKeySignalMessage)
+	structure := protocol.PreKeySignalMessageStructure{
+		RegistrationID:  preKeyMessage.RegistrationID(),
+		PreKeyID:        preKeyMessage.PreKeyID(),
+		SignedPreKeyID:  preKeyMessage.SignedPreKeyID(),
+		KyberPreKeyID:   preKeyMessage.KyberPreKeyID(),
+		KyberCiphertext: preKeyMessage.KyberCiphertext(),
+		BaseKey:         preKeyMessage.BaseKey().Serialize(),
+		IdentityKey:     preKeyMessage.IdentityKey().Serialize(),
+		Message:         preKeyMessage.WhisperMessage().Serialize(),
+		Version:         protocol.CurrentVersion,
+	}
+
+	// The unmodified structure should be accepted.
+	_, err := protocol.NewPreKeySignalMessageFromStruct(&structure, serializer.PreKeySignalMessage, serializer.SignalMessage)
+	if err != nil {
+		logger.Error("Unable to build prekey message: ", err)
+		t.FailNow()
+	}
+
+	// Version 4 prekey messages must have a kyber prekey.
+	withoutKyber := structure
+	withoutKyber.KyberPreKeyID = optional.NewEmptyUint32()
+	withoutKyber.KyberCiphertext = nil
+	_, err = protocol.NewPreKeySignalMessageFromStruct(&withoutKyber, serializer.PreKeySignalMessage, serializer.SignalMessage)
+	if !errors.Is(err, signalerror.ErrIncompleteMessage) {
+		logger.Error("Expected incomplete message error, got: ", err)
+		t.FailNow()
+	}
+
+	// Future versions should be rejected.
+	future := structure
+	future.Version = protocol.CurrentVersion + 1
+	_, err = protocol.NewPreKeySignalMessageFromStruct(&future, serializer.PreKeySignalMessage, serializer.SignalMessage)
+	if !errors.Is(err, signalerror.ErrUnknownMessageVersion) {
+		logger.Error("Expected unknown message version error, got: ", err)
+		t.FailNow()
+	}
+	signalMessage := preKeyMessage.WhisperMessage().Structure()
+	signalMessage.Version = protocol.CurrentVersion + 1
+	_, err = protocol.NewSignalMessageFromStruct(signalMessage, serializer.SignalMessage)
+	if !errors.Is(err, signalerror.ErrUnknownMessageVersion) {
+		logger.Error("Expected unknown message version error, got: ", err)
+		t.FailNow()
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/libsignal/kem"
 	"go.mau.fi/libsignal/keys/identity"
 	"go.mau.fi/libsignal/state/record"
 )
@@ -29,7 +30,8 @@ func GenerateIdentityKeyPair() (*identity.KeyPair, error) {
 //
 // PreKeys IDs are shorts, so they will eventually be repeated. Clients
 // should store PreKeys in a circular buffer, so that they are repeated
-// as infrequently as possible.
+// as infrequently as possible. The start should be at least 1, as PreKeys
+// with ID 0 are treated as last resort PreKeys.
 func GeneratePreKeys(start int, count int, serializer record.PreKeySerializer) ([]*record.PreKey, error) {
 	var preKeys []*record.PreKey
 
@@ -47,12 +49,17 @@ func GeneratePreKeys(start int, count int, serializer record.PreKeySerializer) (
 // GenerateLastResortKey will generate the last resort PreKey. Clients should
 // do this only once, at install time, and durably store it for the length
 // of the install.
+//
+// The key is generated with record.LastResortPreKeyID. Older versions used
+// ID 0 (record.LegacyLastResortPreKeyID), and keys with that ID are still
+// treated as last resort keys, so existing installs don't need to generate
+// a new one. One-time PreKeys must therefore not use ID 0.
 func GenerateLastResortKey(serializer record.PreKeySerializer) (*record.PreKey, error) {
 	keyPair, err := ecc.GenerateKeyPair()
 	if err != nil {
 		return nil, err
 	}
-	return record.NewPreKey(0, keyPair, serializer), nil
+	return record.NewPreKey(record.LastResortPreKeyID, keyPair, serializer), nil
 }
 
 // GenerateSignedPreKey generates a signed PreKey.
@@ -67,6 +74,25 @@ func GenerateSignedPreKey(identityKeyPair *identity.KeyPair, signedPreKeyID uint
 	return record.NewSignedPreKey(signedPreKeyID, timestamp, keyPair, signature, serializer), nil
 }
 
+// GenerateKyberPreKey generates a kyber PreKey signed by the identity key.
+func GenerateKyberPreKey(identityKeyPair *identity.KeyPair, kyberPreKeyID uint32, serializer record.KyberPreKeySerializer) (*record.KyberPreKey, error) {
+	keyPair, err := kem.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	signature := ecc.CalculateSignature(identityKeyPair.PrivateKey(), keyPair.PublicKey().Serialize())
+	timestamp := time.Now().Unix()
+
+	return record.NewKyberPreKey(kyberPreKeyID, timestamp, keyPair, signature, serializer), nil
+}
+
+// GenerateLastResortKyberPreKey will generate the last resort kyber PreKey.
+// Like the last resort PreKey, it should be generated only once and durably
+// stored for the length of the install.
+func GenerateLastResortKyberPreKey(identityKeyPair *identity.KeyPair, serializer record.KyberPreKeySerializer) (*record.KyberPreKey, error) {
+	return GenerateKyberPreKey(identityKeyPair, record.LastResortPreKeyID, serializer)
+}
+
 // GenerateRegistrationID generates a registration ID. Clients should only do
 // this once, at install time.
 func GenerateRegistrationID() uint32 {
@@ -76,6 +102,12 @@ func GenerateRegistrationID() uint32 {
 	return n
 }
 
+// GenerateKeyExchangeSequence generates a random sequence number used to
+// match key exchange responses with the key exchange that was initiated.
+func GenerateKeyExchangeSequence() uint32 {
+	return GenerateRegistrationID()%65534 + 1
+}
+
 //---------- Group Stuff ----------------
 
 func GenerateSenderSigningKey() (*ecc.ECKeyPair, error) {
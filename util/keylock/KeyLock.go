@@ -0,0 +1,79 @@
+// Package keylock provides locks that are acquired by key, which are used to
+// serialize operations on the same session while allowing operations on
+// different sessions to run in parallel.
+package keylock
+
+import (
+	"context"
+	"sync"
+)
+
+// Locker is an interface for acquiring exclusive locks by key. Applications
+// that run in multiple processes can implement it with a distributed lock.
+type Locker interface {
+	// Lock blocks until the lock for the given key is acquired or the
+	// context is done. The returned function releases the lock.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// Default is the in-memory locker that is used by session builders, session
+// ciphers and group ciphers that don't have their own locker set.
+var Default Locker = NewLocker()
+
+// NewLocker returns a new in-memory Locker.
+func NewLocker() *InMemoryLocker {
+	return &InMemoryLocker{
+		locks: make(map[string]*keyLock),
+	}
+}
+
+// InMemoryLocker is a Locker that only locks within the current process.
+// Locks are removed once nobody holds or waits for them.
+type InMemoryLocker struct {
+	lock  sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is a lock for a single key along with the number of goroutines
+// that are holding or waiting for it.
+type keyLock struct {
+	held chan struct{}
+	refs int
+}
+
+// Lock blocks until the lock for the given key is acquired or the context
+// is done. The returned function releases the lock.
+func (l *InMemoryLocker) Lock(ctx context.Context, key string) (func(), error) {
+	l.lock.Lock()
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{held: make(chan struct{}, 1)}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.lock.Unlock()
+
+	select {
+	case kl.held <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				<-kl.held
+				l.release(key, kl)
+			})
+		}, nil
+	case <-ctx.Done():
+		l.release(key, kl)
+		return nil, ctx.Err()
+	}
+}
+
+// release will remove the given lock once nobody references it anymore.
+func (l *InMemoryLocker) release(key string, kl *keyLock) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	kl.refs--
+	if kl.refs == 0 {
+		delete(l.locks, key)
+	}
+}